@@ -0,0 +1,134 @@
+package konfig
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrDeprecatedKeyMsg is the message logged when a loader writes a deprecated key
+var ErrDeprecatedKeyMsg = "Key '%s' is deprecated, use '%s' instead"
+
+var (
+	deprecationsMut    sync.Mutex
+	deprecationsWarned = make(map[string]bool)
+)
+
+// RegisterAlias registers a deprecated key on the global store.
+// See Store.RegisterAlias.
+func RegisterAlias(oldKey, newKey string) Store {
+	return instance().RegisterAlias(oldKey, newKey)
+}
+
+// RegisterAlias registers oldKey as a deprecated name of newKey.
+// When a loader writes oldKey, newKey is also set with the same value unless the loader writes newKey too, in which case newKey wins.
+// Both keys can then be read. A deprecation warning is logged the first time oldKey is written, at most once per key per process.
+func (c *store) RegisterAlias(oldKey, newKey string) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.aliases == nil {
+		c.aliases = make(map[string]string)
+	}
+	c.aliases[oldKey] = newKey
+
+	return c
+}
+
+// setAliases sets the new keys of the deprecated keys found in the loaded values x
+func (c *store) setAliases(x Values) {
+	for oldKey, newKey := range c.aliases {
+		var v, ok = x[oldKey]
+		if !ok {
+			continue
+		}
+
+		c.warnDeprecated(oldKey, newKey)
+
+		if _, ok := x[newKey]; !ok {
+			x[newKey] = v
+		}
+	}
+}
+
+func (c *store) warnDeprecated(oldKey, newKey string) {
+	deprecationsMut.Lock()
+	defer deprecationsMut.Unlock()
+
+	if deprecationsWarned[oldKey] {
+		return
+	}
+	deprecationsWarned[oldKey] = true
+
+	c.cfg.Logger.Get().Warn(fmt.Sprintf(ErrDeprecatedKeyMsg, oldKey, newKey))
+}
+
+// RegisterAliases registers keys as aliases of each other on the global store.
+// See Store.RegisterAliases.
+func RegisterAliases(keys ...string) Store {
+	return instance().RegisterAliases(keys...)
+}
+
+// RegisterAliases registers keys as names of a same value. Reading any of the keys returns the value,
+// writing any of the keys, with Set or from a loader, sets all of them. Unlike RegisterAlias, no warning is logged.
+// If different loaders write different aliases, the value of the last loaded loader wins.
+// If a single loader writes several aliases with different values, the first key in registration order wins.
+func (c *store) RegisterAliases(keys ...string) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.aliasGroups == nil {
+		c.aliasGroups = make(map[string][]string)
+	}
+
+	// merge the groups of the keys which are already aliased
+	var group []string
+	var seen = make(map[string]bool)
+	for _, k := range keys {
+		var g, ok = c.aliasGroups[k]
+		if !ok {
+			g = []string{k}
+		}
+		for _, kk := range g {
+			if !seen[kk] {
+				seen[kk] = true
+				group = append(group, kk)
+			}
+		}
+	}
+
+	for _, k := range group {
+		c.aliasGroups[k] = group
+	}
+
+	return c
+}
+
+// aliasKeys returns the keys aliased with k, including k
+func (c *store) aliasKeys(k string) []string {
+	if g, ok := c.aliasGroups[k]; ok {
+		return g
+	}
+	return []string{k}
+}
+
+// setAliasGroups sets all the aliases of the aliased keys found in the loaded values x
+func (c *store) setAliasGroups(x Values) {
+	var done = make(map[string]bool)
+	for k := range x {
+		var g, ok = c.aliasGroups[k]
+		if !ok || done[g[0]] {
+			continue
+		}
+		done[g[0]] = true
+
+		// the first key of the group written by the loader wins
+		for _, kk := range g {
+			if v, ok := x[kk]; ok {
+				for _, ak := range g {
+					x[ak] = v
+				}
+				break
+			}
+		}
+	}
+}
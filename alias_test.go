@@ -0,0 +1,149 @@
+package konfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/nui/nlogger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	t.Run(
+		"old key populates new key and warns once",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var buf bytes.Buffer
+			SetLogger(nlogger.New(&buf, ""))
+
+			RegisterAlias("database.hostname", "db.host")
+			var l = &DummyLoader{
+				DataToLoad: [][2]string{
+					{"database.hostname", "localhost"},
+				},
+			}
+			RegisterLoader(l)
+
+			require.Nil(t, Load())
+			require.Equal(t, "localhost", Get("db.host"))
+			require.Equal(t, "localhost", Get("database.hostname"))
+
+			require.Nil(t, Load())
+			require.Equal(t, 1, strings.Count(buf.String(), "Key 'database.hostname' is deprecated, use 'db.host' instead"))
+
+			// the new key is removed along with the old key
+			l.DataToLoad = nil
+			require.Nil(t, Load())
+			require.False(t, Exists("db.host"))
+		},
+	)
+
+	t.Run(
+		"new key wins",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterAlias("old.port", "new.port")
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"old.port", "8080"},
+						{"new.port", "9090"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "9090", Get("new.port"))
+			require.Equal(t, "8080", Get("old.port"))
+		},
+	)
+}
+
+func TestRegisterAliases(t *testing.T) {
+	t.Run(
+		"loader write sets all aliases",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterAliases("db.host", "database.host")
+			RegisterAliases("database.host", "pg.host")
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"pg.host", "localhost"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "localhost", Get("db.host"))
+			require.Equal(t, "localhost", Get("database.host"))
+			require.Equal(t, "localhost", Get("pg.host"))
+		},
+	)
+
+	t.Run(
+		"set updates all aliases",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterAliases("a", "b")
+			Set("b", "foo")
+			require.Equal(t, "foo", Get("a"))
+			require.Equal(t, "foo", Get("b"))
+		},
+	)
+
+	t.Run(
+		"precedence",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterAliases("a", "b")
+
+			// the first key in registration order wins within a loader
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"b", "first"},
+						{"a", "second"},
+					},
+				},
+			)
+			// the last loaded loader wins between loaders
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"b", "third"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "third", Get("a"))
+			require.Equal(t, "third", Get("b"))
+
+			var s = New(DefaultConfig())
+			s.RegisterAliases("a", "b")
+			s.RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"b", "first"},
+						{"a", "second"},
+					},
+				},
+			)
+			require.Nil(t, s.Load())
+			require.Equal(t, "second", s.Get("b"))
+		},
+	)
+}
@@ -0,0 +1,160 @@
+package konfig
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultJitterFactor is the default fraction of the delay randomized by a JitterBackoff
+const DefaultJitterFactor = 0.5
+
+var (
+	_ Backoff = ConstantBackoff{}
+	_ Backoff = ExponentialBackoff{}
+	_ Backoff = (*JitterBackoff)(nil)
+)
+
+// Backoff computes the delays between the attempts of an operation: the retries of a load, the restarts of a watcher after a panic
+// or the reconnections of a streaming loader. A Backoff can be shared by several operations, it must be safe for concurrent use.
+type Backoff interface {
+	// NextDelay returns the delay before the attempt, attempts start at 1 after the first failure
+	NextDelay(attempt int) time.Duration
+	// Reset is called once the operation succeeded, so that stateful implementations can reset their state
+	Reset()
+}
+
+// ConstantBackoff is a Backoff waiting the same delay before each attempt
+type ConstantBackoff struct {
+	// Delay is the delay before each attempt
+	Delay time.Duration
+}
+
+// NextDelay implements Backoff, it returns the Delay
+func (b ConstantBackoff) NextDelay(attempt int) time.Duration {
+	return b.Delay
+}
+
+// Reset implements Backoff, a ConstantBackoff has no state
+func (b ConstantBackoff) Reset() {}
+
+// ExponentialBackoff is a Backoff multiplying the delay by Multiplier after each attempt, starting at Initial, up to Max
+type ExponentialBackoff struct {
+	// Initial is the delay before the first attempt
+	Initial time.Duration
+	// Max is the maximum delay, if zero the delay is not capped
+	Max time.Duration
+	// Multiplier is the factor the delay is multiplied by after each attempt, default is 2
+	Multiplier float64
+}
+
+// NextDelay implements Backoff, it returns Initial * Multiplier^(attempt-1) capped to Max
+func (b ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	var m = b.Multiplier
+	if m == 0 {
+		m = 2
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	var d = float64(b.Initial) * math.Pow(m, float64(attempt-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		return b.Max
+	}
+	if d > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// Reset implements Backoff, an ExponentialBackoff has no state
+func (b ExponentialBackoff) Reset() {}
+
+// JitterBackoff is a Backoff randomizing the delays of another Backoff, so that clients failing at the same time do not retry at the same time
+type JitterBackoff struct {
+	// Backoff is the Backoff whose delays are randomized
+	Backoff Backoff
+	// Factor is the fraction of the delay which is randomized, the delay is between (1 - Factor) * delay and delay.
+	// Default is DefaultJitterFactor.
+	Factor float64
+
+	mut  sync.Mutex
+	rand *rand.Rand
+}
+
+// NewJitterBackoff returns a new JitterBackoff randomizing the delays of b by the factor f
+func NewJitterBackoff(b Backoff, f float64) *JitterBackoff {
+	return &JitterBackoff{
+		Backoff: b,
+		Factor:  f,
+	}
+}
+
+// NextDelay implements Backoff, it returns the delay of the wrapped Backoff reduced by a random fraction up to Factor
+func (b *JitterBackoff) NextDelay(attempt int) time.Duration {
+	var d = b.Backoff.NextDelay(attempt)
+	var f = b.Factor
+	if f <= 0 || f > 1 {
+		f = DefaultJitterFactor
+	}
+
+	b.mut.Lock()
+	if b.rand == nil {
+		b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	var r = b.rand.Float64()
+	b.mut.Unlock()
+
+	return d - time.Duration(float64(d)*f*r)
+}
+
+// Reset implements Backoff, it resets the wrapped Backoff
+func (b *JitterBackoff) Reset() {
+	b.Backoff.Reset()
+}
+
+// BackoffLoader is an optional interface a Loader can implement to set the Backoff between the retries of its loads,
+// it overrides the Backoff of the store config and RetryDelay
+type BackoffLoader interface {
+	Backoff() Backoff
+}
+
+// retryBackoff returns the Backoff between the retries of the loads of wl
+func (c *store) retryBackoff(wl *loaderWatcher) Backoff {
+	if bl, ok := loaderBackoff(wl.Loader); ok {
+		if b := bl.Backoff(); b != nil {
+			return b
+		}
+	}
+	if c.cfg.Backoff != nil {
+		return c.cfg.Backoff
+	}
+	return ConstantBackoff{Delay: wl.RetryDelay()}
+}
+
+// resetRetryBackoff resets the Backoff between the retries of the loads of wl once a load succeeded after retries,
+// the default ConstantBackoff has no state
+func (c *store) resetRetryBackoff(wl *loaderWatcher) {
+	if bl, ok := loaderBackoff(wl.Loader); ok {
+		if b := bl.Backoff(); b != nil {
+			b.Reset()
+			return
+		}
+	}
+	if c.cfg.Backoff != nil {
+		c.cfg.Backoff.Reset()
+	}
+}
+
+// loaderBackoff returns l as a BackoffLoader, unwrapping loaders wrapped by a loaderWatcher
+func loaderBackoff(l Loader) (BackoffLoader, bool) {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return loaderBackoff(lt.Loader)
+	case BackoffLoader:
+		return lt, true
+	}
+	return nil, false
+}
@@ -0,0 +1,171 @@
+package konfig
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RecordingBackoff is a Backoff recording the attempts it is asked the delay of
+type RecordingBackoff struct {
+	mut      sync.Mutex
+	attempts []int
+	resets   int
+}
+
+func (b *RecordingBackoff) NextDelay(attempt int) time.Duration {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.attempts = append(b.attempts, attempt)
+	return time.Millisecond
+}
+
+func (b *RecordingBackoff) Reset() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.resets++
+}
+
+type BackoffFlakyLoader struct {
+	FlakyLoader
+	backoff Backoff
+}
+
+func (b *BackoffFlakyLoader) Backoff() Backoff {
+	return b.backoff
+}
+
+func TestConstantBackoff(t *testing.T) {
+	var b = ConstantBackoff{Delay: time.Second}
+	require.Equal(t, time.Second, b.NextDelay(1))
+	require.Equal(t, time.Second, b.NextDelay(10))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		backoff  ExponentialBackoff
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			name:     "first attempt",
+			backoff:  ExponentialBackoff{Initial: time.Second},
+			attempt:  1,
+			expected: time.Second,
+		},
+		{
+			name:     "doubles by default",
+			backoff:  ExponentialBackoff{Initial: 10 * time.Millisecond},
+			attempt:  4,
+			expected: 80 * time.Millisecond,
+		},
+		{
+			name:     "multiplier",
+			backoff:  ExponentialBackoff{Initial: time.Second, Multiplier: 1.5},
+			attempt:  3,
+			expected: 2250 * time.Millisecond,
+		},
+		{
+			name:     "capped to max",
+			backoff:  ExponentialBackoff{Initial: time.Second, Max: time.Minute},
+			attempt:  20,
+			expected: time.Minute,
+		},
+		{
+			name:     "no overflow",
+			backoff:  ExponentialBackoff{Initial: time.Second},
+			attempt:  100,
+			expected: time.Duration(math.MaxInt64),
+		},
+		{
+			name:     "attempt before the first",
+			backoff:  ExponentialBackoff{Initial: time.Second},
+			attempt:  0,
+			expected: time.Second,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, testCase.backoff.NextDelay(testCase.attempt))
+		})
+	}
+}
+
+func TestJitterBackoff(t *testing.T) {
+	var b = NewJitterBackoff(ConstantBackoff{Delay: time.Second}, 0.2)
+	for i := 1; i < 100; i++ {
+		var d = b.NextDelay(i)
+		require.True(t, d > 800*time.Millisecond && d <= time.Second, d)
+	}
+
+	// the default factor is used for an invalid factor
+	b = NewJitterBackoff(ConstantBackoff{Delay: time.Second}, 2)
+	for i := 1; i < 100; i++ {
+		var d = b.NextDelay(i)
+		require.True(t, d > 500*time.Millisecond && d <= time.Second, d)
+	}
+
+	var rb = &RecordingBackoff{}
+	b = NewJitterBackoff(rb, 0)
+	b.NextDelay(3)
+	b.Reset()
+	require.Equal(t, []int{3}, rb.attempts)
+	require.Equal(t, 1, rb.resets)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Run(
+		"config backoff",
+		func(t *testing.T) {
+			var b = &RecordingBackoff{}
+			var c = New(&Config{Backoff: b, NoExitOnError: true})
+			c.RegisterLoader(&FlakyLoader{MapLoader: MapLoader{name: "vault", values: Values{"foo": "bar"}}, failures: 2})
+
+			require.Nil(t, c.Load())
+			require.Equal(t, []int{1, 2}, b.attempts)
+			require.Equal(t, 1, b.resets)
+			require.Equal(t, "bar", c.Get("foo"))
+		},
+	)
+
+	t.Run(
+		"loader backoff overrides the config backoff",
+		func(t *testing.T) {
+			var b, lb = &RecordingBackoff{}, &RecordingBackoff{}
+			var c = New(&Config{Backoff: b, NoExitOnError: true})
+			c.RegisterLoader(&BackoffFlakyLoader{
+				FlakyLoader: FlakyLoader{MapLoader: MapLoader{name: "vault", values: Values{"foo": "bar"}}, failures: 1},
+				backoff:     lb,
+			})
+
+			require.Nil(t, c.Load())
+			require.Empty(t, b.attempts)
+			require.Equal(t, []int{1}, lb.attempts)
+			require.Equal(t, 1, lb.resets)
+		},
+	)
+
+	t.Run(
+		"retry delay by default",
+		func(t *testing.T) {
+			var c = New(&Config{}).(*store)
+			var wl = &loaderWatcher{Loader: &DummyLoader{}}
+			require.Equal(t, ConstantBackoff{}, c.retryBackoff(wl))
+		},
+	)
+
+	t.Run(
+		"watcher restarts",
+		func(t *testing.T) {
+			var b = &RecordingBackoff{}
+			var c = New(&Config{Backoff: b}).(*store)
+			require.Equal(t, time.Millisecond, c.watcherRestartDelay(2))
+			require.Equal(t, []int{3}, b.attempts)
+		},
+	)
+}
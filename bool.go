@@ -0,0 +1,78 @@
+package konfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrBoolMsg is the error message returned when a string is not a boolean token
+	ErrBoolMsg = "Err invalid boolean '%s'"
+	// ErrBoolTokenMsg is the error message returned when a token is both a true and a false boolean token
+	ErrBoolTokenMsg = "Err boolean token '%s' is both true and false"
+)
+
+// BoolTokens are the strings parsed as booleans, they are case insensitive and surrounding spaces are ignored
+type BoolTokens struct {
+	// True are the tokens parsed as true
+	True []string
+	// False are the tokens parsed as false
+	False []string
+}
+
+// DefaultBoolTokens are the tokens used when the config of a store has no BoolTokens,
+// the tokens of strconv.ParseBool and the common yes/no and on/off variants
+var DefaultBoolTokens = BoolTokens{
+	True:  []string{"1", "t", "true", "y", "yes", "on"},
+	False: []string{"0", "f", "false", "n", "no", "off"},
+}
+
+// validate returns an error if a token is both true and false
+func (bt *BoolTokens) validate() error {
+	var trues = make(map[string]bool, len(bt.True))
+	for _, t := range bt.True {
+		trues[normalizeBoolToken(t)] = true
+	}
+	for _, f := range bt.False {
+		if trues[normalizeBoolToken(f)] {
+			return fmt.Errorf(ErrBoolTokenMsg, f)
+		}
+	}
+	return nil
+}
+
+// boolParser maps the normalized boolean tokens to their value
+type boolParser map[string]bool
+
+func newBoolParser(bt *BoolTokens) boolParser {
+	if bt == nil {
+		bt = &DefaultBoolTokens
+	}
+	var bp = make(boolParser, len(bt.True)+len(bt.False))
+	for _, t := range bt.True {
+		bp[normalizeBoolToken(t)] = true
+	}
+	for _, f := range bt.False {
+		bp[normalizeBoolToken(f)] = false
+	}
+	return bp
+}
+
+// parse converts v to a bool, strings are looked up in the tokens and other values are converted with cast.ToBoolE
+func (bp boolParser) parse(v interface{}) (bool, error) {
+	var str, ok = v.(string)
+	if !ok {
+		return cast.ToBoolE(v)
+	}
+	b, ok := bp[normalizeBoolToken(str)]
+	if !ok {
+		return false, fmt.Errorf(ErrBoolMsg, str)
+	}
+	return b, nil
+}
+
+func normalizeBoolToken(t string) string {
+	return strings.ToLower(strings.TrimSpace(t))
+}
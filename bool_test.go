@@ -0,0 +1,105 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoolParser(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		tokens    *BoolTokens
+		value     interface{}
+		expected  bool
+		errString string
+	}{
+		{name: "true", value: "true", expected: true},
+		{name: "yes", value: "Yes", expected: true},
+		{name: "on", value: " ON ", expected: true},
+		{name: "one", value: "1", expected: true},
+		{name: "off", value: "off", expected: false},
+		{name: "no", value: "N", expected: false},
+		{name: "bool", value: true, expected: true},
+		{name: "int", value: 0, expected: false},
+		{name: "unknown token", value: "enabled", errString: "Err invalid boolean 'enabled'"},
+		{name: "empty", value: "", errString: "Err invalid boolean ''"},
+		{
+			name:     "custom tokens",
+			tokens:   &BoolTokens{True: []string{"Enabled"}, False: []string{"disabled"}},
+			value:    "ENABLED",
+			expected: true,
+		},
+		{
+			name:      "custom tokens replace the defaults",
+			tokens:    &BoolTokens{True: []string{"enabled"}, False: []string{"disabled"}},
+			value:     "yes",
+			errString: "Err invalid boolean 'yes'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var b, err = newBoolParser(testCase.tokens).parse(testCase.value)
+			if testCase.errString != "" {
+				require.NotNil(t, err)
+				require.Equal(t, testCase.errString, err.Error())
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, b)
+		})
+	}
+}
+
+func TestBoolTokens(t *testing.T) {
+	t.Run(
+		"getters",
+		func(t *testing.T) {
+			type App struct {
+				Debug bool `konfig:"debug"`
+			}
+
+			reset()
+			Init(DefaultConfig())
+			Bind(App{})
+			RegisterType("feature", false)
+
+			RegisterLoader(&MapLoader{name: "env", values: Values{
+				"debug":   "yes",
+				"feature": "on",
+				"invalid": "enabled",
+			}})
+			require.Nil(t, Load())
+
+			require.True(t, Bool("debug"))
+			require.True(t, MustBool("debug"))
+			require.Equal(t, true, Get("feature"))
+			require.Equal(t, App{Debug: true}, Value())
+			require.False(t, Bool("invalid"))
+
+			var _, err = RequireBool("invalid")
+			require.Equal(t, "Err config 'invalid' cannot be converted to bool: Err invalid boolean 'enabled'", err.Error())
+		},
+	)
+
+	t.Run(
+		"typed key with an invalid token fails the load",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			RegisterType("feature", false)
+
+			RegisterLoader(&MapLoader{name: "env", values: Values{"feature": "enabled"}})
+			require.NotNil(t, Load())
+		},
+	)
+
+	t.Run(
+		"conflicting tokens",
+		func(t *testing.T) {
+			var _, err = NewE(&Config{BoolTokens: &BoolTokens{True: []string{"x"}, False: []string{"X"}}})
+			require.Equal(t, "Err boolean token 'X' is both true and false", err.Error())
+		},
+	)
+}
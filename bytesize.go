@@ -0,0 +1,115 @@
+package konfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrByteSize is the error returned when a string is not a valid byte size
+	ErrByteSize = errors.New("Err invalid byte size")
+)
+
+// byteUnits are the multipliers of the byte size units, decimal units are powers of 1000 and binary units powers of 1024
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"k":   1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pib": 1 << 50,
+}
+
+// ParseByteSize parses a human friendly byte size such as "512", "10KB", "1.5GB" or "2MiB" and returns the number of bytes.
+// Units are case insensitive, decimal units (KB, MB, GB, TB, PB) are powers of 1000 and binary units (KiB, MiB, GiB, TiB, PiB) are powers of 1024.
+// Single letter units (K, M, G, T, P) are binary, like in Kubernetes and JVM flags. A number without unit is a number of bytes.
+func ParseByteSize(s string) (int64, error) {
+	var str = strings.TrimSpace(s)
+	var i = strings.IndexFunc(str, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(str)
+	}
+
+	var n, err = strconv.ParseFloat(str[:i], 64)
+	if err != nil || n < 0 {
+		return 0, ErrByteSize
+	}
+
+	var unit, ok = byteUnits[strings.ToLower(strings.TrimSpace(str[i:]))]
+	if !ok {
+		return 0, ErrByteSize
+	}
+
+	var b = n * unit
+	if b > math.MaxInt64 {
+		return 0, ErrByteSize
+	}
+	return int64(b), nil
+}
+
+// MustByteSize gets the config k and converts it to a byte count with ParseByteSize
+// it panics if it fails.
+func MustByteSize(k string) int64 {
+	return instance().MustByteSize(k)
+}
+func (c *store) MustByteSize(k string) int64 {
+	var b, _ = toByteSize(c.MustGet(k))
+	return b
+}
+
+// ByteSize gets the config k and converts it to a byte count.
+// Strings are parsed with ParseByteSize, other values are converted to an int64.
+// It returns the zero value if it doesn't find the config or if the value is not a valid byte size.
+func ByteSize(k string) int64 {
+	return instance().ByteSize(k)
+}
+func (c *store) ByteSize(k string) int64 {
+	var b, _ = toByteSize(c.Get(k))
+	return b
+}
+
+// RequireByteSize gets the config k from the global store and converts it to a byte count.
+// See Store.RequireByteSize.
+func RequireByteSize(k string) (int64, error) {
+	return instance().RequireByteSize(k)
+}
+
+// RequireByteSize gets the config k and converts it to a byte count,
+// it returns an error if the key does not exist or the value is not a valid byte size.
+func (c *store) RequireByteSize(k string) (int64, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return 0, err
+	}
+	b, err := toByteSize(v)
+	if err != nil {
+		return 0, fmt.Errorf(ErrRequireTypeMsg, k, "byte size", err)
+	}
+	return b, nil
+}
+
+func toByteSize(v interface{}) (int64, error) {
+	if str, ok := v.(string); ok {
+		return ParseByteSize(str)
+	}
+	return cast.ToInt64E(v)
+}
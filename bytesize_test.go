@@ -0,0 +1,68 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	var testCases = []struct {
+		value    string
+		expected int64
+		err      bool
+	}{
+		{value: "512", expected: 512},
+		{value: "512B", expected: 512},
+		{value: "10KB", expected: 10000},
+		{value: "10kb", expected: 10000},
+		{value: "2MiB", expected: 2 << 20},
+		{value: "1GB", expected: 1000000000},
+		{value: "1.5GiB", expected: 3 << 29},
+		{value: "4G", expected: 4 << 30},
+		{value: " 3 TB ", expected: 3000000000000},
+		{value: "1PiB", expected: 1 << 50},
+		{value: "", err: true},
+		{value: "MB", err: true},
+		{value: "10 parsecs", err: true},
+		{value: "-1KB", err: true},
+		{value: "1.2.3KB", err: true},
+		{value: "100000PB", err: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.value, func(t *testing.T) {
+			var b, err = ParseByteSize(testCase.value)
+			if testCase.err {
+				require.Equal(t, ErrByteSize, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, b)
+		})
+	}
+}
+
+func TestByteSizeGetters(t *testing.T) {
+	reset()
+	Set("limits.max_body_size", "10MB")
+	Set("limits.cache_size", 2048)
+	Set("limits.invalid", "10 parsecs")
+
+	require.Equal(t, int64(10000000), ByteSize("limits.max_body_size"))
+	require.Equal(t, int64(10000000), MustByteSize("limits.max_body_size"))
+	require.Equal(t, int64(2048), ByteSize("limits.cache_size"))
+	require.Equal(t, int64(0), ByteSize("limits.invalid"))
+	require.Equal(t, int64(0), ByteSize("nope"))
+	require.Panics(t, func() { MustByteSize("nope") })
+
+	var b, err = RequireByteSize("limits.max_body_size")
+	require.Nil(t, err)
+	require.Equal(t, int64(10000000), b)
+
+	_, err = RequireByteSize("limits.invalid")
+	require.Equal(t, "Err config 'limits.invalid' cannot be converted to byte size: Err invalid byte size", err.Error())
+
+	_, err = RequireByteSize("nope")
+	require.NotNil(t, err)
+}
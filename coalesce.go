@@ -0,0 +1,91 @@
+package konfig
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// hookCoalescer coalesces the runs of the store hooks of the reloads happening within the HookCoalesceWindow of the config
+type hookCoalescer struct {
+	mut     sync.Mutex
+	timer   *time.Timer
+	changed map[string]struct{}
+}
+
+// ChangedKeys returns the keys changed by the loads the running hooks are run for, from the global store.
+// See Store.ChangedKeys.
+func ChangedKeys() []string {
+	return instance().ChangedKeys()
+}
+
+// ChangedKeys returns the sorted keys changed by the loads the running hooks are run for, it is meant to be called from hooks.
+// Hooks run after the load of a single loader, unless the store hooks of several reloads are coalesced
+// with HookCoalesceWindow, in which case it returns the union of the keys changed by the coalesced reloads.
+func (c *store) ChangedKeys() []string {
+	var keys, _ = c.hookChanged.Load().([]string)
+	return keys
+}
+
+// setHookChanged sets the keys returned by ChangedKeys while hooks run, it must be called with the store mutex locked
+func (c *store) setHookChanged(keys []string) {
+	c.hookChanged.Store(keys)
+}
+
+// coalesceHooks schedules a run of the store hooks at the end of the coalescing window,
+// the keys changed are added to the keys of the runs already scheduled in the window
+func (c *store) coalesceHooks(changed []string) {
+	var hc = c.coalescer
+	hc.mut.Lock()
+	defer hc.mut.Unlock()
+
+	if hc.changed == nil {
+		hc.changed = make(map[string]struct{}, len(changed))
+	}
+	for _, k := range changed {
+		hc.changed[k] = struct{}{}
+	}
+
+	if hc.timer == nil {
+		hc.timer = time.AfterFunc(c.cfg.HookCoalesceWindow, c.runCoalescedHooks)
+	}
+}
+
+// runCoalescedHooks runs the store hooks once for all the reloads coalesced in the window
+func (c *store) runCoalescedHooks() {
+	var hc = c.coalescer
+	hc.mut.Lock()
+	var keys = make([]string, 0, len(hc.changed))
+	for k := range hc.changed {
+		keys = append(keys, k)
+	}
+	hc.changed = nil
+	hc.timer = nil
+	hc.mut.Unlock()
+
+	sort.Strings(keys)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.hooks == nil {
+		return
+	}
+	c.setHookChanged(keys)
+	if err := c.runHooks(c.hooks); err != nil {
+		c.cfg.Logger.Get().Error("Error while running store hooks: " + err.Error())
+	}
+}
+
+// stopCoalescing cancels the run of the store hooks scheduled, if any
+func (c *store) stopCoalescing() {
+	var hc = c.coalescer
+	hc.mut.Lock()
+	defer hc.mut.Unlock()
+
+	if hc.timer != nil {
+		hc.timer.Stop()
+		hc.timer = nil
+	}
+	hc.changed = nil
+}
@@ -0,0 +1,116 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookCoalescing(t *testing.T) {
+	var setup = func(window time.Duration) (*MapLoader, *MapLoader, chan []string) {
+		reset()
+		var cfg = DefaultConfig()
+		cfg.HookCoalesceWindow = window
+		Init(cfg)
+
+		var a = &MapLoader{name: "file", values: Values{"foo": "a"}}
+		var b = &MapLoader{name: "vault", values: Values{"bar": "b"}}
+		RegisterLoader(a)
+		RegisterLoader(b)
+
+		var runs = make(chan []string, 10)
+		RegisterHook(func(s Store) error {
+			runs <- s.ChangedKeys()
+			return nil
+		})
+		return a, b, runs
+	}
+
+	var waitRun = func(t *testing.T, runs chan []string) []string {
+		select {
+		case keys := <-runs:
+			return keys
+		case <-time.After(2 * time.Second):
+			t.Fatal("hooks did not run")
+		}
+		return nil
+	}
+
+	t.Run(
+		"disabled by default",
+		func(t *testing.T) {
+			var a, b, runs = setup(0)
+
+			require.Nil(t, Load())
+			require.Equal(t, []string{"foo"}, waitRun(t, runs))
+			require.Equal(t, []string{"bar"}, waitRun(t, runs))
+
+			a.values = Values{"foo": "aa"}
+			b.values = Values{"bar": "bb"}
+			require.Nil(t, ReloadLoader("file"))
+			require.Nil(t, ReloadLoader("vault"))
+			require.Equal(t, []string{"foo"}, waitRun(t, runs))
+			require.Equal(t, []string{"bar"}, waitRun(t, runs))
+		},
+	)
+
+	t.Run(
+		"reloads within the window run the hooks once",
+		func(t *testing.T) {
+			var a, b, runs = setup(100 * time.Millisecond)
+
+			// the hooks of Load are not coalesced
+			require.Nil(t, Load())
+			require.Len(t, runs, 2)
+			<-runs
+			<-runs
+
+			a.values = Values{"foo": "aa"}
+			b.values = Values{"bar": "bb", "baz": "b"}
+			require.Nil(t, ReloadLoader("file"))
+			require.Nil(t, ReloadLoader("vault"))
+			require.Len(t, runs, 0)
+
+			require.Equal(t, []string{"bar", "baz", "foo"}, waitRun(t, runs))
+			select {
+			case <-runs:
+				t.Fatal("hooks ran twice")
+			case <-time.After(200 * time.Millisecond):
+			}
+
+			// a new window starts with the next reload
+			a.values = Values{"foo": "aaa"}
+			require.Nil(t, ReloadLoader("file"))
+			require.Equal(t, []string{"foo"}, waitRun(t, runs))
+		},
+	)
+
+	t.Run(
+		"reset cancels the scheduled run",
+		func(t *testing.T) {
+			var _, _, runs = setup(100 * time.Millisecond)
+
+			require.Nil(t, Load())
+			<-runs
+			<-runs
+
+			require.Nil(t, ReloadLoader("file"))
+			require.Nil(t, Reset())
+
+			select {
+			case <-runs:
+				t.Fatal("hooks ran after reset")
+			case <-time.After(200 * time.Millisecond):
+			}
+		},
+	)
+
+	t.Run(
+		"invalid window",
+		func(t *testing.T) {
+			var _, err = NewE(&Config{HookCoalesceWindow: -1})
+			require.NotNil(t, err)
+		},
+	)
+}
@@ -0,0 +1,97 @@
+package konfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrCodecMsg is the error message returned when a value cannot be decoded by the codec registered for its key
+	ErrCodecMsg = "Err decoding key '%s' with value '%v': %v"
+)
+
+// Codec decodes a raw value, usually a human friendly string such as "30s" or "10MB", into a typed value
+type Codec func(interface{}) (interface{}, error)
+
+// DurationCodec is a Codec decoding values to a time.Duration, strings are parsed with time.ParseDuration
+func DurationCodec(v interface{}) (interface{}, error) {
+	return cast.ToDurationE(v)
+}
+
+// ByteSizeCodec is a Codec decoding values to an int64 byte count, strings are parsed with ParseByteSize
+func ByteSizeCodec(v interface{}) (interface{}, error) {
+	return toByteSize(v)
+}
+
+// keyCodec is a codec registered for a key pattern
+type keyCodec struct {
+	pattern string
+	codec   Codec
+}
+
+// RegisterCodec registers a codec for the keys matching the pattern on the global store.
+// See Store.RegisterCodec.
+func RegisterCodec(pattern string, codec Codec) Store {
+	return instance().RegisterCodec(pattern, codec)
+}
+
+// RegisterCodec registers a codec decoding the values of the keys matching the pattern when they are loaded.
+// The pattern is matched against each segment of the key separated by KeySep,
+// a segment of the pattern is either a literal or a path.Match pattern (e.g. "*.timeout" matches "http.timeout" and "db.timeout", "limits.*_size" matches "limits.max_body_size").
+// If several patterns match a key, the first registered codec is used.
+// Values are decoded after the loader transforms, a value which cannot be decoded fails the load of the loader with an error naming the key and the raw value.
+// Values set directly on the store with Set are not decoded.
+func (c *store) RegisterCodec(pattern string, codec Codec) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.codecs = append(c.codecs, keyCodec{pattern: pattern, codec: codec})
+	return c
+}
+
+// decode decodes the values in v with the registered codecs
+func (c *store) decode(v Values) (Values, error) {
+	c.mut.Lock()
+	var codecs = c.codecs
+	c.mut.Unlock()
+
+	if len(codecs) == 0 {
+		return v, nil
+	}
+
+	for k, vv := range v {
+		for _, kc := range codecs {
+			if !matchKey(kc.pattern, k) {
+				continue
+			}
+			var dv, err = kc.codec(vv)
+			if err != nil {
+				return nil, fmt.Errorf(ErrCodecMsg, k, vv, err)
+			}
+			v[k] = dv
+			break
+		}
+	}
+	return v, nil
+}
+
+// matchKey tells wether the key k matches the pattern p segment by segment
+func matchKey(p, k string) bool {
+	if p == k {
+		return true
+	}
+
+	var ps, ks = strings.Split(p, KeySep), strings.Split(k, KeySep)
+	if len(ps) != len(ks) {
+		return false
+	}
+	for i := range ps {
+		if ok, err := path.Match(ps[i], ks[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchKey(t *testing.T) {
+	var testCases = []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{pattern: "http.timeout", key: "http.timeout", match: true},
+		{pattern: "*.timeout", key: "http.timeout", match: true},
+		{pattern: "*.timeout", key: "http.client.timeout"},
+		{pattern: "*.*.timeout", key: "http.client.timeout", match: true},
+		{pattern: "limits.*_size", key: "limits.max_body_size", match: true},
+		{pattern: "limits.*_size", key: "limits.max_body"},
+		{pattern: "limits.[", key: "limits.foo"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.pattern+" "+testCase.key, func(t *testing.T) {
+			require.Equal(t, testCase.match, matchKey(testCase.pattern, testCase.key))
+		})
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	RegisterCodec("*.timeout", DurationCodec)
+	RegisterCodec("limits.*_size", ByteSizeCodec)
+	RegisterCodec("*.*", func(v interface{}) (interface{}, error) { return "shadowed", nil })
+
+	var l = &MapLoader{
+		name: "l1",
+		values: Values{
+			"http.timeout":         "30s",
+			"limits.max_body_size": "10MB",
+			"limits.cache_size":    1024,
+			"name":                 "app",
+		},
+	}
+	RegisterLoader(l)
+	require.Nil(t, Load())
+
+	require.Equal(t, 30*time.Second, MustGet("http.timeout"))
+	require.Equal(t, int64(10000000), MustGet("limits.max_body_size"))
+	require.Equal(t, int64(1024), MustGet("limits.cache_size"))
+	require.Equal(t, "app", MustGet("name"))
+
+	// values set directly are not decoded
+	Set("http.timeout", "1m")
+	require.Equal(t, "1m", MustGet("http.timeout"))
+
+	// a value which cannot be decoded fails the load
+	l.values = Values{
+		"limits.max_body_size": "10 parsecs",
+	}
+	var err = Load()
+	require.NotNil(t, err)
+	require.Equal(t, "Err decoding key 'limits.max_body_size' with value '10 parsecs': Err invalid byte size", err.Error())
+
+	// the dry run decodes the values too
+	l.values = Values{
+		"http.timeout": "5s",
+	}
+	var r = DryRun(context.Background())
+	require.Empty(t, r.Errors)
+	require.Equal(t, 5*time.Second, r.Values["http.timeout"])
+}
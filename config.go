@@ -1,6 +1,8 @@
 package konfig
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/lalamove/nui/ngetter"
 	"github.com/lalamove/nui/nlogger"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -26,6 +29,10 @@ var (
 	ErrConfigNotFoundMsg = "Err config '%s' not found"
 	// ErrStrictKeyNotFoundMsg is the error returned when a strict key is not found in the konfig store
 	ErrStrictKeyNotFoundMsg = "Err strict key '%s' not found"
+	// ErrNilConfig is the error returned by InitE and NewE when the config is nil
+	ErrNilConfig = errors.New("Err nil config")
+	// ErrInvalidConfigMsg is the error message returned by InitE and NewE when a setting of the config is invalid
+	ErrInvalidConfigMsg = "Err invalid config, %s must not be negative: %v"
 )
 
 const (
@@ -65,6 +72,49 @@ type Config struct {
 	Logger nlogger.Provider
 	// Metrics sets whether a konfig.Store should record metrics for config loaders
 	Metrics bool
+	// MetricsRegisterer is the prometheus registerer the metrics are registered with when the store starts watching,
+	// default is prometheus.DefaultRegisterer
+	MetricsRegisterer prometheus.Registerer
+	// MetricsOptions sets the namespace, the subsystem and constant labels of the metrics, they are validated when the store is created
+	MetricsOptions MetricsOptions
+	// Templates enables rendering string values containing "{{" as Go templates after each load.
+	// Templates are executed with all the values of the store as context and the rendered result is stored instead of the template.
+	Templates bool
+	// References enables resolving references of the form "${ref:key}" in string values to the value of key after each load.
+	// References are resolved before templates, a value made of a single reference keeps the type of the referenced value.
+	References bool
+	// ListSeparator is the separator string values are split on when they are read as slices, e.g. "," for HOSTS=a,b,c loaded from the environment.
+	// If empty, StringSlice splits strings on white spaces and IntSlice and FloatSlice split them on DefaultListSeparator. See SplitList.
+	ListSeparator string
+	// BoolTokens are the strings parsed as booleans by Bool, bound bool fields and typed keys, they are case insensitive.
+	// Default is DefaultBoolTokens.
+	BoolTokens *BoolTokens
+	// HookTimeout is the maximum duration a hook can run for. If a hook runs longer, ErrHookTimeout is returned for the hook
+	// and the other hooks are run while it keeps running in the background. If zero, hooks are not bounded.
+	HookTimeout time.Duration
+	// HookCoalesceWindow is the window within which the store hooks of reloads are coalesced into a single run,
+	// e.g. when a file change and a vault renewal trigger reloads a few milliseconds apart.
+	// The window starts at the first reload, the store hooks then run once at its end and ChangedKeys returns the union of the keys changed by the reloads.
+	// Loads of Load and loader hooks are not coalesced. If zero, store hooks run after each load.
+	HookCoalesceWindow time.Duration
+	// Merge is the function merging the values of a loader over the values of the store when the loader loads,
+	// default is MergeReplace. Built in alternatives are MergeDeep and MergeAppend.
+	Merge MergeFunc
+	// WatcherMaxRestarts is the maximum number of times a watcher goroutine is restarted after a panic.
+	// Once restarts are exhausted, the watcher is marked as failed and the store is stopped if the loader StopOnFailure.
+	WatcherMaxRestarts int
+	// WatcherRestartDelay is the delay before the first restart of a watcher after a panic, it doubles after each restart
+	// up to one minute. Default is one second.
+	WatcherRestartDelay time.Duration
+	// Backoff is the Backoff between the retries of the loads of the loaders and between the restarts of the watchers after a panic,
+	// it overrides the RetryDelay of the loaders and WatcherRestartDelay. A loader implementing BackoffLoader overrides it for its retries.
+	Backoff Backoff
+	// KeepUnregisteredValues tells wether the values of a loader removed with UnregisterLoader are left in the store.
+	// By default they are removed and the keys are restored from the loaders registered before it.
+	KeepUnregisteredValues bool
+	// MaxStaleness is the maximum duration without a successful load of any of the loaders of the store,
+	// once exceeded the store is stale: Stale returns true and Health returns an error. If zero, staleness is not checked.
+	MaxStaleness time.Duration
 }
 
 // Store is the interface
@@ -78,16 +128,66 @@ type Store interface {
 	RegisterLoader(l Loader, loaderHooks ...func(Store) error) *ConfigLoader
 	// RegisterLoaderWatcher reigsters a LoaderWatcher in the store and adds the given loader hooks.
 	RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *ConfigLoader
+	// UnregisterLoader removes the loader with the given name from the store, closes its watcher and removes its values unless KeepUnregisteredValues is set.
+	UnregisterLoader(name string) error
+	// Reset returns the store to a pristine state: watchers and closers are closed, loaders are unregistered and values, the bound value, hooks and groups are cleared.
+	Reset() error
 	// RegisterCloser registers an io.Closer in the store. A closer closes when konfig fails to load configs.
 	RegisterCloser(closer io.Closer) Store
+	// RegisterHook registers hooks on the store. Store hooks run after every successful load of any of the store's loaders, after the loader hooks.
+	RegisterHook(hooks ...func(Store) error) Store
+	// BindLogLevel registers a hook calling set with the value of the key k after every load so that the log level can be changed without a restart. If the key is not set, set is not called.
+	BindLogLevel(k string, set func(string) error) Store
+	// RegisterInitHook registers hooks running once, after the first successful load of the store.
+	RegisterInitHook(hooks ...func(Store) error) Store
+	// RegisterOrderedHooks registers store hooks with a priority and dependencies, it returns an error if the dependencies are cyclic.
+	RegisterOrderedHooks(hooks ...Hook) error
+	// RegisterDerived registers a key whose value is computed from the store's values after each load. Derived keys cannot be overwritten by loaders.
+	RegisterDerived(k string, f func(Values) interface{}) Store
+	// RegisterType declares the type of a key with a sample value, values of the key are converted to the type at load time.
+	RegisterType(k string, sample interface{}) Store
+	// RegisterCodec registers a codec decoding the values of the keys matching a pattern when they are loaded, e.g. "10MB" to an int64 byte count.
+	RegisterCodec(pattern string, codec Codec) Store
+	// RegisterAlias registers oldKey as a deprecated name of newKey, when a loader writes oldKey newKey is also set and a deprecation warning is logged.
+	RegisterAlias(oldKey, newKey string) Store
+	// RegisterAliases registers keys as names of a same value, writing any of the keys sets all of them.
+	RegisterAliases(keys ...string) Store
 	// Strict specifies mandatory keys on the konfig. When Strict is called, konfig will check that the specified keys are present, else it will return a non nil error.
 	// Then, after every following `Load` of a loader, it will check if the strict keys are still present in the konfig and consider the load a failure if a key is not present anymore.
 	Strict(...string) Store
+	// ExpectKeys declares keys or prefixes the loader with the given name is expected to load. Load fails with an error listing the missing keys and their loader.
+	ExpectKeys(loader string, keys ...string) Store
 	// RunHooks runs all hooks and child groups hooks
 	RunHooks() error
+	// ChangedKeys returns the keys changed by the loads the running hooks are run for, it is meant to be called from hooks.
+	ChangedKeys() []string
+	// WaitForKeys blocks until all the keys are present in the store or ctx is done, it returns the keys still missing and an error if ctx is done first.
+	WaitForKeys(ctx context.Context, keys ...string) ([]string, error)
+	// Errors returns a channel receiving the errors of hooks panicking or timing out and of watchers panicking
+	Errors() <-chan error
+	// Events returns a channel receiving an event with the changed keys and the error after each load of a loader, the oldest event is dropped if the buffer is full
+	Events() <-chan Event
+	// Health returns a non nil error if a watcher failed permanently or if the config is stale
+	Health() error
+	// Stale tells wether no successful load occurred within MaxStaleness or a loader did not load within its MaxAge
+	Stale() bool
+	// Degraded returns the names of the loaders which failed during the initial load and did not load successfully since
+	Degraded() []string
+	// Stats returns the runtime state of the store and its loaders
+	Stats() StoreStats
+	// Describe returns the name, type, parsers, watch status and priority of the loaders of the store in registration order
+	Describe() []LoaderDescription
 
 	// Load loads all loaders registered in the store. If it faisl it returns a non nil error
 	Load() error
+	// LoadContext loads all loaders registered in the store sharing the given context. If it fails it returns a non nil error.
+	LoadContext(ctx context.Context) error
+	// Reload reloads synchronously all loaders registered in the store. If it fails it returns a non nil error.
+	Reload() error
+	// ReloadLoader reloads synchronously the loader with the given name. If it fails it returns a non nil error.
+	ReloadLoader(name string) error
+	// DryRun loads all the enabled loaders registered in the store into a throwaway store and returns the values and the errors, without modifying the store or running hooks.
+	DryRun(ctx context.Context) DryRunResult
 	// Watch starts all watchers registered in the store. If it fails it returns a non nil error.
 	Watch() error
 
@@ -97,15 +197,41 @@ type Store interface {
 
 	// Group lazyloads a child Store from the current store. If the group already exists, it just returns it, else it creates it and returns it. Groups are useful to namespace configs by domain.
 	Group(g string) Store
+	// GetGroup returns the group g of the store and wether it exists, unlike Group it does not create the group.
+	GetGroup(g string) (Store, bool)
+	// Parent returns the store of which the store is a group, or nil if the store is not a group.
+	Parent() Store
+	// RegisterGroupHook registers hooks run with the store after every successful load of the store g, usually another group. It returns an error if it would create a cycle between stores.
+	RegisterGroupHook(g Store, hooks ...func(Store) error) error
+	// Getter returns a ngetter.GetterTyped reading the key k from the store each time it is called.
+	Getter(k string) ngetter.GetterTyped
 
 	// Get gets the value with the key k fron the store. If the key is not set, Get returns nil. To check wether a value is really set, use Exists.
 	Get(k string) interface{}
 	// MustGet tries to get the value with the key k from the store. If the key k does not exist in the store, MustGet panics.
 	MustGet(k string) interface{}
+	// Lookup gets the value with the key k from the store and wether the key is set. A key set to an explicit null returns nil and true.
+	Lookup(k string) (interface{}, bool)
 	// Set sets the key k with the value v in the store.
 	Set(k string, v interface{})
+	// CompareAndSet atomically sets the key k with the value nv if its current value equals ov and returns wether it was set. If ov is nil, the key must not be set.
+	CompareAndSet(k string, ov, nv interface{}) bool
+	// Persist writes the value v of the key k to the source of the loader responsible for the key, if the loader implements Writer, and sets it in the store.
+	Persist(k string, v interface{}) error
+	// PersistMany writes the values to the sources of the loaders responsible for their keys, atomically for loaders implementing BatchWriter with a transactional backend.
+	PersistMany(values map[string]interface{}) error
 	// Exists checks wether the key k is set in the store.
 	Exists(k string) bool
+	// Snapshot returns a copy of all the values currently set in the store.
+	Snapshot() Values
+	// Source returns the name of the loader responsible for the key k, either declared through the KeyOwner interface or the last loader which wrote the key.
+	Source(k string) string
+	// Provenance returns the name of the loader which last wrote the key k and when. If the key was set with Set, the loader name is empty. ok is false if the key is not set.
+	Provenance(k string) (loader string, at time.Time, ok bool)
+	// Explain returns the value each loader provided for the key k, from the lowest to the highest precedence, and which one is in effect.
+	Explain(k string) []LayerValue
+	// LastChanged returns the last time the value of the key k changed. ok is false if the key is not set.
+	LastChanged(k string) (time.Time, bool)
 	// MustString tries to get the value with the key k from the store and casts it to a string. If the key k does not exist in the store, MustGet panics.
 	MustString(k string) string
 
@@ -148,6 +274,11 @@ type Store interface {
 	// IntSlice tries to get the value with the key k from the store and casts it to a []int. If the key k does not exist it returns the Zero value.
 	IntSlice(k string) []int
 
+	// MustFloatSlice tries to get the value with the key k from the store and casts it to a []float64. If the key k does not exist in the store, MustFloatSlice panics.
+	MustFloatSlice(k string) []float64
+	// FloatSlice tries to get the value with the key k from the store and casts it to a []float64. If the key k does not exist it returns the Zero value.
+	FloatSlice(k string) []float64
+
 	// MustStringMap tries to get the value with the key k from the store and casts it to a map[string]interface{}. If the key k does not exist in the store, MustStringMap panics.
 	MustStringMap(k string) map[string]interface{}
 	// StringMap tries to get the value with the key k from the store and casts it to a map[string]interface{}. If the key k does not exist it returns the Zero value.
@@ -158,25 +289,90 @@ type Store interface {
 	// StringMapString tries to get the value with the key k from the store and casts it to a map[string]string. If the key k does not exist it returns the Zero value.
 	StringMapString(k string) map[string]string
 
+	// MustBytes tries to get the value with the key k from the store and casts it to a []byte. If the key k does not exist in the store, MustBytes panics.
+	MustBytes(k string) []byte
+	// Bytes tries to get the value with the key k from the store and casts it to a []byte. []byte values are returned as is. If the key k does not exist it returns the Zero value.
+	Bytes(k string) []byte
+	// MustByteSize tries to get the value with the key k from the store and parses it to a byte count (e.g. "10MB", "2MiB"). If the key k does not exist in the store, MustByteSize panics.
+	MustByteSize(k string) int64
+	// ByteSize tries to get the value with the key k from the store and parses it to a byte count (e.g. "10MB", "2MiB"). If the key k does not exist or the value is invalid it returns the Zero value.
+	ByteSize(k string) int64
+	// TLSCertificate parses the PEM encoded certificate and private key at the keys certKey and keyKey into a tls.Certificate. The error names the key whose value is missing or malformed.
+	TLSCertificate(certKey, keyKey string) (tls.Certificate, error)
+
+	// Require gets the value with the key k from the store. If the key k does not exist it returns an error.
+	Require(k string) (interface{}, error)
+	// RequireString tries to get the value with the key k from the store and casts it to a string. If the key k does not exist or the value cannot be converted it returns an error.
+	RequireString(k string) (string, error)
+	// RequireInt tries to get the value with the key k from the store and casts it to an int. If the key k does not exist or the value cannot be converted it returns an error.
+	RequireInt(k string) (int, error)
+	// RequireFloat tries to get the value with the key k from the store and casts it to a float64. If the key k does not exist or the value cannot be converted it returns an error.
+	RequireFloat(k string) (float64, error)
+	// RequireBool tries to get the value with the key k from the store and casts it to a bool. If the key k does not exist or the value cannot be converted it returns an error.
+	RequireBool(k string) (bool, error)
+	// RequireDuration tries to get the value with the key k from the store and casts it to a time.Duration. If the key k does not exist or the value cannot be converted it returns an error.
+	RequireDuration(k string) (time.Duration, error)
+	// RequireByteSize tries to get the value with the key k from the store and parses it to a byte count. If the key k does not exist or the value is not a valid byte size it returns an error.
+	RequireByteSize(k string) (int64, error)
+	// RequireIntSlice tries to get the value with the key k from the store and casts it to a []int. If the key k does not exist or an element cannot be converted it returns an error.
+	RequireIntSlice(k string) ([]int, error)
+	// RequireFloatSlice tries to get the value with the key k from the store and casts it to a []float64. If the key k does not exist or an element cannot be converted it returns an error.
+	RequireFloatSlice(k string) ([]float64, error)
+
 	// Bind binds a value (either a map[string]interface{} or a struct) to the config store. When config values are set on the config store, they are also set on the bound value.
 	Bind(interface{})
 
 	// Value returns the value bound to the config store.
 	// It panics if no bound value has been set
 	Value() interface{}
+	// BoundSnapshot returns a snapshot of the bound value, it is swapped atomically once all the updates of a load are applied.
+	// It panics if no bound value has been set
+	BoundSnapshot() interface{}
+	// WatchField registers a function called with the old and the new value of a field of the bound struct, e.g. "DB.MaxConns", when a load or a Set changes it.
+	WatchField(path string, f func(oldValue, newValue interface{})) Store
+
+	// ToEnv flattens the store into a list of "PREFIX_KEY=value" environment variables suitable for exec.Cmd.Env.
+	ToEnv(prefix string) []string
 }
 
 // store is the concrete implementation of the Store
 type store struct {
-	name       string
-	cfg        *Config
-	m          *atomic.Value
-	mut        *sync.Mutex
-	groups     map[string]*store
-	v          *value
-	metrics    map[string]prometheus.Collector
-	strictKeys []string
-	loaded     bool
+	name        string
+	cfg         *Config
+	m           *atomic.Value
+	mut         *sync.Mutex
+	groups      map[string]*store
+	parent      *store
+	hookTargets []*store
+	v           *value
+	metrics     map[string]prometheus.Collector
+	strictKeys  []string
+	expected    []expectedKeys
+	loaded      bool
+	hooks       LoaderHooks
+	hookDefs    []Hook
+	initHooks   LoaderHooks
+	initialized bool
+	derived     []derivedKey
+	types       map[string]typedKey
+	codecs      []keyCodec
+	aliases     map[string]string
+	aliasGroups map[string][]string
+	sources     map[string]provenance
+	changed     map[string]time.Time
+	templates   map[string]string
+	refs        map[string]string
+	coalescer   *hookCoalescer
+	hookChanged *atomic.Value
+	bools       boolParser
+	errs        chan error
+	events      chan Event
+	updated     chan struct{}
+	created     time.Time
+
+	// stateMut guards the loaders, the values of the loaders and the sources and change times of the keys.
+	// It is never held while hooks run so that the store can be introspected from hooks.
+	stateMut *sync.RWMutex
 
 	WatcherLoaders []*loaderWatcher
 	WatcherClosers Closers
@@ -188,16 +384,67 @@ var (
 	once sync.Once
 )
 
-// Init initiates the global config store with the given Config cfg
+// Init initiates the global config store with the given Config cfg.
+// It panics if metrics are enabled and the MetricsOptions are invalid
 func Init(cfg *Config) {
 	c = newStore(cfg)
 }
 
-// New returns a new Store with the given config
+// New returns a new Store with the given config.
+// It panics if metrics are enabled and the MetricsOptions are invalid
 func New(cfg *Config) Store {
 	return newStore(cfg)
 }
 
+// InitE initiates the global config store with the given Config cfg like Init,
+// but it returns an error instead of initiating the store if the config is invalid
+func InitE(cfg *Config) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	Init(cfg)
+	return nil
+}
+
+// NewE returns a new Store with the given config like New, but it returns an error if the config is invalid
+func NewE(cfg *Config) (Store, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return New(cfg), nil
+}
+
+// validate returns an error if the config is nil or a setting is invalid
+func (cfg *Config) validate() error {
+	if cfg == nil {
+		return ErrNilConfig
+	}
+	if cfg.HookTimeout < 0 {
+		return fmt.Errorf(ErrInvalidConfigMsg, "HookTimeout", cfg.HookTimeout)
+	}
+	if cfg.HookCoalesceWindow < 0 {
+		return fmt.Errorf(ErrInvalidConfigMsg, "HookCoalesceWindow", cfg.HookCoalesceWindow)
+	}
+	if cfg.BoolTokens != nil {
+		if err := cfg.BoolTokens.validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.WatcherMaxRestarts < 0 {
+		return fmt.Errorf(ErrInvalidConfigMsg, "WatcherMaxRestarts", cfg.WatcherMaxRestarts)
+	}
+	if cfg.WatcherRestartDelay < 0 {
+		return fmt.Errorf(ErrInvalidConfigMsg, "WatcherRestartDelay", cfg.WatcherRestartDelay)
+	}
+	if cfg.MaxStaleness < 0 {
+		return fmt.Errorf(ErrInvalidConfigMsg, "MaxStaleness", cfg.MaxStaleness)
+	}
+	if cfg.Metrics {
+		return cfg.MetricsOptions.validate()
+	}
+	return nil
+}
+
 // SetLogger sets the logger used in the global store
 func SetLogger(l nlogger.Logger) {
 	instance().SetLogger(l)
@@ -217,10 +464,12 @@ func RegisterLoader(l Loader, loaderHooks ...func(Store) error) *ConfigLoader {
 func (c *store) RegisterLoader(l Loader, loaderHooks ...func(Store) error) *ConfigLoader {
 	var lw = c.newLoaderWatcher(l, NopWatcher{}, loaderHooks)
 
+	c.stateMut.Lock()
 	c.WatcherLoaders = append(
 		c.WatcherLoaders,
 		lw,
 	)
+	c.stateMut.Unlock()
 
 	return c.newConfigLoader(lw)
 }
@@ -232,11 +481,13 @@ func RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *
 func (c *store) RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *ConfigLoader {
 	var lwatcher = c.newLoaderWatcher(lw, lw, loaderHooks)
 
+	c.stateMut.Lock()
 	c.WatcherClosers = append(c.WatcherClosers, lw)
 	c.WatcherLoaders = append(
 		c.WatcherLoaders,
 		lwatcher,
 	)
+	c.stateMut.Unlock()
 
 	return c.newConfigLoader(lwatcher)
 }
@@ -251,6 +502,24 @@ func (c *store) RegisterCloser(closer io.Closer) Store {
 	return c
 }
 
+// RegisterHook adds hooks to the global store. Store hooks run after every successful load
+// of any of the store's loaders, after the loader hooks.
+// It panics if a hook is nil.
+func RegisterHook(hooks ...func(Store) error) Store {
+	return instance().RegisterHook(hooks...)
+}
+func (c *store) RegisterHook(hooks ...func(Store) error) Store {
+	var hs = make([]Hook, len(hooks))
+	for i, h := range hooks {
+		hs[i] = Hook{Run: h}
+	}
+	// unnamed hooks cannot introduce cycles, the only error is a nil hook
+	if err := c.RegisterOrderedHooks(hs...); err != nil {
+		panic(err)
+	}
+	return c
+}
+
 // Strict specifies mandatory keys on the konfig. After strict is called, konfig will wait for the first config Load to happen and will check if the
 // specified strict keys are present, if not, Load will return a non nil error. Then, after every following `Load` of a loader, it will check if the strict keys are still present in the konfig and consider the load a failure if a key is not present anymore.
 func Strict(keys ...string) Store {
@@ -275,14 +544,19 @@ func RunHooks() error {
 }
 func (c *store) RunHooks() error {
 	// run all hooks
-	for _, wl := range c.WatcherLoaders {
+	for _, wl := range c.loaders() {
 		if wl.loaderHooks != nil {
-			if err := wl.loaderHooks.Run(c); err != nil {
+			if err := c.runHooks(wl.loaderHooks); err != nil {
 				return err
 			}
 		}
 	}
 
+	// run store hooks
+	if err := c.runHooks(c.hooks); err != nil {
+		return err
+	}
+
 	// run hooks on chil groups
 	if c.groups != nil {
 		for _, gr := range c.groups {
@@ -348,7 +622,15 @@ func newStore(cfg *Config) *store {
 		m:              &mValue,
 		cfg:            cfg,
 		mut:            &sync.Mutex{},
+		stateMut:       &sync.RWMutex{},
 		groups:         make(map[string]*store),
+		sources:        make(map[string]provenance),
+		changed:        make(map[string]time.Time),
+		coalescer:      &hookCoalescer{},
+		hookChanged:    &atomic.Value{},
+		bools:          newBoolParser(cfg.BoolTokens),
+		errs:           make(chan error, errorsChanSize),
+		created:        time.Now(),
 		WatcherLoaders: make([]*loaderWatcher, 0, 10),
 		WatcherClosers: make(Closers, 0, 10),
 		Closers:        make(Closers, 0, 10),
@@ -2,8 +2,14 @@ package konfig
 
 import (
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"log"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +76,134 @@ func TestSingleton(t *testing.T) {
 	)
 }
 
+func TestStoreMethods(t *testing.T) {
+	// every package level function using the global store must have a Store method
+	var pkgs, err = parser.ParseDir(token.NewFileSet(), ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.Nil(t, err)
+
+	var storeType = reflect.TypeOf((*Store)(nil)).Elem()
+	var checked int
+	for _, f := range pkgs["konfig"].Files {
+		for _, decl := range f.Decls {
+			var fn, ok = decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !fn.Name.IsExported() {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				var sel, ok = n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if call, ok := sel.X.(*ast.CallExpr); ok {
+					if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "instance" {
+						var _, ok = storeType.MethodByName(sel.Sel.Name)
+						require.True(t, ok, "Store has no method %s used by %s", sel.Sel.Name, fn.Name.Name)
+						checked++
+					}
+				}
+				return true
+			})
+		}
+	}
+	require.NotZero(t, checked)
+}
+
+func TestIndependentStores(t *testing.T) {
+	type App struct {
+		Name string `konfig:"name"`
+	}
+
+	reset()
+	Init(DefaultConfig())
+	Set("name", "global")
+
+	var a, b = New(&Config{Name: "a"}), New(&Config{Name: "b"})
+	var hooks = map[string]int{}
+	for _, st := range []Store{a, b} {
+		var name = st.Name()
+		st.Bind(App{})
+		st.RegisterType("port", 0)
+		st.RegisterLoader(&MapLoader{name: name, values: Values{"name": name, "port": "80"}})
+		st.RegisterHook(func(s Store) error {
+			hooks[s.Name()]++
+			return nil
+		})
+	}
+
+	require.Nil(t, a.Load())
+	require.Equal(t, "a", a.MustString("name"))
+	require.Equal(t, 80, a.Get("port"))
+	require.Equal(t, App{Name: "a"}, a.Value())
+	require.Equal(t, "a", a.Getter("name").String())
+	require.Equal(t, "a", a.Source("name"))
+	require.False(t, b.Exists("name"))
+	require.Equal(t, "global", Get("name"))
+
+	require.Nil(t, b.Load())
+	require.Nil(t, b.RunHooks())
+	require.Equal(t, "b", b.MustString("name"))
+	require.Equal(t, App{Name: "b"}, b.Value())
+	require.Equal(t, "a", a.MustString("name"))
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, hooks)
+	require.Equal(t, "global", Get("name"))
+}
+
+func TestInitE(t *testing.T) {
+	var testCases = []struct {
+		name string
+		cfg  *Config
+		err  error
+	}{
+		{
+			name: "nil config",
+			err:  ErrNilConfig,
+		},
+		{
+			name: "negative hook timeout",
+			cfg:  &Config{HookTimeout: -time.Second},
+			err:  fmt.Errorf(ErrInvalidConfigMsg, "HookTimeout", -time.Second),
+		},
+		{
+			name: "negative watcher max restarts",
+			cfg:  &Config{WatcherMaxRestarts: -1},
+			err:  fmt.Errorf(ErrInvalidConfigMsg, "WatcherMaxRestarts", -1),
+		},
+		{
+			name: "negative watcher restart delay",
+			cfg:  &Config{WatcherRestartDelay: -time.Second},
+			err:  fmt.Errorf(ErrInvalidConfigMsg, "WatcherRestartDelay", -time.Second),
+		},
+		{
+			name: "valid config",
+			cfg:  DefaultConfig(),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			reset()
+			var prev = instance()
+
+			var err = InitE(testCase.cfg)
+			var s, nerr = NewE(testCase.cfg)
+			if testCase.err != nil {
+				require.Equal(t, testCase.err, err)
+				require.Equal(t, testCase.err, nerr)
+				require.Nil(t, s)
+				// the global store is left untouched
+				require.True(t, prev == instance())
+				return
+			}
+			require.Nil(t, err)
+			require.Nil(t, nerr)
+			require.NotNil(t, s)
+			require.False(t, prev == instance())
+		})
+	}
+}
+
 func TestConfigWatcherLoader(t *testing.T) {
 	var testCases = []struct {
 		name       string
@@ -264,7 +398,12 @@ func TestConfigWatcherLoader(t *testing.T) {
 				wl2.EXPECT().Start().Times(1).Return(nil)
 				wl2.EXPECT().Done().MinTimes(1).Return(d2)
 				wl2.EXPECT().Watch().MinTimes(1).Return(c2)
-				wl2.EXPECT().Close().Times(1).Return(nil)
+				// close the done chan so that the watcher stops instead of reloading for the other tests
+				wl2.EXPECT().Close().Times(1).DoAndReturn(func() error {
+					close(d2)
+					return nil
+				})
+				wl2.EXPECT().Err().AnyTimes().Return(nil)
 
 				wl1.EXPECT().Start().Times(1).Return(nil)
 				wl1.EXPECT().Done().MinTimes(1).Return(d)
@@ -440,6 +579,22 @@ func TestStoreMisc(t *testing.T) {
 			require.True(t, c.cfg.Logger.Get().(nlogger.Logger) == l)
 		},
 	)
+
+	t.Run(
+		"Snapshot",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			Set("foo", "bar")
+			var v = Snapshot()
+			require.Equal(t, Values{"foo": "bar"}, v)
+
+			// modifying the snapshot does not modify the store
+			v.Set("foo", "baz")
+			require.Equal(t, "bar", Get("foo"))
+		},
+	)
 }
 
 func TestRegisterLoaderHooks(t *testing.T) {
@@ -579,6 +734,41 @@ func TestRunHooks(t *testing.T) {
 	)
 }
 
+func TestRegisterHook(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var ran = make([]string, 0)
+	RegisterHook(func(Store) error {
+		ran = append(ran, "store")
+		return nil
+	})
+	RegisterLoader(
+		&DummyLoader{
+			DataToLoad: [][2]string{{"foo", "bar"}},
+		},
+		func(s Store) error {
+			ran = append(ran, "loader")
+			return nil
+		},
+	)
+
+	// loader hooks run before store hooks
+	require.Nil(t, Load())
+	require.Equal(t, []string{"loader", "store"}, ran)
+
+	require.Nil(t, RunHooks())
+	require.Equal(t, []string{"loader", "store", "loader", "store"}, ran)
+
+	RegisterHook(func(Store) error {
+		return errors.New("err")
+	})
+	require.NotNil(t, Load())
+	require.NotNil(t, RunHooks())
+
+	require.Panics(t, func() { RegisterHook(nil) })
+}
+
 type TestCloser struct {
 	err    error
 	closed bool
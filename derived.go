@@ -0,0 +1,43 @@
+package konfig
+
+// derivedKey is a key whose value is computed from the other values of the store
+type derivedKey struct {
+	key string
+	f   func(Values) interface{}
+}
+
+// RegisterDerived registers a derived key on the global store.
+// See Store.RegisterDerived.
+func RegisterDerived(k string, f func(Values) interface{}) Store {
+	return instance().RegisterDerived(k, f)
+}
+
+// RegisterDerived registers a key k whose value is computed by f after each load of any loader of the store.
+// f receives all the values of the store and must not modify them.
+// Derived keys are computed in registration order, therefore a derived key can depend on previously registered derived keys.
+// As they are computed after the loaders' values are added, derived keys cannot be overwritten by loaders.
+func (c *store) RegisterDerived(k string, f func(Values) interface{}) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.derived = append(c.derived, derivedKey{key: k, f: f})
+
+	return c
+}
+
+// setDerived computes the derived keys and sets them in m.
+// It returns the derived values.
+func (c *store) setDerived(m s) Values {
+	if len(c.derived) == 0 {
+		return nil
+	}
+
+	var x = make(Values, len(c.derived))
+	for _, d := range c.derived {
+		var v = d.f(Values(m))
+		m[d.key] = v
+		x[d.key] = v
+	}
+
+	return x
+}
@@ -0,0 +1,74 @@
+package konfig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDerived(t *testing.T) {
+	t.Run(
+		"computed after load",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterDerived("db.dsn", func(v Values) interface{} {
+				return fmt.Sprintf("%v:%v", v["db.host"], v["db.port"])
+			})
+			RegisterDerived("db.url", func(v Values) interface{} {
+				return fmt.Sprintf("tcp://%v", v["db.dsn"])
+			})
+
+			var l = &DummyLoader{
+				DataToLoad: [][2]string{
+					{"db.host", "localhost"},
+					{"db.port", "5432"},
+					{"db.dsn", "overwritten"},
+				},
+			}
+			RegisterLoader(l)
+
+			require.Nil(t, Load())
+			require.Equal(t, "localhost:5432", MustString("db.dsn"))
+			require.Equal(t, "tcp://localhost:5432", MustString("db.url"))
+
+			// recomputed on reload
+			l.DataToLoad[1][1] = "5433"
+			require.Nil(t, Load())
+			require.Equal(t, "localhost:5433", MustString("db.dsn"))
+			require.Equal(t, "tcp://localhost:5433", MustString("db.url"))
+		},
+	)
+
+	t.Run(
+		"bound value",
+		func(t *testing.T) {
+			type DB struct {
+				DSN string `konfig:"dsn"`
+			}
+			type Config struct {
+				DB DB `konfig:"db"`
+			}
+
+			reset()
+			Init(DefaultConfig())
+			Bind(Config{})
+
+			RegisterDerived("db.dsn", func(v Values) interface{} {
+				return fmt.Sprintf("%v", v["db.host"])
+			})
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"db.host", "localhost"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "localhost", Value().(Config).DB.DSN)
+		},
+	)
+}
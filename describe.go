@@ -0,0 +1,69 @@
+package konfig
+
+import (
+	"fmt"
+)
+
+// ParserLoader is an optional interface a Loader can implement to declare the parsers it parses the data it loads with,
+// they are listed by Describe (e.g. "json" for a file loader parsing a JSON file).
+type ParserLoader interface {
+	// Parsers returns the names of the parsers of the loader, in the order of its sources
+	Parsers() []string
+}
+
+// LoaderDescription describes a loader registered in a store
+type LoaderDescription struct {
+	// Name is the name of the loader
+	Name string
+	// Type is the Go type of the loader (e.g. "*klfile.Loader")
+	Type string
+	// Parsers are the names of the parsers of the loader, it is nil if the loader does not implement ParserLoader
+	Parsers []string
+	// Watched tells wether the loader was registered with a watcher
+	Watched bool
+	// Priority is the precedence of the values of the loader, the values of loaders with a higher priority override the values of the others.
+	// It is the position of the loader in the registration order, starting at 0.
+	Priority int
+	// Enabled tells wether the loader is enabled
+	Enabled bool
+}
+
+// Describe returns the description of the loaders of the global store.
+// See Store.Describe.
+func Describe() []LoaderDescription {
+	return instance().Describe()
+}
+
+// Describe returns the description of the loaders of the store in registration order, so from the lowest to the highest priority:
+// their name, their type, their parsers, wether they are watched and enabled.
+// It documents the effective config pipeline at runtime, for example to print it in a startup banner, and complements Stats and Explain.
+// It does not lock the store, so it can be called from hooks.
+func (c *store) Describe() []LoaderDescription {
+	var wls = c.loaders()
+
+	var d = make([]LoaderDescription, len(wls))
+	for i, wl := range wls {
+		var l = wl.Loader
+		// the loader of a watcher loader built with NewLoaderWatcher
+		if lw, ok := l.(*loaderWatcher); ok {
+			l = lw.Loader
+		}
+
+		d[i] = LoaderDescription{
+			Name:     wl.Name(),
+			Type:     fmt.Sprintf("%T", l),
+			Watched:  !isNopWatcher(wl.Watcher),
+			Priority: i,
+			Enabled:  wl.isEnabled(),
+		}
+		if pl, ok := l.(ParserLoader); ok {
+			d[i].Parsers = pl.Parsers()
+		}
+	}
+	return d
+}
+
+func isNopWatcher(w Watcher) bool {
+	var _, ok = w.(NopWatcher)
+	return ok
+}
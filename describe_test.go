@@ -0,0 +1,72 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type parserLoader struct {
+	MapLoader
+	parsers []string
+}
+
+func (p *parserLoader) Parsers() []string {
+	return p.parsers
+}
+
+func TestDescribe(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	RegisterLoader(&MapLoader{name: "defaults"})
+	RegisterLoaderWatcher(
+		NewLoaderWatcher(
+			&parserLoader{MapLoader: MapLoader{name: "file"}, parsers: []string{"yaml", "json"}},
+			NewMockWatcher(ctrl),
+		),
+	).EnabledIf(func() bool { return false })
+
+	require.Equal(
+		t,
+		[]LoaderDescription{
+			{
+				Name:     "defaults",
+				Type:     "*konfig.MapLoader",
+				Priority: 0,
+				Enabled:  true,
+			},
+			{
+				Name:     "file",
+				Type:     "*konfig.parserLoader",
+				Parsers:  []string{"yaml", "json"},
+				Watched:  true,
+				Priority: 1,
+				Enabled:  false,
+			},
+		},
+		Describe(),
+	)
+}
+
+func TestDescribeFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var d []LoaderDescription
+	RegisterHook(func(s Store) error {
+		d = s.Describe()
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.Len(t, d, 1)
+	require.Equal(t, "a", d[0].Name)
+}
@@ -0,0 +1,116 @@
+package konfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RedactedValue is the value replacing redacted values in key changes
+const RedactedValue = "<redacted>"
+
+// ChangeKind is the kind of a KeyChange
+type ChangeKind int
+
+// Kinds of key changes
+const (
+	// KeyAdded is the kind of a key set only in the second values
+	KeyAdded ChangeKind = iota
+	// KeyRemoved is the kind of a key set only in the first values
+	KeyRemoved
+	// KeyValueChanged is the kind of a key whose value changed but kept the same type
+	KeyValueChanged
+	// KeyTypeChanged is the kind of a key whose value changed type
+	KeyTypeChanged
+)
+
+// String returns the name of the change kind
+func (k ChangeKind) String() string {
+	switch k {
+	case KeyAdded:
+		return "added"
+	case KeyRemoved:
+		return "removed"
+	case KeyValueChanged:
+		return "value changed"
+	case KeyTypeChanged:
+		return "type changed"
+	}
+	return "unknown"
+}
+
+// KeyChange is the change of a key between two values
+type KeyChange struct {
+	Key  string
+	Kind ChangeKind
+	// Old is the value in the first values, it is nil if the key was added
+	Old interface{}
+	// New is the value in the second values, it is nil if the key was removed
+	New interface{}
+}
+
+// String returns a human readable line describing the change
+func (kc KeyChange) String() string {
+	switch kc.Kind {
+	case KeyAdded:
+		return fmt.Sprintf("+ %s: %v", kc.Key, kc.New)
+	case KeyRemoved:
+		return fmt.Sprintf("- %s: %v", kc.Key, kc.Old)
+	case KeyTypeChanged:
+		return fmt.Sprintf("~ %s: %v (%T) -> %v (%T)", kc.Key, kc.Old, kc.Old, kc.New, kc.New)
+	}
+	return fmt.Sprintf("~ %s: %v -> %v", kc.Key, kc.Old, kc.New)
+}
+
+// Diff returns the changes of the keys between the values a and b, sorted by key.
+// The values of the keys in redacted, and of the keys they prefix followed by KeySep (e.g. "vault" redacts "vault.token"),
+// are replaced with RedactedValue in the changes. Changes are detected before the redaction.
+func Diff(a, b Values, redacted ...string) []KeyChange {
+	var changes = make([]KeyChange, 0)
+
+	for k, ov := range a {
+		var nv, ok = b[k]
+		switch {
+		case !ok:
+			changes = append(changes, KeyChange{Key: k, Kind: KeyRemoved, Old: ov})
+		case reflect.TypeOf(ov) != reflect.TypeOf(nv):
+			changes = append(changes, KeyChange{Key: k, Kind: KeyTypeChanged, Old: ov, New: nv})
+		case !reflect.DeepEqual(ov, nv):
+			changes = append(changes, KeyChange{Key: k, Kind: KeyValueChanged, Old: ov, New: nv})
+		}
+	}
+
+	for k, nv := range b {
+		if _, ok := a[k]; !ok {
+			changes = append(changes, KeyChange{Key: k, Kind: KeyAdded, New: nv})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	for i := range changes {
+		if !isRedacted(changes[i].Key, redacted) {
+			continue
+		}
+		if changes[i].Old != nil {
+			changes[i].Old = RedactedValue
+		}
+		if changes[i].New != nil {
+			changes[i].New = RedactedValue
+		}
+	}
+
+	return changes
+}
+
+func isRedacted(k string, redacted []string) bool {
+	for _, r := range redacted {
+		if k == r || strings.HasPrefix(k, r+KeySep) {
+			return true
+		}
+	}
+	return false
+}
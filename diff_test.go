@@ -0,0 +1,77 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		a        Values
+		b        Values
+		redacted []string
+		expected []KeyChange
+	}{
+		{
+			name:     "same values",
+			a:        Values{"foo": "bar", "list": []string{"a"}},
+			b:        Values{"foo": "bar", "list": []string{"a"}},
+			expected: []KeyChange{},
+		},
+		{
+			name: "changes",
+			a: Values{
+				"removed": "foo",
+				"value":   "foo",
+				"type":    "1",
+				"same":    1,
+			},
+			b: Values{
+				"added": "bar",
+				"value": "bar",
+				"type":  1,
+				"same":  1,
+			},
+			expected: []KeyChange{
+				{Key: "added", Kind: KeyAdded, New: "bar"},
+				{Key: "removed", Kind: KeyRemoved, Old: "foo"},
+				{Key: "type", Kind: KeyTypeChanged, Old: "1", New: 1},
+				{Key: "value", Kind: KeyValueChanged, Old: "foo", New: "bar"},
+			},
+		},
+		{
+			name: "redacted",
+			a: Values{
+				"vault.token":  "foo",
+				"vault.secret": "foo",
+				"vaults":       "foo",
+			},
+			b: Values{
+				"vault.token": "bar",
+				"vaults":      "bar",
+			},
+			redacted: []string{"vault"},
+			expected: []KeyChange{
+				{Key: "vault.secret", Kind: KeyRemoved, Old: RedactedValue},
+				{Key: "vault.token", Kind: KeyValueChanged, Old: RedactedValue, New: RedactedValue},
+				{Key: "vaults", Kind: KeyValueChanged, Old: "foo", New: "bar"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, Diff(testCase.a, testCase.b, testCase.redacted...))
+		})
+	}
+}
+
+func TestKeyChangeString(t *testing.T) {
+	require.Equal(t, "+ foo: bar", KeyChange{Key: "foo", Kind: KeyAdded, New: "bar"}.String())
+	require.Equal(t, "- foo: bar", KeyChange{Key: "foo", Kind: KeyRemoved, Old: "bar"}.String())
+	require.Equal(t, "~ foo: bar -> baz", KeyChange{Key: "foo", Kind: KeyValueChanged, Old: "bar", New: "baz"}.String())
+	require.Equal(t, "~ foo: 1 (string) -> 1 (int)", KeyChange{Key: "foo", Kind: KeyTypeChanged, Old: "1", New: 1}.String())
+	require.Equal(t, "type changed", KeyTypeChanged.String())
+}
@@ -0,0 +1,105 @@
+package konfig
+
+import (
+	"context"
+)
+
+// DryRunResult is the result of a dry run load
+type DryRunResult struct {
+	// Values are the values the store would hold after the load
+	Values Values
+	// Errors are the errors returned by the loaders and the strict keys check
+	Errors []error
+}
+
+type dryRunKey struct{}
+
+// IsDryRun tells wether ctx is the context of a load made by DryRun.
+// Loaders implementing ContextLoader and keeping a state between loads can use it to leave their state untouched during a dry run.
+func IsDryRun(ctx context.Context) bool {
+	var dryRun, _ = ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// DryRun loads all the loaders registered in the global store without applying their values.
+// See Store.DryRun.
+func DryRun(ctx context.Context) DryRunResult {
+	return instance().DryRun(ctx)
+}
+
+// DryRun loads all the enabled loaders registered in the store into a throwaway store and returns its values and the errors encountered.
+// Loader transforms, codecs, aliases, typed keys, derived keys, references, templates, strict keys and expected keys are applied like in a real load, but the store is left untouched:
+// hooks are not run, watchers are not started and metrics are not recorded.
+// Unlike Load, it does not stop at the first failing loader so that all errors are reported.
+// Loaders keeping a state between loads, such as the vault loader renewing its token, may still update their own state,
+// unless they implement ContextLoader and check IsDryRun.
+// It copies the settings of the store with the store locked, so it must not be called from hooks.
+func (c *store) DryRun(ctx context.Context) DryRunResult {
+	var d = c.dryRunStore()
+
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, dryRunKey{}, true))
+	defer cancel()
+
+	var r DryRunResult
+	for _, wl := range c.loaders() {
+		if !wl.isEnabled() {
+			continue
+		}
+
+		var dwl = d.newLoaderWatcher(wl.Loader, NopWatcher{}, nil)
+		dwl.transforms = wl.transforms
+		d.WatcherLoaders = append(d.WatcherLoaders, dwl)
+
+		if err := d.loaderLoadRetry(ctx, dwl, 0); err != nil {
+			r.Errors = append(r.Errors, err)
+		}
+	}
+
+	if err := d.checkStrictKeys(); err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+	if err := d.checkExpectedKeys(); err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+
+	r.Values = d.Snapshot()
+
+	return r
+}
+
+// dryRunStore returns a new store with a copy of the key settings of the store, without metrics
+func (c *store) dryRunStore() *store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var cfg = *c.cfg
+	cfg.Metrics = false
+
+	var d = newStore(&cfg)
+
+	d.strictKeys = append([]string(nil), c.strictKeys...)
+	d.derived = append([]derivedKey(nil), c.derived...)
+	d.expected = append([]expectedKeys(nil), c.expected...)
+	d.codecs = append([]keyCodec(nil), c.codecs...)
+
+	if c.types != nil {
+		d.types = make(map[string]typedKey, len(c.types))
+		for k, v := range c.types {
+			d.types[k] = v
+		}
+	}
+	if c.aliases != nil {
+		d.aliases = make(map[string]string, len(c.aliases))
+		for k, v := range c.aliases {
+			d.aliases[k] = v
+		}
+	}
+	if c.aliasGroups != nil {
+		d.aliasGroups = make(map[string][]string, len(c.aliasGroups))
+		for k, v := range c.aliasGroups {
+			d.aliasGroups[k] = v
+		}
+	}
+
+	return d
+}
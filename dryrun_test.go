@@ -0,0 +1,75 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+
+	var hookRan bool
+	c.RegisterHook(func(Store) error {
+		hookRan = true
+		return nil
+	})
+	c.RegisterAlias("old", "new")
+	c.RegisterType("port", 0)
+	c.Strict("missing")
+
+	c.RegisterLoader(&MapLoader{
+		name: "l1",
+		values: Values{
+			"old":  "foo",
+			"port": "8080",
+		},
+	}, func(Store) error {
+		hookRan = true
+		return nil
+	})
+	c.RegisterLoader(&DummyLoader{err: true})
+	c.RegisterLoader(&MapLoader{
+		name:   "disabled",
+		values: Values{"disabled": true},
+	}).EnabledIf(func() bool { return false })
+	c.RegisterLoader(&MapLoader{
+		name:   "l2",
+		values: Values{"bar": "baz"},
+	})
+
+	var r = c.DryRun(context.Background())
+
+	require.Equal(t, Values{
+		"old":  "foo",
+		"new":  "foo",
+		"port": 8080,
+		"bar":  "baz",
+	}, r.Values)
+	require.Len(t, r.Errors, 2)
+	require.Contains(t, r.Errors[1].Error(), "missing")
+
+	// the store is left untouched
+	require.False(t, hookRan)
+	require.Equal(t, Values{}, c.Snapshot())
+	for _, wl := range c.(*store).WatcherLoaders {
+		require.Nil(t, wl.values)
+		require.True(t, wl.lastLoad.IsZero())
+	}
+}
+
+func TestIsDryRun(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+
+	var l = &ContextDummyLoader{}
+	c.RegisterLoader(l)
+
+	c.DryRun(context.Background())
+	require.True(t, IsDryRun(l.ctx))
+
+	require.Nil(t, c.Load())
+	require.False(t, IsDryRun(l.ctx))
+}
@@ -0,0 +1,61 @@
+package konfig
+
+import (
+	"fmt"
+
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	// ErrDuplicateKeyMsg is the error message returned when a key is set by two sources of a single load
+	// and the DuplicateKeyPolicy is DuplicateError
+	ErrDuplicateKeyMsg = "Err key '%s' is set by both '%s' and '%s'"
+)
+
+// DuplicateKeyPolicy is the policy applied by the built-in loaders reading several sources (e.g. several files or secrets)
+// when a key is set by more than one of them during a single load.
+// It does not apply across loaders, their values are merged by the store in registration order.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateOverwrite silently keeps the value of the last source, it is the default policy
+	DuplicateOverwrite DuplicateKeyPolicy = iota
+	// DuplicateError fails the load with an error naming the key and both sources
+	DuplicateError
+	// DuplicateWarn keeps the value of the last source and logs a warning naming the key and both sources
+	DuplicateWarn
+)
+
+// KeySources records the source of each key set during a single load and applies a DuplicateKeyPolicy.
+// A new KeySources must be created for each load.
+type KeySources struct {
+	policy  DuplicateKeyPolicy
+	logger  nlogger.Provider
+	sources map[string]string
+}
+
+// NewKeySources returns a new KeySources applying the policy p, warnings are logged with the logger l
+func NewKeySources(p DuplicateKeyPolicy, l nlogger.Provider) *KeySources {
+	return &KeySources{
+		policy:  p,
+		logger:  l,
+		sources: make(map[string]string),
+	}
+}
+
+// Set sets the key k to v in cs from the given source.
+// If k was set by another source during the load, it applies the policy,
+// with DuplicateError it returns an error and k is not set.
+func (ks *KeySources) Set(cs Values, source, k string, v interface{}) error {
+	if prev, ok := ks.sources[k]; ok && prev != source {
+		switch ks.policy {
+		case DuplicateError:
+			return fmt.Errorf(ErrDuplicateKeyMsg, k, prev, source)
+		case DuplicateWarn:
+			ks.logger.Get().Warn(fmt.Sprintf(ErrDuplicateKeyMsg, k, prev, source))
+		}
+	}
+	ks.sources[k] = source
+	cs.Set(k, v)
+	return nil
+}
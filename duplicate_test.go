@@ -0,0 +1,64 @@
+package konfig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lalamove/nui/nlogger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySources(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		policy   DuplicateKeyPolicy
+		err      bool
+		warn     bool
+		expected Values
+	}{
+		{
+			name:     "overwrite",
+			policy:   DuplicateOverwrite,
+			expected: Values{"foo": "b", "bar": "a"},
+		},
+		{
+			name:   "error",
+			policy: DuplicateError,
+			err:    true,
+		},
+		{
+			name:     "warn",
+			policy:   DuplicateWarn,
+			warn:     true,
+			expected: Values{"foo": "b", "bar": "a"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var ks = NewKeySources(testCase.policy, nlogger.NewProvider(nlogger.New(&buf, "")))
+			var v = Values{}
+
+			require.Nil(t, ks.Set(v, "a.json", "foo", "a"))
+			require.Nil(t, ks.Set(v, "a.json", "bar", "a"))
+			// the same source can set a key twice
+			require.Nil(t, ks.Set(v, "a.json", "foo", "a"))
+
+			var err = ks.Set(v, "b.json", "foo", "b")
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, "Err key 'foo' is set by both 'a.json' and 'b.json'", err.Error())
+				require.Equal(t, "a", v["foo"])
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+			if testCase.warn {
+				require.Contains(t, buf.String(), "Err key 'foo' is set by both 'a.json' and 'b.json'")
+			} else {
+				require.Empty(t, buf.String())
+			}
+		})
+	}
+}
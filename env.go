@@ -0,0 +1,89 @@
+package konfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// EnvKeySep is the separator used between key segments when generating environment variables
+	EnvKeySep = "_"
+	// EnvSliceSep is the separator used between elements when a slice is encoded as an environment variable
+	EnvSliceSep = ","
+)
+
+// ToEnv flattens the global store into a list of environment variables.
+// See Store.ToEnv for the details of the encoding.
+func ToEnv(prefix string) []string {
+	return instance().ToEnv(prefix)
+}
+
+// ToEnv flattens the store into a list of "PREFIX_KEY=value" entries suitable for exec.Cmd.Env.
+// Keys are uppercased and KeySep is replaced with EnvKeySep. If prefix is empty, keys are not prefixed.
+// Nested maps are flattened recursively, their keys being joined with EnvKeySep.
+// Slices are encoded as a single variable, elements being converted to strings and joined with EnvSliceSep.
+// All other values are converted to strings using cast.ToString. Entries are sorted by name.
+func (c *store) ToEnv(prefix string) []string {
+	var m = c.m.Load().(s)
+	var env = make(map[string]string, len(m))
+
+	var p string
+	if prefix != "" {
+		p = strings.ToUpper(prefix) + EnvKeySep
+	}
+
+	for k, v := range m {
+		flattenEnv(env, p+envKey(k), v)
+	}
+
+	var r = make([]string, 0, len(env))
+	for k, v := range env {
+		r = append(r, k+"="+v)
+	}
+	sort.Strings(r)
+
+	return r
+}
+
+func envKey(k string) string {
+	return strings.ToUpper(strings.Replace(k, KeySep, EnvKeySep, -1))
+}
+
+func flattenEnv(env map[string]string, k string, v interface{}) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for kk, vv := range vt {
+			flattenEnv(env, k+EnvKeySep+envKey(kk), vv)
+		}
+		return
+	case map[interface{}]interface{}:
+		for kk, vv := range vt {
+			flattenEnv(env, k+EnvKeySep+envKey(fmt.Sprintf("%v", kk)), vv)
+		}
+		return
+	case map[string]string:
+		for kk, vv := range vt {
+			env[k+EnvKeySep+envKey(kk)] = vv
+		}
+		return
+	case []byte:
+		env[k] = string(vt)
+		return
+	}
+
+	var rv = reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		var elems = make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elems[i] = cast.ToString(rv.Index(i).Interface())
+		}
+		env[k] = strings.Join(elems, EnvSliceSep)
+		return
+	}
+
+	env[k] = cast.ToString(v)
+}
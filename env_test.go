@@ -0,0 +1,75 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToEnv(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		prefix   string
+		values   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:   "scalars with prefix",
+			prefix: "app",
+			values: map[string]interface{}{
+				"db.host": "localhost",
+				"db.port": 5432,
+				"debug":   true,
+			},
+			expected: []string{
+				"APP_DB_HOST=localhost",
+				"APP_DB_PORT=5432",
+				"APP_DEBUG=true",
+			},
+		},
+		{
+			name: "no prefix",
+			values: map[string]interface{}{
+				"foo": "bar",
+			},
+			expected: []string{
+				"FOO=bar",
+			},
+		},
+		{
+			name: "nested maps and slices",
+			values: map[string]interface{}{
+				"db": map[string]interface{}{
+					"host": "localhost",
+					"opts": map[interface{}]interface{}{
+						"ssl": false,
+					},
+				},
+				"hosts": []string{"a", "b", "c"},
+				"ports": []interface{}{80, 443},
+			},
+			expected: []string{
+				"DB_HOST=localhost",
+				"DB_OPTS_SSL=false",
+				"HOSTS=a,b,c",
+				"PORTS=80,443",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var c = newStore(DefaultConfig())
+			for k, v := range testCase.values {
+				c.Set(k, v)
+			}
+			require.Equal(t, testCase.expected, c.ToEnv(testCase.prefix))
+		})
+	}
+
+	t.Run("global store", func(t *testing.T) {
+		reset()
+		Set("foo", "bar")
+		require.Equal(t, []string{"X_FOO=bar"}, ToEnv("x"))
+	})
+}
@@ -0,0 +1,72 @@
+package konfig
+
+import (
+	"time"
+)
+
+// eventsChanSize is the size of the buffer of the store events channel
+const eventsChanSize = 16
+
+// Event is a load event of a loader
+type Event struct {
+	// Loader is the name of the loader
+	Loader string
+	// Time is the time the load ended
+	Time time.Time
+	// Changed are the keys whose value changed during the load, sorted, including the keys removed from the store
+	Changed []string
+	// Err is the error of the load, nil if the load succeeded
+	Err error
+}
+
+// Events returns a channel receiving an event after each load of a loader of the global store.
+// See Store.Events.
+func Events() <-chan Event {
+	return instance().Events()
+}
+
+// Events returns a channel receiving an event after each load of a loader, including the initial load and the reloads triggered by watchers.
+// It complements hooks for consumers selecting on channels. Events are sent from the first call to Events.
+// The channel is buffered and events are sent without blocking:
+// if the consumer is slow and the buffer is full, the oldest event is dropped to make room for the new one, so the latest events are always delivered.
+func (c *store) Events() <-chan Event {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.events == nil {
+		c.events = make(chan Event, eventsChanSize)
+	}
+	return c.events
+}
+
+// sendEvent sends the load event of the loader wl on the events channel, dropping the oldest event if it is full
+func (c *store) sendEvent(wl *loaderWatcher, err error) {
+	c.mut.Lock()
+	var events = c.events
+	c.mut.Unlock()
+
+	// nobody listens to events
+	if events == nil {
+		return
+	}
+
+	var e = Event{
+		Loader:  wl.Name(),
+		Time:    time.Now(),
+		Changed: wl.changedKeys,
+		Err:     err,
+	}
+
+	for {
+		select {
+		case events <- e:
+			return
+		default:
+		}
+		// the buffer is full, we drop the oldest event
+		select {
+		case <-events:
+		default:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l = &MapLoader{
+		name: "l1",
+		values: Values{
+			"foo": "bar",
+			"baz": "qux",
+		},
+	}
+	RegisterLoader(l)
+
+	var events = Events()
+	require.True(t, events == Events())
+
+	var before = time.Now()
+	require.Nil(t, Load())
+
+	var e = <-events
+	require.Equal(t, "l1", e.Loader)
+	require.Equal(t, []string{"baz", "foo"}, e.Changed)
+	require.Nil(t, e.Err)
+	require.False(t, e.Time.Before(before))
+
+	// reloading the same values changes nothing
+	require.Nil(t, Load())
+	e = <-events
+	require.Equal(t, "l1", e.Loader)
+	require.Empty(t, e.Changed)
+
+	// changed and removed keys
+	l.values = Values{
+		"foo": "quux",
+	}
+	require.Nil(t, Load())
+	e = <-events
+	require.Equal(t, []string{"baz", "foo"}, e.Changed)
+
+	select {
+	case e = <-events:
+		t.Fatalf("unexpected event %v", e)
+	default:
+	}
+}
+
+func TestEventsError(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	RegisterLoader(&DummyLoader{err: true})
+
+	var events = Events()
+	require.NotNil(t, Load())
+
+	var e = <-events
+	require.Equal(t, "dummy", e.Loader)
+	require.NotNil(t, e.Err)
+	require.Empty(t, e.Changed)
+}
+
+func TestEventsDropOldest(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l = &MapLoader{name: "l1", values: Values{}}
+	RegisterLoader(l)
+
+	var events = Events()
+	for i := 0; i < eventsChanSize+4; i++ {
+		l.values = Values{"i": i}
+		require.Nil(t, Load())
+	}
+
+	require.Equal(t, eventsChanSize, len(events))
+
+	var e Event
+	for len(events) > 0 {
+		e = <-events
+	}
+	require.Equal(t, []string{"i"}, e.Changed)
+	require.Equal(t, eventsChanSize+3, MustGet("i"))
+}
+
+func TestEventsNotListening(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+	c.RegisterLoader(&MapLoader{name: "l1", values: Values{"foo": "bar"}})
+
+	require.Nil(t, c.Load())
+	require.Nil(t, c.(*store).events)
+}
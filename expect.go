@@ -0,0 +1,94 @@
+package konfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrExpectedKeysMsg is the error message returned when expected keys are missing after a load of the store
+	ErrExpectedKeysMsg = "Err expected keys missing: %s"
+	// ErrExpectedKeyMsg is the description of an expected key missing in ErrExpectedKeysMsg
+	ErrExpectedKeyMsg = "'%s' (expected from loader '%s')"
+	// ErrExpectedKeyNoLoaderMsg is the description of an expected key missing when its loader is not registered
+	ErrExpectedKeyNoLoaderMsg = "'%s' (expected from loader '%s', which is not registered)"
+)
+
+// expectedKeys are keys expected to be loaded by a loader
+type expectedKeys struct {
+	loader string
+	keys   []string
+}
+
+// ExpectKeys declares keys the loader with the given name is expected to load in the global store.
+// See Store.ExpectKeys.
+func ExpectKeys(loader string, keys ...string) Store {
+	return instance().ExpectKeys(loader, keys...)
+}
+
+// ExpectKeys declares keys the loader with the given name is expected to load.
+// A key is present if it is set in the store or if it prefixes a key set in the store followed by KeySep (e.g. "db" is present if "db.host" is set),
+// so a prefix can be used to check that a whole loader ran.
+// After loading all the loaders, Load checks the expected keys and returns an error listing all the missing keys,
+// the loader expected to load them and wether this loader is registered.
+func (c *store) ExpectKeys(loader string, keys ...string) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.expected = append(c.expected, expectedKeys{loader: loader, keys: keys})
+	return c
+}
+
+// checkExpectedKeys returns an error listing the expected keys missing in the store
+func (c *store) checkExpectedKeys() error {
+	c.mut.Lock()
+	var expected = c.expected
+	c.mut.Unlock()
+
+	if len(expected) == 0 {
+		return nil
+	}
+
+	var m = c.m.Load().(s)
+	var missing []string
+	for _, e := range expected {
+		for _, k := range e.keys {
+			if keyPresent(m, k) {
+				continue
+			}
+			if c.loaderRegistered(e.loader) {
+				missing = append(missing, fmt.Sprintf(ErrExpectedKeyMsg, k, e.loader))
+			} else {
+				missing = append(missing, fmt.Sprintf(ErrExpectedKeyNoLoaderMsg, k, e.loader))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf(ErrExpectedKeysMsg, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (c *store) loaderRegistered(name string) bool {
+	for _, wl := range c.loaders() {
+		if wl.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// keyPresent tells wether the key k is set in m or prefixes a key set in m
+func keyPresent(m s, k string) bool {
+	if _, ok := m[k]; ok {
+		return true
+	}
+	var prefix = k + KeySep
+	for kk := range m {
+		if strings.HasPrefix(kk, prefix) {
+			return true
+		}
+	}
+	return false
+}
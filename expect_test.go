@@ -0,0 +1,74 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectKeys(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		expect   func(c Store)
+		err      bool
+		contains []string
+	}{
+		{
+			name: "keys and prefixes present",
+			expect: func(c Store) {
+				c.ExpectKeys("l1", "foo", "db")
+			},
+		},
+		{
+			name: "missing keys",
+			expect: func(c Store) {
+				c.ExpectKeys("l1", "foo", "bar")
+				c.ExpectKeys("vault", "secrets")
+			},
+			err: true,
+			contains: []string{
+				"'bar' (expected from loader 'l1')",
+				"'secrets' (expected from loader 'vault', which is not registered)",
+			},
+		},
+		{
+			name: "prefix is not a key prefix",
+			expect: func(c Store) {
+				c.ExpectKeys("l1", "d")
+			},
+			err:      true,
+			contains: []string{"'d' (expected from loader 'l1')"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			reset()
+			var c = New(DefaultConfig())
+			c.RegisterLoader(&MapLoader{
+				name: "l1",
+				values: Values{
+					"foo":     "bar",
+					"db.host": "localhost",
+				},
+			})
+			testCase.expect(c)
+
+			var err = c.Load()
+			if !testCase.err {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			for _, s := range testCase.contains {
+				require.Contains(t, err.Error(), s)
+			}
+
+			// the dry run reports the same error
+			var r = c.DryRun(context.Background())
+			require.Len(t, r.Errors, 1)
+			require.Equal(t, err.Error(), r.Errors[0].Error())
+		})
+	}
+}
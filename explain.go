@@ -0,0 +1,50 @@
+package konfig
+
+// LayerValue is the value a loader provided for a key
+type LayerValue struct {
+	// Loader is the name of the loader, it is empty if the value was set directly on the store with Set
+	Loader string
+	// Value is the value provided by the loader
+	Value interface{}
+	// Effective tells wether the value is the one in effect in the store
+	Effective bool
+}
+
+// Explain returns the value each loader of the global store provided for the key k and which one is in effect.
+// See Store.Explain.
+func Explain(k string) []LayerValue {
+	return instance().Explain(k)
+}
+
+// Explain returns, for each loader which provided a value for the key k, the value it provided and wether it is the one in effect,
+// in the order the loaders were registered, so from the lowest to the highest precedence.
+// If the value in effect was set directly on the store with Set, it is returned last with an empty loader name.
+// Values are the contributions of the loaders after their transforms, if the store has a Merge function the value in effect is the merge of the values up to the effective layer:
+// use Get to read it. It does not lock the store, so it can be called from hooks.
+func (c *store) Explain(k string) []LayerValue {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	var p, set = c.sources[k]
+	var layers []LayerValue
+	for _, wl := range c.WatcherLoaders {
+		if v, ok := wl.values[k]; ok {
+			layers = append(layers, LayerValue{
+				Loader:    wl.Name(),
+				Value:     v,
+				Effective: set && p.wl == wl,
+			})
+		}
+	}
+
+	if set && p.wl == nil {
+		if v, ok := c.m.Load().(s)[k]; ok {
+			layers = append(layers, LayerValue{
+				Value:     v,
+				Effective: true,
+			})
+		}
+	}
+
+	return layers
+}
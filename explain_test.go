@@ -0,0 +1,85 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l1 = &MapLoader{name: "file", values: Values{"db.host": "localhost", "db.port": 5432}}
+	var l2 = &MapLoader{name: "env", values: Values{"db.host": "db.internal"}}
+	var l3 = &MapLoader{name: "vault", values: Values{"db.pass": "secret"}}
+	RegisterLoader(l1)
+	RegisterLoader(l2)
+	RegisterLoader(l3)
+	require.Nil(t, Load())
+
+	require.Equal(
+		t,
+		[]LayerValue{
+			{Loader: "file", Value: "localhost"},
+			{Loader: "env", Value: "db.internal", Effective: true},
+		},
+		Explain("db.host"),
+	)
+	require.Equal(
+		t,
+		[]LayerValue{
+			{Loader: "file", Value: 5432, Effective: true},
+		},
+		Explain("db.port"),
+	)
+	require.Nil(t, Explain("nope"))
+
+	// a key not provided anymore by the effective loader falls back to the previous layer
+	l2.values = Values{}
+	require.Nil(t, Load())
+	require.Equal(
+		t,
+		[]LayerValue{
+			{Loader: "file", Value: "localhost", Effective: true},
+		},
+		Explain("db.host"),
+	)
+
+	// a value set directly is in effect over the loaders
+	Set("db.port", 5433)
+	require.Equal(
+		t,
+		[]LayerValue{
+			{Loader: "file", Value: 5432},
+			{Value: 5433, Effective: true},
+		},
+		Explain("db.port"),
+	)
+
+	Set("other", "foo")
+	require.Equal(
+		t,
+		[]LayerValue{
+			{Value: "foo", Effective: true},
+		},
+		Explain("other"),
+	)
+}
+
+func TestExplainFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var layers []LayerValue
+	RegisterHook(func(s Store) error {
+		layers = s.Explain("foo")
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.Equal(t, []LayerValue{{Loader: "a", Value: "bar", Effective: true}}, layers)
+}
@@ -0,0 +1,83 @@
+package konfig
+
+import "fmt"
+
+// FailureMode is the behaviour of the store when a loader fails during the initial load
+type FailureMode int
+
+const (
+	// FailureFatal makes the initial load fail, it is the default
+	FailureFatal FailureMode = iota
+	// FailureWarn logs a warning and continues the initial load without the loader values
+	FailureWarn
+	// FailureIgnore continues the initial load without the loader values
+	FailureIgnore
+)
+
+// ErrLoaderDegradedMsg is the message logged when a loader with FailureWarn fails during the initial load
+var ErrLoaderDegradedMsg = "Err loader '%s' failed during initial load, continuing without it: %v"
+
+var failureModeNames = map[FailureMode]string{
+	FailureFatal:  "fatal",
+	FailureWarn:   "warn",
+	FailureIgnore: "ignore",
+}
+
+func (m FailureMode) String() string {
+	if n, ok := failureModeNames[m]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// FailureMode sets the behaviour of the store when the loader fails during the initial load of the store.
+// With FailureWarn or FailureIgnore, the store starts without the values of the loader and the loader is reported by Degraded
+// until one of its loads succeeds. Failures after the initial load are not affected.
+func (cl *ConfigLoader) FailureMode(mode FailureMode) *ConfigLoader {
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	cl.loaderWatcher.failureMode = mode
+
+	return cl
+}
+
+// Degraded returns the names of the loaders of the global store which degraded during the initial load.
+// See Store.Degraded.
+func Degraded() []string {
+	return instance().Degraded()
+}
+
+// Degraded returns the names of the loaders which failed during the initial load of the store
+// and did not load successfully since, because their FailureMode is FailureWarn or FailureIgnore.
+func (c *store) Degraded() []string {
+	var r []string
+	for _, wl := range c.loaders() {
+		wl.statusMut.Lock()
+		var degraded = wl.degraded
+		wl.statusMut.Unlock()
+
+		if degraded {
+			r = append(r, wl.Name())
+		}
+	}
+	return r
+}
+
+// degrade handles the failure err of the loader wl during the initial load,
+// it returns true if the initial load can continue without the loader
+func (c *store) degrade(wl *loaderWatcher, err error) bool {
+	switch wl.failureMode {
+	case FailureWarn:
+		c.cfg.Logger.Get().Warn(fmt.Sprintf(ErrLoaderDegradedMsg, wl.Name(), err))
+	case FailureIgnore:
+	default:
+		return false
+	}
+
+	wl.statusMut.Lock()
+	wl.degraded = true
+	wl.statusMut.Unlock()
+
+	return true
+}
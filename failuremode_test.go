@@ -0,0 +1,99 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureMode(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		mode     FailureMode
+		err      bool
+		degraded []string
+	}{
+		{
+			name: "fatal",
+			mode: FailureFatal,
+			err:  true,
+		},
+		{
+			name:     "warn",
+			mode:     FailureWarn,
+			degraded: []string{"vault"},
+		},
+		{
+			name:     "ignore",
+			mode:     FailureIgnore,
+			degraded: []string{"vault"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var c = New(&Config{NoExitOnError: true})
+
+			var file = &OwnerLoader{
+				name: "file",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "bar"}},
+				},
+			}
+			var vault = &OwnerLoader{
+				name: "vault",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"secret", "s3cr3t"}},
+					err:        true,
+				},
+			}
+			c.RegisterLoader(vault).FailureMode(testCase.mode)
+			c.RegisterLoader(file)
+
+			var err = c.Load()
+			if testCase.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, "bar", c.Get("foo"))
+			require.Nil(t, c.Get("secret"))
+			require.Equal(t, testCase.degraded, c.Degraded())
+			require.True(t, c.Stats().Loaders[0].Degraded)
+
+			// failures after the initial load are not degraded
+			require.NotNil(t, c.Load())
+
+			// the loader recovers
+			vault.err = false
+			require.Nil(t, c.ReloadLoader("vault"))
+			require.Equal(t, "s3cr3t", c.Get("secret"))
+			require.Nil(t, c.Degraded())
+		})
+	}
+}
+
+func TestFailureModeString(t *testing.T) {
+	require.Equal(t, "fatal", FailureFatal.String())
+	require.Equal(t, "warn", FailureWarn.String())
+	require.Equal(t, "ignore", FailureIgnore.String())
+	require.Equal(t, "unknown", FailureMode(42).String())
+}
+
+func TestDegradedFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&DummyLoader{err: true}).FailureMode(FailureWarn)
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var degraded []string
+	RegisterHook(func(s Store) error {
+		degraded = s.Degraded()
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.Len(t, degraded, 1)
+}
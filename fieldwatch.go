@@ -0,0 +1,106 @@
+package konfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNoBoundValue is the error thrown when trying to watch a field of the bound value before a value is bound to the store
+	ErrNoBoundValue = errors.New("Err no value bound to the store")
+	// ErrFieldNotFoundMsg is the error message thrown when trying to watch a field which does not exist in the bound value
+	ErrFieldNotFoundMsg = "Err field '%s' not found in bound value"
+)
+
+// fieldWatcher is a function called when the field at path of the bound value changes
+type fieldWatcher struct {
+	path []string
+	f    func(oldValue, newValue interface{})
+}
+
+// WatchField registers a function called when the field at the given path of the value bound to the global store changes.
+// See Store.WatchField.
+func WatchField(path string, f func(oldValue, newValue interface{})) Store {
+	return instance().WatchField(path, f)
+}
+
+// WatchField registers a function called with the old and the new value of the field at the given path of the bound value
+// when a load or a Set changes it, e.g. "DB.MaxConns" for the field MaxConns of the struct field DB, pointers to structs are followed.
+// The path is made of the names of the fields separated by KeySep, if the bound value is a map[string]interface{} the path is a key of the map.
+// Values are compared with reflect.DeepEqual between two snapshots of the bound value (see BoundSnapshot),
+// so f is called once per load changing the field, even if several keys of the field change. The value of a field behind a nil pointer is nil.
+// Like hooks, f is called synchronously with the store locked, it can read the store but must not write it.
+// It panics if no value is bound to the store or if the field does not exist in the bound struct.
+func (c *store) WatchField(path string, f func(oldValue, newValue interface{})) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.v == nil {
+		panic(ErrNoBoundValue)
+	}
+
+	var p = strings.Split(path, KeySep)
+	if c.v.isMap {
+		p = []string{path}
+	} else if !hasField(c.v.vt, p) {
+		panic(fmt.Errorf(ErrFieldNotFoundMsg, path))
+	}
+
+	c.v.fieldWatchers = append(c.v.fieldWatchers, fieldWatcher{path: p, f: f})
+	return c
+}
+
+// notifyFields calls the field watchers of the fields which changed between the snapshots ov and nv
+func (val *value) notifyFields(ov, nv interface{}) {
+	for _, fw := range val.fieldWatchers {
+		var of, nf = fieldValue(ov, fw.path), fieldValue(nv, fw.path)
+		if !reflect.DeepEqual(of, nf) {
+			fw.f(of, nf)
+		}
+	}
+}
+
+// hasField tells wether the type t has an exported field at the path p
+func hasField(t reflect.Type, p []string) bool {
+	for _, name := range p {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		var sf, ok = t.FieldByName(name)
+		if !ok || sf.PkgPath != "" {
+			return false
+		}
+		t = sf.Type
+	}
+	return true
+}
+
+// fieldValue returns the value of the field at the path p of v, or nil if a pointer on the path is nil
+func fieldValue(v interface{}, p []string) interface{} {
+	var rv = reflect.ValueOf(v)
+	for _, name := range p {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil
+			}
+			rv = rv.Elem()
+		}
+		switch rv.Kind() {
+		case reflect.Struct:
+			rv = rv.FieldByName(name)
+		case reflect.Map:
+			rv = rv.MapIndex(reflect.ValueOf(name))
+		default:
+			return nil
+		}
+		if !rv.IsValid() {
+			return nil
+		}
+	}
+	return rv.Interface()
+}
@@ -0,0 +1,116 @@
+package konfig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchField(t *testing.T) {
+	type DBConfig struct {
+		Host     string `konfig:"host"`
+		MaxConns int    `konfig:"max_conns"`
+	}
+	type Config struct {
+		Port int       `konfig:"port"`
+		DB   *DBConfig `konfig:"db"`
+	}
+
+	t.Run(
+		"calls the watcher when the field changes",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			Bind(Config{})
+
+			var changes [][2]interface{}
+			WatchField("DB.MaxConns", func(ov, nv interface{}) {
+				changes = append(changes, [2]interface{}{ov, nv})
+			})
+
+			var l = &MapLoader{name: "l1", values: Values{"port": 8080, "db.host": "db1", "db.max_conns": 10}}
+			RegisterLoader(l)
+			require.Nil(t, Load())
+			// the DB field was nil before the first load
+			require.Equal(t, [][2]interface{}{{nil, 10}}, changes)
+
+			// other fields changing do not call the watcher
+			l.values = Values{"port": 9090, "db.host": "db2", "db.max_conns": 10}
+			require.Nil(t, Load())
+			require.Len(t, changes, 1)
+
+			l.values = Values{"port": 9090, "db.host": "db2", "db.max_conns": 20}
+			require.Nil(t, Load())
+			require.Equal(t, [][2]interface{}{{nil, 10}, {10, 20}}, changes)
+
+			Set("db.max_conns", 30)
+			require.Equal(t, [2]interface{}{20, 30}, changes[2])
+		},
+	)
+
+	t.Run(
+		"watches a struct field",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			Bind(Config{})
+
+			var calls int
+			var last *DBConfig
+			WatchField("DB", func(ov, nv interface{}) {
+				calls++
+				last = nv.(*DBConfig)
+			})
+
+			var l = &MapLoader{name: "l1", values: Values{"db.host": "db1", "db.max_conns": 10}}
+			RegisterLoader(l)
+			require.Nil(t, Load())
+			require.Equal(t, 1, calls)
+			require.Equal(t, &DBConfig{Host: "db1", MaxConns: 10}, last)
+
+			// both keys change in a single load
+			l.values = Values{"db.host": "db2", "db.max_conns": 20}
+			require.Nil(t, Load())
+			require.Equal(t, 2, calls)
+			require.Equal(t, &DBConfig{Host: "db2", MaxConns: 20}, last)
+		},
+	)
+
+	t.Run(
+		"bound map",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			Bind(map[string]interface{}{})
+
+			var changes []string
+			WatchField("db.host", func(ov, nv interface{}) {
+				changes = append(changes, fmt.Sprintf("%v -> %v", ov, nv))
+			})
+
+			RegisterLoader(&MapLoader{name: "l1", values: Values{"db.host": "db1"}})
+			require.Nil(t, Load())
+			require.Equal(t, []string{"<nil> -> db1"}, changes)
+		},
+	)
+
+	t.Run(
+		"panics",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			require.PanicsWithValue(t, ErrNoBoundValue, func() {
+				WatchField("Port", func(ov, nv interface{}) {})
+			})
+
+			Bind(Config{})
+			require.Panics(t, func() {
+				WatchField("DB.User", func(ov, nv interface{}) {})
+			})
+			require.Panics(t, func() {
+				WatchField("Port.Number", func(ov, nv interface{}) {})
+			})
+		},
+	)
+}
@@ -2,11 +2,14 @@ package konfig
 
 import "github.com/lalamove/nui/ngetter"
 
-// Getter returns a mgetter.Getter for the key k
+// Getter returns a ngetter.GetterTyped for the key k of the global store.
+// See Store.Getter.
 func Getter(k string) ngetter.GetterTyped {
 	return instance().Getter(k)
 }
 
+// Getter returns a ngetter.GetterTyped reading the key k from the store each time it is called
+
 func (c *store) Getter(k string) ngetter.GetterTyped {
 	return ngetter.GetterTypedFunc(func() interface{} {
 		return c.Get(k)
@@ -28,15 +28,18 @@ require (
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v0.9.2
 	github.com/radovskyb/watcher v1.0.5
+	github.com/robfig/cron v1.2.0
 	github.com/ryanuber/go-glob v0.0.0-20160226084822-572520ed46db
 	github.com/spf13/cast v1.3.0
 	github.com/stretchr/testify v1.3.0
+	go.etcd.io/bbolt v1.3.5
 	go.etcd.io/etcd v3.3.10+incompatible
 	golang.org/x/net v0.0.0-20190110200230-915654e7eabc
-	golang.org/x/sys v0.0.0-20190109145017-48ac38b7c8cb
+	golang.org/x/sys v0.0.0-20200202164722-d101bd2416d5
 	golang.org/x/text v0.3.0
 	golang.org/x/time v0.0.0-20181108054448-85acf8d2951c
 	google.golang.org/genproto v0.0.0-20190110221437-6909d8a4a91b
 	google.golang.org/grpc v1.17.0
-	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v2 v2.2.8
+	gopkg.in/yaml.v3 v3.0.1
 )
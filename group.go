@@ -1,5 +1,17 @@
 package konfig
 
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrGroupHookCycleMsg is the error message returned when registering group hooks creating a cycle between stores
+var ErrGroupHookCycleMsg = "Err cyclic group hooks between: %s"
+
+// groupHooksMut guards the hookTargets of all the stores
+var groupHooksMut sync.Mutex
+
 // Group gets a group of configs
 func Group(groupName string) Store {
 	return instance().Group(groupName)
@@ -18,6 +30,89 @@ func (c *store) lazyGroup(groupName string) Store {
 	}
 	c.groups[groupName] = newStore(c.cfg)
 	c.groups[groupName].name = groupName
+	c.groups[groupName].parent = c
 
 	return c.groups[groupName]
 }
+
+// GetGroup returns the group g of the global store and wether it exists.
+// See Store.GetGroup.
+func GetGroup(g string) (Store, bool) {
+	return instance().GetGroup(g)
+}
+
+// GetGroup returns the group g of the store and wether it exists, unlike Group it does not create the group.
+func (c *store) GetGroup(g string) (Store, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var v, ok = c.groups[g]
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+// Parent returns the store of which the store is a group, e.g. the global store for konfig.Group("db").
+// It returns nil if the store is not a group.
+func (c *store) Parent() Store {
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent
+}
+
+// RegisterGroupHook registers hooks on the global store run after every successful load of the group g.
+// See Store.RegisterGroupHook.
+func RegisterGroupHook(g Store, hooks ...func(Store) error) error {
+	return instance().RegisterGroupHook(g, hooks...)
+}
+
+// RegisterGroupHook registers hooks run with the store after every successful load of the store g, usually another group,
+// so that a change in a group can trigger a recomputation in another one. They run with the store hooks of g, ChangedKeys of g returns the keys changed.
+// The hooks run while g is locked: calling Set on the store from the hooks is safe, but calling Set on g is not.
+// Group hooks must not form cycles, where two stores run hooks on the loads of each other, as their hooks setting values in each other could deadlock.
+// If registering the hooks would create a cycle, an error is returned and the hooks are not registered.
+// If g is not a store created by konfig, e.g. a wrapper of a store, the hooks are registered with its RegisterHook and cycles are not detected.
+func (c *store) RegisterGroupHook(g Store, hooks ...func(Store) error) error {
+	var lh = LoaderHooks(hooks)
+	var gs, ok = g.(*store)
+	if !ok {
+		g.RegisterHook(func(Store) error {
+			return c.runHooks(lh)
+		})
+		return nil
+	}
+
+	groupHooksMut.Lock()
+	defer groupHooksMut.Unlock()
+
+	if path := hookPath(c, gs, nil); path != nil {
+		var names = make([]string, 0, len(path)+1)
+		for _, s := range path {
+			names = append(names, s.name)
+		}
+		return fmt.Errorf(ErrGroupHookCycleMsg, strings.Join(append(names, c.name), " -> "))
+	}
+	gs.hookTargets = append(gs.hookTargets, c)
+
+	gs.RegisterHook(func(Store) error {
+		return c.runHooks(lh)
+	})
+	return nil
+}
+
+// hookPath returns the path of stores from the store from to the store to, following the stores whose hooks the loads of a store run.
+// The path includes from and to, it is nil if there is none. It must be called with groupHooksMut locked.
+func hookPath(from, to *store, path []*store) []*store {
+	path = append(path, from)
+	if from == to {
+		return path
+	}
+	for _, t := range from.hookTargets {
+		if p := hookPath(t, to, path); p != nil {
+			return p
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package konfig
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -22,3 +24,111 @@ func TestGroup(t *testing.T) {
 		},
 	)
 }
+
+func TestGetGroup(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var _, ok = GetGroup("db")
+	require.False(t, ok)
+
+	var db = Group("db")
+	g, ok := GetGroup("db")
+	require.True(t, ok)
+	require.Equal(t, db, g)
+
+	require.Equal(t, Instance(), db.Parent())
+	require.Nil(t, Instance().Parent())
+	require.Equal(t, db, db.Parent().Group("db"))
+}
+
+type wrappedStore struct {
+	Store
+}
+
+func TestRegisterGroupHook(t *testing.T) {
+	t.Run(
+		"runs the hooks with the store on loads of the group",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var secrets = Group("secrets")
+			var db = Group("db")
+			var l = &MapLoader{name: "vault", values: Values{"db.password": "secret"}}
+			secrets.RegisterLoader(l)
+			db.Set("host", "localhost")
+
+			require.Nil(t, db.RegisterGroupHook(secrets, func(s Store) error {
+				s.Set("dsn", fmt.Sprintf("%s@%s", secrets.MustString("db.password"), s.MustString("host")))
+				return nil
+			}))
+
+			require.Nil(t, secrets.Load())
+			require.Equal(t, "secret@localhost", db.Get("dsn"))
+
+			l.values = Values{"db.password": "rotated"}
+			require.Nil(t, secrets.Reload())
+			require.Equal(t, "rotated@localhost", db.Get("dsn"))
+		},
+	)
+
+	t.Run(
+		"hook errors fail the load of the group",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var secrets = Group("secrets")
+			secrets.RegisterLoader(&MapLoader{name: "vault", values: Values{"foo": "bar"}})
+			require.Nil(t, RegisterGroupHook(secrets, func(Store) error {
+				return errors.New("recompute")
+			}))
+
+			require.NotNil(t, secrets.Reload())
+		},
+	)
+
+	t.Run(
+		"other store implementations",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var secrets = Group("secrets")
+			secrets.RegisterLoader(&MapLoader{name: "vault", values: Values{"foo": "bar"}})
+
+			var ran bool
+			require.Nil(t, RegisterGroupHook(wrappedStore{secrets}, func(Store) error {
+				ran = true
+				return nil
+			}))
+
+			require.Nil(t, secrets.Load())
+			require.True(t, ran)
+		},
+	)
+
+	t.Run(
+		"cycles",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var a, b, c = Group("a"), Group("b"), Group("c")
+			require.Nil(t, b.RegisterGroupHook(a, func(Store) error { return nil }))
+			require.Nil(t, c.RegisterGroupHook(b, func(Store) error { return nil }))
+
+			var err = a.RegisterGroupHook(c, func(Store) error { return nil })
+			require.Equal(t, "Err cyclic group hooks between: a -> b -> c -> a", err.Error())
+
+			err = a.RegisterGroupHook(a, func(Store) error { return nil })
+			require.Equal(t, "Err cyclic group hooks between: a -> a", err.Error())
+
+			// the global store is not part of the cycle
+			require.Nil(t, RegisterGroupHook(c, func(Store) error { return nil }))
+			require.Nil(t, a.Reset())
+			require.Nil(t, a.RegisterGroupHook(c, func(Store) error { return nil }))
+		},
+	)
+}
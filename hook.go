@@ -0,0 +1,265 @@
+package konfig
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// errorsChanSize is the size of the buffer of the store errors channel
+const errorsChanSize = 16
+
+// ErrHookTimeout is the error returned when a hook runs longer than the store HookTimeout
+var ErrHookTimeout = errors.New("Err hook timed out")
+
+// HookPanicError is the error returned when a hook panics
+type HookPanicError struct {
+	// Value is the value the hook panicked with
+	Value interface{}
+	// Stack is the stack trace of the goroutine when the hook panicked
+	Stack []byte
+}
+
+func (e *HookPanicError) Error() string {
+	return fmt.Sprintf("Err hook panicked: %v", e.Value)
+}
+
+// Errors returns a channel receiving the errors of the global store hooks panicking or timing out.
+// See Store.Errors.
+func Errors() <-chan error {
+	return instance().Errors()
+}
+
+// Errors returns a channel receiving the errors of hooks panicking or timing out.
+// Errors are sent without blocking, if nobody reads the channel and its buffer is full, errors are dropped.
+func (c *store) Errors() <-chan error {
+	return c.errs
+}
+
+// runHooks runs the hooks with the store HookTimeout and reports panics and timeouts on the errors channel
+func (c *store) runHooks(hooks LoaderHooks) error {
+	var err = hooks.run(c, c.cfg.HookTimeout)
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, e := range merr.Errors {
+			if _, ok := e.(*HookPanicError); ok || e == ErrHookTimeout {
+				c.reportError(e)
+			}
+		}
+	}
+	return err
+}
+
+func (c *store) reportError(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+func (l LoaderHooks) run(cfg Store, timeout time.Duration) error {
+	var multiErr *multierror.Error
+	for _, h := range l {
+		if err := runHook(h, cfg, timeout); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+func runHook(h func(Store) error, cfg Store, timeout time.Duration) error {
+	if timeout <= 0 {
+		return safeHook(h, cfg)
+	}
+
+	var errChan = make(chan error, 1)
+	go func() {
+		errChan <- safeHook(h, cfg)
+	}()
+
+	var t = time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-t.C:
+		return ErrHookTimeout
+	}
+}
+
+// safeHook runs the hook and recovers if it panics
+func safeHook(h func(Store) error, cfg Store) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HookPanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	return h(cfg)
+}
+
+var (
+	// ErrHookNoRunMsg is the error message returned when registering a hook without a Run function
+	ErrHookNoRunMsg = "Err hook '%s' has no Run function"
+	// ErrHookDuplicateMsg is the error message returned when registering a hook with a name already registered
+	ErrHookDuplicateMsg = "Err hook '%s' already registered"
+	// ErrHookCycleMsg is the error message returned when hook dependencies are cyclic
+	ErrHookCycleMsg = "Err cyclic hook dependencies between: %s"
+)
+
+// Hook is a store hook with an explicit position in the execution order.
+// Hooks run in a topological order of their dependencies, hooks without dependencies between them run by ascending Priority
+// then in registration order.
+type Hook struct {
+	// Name is the name of the hook, it is used by other hooks to depend on it
+	Name string
+	// Priority is the priority of the hook, hooks with a lower priority run first. Hooks registered with RegisterHook have a priority of 0.
+	Priority int
+	// After is the list of names of the hooks which must run before this one.
+	// Dependencies on hooks which are not registered are ignored.
+	After []string
+	// Run is the function ran
+	Run func(Store) error
+}
+
+// RegisterOrderedHooks registers hooks on the global store. See Store.RegisterOrderedHooks.
+func RegisterOrderedHooks(hooks ...Hook) error {
+	return instance().RegisterOrderedHooks(hooks...)
+}
+
+// RegisterOrderedHooks registers hooks on the store, they run after every successful load of any of the store's loaders,
+// after the loader hooks. The execution order is validated at registration,
+// if the dependencies are cyclic an error is returned and none of the hooks are registered.
+func (c *store) RegisterOrderedHooks(hooks ...Hook) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var defs = make([]Hook, len(c.hookDefs), len(c.hookDefs)+len(hooks))
+	copy(defs, c.hookDefs)
+
+	var names = make(map[string]bool, len(defs))
+	for _, h := range defs {
+		if h.Name != "" {
+			names[h.Name] = true
+		}
+	}
+
+	for _, h := range hooks {
+		if h.Run == nil {
+			return fmt.Errorf(ErrHookNoRunMsg, h.Name)
+		}
+		if h.Name != "" {
+			if names[h.Name] {
+				return fmt.Errorf(ErrHookDuplicateMsg, h.Name)
+			}
+			names[h.Name] = true
+		}
+		defs = append(defs, h)
+	}
+
+	var sorted, err = sortHooks(defs)
+	if err != nil {
+		return err
+	}
+
+	c.hookDefs = defs
+	c.hooks = sorted
+	return nil
+}
+
+// sortHooks sorts the hooks topologically, ties are broken by priority then by registration order
+func sortHooks(defs []Hook) (LoaderHooks, error) {
+	var idx = make(map[string]int, len(defs))
+	for i, h := range defs {
+		if h.Name != "" {
+			idx[h.Name] = i
+		}
+	}
+
+	var done = make([]bool, len(defs))
+	var sorted = make(LoaderHooks, 0, len(defs))
+
+	for len(sorted) < len(defs) {
+		var next = -1
+		for i, h := range defs {
+			if done[i] || !hookReady(h, idx, done) {
+				continue
+			}
+			if next == -1 || h.Priority < defs[next].Priority {
+				next = i
+			}
+		}
+
+		if next == -1 {
+			var cycle []string
+			for i, h := range defs {
+				if !done[i] {
+					cycle = append(cycle, h.Name)
+				}
+			}
+			return nil, fmt.Errorf(ErrHookCycleMsg, strings.Join(cycle, ", "))
+		}
+
+		done[next] = true
+		sorted = append(sorted, defs[next].Run)
+	}
+
+	return sorted, nil
+}
+
+// hookReady tells wether all the registered dependencies of the hook have been sorted
+func hookReady(h Hook, idx map[string]int, done []bool) bool {
+	for _, dep := range h.After {
+		if i, ok := idx[dep]; ok && !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterInitHook adds init hooks to the global store.
+// See Store.RegisterInitHook.
+func RegisterInitHook(hooks ...func(Store) error) Store {
+	return instance().RegisterInitHook(hooks...)
+}
+
+// RegisterInitHook adds hooks which run once, after the first successful Load of the store, unlike store hooks which run after every load.
+// If they fail, Load returns their error and they run again on the next Load. Hooks registered after the init hooks ran run immediately,
+// their errors are logged and reported on the errors channel.
+func (c *store) RegisterInitHook(hooks ...func(Store) error) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.initialized {
+		if err := c.runHooks(hooks); err != nil {
+			c.cfg.Logger.Get().Error("Error while running init hooks: " + err.Error())
+			c.reportError(err)
+		}
+		return c
+	}
+
+	c.initHooks = append(c.initHooks, hooks...)
+	return c
+}
+
+func (c *store) runInitHooks() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.initialized {
+		return nil
+	}
+	if err := c.runHooks(c.initHooks); err != nil {
+		return err
+	}
+	c.initialized = true
+	c.initHooks = nil
+
+	return nil
+}
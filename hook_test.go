@@ -0,0 +1,243 @@
+package konfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookIsolation(t *testing.T) {
+	t.Run(
+		"panic is recovered and other hooks run",
+		func(t *testing.T) {
+			var ran []string
+			var loaderHooks = LoaderHooks{
+				func(Store) error {
+					panic("boom")
+				},
+				func(Store) error {
+					ran = append(ran, "second")
+					return errors.New("err")
+				},
+				func(Store) error {
+					ran = append(ran, "third")
+					return nil
+				},
+			}
+
+			var err = loaderHooks.Run(New(DefaultConfig()))
+			require.NotNil(t, err)
+			require.Equal(t, []string{"second", "third"}, ran)
+
+			var merr, ok = err.(*multierror.Error)
+			require.True(t, ok)
+			require.Len(t, merr.Errors, 2)
+
+			var perr, isPanic = merr.Errors[0].(*HookPanicError)
+			require.True(t, isPanic)
+			require.Equal(t, "boom", perr.Value)
+			require.NotEmpty(t, perr.Stack)
+		},
+	)
+
+	t.Run(
+		"panic reported on errors channel",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var ran bool
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"foo", "bar"},
+					},
+				},
+			)
+			RegisterHook(
+				func(Store) error {
+					panic("boom")
+				},
+				func(Store) error {
+					ran = true
+					return nil
+				},
+			)
+
+			require.NotNil(t, Load())
+			require.True(t, ran)
+
+			select {
+			case err := <-Errors():
+				require.IsType(t, &HookPanicError{}, err)
+			default:
+				t.Error("expected an error on the errors channel")
+			}
+		},
+	)
+
+	t.Run(
+		"hook timeout",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.HookTimeout = 10 * time.Millisecond
+			var s = New(cfg).(*store)
+
+			var done = make(chan struct{})
+			defer close(done)
+
+			var ran bool
+			var err = s.runHooks(LoaderHooks{
+				func(Store) error {
+					<-done
+					return nil
+				},
+				func(Store) error {
+					ran = true
+					return nil
+				},
+			})
+			require.NotNil(t, err)
+			require.True(t, ran)
+			require.Equal(t, ErrHookTimeout, <-s.Errors())
+		},
+	)
+}
+
+func TestRegisterOrderedHooks(t *testing.T) {
+	t.Run(
+		"dependencies and priorities",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var ran []string
+			var hook = func(name string) func(Store) error {
+				return func(Store) error {
+					ran = append(ran, name)
+					return nil
+				}
+			}
+
+			RegisterHook(hook("plain"))
+			require.Nil(t, RegisterOrderedHooks(
+				Hook{
+					Name:  "db",
+					After: []string{"logger"},
+					Run:   hook("db"),
+				},
+				Hook{
+					Name:     "logger",
+					Priority: 10,
+					Run:      hook("logger"),
+				},
+				Hook{
+					Name:     "first",
+					Priority: -1,
+					Run:      hook("first"),
+				},
+			))
+
+			require.Nil(t, RunHooks())
+			require.Equal(t, []string{"first", "plain", "logger", "db"}, ran)
+		},
+	)
+
+	t.Run(
+		"cycle",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var noop = func(Store) error { return nil }
+			require.Nil(t, RegisterOrderedHooks(
+				Hook{Name: "a", After: []string{"b"}, Run: noop},
+			))
+
+			var err = RegisterOrderedHooks(
+				Hook{Name: "b", After: []string{"c"}, Run: noop},
+				Hook{Name: "c", After: []string{"a"}, Run: noop},
+			)
+			require.NotNil(t, err)
+			require.Equal(t, "Err cyclic hook dependencies between: a, b, c", err.Error())
+
+			// nothing was registered
+			require.Len(t, instance().hooks, 1)
+		},
+	)
+
+	t.Run(
+		"duplicate and missing run",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var noop = func(Store) error { return nil }
+			require.Nil(t, RegisterOrderedHooks(Hook{Name: "a", Run: noop}))
+			require.NotNil(t, RegisterOrderedHooks(Hook{Name: "a", Run: noop}))
+			require.NotNil(t, RegisterOrderedHooks(Hook{Name: "b"}))
+		},
+	)
+}
+
+func TestInitHooks(t *testing.T) {
+	t.Run(
+		"init hooks run once after first load",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var initRuns, reloadRuns int
+			c.RegisterInitHook(func(Store) error {
+				initRuns++
+				return nil
+			})
+			c.RegisterHook(func(Store) error {
+				reloadRuns++
+				return nil
+			})
+			c.RegisterLoader(&DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			})
+
+			require.Nil(t, c.Load())
+			require.Nil(t, c.Reload())
+			require.Nil(t, c.Load())
+			require.Equal(t, 1, initRuns)
+			require.Equal(t, 3, reloadRuns)
+
+			// registered after the first load, it runs immediately
+			c.RegisterInitHook(func(Store) error {
+				initRuns++
+				return nil
+			})
+			require.Equal(t, 2, initRuns)
+			require.Nil(t, c.Load())
+			require.Equal(t, 2, initRuns)
+		},
+	)
+
+	t.Run(
+		"failing init hooks run again on next load",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var runs int
+			c.RegisterInitHook(func(Store) error {
+				runs++
+				if runs == 1 {
+					return errors.New("err")
+				}
+				return nil
+			})
+			c.RegisterLoader(&DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			})
+
+			require.NotNil(t, c.Load())
+			require.Nil(t, c.Load())
+			require.Nil(t, c.Load())
+			require.Equal(t, 2, runs)
+		},
+	)
+}
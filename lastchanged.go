@@ -0,0 +1,69 @@
+package konfig
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// LastChanged returns the last time the value of the key k changed in the global store.
+// See Store.LastChanged.
+func LastChanged(k string) (time.Time, bool) {
+	return instance().LastChanged(k)
+}
+
+// LastChanged returns the last time the value of the key k changed in the store.
+// Unlike Provenance, loads writing the same value again do not update it.
+// ok is false if the key is not set in the store. It does not lock the store, so it can be called from hooks and WatchField callbacks.
+func (c *store) LastChanged(k string) (time.Time, bool) {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	var at, ok = c.changed[k]
+	return at, ok
+}
+
+// setChanged records the keys in ox and xs whose value differs between the previous values m and the new values nm
+// and returns them sorted. Keys removed from the store are forgotten and returned as changed.
+// Only the keys written by the load are diffed, the keys of ox also in xs are diffed once.
+// It must be called with the state mutex locked.
+func (c *store) setChanged(m s, nm s, ox Values, xs ...Values) []string {
+	var now = time.Now()
+	var changed = make(map[string]struct{})
+	var diff = func(k string) {
+		var nv, ok = nm[k]
+		if !ok {
+			if _, ok := m[k]; ok {
+				changed[k] = struct{}{}
+			}
+			delete(c.changed, k)
+			return
+		}
+		if ov, ok := m[k]; ok && reflect.DeepEqual(ov, nv) {
+			if _, ok := c.changed[k]; ok {
+				return
+			}
+		} else {
+			changed[k] = struct{}{}
+		}
+		c.changed[k] = now
+	}
+
+	for k := range ox {
+		if !inValues(k, xs) {
+			diff(k)
+		}
+	}
+	for _, x := range xs {
+		for k := range x {
+			diff(k)
+		}
+	}
+
+	var keys = make([]string, 0, len(changed))
+	for k := range changed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
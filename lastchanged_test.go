@@ -0,0 +1,94 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastChanged(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l = &MapLoader{
+		name: "l1",
+		values: Values{
+			"foo": "bar",
+			"baz": []string{"a"},
+		},
+	}
+	RegisterLoader(l)
+
+	var before = time.Now()
+	require.Nil(t, Load())
+
+	var fooAt, ok = LastChanged("foo")
+	require.True(t, ok)
+	require.False(t, fooAt.Before(before))
+
+	bazAt, ok := LastChanged("baz")
+	require.True(t, ok)
+
+	_, ok = LastChanged("nope")
+	require.False(t, ok)
+
+	// reloading the same values does not change the timestamps
+	time.Sleep(time.Millisecond)
+	require.Nil(t, Load())
+
+	at, ok := LastChanged("foo")
+	require.True(t, ok)
+	require.Equal(t, fooAt, at)
+
+	at, ok = LastChanged("baz")
+	require.True(t, ok)
+	require.Equal(t, bazAt, at)
+
+	// changing a value only updates its key, removing a value forgets its key
+	l.values = Values{
+		"foo": "qux",
+	}
+	before = time.Now()
+	require.Nil(t, Load())
+
+	at, ok = LastChanged("foo")
+	require.True(t, ok)
+	require.False(t, at.Before(before))
+
+	_, ok = LastChanged("baz")
+	require.False(t, ok)
+
+	// setting the same value does not change the timestamp
+	fooAt = at
+	time.Sleep(time.Millisecond)
+	Set("foo", "qux")
+	at, ok = LastChanged("foo")
+	require.True(t, ok)
+	require.Equal(t, fooAt, at)
+
+	before = time.Now()
+	Set("foo", "quux")
+	at, ok = LastChanged("foo")
+	require.True(t, ok)
+	require.False(t, at.Before(before))
+}
+
+func TestLastChangedFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var before = time.Now()
+	var at time.Time
+	var ok bool
+	RegisterHook(func(s Store) error {
+		at, ok = s.LastChanged("foo")
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.True(t, ok)
+	require.False(t, at.Before(before))
+}
@@ -1,8 +1,8 @@
 package konfig
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"sync"
 	"time"
 
@@ -26,17 +26,20 @@ type Loader interface {
 	RetryDelay() time.Duration
 }
 
+// ContextLoader is an optional interface a Loader can implement to receive the context of the load cycle.
+// If a Loader implements it, LoadContext is called instead of Load, it must return when the context is done.
+type ContextLoader interface {
+	// LoadContext loads config values in a Values, it must stop when ctx is done
+	LoadContext(ctx context.Context, v Values) error
+}
+
 // LoaderHooks are functions ran when a config load has been performed
 type LoaderHooks []func(Store) error
 
-// Run runs all loader and stops when it encounters an error
+// Run runs all hooks, each hook runs in isolation: a hook returning an error or panicking does not prevent the others from running.
+// Panics are recovered and returned as a *HookPanicError. Errors are aggregated in a multierror.Error.
 func (l LoaderHooks) Run(cfg Store) error {
-	for _, h := range l {
-		if err := h(cfg); err != nil {
-			return err
-		}
-	}
-	return nil
+	return l.run(cfg, 0)
 }
 
 // LoadWatch loads the config then starts watching it
@@ -58,12 +61,34 @@ func Load() error {
 }
 
 func (c *store) Load() error {
-	if len(c.WatcherLoaders) == 0 {
+	return c.LoadContext(context.Background())
+}
+
+// LoadContext is a function running load on the global config instance with the given context.
+// See Store.LoadContext.
+func LoadContext(ctx context.Context) error {
+	return instance().LoadContext(ctx)
+}
+
+// LoadContext loads all the loaders of the store, the context is shared by all loaders of the load cycle.
+// Loaders implementing ContextLoader receive the context, other loaders are not started once the context is done.
+// The context is cancelled when the load cycle ends, whether it succeeded or one of the loaders failed.
+func (c *store) LoadContext(ctx context.Context) error {
+	var wls = c.loaders()
+	if len(wls) == 0 {
 		panic(ErrNoLoaders)
 	}
-	for _, l := range c.WatcherLoaders {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, l := range wls {
 		// we load the loader once, then we start the reload worker with the watcher
-		if err := c.loaderLoadRetry(l, 0); err != nil {
+		if err := c.loaderLoad(ctx, l); err != nil {
+			// during the initial load, non critical loaders can degrade
+			if !c.loaded && ctx.Err() == nil && c.degrade(l, err) {
+				continue
+			}
 
 			// if loader says we should stop in failure, stop the world
 			// else just return the error
@@ -80,11 +105,70 @@ func (c *store) Load() error {
 		c.cfg.Logger.Get().Error("Error while checking strict keys: " + err.Error())
 		return err
 	}
+
+	// and the keys expected from the loaders
+	if err := c.checkExpectedKeys(); err != nil {
+		c.cfg.Logger.Get().Error("Error while checking expected keys: " + err.Error())
+		return err
+	}
+
+	// we run the init hooks after the first successful load
+	if err := c.runInitHooks(); err != nil {
+		c.cfg.Logger.Get().Error("Error while running init hooks: " + err.Error())
+		return err
+	}
 	c.loaded = true
 
 	return nil
 }
 
+// Reload reloads synchronously all loaders registered in the global store.
+// See Store.Reload.
+func Reload() error {
+	return instance().Reload()
+}
+
+// Reload reloads synchronously all loaders registered in the store, it stops and returns at the first error.
+// Unlike Load, it never stops the store on failure, errors are left to the caller.
+// It is safe to call concurrently with reloads triggered by watchers, loads of a same loader are serialized.
+// As it writes the store, it must not be called from hooks.
+func (c *store) Reload() error {
+	for _, wl := range c.loaders() {
+		if err := c.reloadLoader(wl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReloadLoader reloads synchronously the loader with the given name in the global store.
+// See Store.ReloadLoader.
+func ReloadLoader(name string) error {
+	return instance().ReloadLoader(name)
+}
+
+// ReloadLoader reloads synchronously the loader with the given name,
+// if no loader with the given name is registered in the store it returns ErrLoaderNotFound.
+// Like Reload, it must not be called from hooks.
+func (c *store) ReloadLoader(name string) error {
+	for _, wl := range c.loaders() {
+		if wl.Name() == name {
+			return c.reloadLoader(wl)
+		}
+	}
+	return ErrLoaderNotFound
+}
+
+// loaders returns the loaders registered in the store.
+// The loaders registered are never modified in place, they are replaced when a loader is unregistered,
+// so that the returned slice can be iterated without holding the state mutex while loaders are registered and unregistered.
+// It does not lock the store mutex, so it can be called from hooks.
+func (c *store) loaders() []*loaderWatcher {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+	return c.WatcherLoaders
+}
+
 // ConfigLoader is a wrapper of Loader with methods to add hooks
 type ConfigLoader struct {
 	*loaderWatcher
@@ -117,28 +201,139 @@ func (cl *ConfigLoader) AddHooks(loaderHooks ...func(Store) error) *ConfigLoader
 	return cl
 }
 
+// EnabledIf sets a predicate telling wether the loader is enabled. It is evaluated before each load of the loader.
+// A disabled loader is skipped and the values it loaded while it was enabled are removed from the store.
+// If the loader is disabled when the store starts watching, its watcher is not started.
+func (cl *ConfigLoader) EnabledIf(f func() bool) *ConfigLoader {
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	cl.loaderWatcher.enabled = f
+
+	return cl
+}
+
+// reloadLoader loads the loader and records reload metrics if they are enabled
+func (c *store) reloadLoader(wl *loaderWatcher) error {
+	var t *prometheus.Timer
+	if c.cfg.Metrics {
+		t = prometheus.NewTimer(wl.metrics.configReloadDuration)
+	}
+
+	if err := c.loaderLoad(context.Background(), wl); err != nil {
+		// if metrics is enabled we record a load failure
+		if c.cfg.Metrics {
+			wl.metrics.configReloadFailure.Inc()
+			t.ObserveDuration()
+		}
+		return err
+	}
+
+	if c.cfg.Metrics {
+		t.ObserveDuration()
+		wl.metrics.configReloadSuccess.Inc()
+	}
+
+	return nil
+}
+
+// loaderLoad loads the loader with retries, loads of a same loader are serialized
+func (c *store) loaderLoad(ctx context.Context, wl *loaderWatcher) error {
+	wl.loadMut.Lock()
+	defer wl.loadMut.Unlock()
+
+	// the loader was unregistered while we were waiting for its previous load
+	if wl.unregistered {
+		return nil
+	}
+
+	wl.changedKeys = nil
+
+	if !wl.isEnabled() {
+		c.unloadLoader(wl)
+		c.sendEvent(wl, nil)
+		return nil
+	}
+
+	var start = time.Now()
+	var err = c.loaderLoadRetry(ctx, wl, 0)
+	wl.setLoadStatus(start, time.Since(start), err)
+	c.sendEvent(wl, err)
+
+	return err
+}
+
+// unloadLoader removes the values of the loader from the store
+func (c *store) unloadLoader(wl *loaderWatcher) {
+	if len(wl.values) == 0 {
+		return
+	}
+
+	var v = Values{}
+	if err := v.loadLoader(wl, wl.values, c); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+		return
+	}
+}
+
 // We don't look for Done on the watcher here as the NopWatcher needs to run load at least once
-func (c *store) loaderLoadRetry(wl *loaderWatcher, retry int) error {
+func (c *store) loaderLoadRetry(ctx context.Context, wl *loaderWatcher, retry int) error {
 	// we create a new Values
 	var v = make(Values, len(wl.values))
 
+	// we record the attempt, retries included
+	if c.cfg.Metrics {
+		wl.metrics.loadAttempts.Inc()
+	}
+
 	// we call the loader
-	if err := wl.Load(v); err != nil {
+	if err := wl.loadContext(ctx, v); err != nil {
 
-		if retry >= wl.MaxRetry() {
+		if ctx.Err() != nil || retry >= wl.MaxRetry() {
 			c.cfg.Logger.Get().Error(err.Error())
 			return err
 		}
 
 		// wait before retrying
-		time.Sleep(wl.RetryDelay())
+		var t = time.NewTimer(c.retryBackoff(wl).NextDelay(retry + 1))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			c.cfg.Logger.Get().Error(ctx.Err().Error())
+			return ctx.Err()
+		}
 
-		return c.loaderLoadRetry(wl, retry+1)
+		return c.loaderLoadRetry(ctx, wl, retry+1)
+	}
+	if retry > 0 {
+		c.resetRetryBackoff(wl)
+	}
+
+	// we normalize the keys to the store separator
+	v = normalizeKeys(v, loaderSeparator(wl))
+
+	// we transform the values, transforms failing are not retried
+	if len(wl.transforms) > 0 {
+		var err error
+		if v, err = wl.transform(v); err != nil {
+			c.cfg.Logger.Get().Error(err.Error())
+			return err
+		}
+	}
+
+	// we decode the values with the registered codecs
+	var err error
+	if v, err = c.decode(v); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+		return err
 	}
 
 	// we add the values to the store
-	v.load(wl.values, c)
-	wl.values = v
+	if err := v.loadLoader(wl, wl.values, c); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+		return err
+	}
 
 	// if we have strict keys setup on the store and we have already loaded configs
 	// we check those keys now, if they are not present, we will return the error.
@@ -152,7 +347,8 @@ func (c *store) loaderLoadRetry(wl *loaderWatcher, retry int) error {
 	// we run the hooks
 	if wl.loaderHooks != nil {
 		c.mut.Lock()
-		if err := wl.loaderHooks.Run(c); err != nil {
+		c.setHookChanged(wl.changedKeys)
+		if err := c.runHooks(wl.loaderHooks); err != nil {
 			c.cfg.Logger.Get().Error("Error while running loader hooks: " + err.Error())
 			c.mut.Unlock()
 			return err
@@ -160,16 +356,30 @@ func (c *store) loaderLoadRetry(wl *loaderWatcher, retry int) error {
 		c.mut.Unlock()
 	}
 
+	// we run the store hooks, the runs of reloads are coalesced if a window is set
+	if c.hooks != nil {
+		if c.cfg.HookCoalesceWindow > 0 && c.loaded {
+			c.coalesceHooks(wl.changedKeys)
+			return nil
+		}
+		c.mut.Lock()
+		c.setHookChanged(wl.changedKeys)
+		if err := c.runHooks(c.hooks); err != nil {
+			c.cfg.Logger.Get().Error("Error while running store hooks: " + err.Error())
+			c.mut.Unlock()
+			return err
+		}
+		c.mut.Unlock()
+	}
+
 	return nil
 }
 
-func (c *store) watchLoader(wl *loaderWatcher) {
-	// if a panic occurs close everything
+func (c *store) watchLoader(wl *loaderWatcher, restarts int) {
+	// if a panic occurs we restart the watcher with backoff
 	defer func() {
 		if r := recover(); r != nil {
-			c.cfg.Logger.Get().Error(fmt.Sprintf("%v", r))
-			c.stop()
-			return
+			c.watcherPanic(wl, restarts, r)
 		}
 	}()
 
@@ -192,29 +402,13 @@ func (c *store) watchLoader(wl *loaderWatcher) {
 				}
 				return
 			default:
-
-				var t *prometheus.Timer
-				if c.cfg.Metrics {
-					t = prometheus.NewTimer(wl.metrics.configReloadDuration)
-				}
-
-				if err := c.loaderLoadRetry(wl, 0); err != nil {
-					// if metrics is enabled we record a load failure
-					if c.cfg.Metrics {
-						wl.metrics.configReloadFailure.Inc()
-						t.ObserveDuration()
-					}
+				if err := c.reloadLoader(wl); err != nil {
 					if !wl.StopOnFailure() {
 						continue
 					}
 					c.stop()
 					return
 				}
-
-				if c.cfg.Metrics {
-					t.ObserveDuration()
-					wl.metrics.configReloadSuccess.Inc()
-				}
 			}
 		}
 	}
@@ -0,0 +1,144 @@
+// Package klage provides a loader wrapper decrypting values encrypted with age.
+package klage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lalamove/konfig"
+)
+
+var _ konfig.Loader = (*Loader)(nil)
+
+var (
+	// ErrNoLoader is the error thrown when trying to create a Loader without a loader
+	ErrNoLoader = errors.New("no loader provided")
+	// ErrNoIdentity is the error thrown when trying to create a Loader without an identity file nor a decrypt function
+	ErrNoIdentity = errors.New("no identity provided")
+	// ErrDecryptMsg is the error message returned when a value cannot be decrypted
+	ErrDecryptMsg = "Err decrypting key '%s': %v"
+)
+
+const (
+	defaultPrefix  = "age:"
+	defaultCommand = "age"
+	armorHeader    = "-----BEGIN AGE ENCRYPTED FILE-----"
+)
+
+// DecryptFunc decrypts an age ciphertext, binary or ASCII armored, and returns the plaintext.
+// It can wrap age.Decrypt from the age library (filippo.io/age) with parsed identities to decrypt in process.
+type DecryptFunc func(ciphertext []byte) ([]byte, error)
+
+// Config is the config of a Loader
+type Config struct {
+	// IdentityFile is the path to the age identity file used to decrypt values with the age binary
+	IdentityFile string
+	// Command is the path to the age binary, default is "age"
+	Command string
+	// Decrypt decrypts the values, if set IdentityFile and Command are ignored
+	Decrypt DecryptFunc
+	// Prefix is the prefix of the values to decrypt, default is "age:".
+	// The ciphertext following the prefix is either ASCII armored or base64 encoded.
+	Prefix string
+}
+
+// Loader wraps a konfig.Loader, values loaded by the wrapped loader starting with the configured prefix
+// are decrypted with age and replaced with their plaintext.
+type Loader struct {
+	konfig.Loader
+	cfg *Config
+}
+
+// New creates a new Loader wrapping the loader l with the given config cfg
+func New(l konfig.Loader, cfg *Config) *Loader {
+	if l == nil {
+		panic(ErrNoLoader)
+	}
+	if cfg.Decrypt == nil {
+		if cfg.IdentityFile == "" {
+			panic(ErrNoIdentity)
+		}
+		if cfg.Command == "" {
+			cfg.Command = defaultCommand
+		}
+		cfg.Decrypt = CommandDecrypt(cfg.Command, cfg.IdentityFile)
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+
+	return &Loader{
+		Loader: l,
+		cfg:    cfg,
+	}
+}
+
+// Load implements konfig.Loader interface.
+// It loads the values of the wrapped loader and decrypts the values matching the prefix before setting them in cs.
+func (al *Loader) Load(cs konfig.Values) error {
+	var v = konfig.Values{}
+	if err := al.Loader.Load(v); err != nil {
+		return err
+	}
+
+	for k, vv := range v {
+		var str, ok = vv.(string)
+		if !ok || !strings.HasPrefix(str, al.cfg.Prefix) {
+			cs.Set(k, vv)
+			continue
+		}
+
+		var plaintext, err = al.decrypt(strings.TrimPrefix(str, al.cfg.Prefix))
+		if err != nil {
+			return konfig.NewLoadError(al.Name(), konfig.CategoryParse, fmt.Errorf(ErrDecryptMsg, k, err))
+		}
+		cs.Set(k, plaintext)
+	}
+
+	return nil
+}
+
+func (al *Loader) decrypt(ciphertext string) (string, error) {
+	var b []byte
+	ciphertext = strings.TrimSpace(ciphertext)
+	if strings.HasPrefix(ciphertext, armorHeader) {
+		b = []byte(ciphertext + "\n")
+	} else {
+		var err error
+		if b, err = base64.StdEncoding.DecodeString(ciphertext); err != nil {
+			return "", err
+		}
+	}
+
+	var pt, err = al.cfg.Decrypt(b)
+	if err != nil {
+		return "", err
+	}
+	return string(pt), nil
+}
+
+// CommandDecrypt returns a DecryptFunc running the age binary at path with the given identity file to decrypt values
+func CommandDecrypt(path, identityFile string) DecryptFunc {
+	return func(ciphertext []byte) ([]byte, error) {
+		var cmd = exec.Command(path, "--decrypt", "--identity", identityFile)
+		cmd.Env = os.Environ()
+		cmd.Stdin = bytes.NewReader(ciphertext)
+
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("%v: %s", err, msg)
+			}
+			return nil, err
+		}
+		return stdout.Bytes(), nil
+	}
+}
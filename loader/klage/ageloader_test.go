@@ -0,0 +1,184 @@
+package klage
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const armored = "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCg==\n-----END AGE ENCRYPTED FILE-----"
+
+// fakeDecrypt "decrypts" ciphertexts of the form "enc(<plaintext>)"
+func fakeDecrypt(ciphertext []byte) ([]byte, error) {
+	var s = string(ciphertext)
+	if !strings.HasPrefix(s, "enc(") || !strings.HasSuffix(s, ")") {
+		return nil, errors.New("no identity matched any of the recipients")
+	}
+	return []byte(strings.TrimSuffix(strings.TrimPrefix(s, "enc("), ")")), nil
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestLoader(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		values   konfig.Values
+		cfg      *Config
+		expected konfig.Values
+		err      string
+	}{
+		{
+			name: "DecryptPrefixedValues",
+			values: konfig.Values{
+				"db.pass": "age:" + b64("enc(secret)"),
+				"db.host": "localhost",
+				"db.port": 5432,
+			},
+			cfg: &Config{Decrypt: fakeDecrypt},
+			expected: konfig.Values{
+				"db.pass": "secret",
+				"db.host": "localhost",
+				"db.port": 5432,
+			},
+		},
+		{
+			name: "CustomPrefix",
+			values: konfig.Values{
+				"token": "enc:" + b64("enc(secret)"),
+				"other": "age:foo",
+			},
+			cfg: &Config{Decrypt: fakeDecrypt, Prefix: "enc:"},
+			expected: konfig.Values{
+				"token": "secret",
+				"other": "age:foo",
+			},
+		},
+		{
+			name: "Armored",
+			values: konfig.Values{
+				"token": "age:" + armored,
+			},
+			cfg: &Config{Decrypt: func(ciphertext []byte) ([]byte, error) {
+				if string(ciphertext) != armored+"\n" {
+					return nil, errors.New("unexpected ciphertext")
+				}
+				return []byte("secret"), nil
+			}},
+			expected: konfig.Values{
+				"token": "secret",
+			},
+		},
+		{
+			name: "ErrorDecrypting",
+			values: konfig.Values{
+				"db.pass": "age:" + b64("garbage"),
+			},
+			cfg: &Config{Decrypt: fakeDecrypt},
+			err: "Err decrypting key 'db.pass': no identity matched any of the recipients",
+		},
+		{
+			name: "ErrorBase64",
+			values: konfig.Values{
+				"db.pass": "age:!!!",
+			},
+			cfg: &Config{Decrypt: fakeDecrypt},
+			err: "Err decrypting key 'db.pass'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var l = mocks.NewMockLoader(ctrl)
+			l.EXPECT().Load(konfig.Values{}).DoAndReturn(func(v konfig.Values) error {
+				for k, vv := range testCase.values {
+					v.Set(k, vv)
+				}
+				return nil
+			})
+			if testCase.err != "" {
+				l.EXPECT().Name().Return("file")
+			}
+
+			var v = konfig.Values{}
+			var err = New(l, testCase.cfg).Load(v)
+			if testCase.err != "" {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryParse, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), testCase.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestLoaderErrorLoading(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var l = mocks.NewMockLoader(ctrl)
+	l.EXPECT().Load(konfig.Values{}).Return(errors.New("err"))
+
+	require.NotNil(t, New(l, &Config{Decrypt: fakeDecrypt}).Load(konfig.Values{}))
+}
+
+func TestNew(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+	var l = mocks.NewMockLoader(ctrl)
+
+	require.PanicsWithValue(t, ErrNoLoader, func() {
+		New(nil, &Config{Decrypt: fakeDecrypt})
+	})
+	require.PanicsWithValue(t, ErrNoIdentity, func() {
+		New(l, &Config{})
+	})
+
+	var al = New(l, &Config{IdentityFile: "key.txt"})
+	require.Equal(t, defaultCommand, al.cfg.Command)
+	require.Equal(t, defaultPrefix, al.cfg.Prefix)
+	require.NotNil(t, al.cfg.Decrypt)
+}
+
+func TestCommandDecrypt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+
+	var dir, err = ioutil.TempDir("", "klage")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// fake age binary checking its arguments and echoing its input
+	var bin = filepath.Join(dir, "age")
+	require.Nil(t, ioutil.WriteFile(
+		bin,
+		[]byte("#!/bin/sh\nif [ \"$*\" != '--decrypt --identity key.txt' ]; then echo \"bad args: $*\" >&2; exit 1; fi\ncat\n"),
+		0700,
+	))
+
+	var b []byte
+	b, err = CommandDecrypt(bin, "key.txt")([]byte("secret"))
+	require.Nil(t, err)
+	require.Equal(t, "secret", string(b))
+
+	_, err = CommandDecrypt(bin, "other.txt")([]byte("secret"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "bad args")
+}
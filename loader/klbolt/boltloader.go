@@ -0,0 +1,215 @@
+// Package klbolt provides a loader reading the keys of a bucket of a bbolt (BoltDB) file.
+package klbolt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/watcher/kwfile"
+	"github.com/lalamove/nui/nlogger"
+	"github.com/lalamove/nui/nstrings"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	_ konfig.Loader = (*Loader)(nil)
+	// ErrNoPath is the error thrown when trying to create a bolt loader with no path in config
+	ErrNoPath = errors.New("no path provided")
+	// ErrNoBucket is the error thrown when trying to create a bolt loader with no bucket in config
+	ErrNoBucket = errors.New("no bucket provided")
+	// ErrBucketNotFoundMsg is the error message returned when the bucket does not exist in the file
+	ErrBucketNotFoundMsg = "Err bucket '%s' not found in '%s'"
+	// ErrLockedMsg is the error message returned when the file stays locked by a writer for longer than the LockTimeout
+	ErrLockedMsg = "Err '%s' is locked by a writer"
+	// ErrCodecMsg is the error message returned when the value of a key cannot be decoded by the codec
+	ErrCodecMsg = "Err decoding key '%s': %v"
+	// DefaultRate is the default polling rate to check the file
+	DefaultRate = 10 * time.Second
+	// DefaultLockTimeout is the default time to wait for the lock of a writer to be released
+	DefaultLockTimeout = time.Second
+)
+
+const (
+	defaultName = "bolt"
+)
+
+// Config is the config for the bolt loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Path is the path to the bbolt file
+	Path string
+	// Bucket is the name of the bucket to load, nested buckets are loaded with their name and konfig.KeySep prepended to their keys
+	Bucket string
+	// Codec decodes the values of the bucket, it receives each value as a string.
+	// If nil, values are added to the konfig.Store as strings.
+	Codec konfig.Codec
+	// Prefix is a prefix to prepend keys when adding into the konfig.Store
+	Prefix string
+	// Replacer is a Replacer for the key before adding to the konfig.Store
+	Replacer nstrings.Replacer
+	// LockTimeout is the time to wait for the file to be unlocked when a writer holds it, the load fails after it.
+	// Default is DefaultLockTimeout.
+	LockTimeout time.Duration
+	// MaxRetry is the maximum number of times load can be retried in config
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// Debug sets the debug mode on the bolt loader
+	Debug bool
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
+	// Watch sets whether the loader should also be a konfig.Watcher, watching the file with a kwfile.FileWatcher
+	Watch bool
+	// Rate is the kwfile polling rate
+	// Default is 10 seconds
+	Rate time.Duration
+}
+
+// Loader is the structure representing a bolt loader.
+// A bolt loader loads the keys of a bucket of a bbolt file and stores them in the konfig.Store.
+// The file is opened read only for each load, so that an agent syncing it can update it between loads.
+type Loader struct {
+	*kwfile.FileWatcher
+	cfg *Config
+}
+
+// New creates a new Loader from the Config cfg.
+func New(cfg *Config) *Loader {
+	if cfg.Path == "" {
+		panic(ErrNoPath)
+	}
+	if cfg.Bucket == "" {
+		panic(ErrNoBucket)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.LockTimeout == 0 {
+		cfg.LockTimeout = DefaultLockTimeout
+	}
+	if cfg.Rate == 0 {
+		cfg.Rate = DefaultRate
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	var l = &Loader{
+		cfg: cfg,
+	}
+	if cfg.Watch {
+		l.FileWatcher = kwfile.New(
+			&kwfile.Config{
+				Files:  []string{cfg.Path},
+				Rate:   cfg.Rate,
+				Debug:  cfg.Debug,
+				Logger: cfg.Logger,
+			},
+		)
+	}
+
+	return l
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// MaxRetry implements konfig.Loader interface and returns the maximum number
+// of time Load method can be retried
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay implements konfig.Loader interface and returns the delay between each retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Load implements the konfig.Loader interface. It reads the keys of the bucket and adds them to the konfig.Store.
+// If a writer holds the lock of the file for longer than the LockTimeout, the load fails with an error built with ErrLockedMsg
+// and the store keeps the values of the previous load, it is retried according to MaxRetry and RetryDelay.
+func (l *Loader) Load(s konfig.Values) error {
+	// bbolt creates missing files, even read only
+	if _, err := os.Stat(l.cfg.Path); err != nil {
+		var category = konfig.CategoryUnknown
+		if os.IsNotExist(err) {
+			category = konfig.CategoryNotFound
+		}
+		return konfig.NewLoadError(l.cfg.Name, category, err)
+	}
+
+	var db, err = bolt.Open(l.cfg.Path, 0600, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  l.cfg.LockTimeout,
+	})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryUnknown, fmt.Errorf(ErrLockedMsg, l.cfg.Path))
+		}
+		return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err)
+	}
+	defer db.Close()
+
+	// values are decoded in their own map so that a failed load does not set partial values
+	var v = konfig.Values{}
+	if err := db.View(func(tx *bolt.Tx) error {
+		var b = tx.Bucket([]byte(l.cfg.Bucket))
+		if b == nil {
+			return konfig.NewLoadError(
+				l.cfg.Name,
+				konfig.CategoryNotFound,
+				fmt.Errorf(ErrBucketNotFoundMsg, l.cfg.Bucket, l.cfg.Path),
+			)
+		}
+		return l.loadBucket(b, "", v)
+	}); err != nil {
+		return err
+	}
+
+	for k, x := range v {
+		s.Set(k, x)
+	}
+	return nil
+}
+
+// loadBucket adds the keys of the bucket b to v, the keys of nested buckets are prefixed with the path of their bucket
+func (l *Loader) loadBucket(b *bolt.Bucket, path string, v konfig.Values) error {
+	return b.ForEach(func(k, x []byte) error {
+		var key = path + string(k)
+		// a nil value is a nested bucket
+		if x == nil {
+			return l.loadBucket(b.Bucket(k), key+konfig.KeySep, v)
+		}
+
+		var configKey = l.cfg.Prefix + key
+		if l.cfg.Replacer != nil {
+			configKey = l.cfg.Replacer.Replace(configKey)
+		}
+
+		if l.cfg.Codec == nil {
+			v.Set(configKey, string(x))
+			return nil
+		}
+		d, err := l.cfg.Codec(string(x))
+		if err != nil {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, fmt.Errorf(ErrCodecMsg, key, err))
+		}
+		v.Set(configKey, d)
+		return nil
+	})
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "BOLTLOADER | "))
+}
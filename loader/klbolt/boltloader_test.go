@@ -0,0 +1,194 @@
+package klbolt
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// writeDB writes the values in the bucket b of the bbolt file at path, keys with a map value are written as nested buckets
+func writeDB(t *testing.T, path string, b string, values map[string]interface{}) {
+	var db, err = bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	require.Nil(t, err)
+	defer db.Close()
+
+	var put func(bk *bolt.Bucket, values map[string]interface{}) error
+	put = func(bk *bolt.Bucket, values map[string]interface{}) error {
+		for k, v := range values {
+			if m, ok := v.(map[string]interface{}); ok {
+				var nb, err = bk.CreateBucketIfNotExists([]byte(k))
+				if err != nil {
+					return err
+				}
+				if err := put(nb, m); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := bk.Put([]byte(k), []byte(v.(string))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	require.Nil(t, db.Update(func(tx *bolt.Tx) error {
+		var bk, err = tx.CreateBucketIfNotExists([]byte(b))
+		if err != nil {
+			return err
+		}
+		return put(bk, values)
+	}))
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	var dir, err = ioutil.TempDir("", "klbolt")
+	require.Nil(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestBoltLoader(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		cfg       Config
+		values    map[string]interface{}
+		noFile    bool
+		expected  konfig.Values
+		errString string
+		category  konfig.ErrorCategory
+	}{
+		{
+			name:     "load bucket",
+			cfg:      Config{Bucket: "config"},
+			values:   map[string]interface{}{"foo": "bar", "port": "8080"},
+			expected: konfig.Values{"foo": "bar", "port": "8080"},
+		},
+		{
+			name: "nested buckets and prefix",
+			cfg:  Config{Bucket: "config", Prefix: "app."},
+			values: map[string]interface{}{
+				"foo": "bar",
+				"db":  map[string]interface{}{"host": "localhost", "replica": map[string]interface{}{"host": "replica"}},
+			},
+			expected: konfig.Values{"app.foo": "bar", "app.db.host": "localhost", "app.db.replica.host": "replica"},
+		},
+		{
+			name: "codec",
+			cfg: Config{Bucket: "config", Codec: func(v interface{}) (interface{}, error) {
+				return "decoded " + v.(string), nil
+			}},
+			values:   map[string]interface{}{"foo": "bar"},
+			expected: konfig.Values{"foo": "decoded bar"},
+		},
+		{
+			name: "codec error",
+			cfg: Config{Bucket: "config", Codec: func(v interface{}) (interface{}, error) {
+				return nil, errors.New("invalid")
+			}},
+			values:    map[string]interface{}{"foo": "bar"},
+			expected:  konfig.Values{},
+			errString: "Err decoding key 'foo': invalid",
+			category:  konfig.CategoryParse,
+		},
+		{
+			name:      "bucket not found",
+			cfg:       Config{Bucket: "missing"},
+			values:    map[string]interface{}{"foo": "bar"},
+			expected:  konfig.Values{},
+			errString: "Err bucket 'missing' not found in",
+			category:  konfig.CategoryNotFound,
+		},
+		{
+			name:      "file not found",
+			cfg:       Config{Bucket: "config"},
+			noFile:    true,
+			expected:  konfig.Values{},
+			errString: "no such file",
+			category:  konfig.CategoryNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var dir, clean = tempDir(t)
+			defer clean()
+
+			var cfg = testCase.cfg
+			cfg.Path = filepath.Join(dir, "config.db")
+			if !testCase.noFile {
+				writeDB(t, cfg.Path, "config", testCase.values)
+			}
+
+			var v = konfig.Values{}
+			var err = New(&cfg).Load(v)
+			require.Equal(t, testCase.expected, v)
+			if testCase.errString == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), testCase.errString)
+			require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+		})
+	}
+}
+
+func TestBoltLoaderLocked(t *testing.T) {
+	var dir, clean = tempDir(t)
+	defer clean()
+
+	var path = filepath.Join(dir, "config.db")
+	writeDB(t, path, "config", map[string]interface{}{"foo": "bar"})
+
+	// a writer keeping the file open holds its lock
+	var db, err = bolt.Open(path, 0600, nil)
+	require.Nil(t, err)
+
+	var l = New(&Config{Path: path, Bucket: "config", LockTimeout: 50 * time.Millisecond})
+	var v = konfig.Values{}
+	err = l.Load(v)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "is locked by a writer")
+	require.Empty(t, v)
+
+	require.Nil(t, db.Close())
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar"}, v)
+}
+
+func TestBoltLoaderWatch(t *testing.T) {
+	var dir, clean = tempDir(t)
+	defer clean()
+
+	var path = filepath.Join(dir, "config.db")
+	writeDB(t, path, "config", map[string]interface{}{"foo": "bar"})
+
+	var s = konfig.New(konfig.DefaultConfig())
+	var l = New(&Config{Path: path, Bucket: "config", Watch: true, Rate: 10 * time.Millisecond})
+
+	var reloaded = make(chan struct{}, 10)
+	s.RegisterLoaderWatcher(l, func(konfig.Store) error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	require.Nil(t, s.LoadWatch())
+	defer s.Reset()
+	<-reloaded
+	require.Equal(t, "bar", s.Get("foo"))
+
+	writeDB(t, path, "config", map[string]interface{}{"foo": "baz"})
+
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("store was not reloaded")
+	}
+	require.Equal(t, "baz", s.Get("foo"))
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/consul/api"
@@ -13,11 +15,18 @@ import (
 	"github.com/lalamove/konfig/watcher/kwpoll"
 	"github.com/lalamove/nui/nlogger"
 	"github.com/lalamove/nui/nstrings"
+	"github.com/spf13/cast"
 )
 
 var (
-	defaultTimeout               = 5 * time.Second
-	_              konfig.Loader = (*Loader)(nil)
+	defaultTimeout                     = 5 * time.Second
+	_              konfig.Loader       = (*Loader)(nil)
+	_              konfig.ParserLoader = (*Loader)(nil)
+	_              konfig.KeySeparator = (*Loader)(nil)
+	_              konfig.Writer       = (*Loader)(nil)
+	_              konfig.BatchWriter  = (*Loader)(nil)
+	// ErrTxnMsg is the error message returned when a Consul transaction is rolled back
+	ErrTxnMsg = "Err consul transaction rolled back: %s"
 )
 
 const (
@@ -35,9 +44,11 @@ type Key struct {
 	QueryOptions *api.QueryOptions
 }
 
-// ConsulKV is an interface that consul client.KV implements. It is used to retrieve keys.
+// ConsulKV is an interface that consul client.KV implements. It is used to retrieve and write keys.
 type ConsulKV interface {
 	Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error)
+	Put(p *api.KVPair, q *api.WriteOptions) (*api.WriteMeta, error)
+	Txn(txn api.KVTxnOps, q *api.QueryOptions) (bool, *api.KVTxnResponse, *api.QueryMeta, error)
 }
 
 // Config is the structure representing the config of a Loader
@@ -56,6 +67,9 @@ type Config struct {
 	Prefix string
 	// Replacer is a Replacer for the key before adding to the konfig.Store
 	Replacer nstrings.Replacer
+	// Separator is the separator used in the Consul keys (e.g. "/"), the store replaces it with konfig.KeySep.
+	// It is applied after the Prefix and Replacer. If empty, keys are not modified.
+	Separator string
 	// Watch tells if there should be a watcher with the loader
 	Watch bool
 	// Rater is the rater to pass to the poll watcher
@@ -129,6 +143,18 @@ func New(cfg *Config) *Loader {
 // Name returns the name of the loader
 func (l *Loader) Name() string { return l.cfg.Name }
 
+// Parsers implements konfig.ParserLoader, it returns the names of the parsers of the keys,
+// the keys without a parser are loaded as strings and are not listed
+func (l *Loader) Parsers() []string {
+	var p []string
+	for _, k := range l.cfg.Keys {
+		if k.Parser != nil {
+			p = append(p, parser.Name(k.Parser))
+		}
+	}
+	return p
+}
+
 // Load implements konfig.Loader,
 // it loads environment variables into the konfig.Store
 // based on config passed to the loader
@@ -136,10 +162,14 @@ func (l *Loader) Load(s konfig.Values) error {
 	for _, k := range l.cfg.Keys {
 		kp, _, err := l.keyValue(k.Key)
 		if err != nil {
-			return err
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryNetwork, err)
 		}
 		if kp == nil && l.cfg.StrictMode {
-			return fmt.Errorf("provided key \"%v\" was not found", k.Key)
+			return konfig.NewLoadError(
+				l.cfg.Name,
+				konfig.CategoryNotFound,
+				fmt.Errorf("provided key \"%v\" was not found", k.Key),
+			)
 		} else if kp == nil {
 			l.cfg.Logger.Get().Warn(fmt.Sprintf("provided key \"%v\" was not found", k.Key))
 			return nil
@@ -154,7 +184,7 @@ func (l *Loader) Load(s konfig.Values) error {
 		// else we just convert the value to a string
 		if k.Parser != nil {
 			if err := k.Parser.Parse(bytes.NewReader(kp.Value), s); err != nil {
-				return err
+				return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err)
 			}
 		} else {
 			s.Set(configKey, string(kp.Value))
@@ -179,6 +209,70 @@ func (l *Loader) StopOnFailure() bool {
 	return l.cfg.StopOnFailure
 }
 
+// Separator implements konfig.KeySeparator, it returns the separator used in the Consul keys
+func (l *Loader) Separator() string {
+	return l.cfg.Separator
+}
+
+// Set implements konfig.Writer, it writes the value v to the Consul key loaded as the key k of the store.
+// Values are written as strings, keys with a Parser cannot be written.
+func (l *Loader) Set(k string, v interface{}) error {
+	var key, ok = l.consulKey(k)
+	if !ok {
+		return fmt.Errorf(konfig.ErrNotWritableMsg, l.cfg.Name, k)
+	}
+	var _, err = l.cfg.kvClient.Put(&api.KVPair{Key: key, Value: []byte(cast.ToString(v))}, nil)
+	return err
+}
+
+// SetMany implements konfig.BatchWriter, it writes the values in a single Consul transaction, all of them are written or none.
+func (l *Loader) SetMany(v konfig.Values) error {
+	var ops = make(api.KVTxnOps, 0, len(v))
+	for k, vv := range v {
+		var key, ok = l.consulKey(k)
+		if !ok {
+			return fmt.Errorf(konfig.ErrNotWritableMsg, l.cfg.Name, k)
+		}
+		ops = append(ops, &api.KVTxnOp{Verb: api.KVSet, Key: key, Value: []byte(cast.ToString(vv))})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Key < ops[j].Key })
+
+	var ok, res, _, err = l.cfg.kvClient.Txn(ops, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		var msgs = make([]string, 0, len(res.Errors))
+		for _, e := range res.Errors {
+			msgs = append(msgs, e.What)
+		}
+		return fmt.Errorf(ErrTxnMsg, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// consulKey returns the Consul key loaded as the key k of the store, keys with a parser are not returned
+func (l *Loader) consulKey(k string) (string, bool) {
+	for _, key := range l.cfg.Keys {
+		if key.Parser == nil && l.configKey(key.Key) == k {
+			return key.Key, true
+		}
+	}
+	return "", false
+}
+
+// configKey returns the key of the store of the Consul key k
+func (l *Loader) configKey(k string) string {
+	var configKey = l.cfg.Prefix + k
+	if l.cfg.Replacer != nil {
+		configKey = l.cfg.Replacer.Replace(configKey)
+	}
+	if l.cfg.Separator != "" {
+		configKey = strings.Replace(configKey, l.cfg.Separator, konfig.KeySep, -1)
+	}
+	return configKey
+}
+
 // keyValue is a quick helper to load KVPair from
 // the consul server
 func (l *Loader) keyValue(k string) (pair *api.KVPair, qm *api.QueryMeta, err error) {
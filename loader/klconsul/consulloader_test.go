@@ -1,6 +1,7 @@
 package klconsul
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -213,10 +214,72 @@ func TestLoaderMethods(t *testing.T) {
 		StopOnFailure: true,
 		Client:        client,
 		Keys:          []Key{{Key: "key1"}},
+		Separator:     "/",
 	})
 
 	require.True(t, l.StopOnFailure())
 	require.Equal(t, "consulloader", l.Name())
+	require.Equal(t, "/", l.Separator())
 	require.Equal(t, 3, l.MaxRetry())
 	require.Equal(t, 10*time.Second, l.RetryDelay())
 }
+
+func TestSet(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, _ := api.NewClient(&api.Config{})
+
+	var l = New(&Config{
+		Client:    client,
+		Keys:      []Key{{Key: "app/max_conns"}, {Key: "app/json", Parser: mocks.NewMockParser(ctrl)}},
+		Prefix:    "consul/",
+		Separator: "/",
+	})
+
+	var kvClient = mocks.NewMockConsulKV(ctrl)
+	kvClient.EXPECT().Put(&api.KVPair{Key: "app/max_conns", Value: []byte("20")}, nil).Return(&api.WriteMeta{}, nil)
+	l.cfg.kvClient = kvClient
+
+	require.Nil(t, l.Set("consul.app.max_conns", 20))
+
+	// keys with a parser and unknown keys cannot be written
+	require.Equal(t, fmt.Errorf(konfig.ErrNotWritableMsg, "consul", "consul.app.json"), l.Set("consul.app.json", "{}"))
+	require.Equal(t, fmt.Errorf(konfig.ErrNotWritableMsg, "consul", "foo"), l.Set("foo", "bar"))
+}
+
+func TestSetMany(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, _ := api.NewClient(&api.Config{})
+
+	var l = New(&Config{
+		Client:    client,
+		Keys:      []Key{{Key: "db/host"}, {Key: "db/port"}},
+		Separator: "/",
+	})
+
+	var ops = api.KVTxnOps{
+		&api.KVTxnOp{Verb: api.KVSet, Key: "db/host", Value: []byte("remote")},
+		&api.KVTxnOp{Verb: api.KVSet, Key: "db/port", Value: []byte("5432")},
+	}
+	var kvClient = mocks.NewMockConsulKV(ctrl)
+	gomock.InOrder(
+		kvClient.EXPECT().Txn(ops, nil).Return(true, &api.KVTxnResponse{}, &api.QueryMeta{}, nil),
+		kvClient.EXPECT().Txn(ops, nil).Return(
+			false,
+			&api.KVTxnResponse{Errors: api.TxnErrors{{OpIndex: 1, What: "permission denied"}}},
+			&api.QueryMeta{},
+			nil,
+		),
+	)
+	l.cfg.kvClient = kvClient
+
+	var v = konfig.Values{"db.host": "remote", "db.port": 5432}
+	require.Nil(t, l.SetMany(v))
+	require.Equal(t, fmt.Errorf(ErrTxnMsg, "permission denied"), l.SetMany(v))
+
+	// nothing is written if a key cannot be written
+	require.Equal(t, fmt.Errorf(konfig.ErrNotWritableMsg, "consul", "foo"), l.SetMany(konfig.Values{"db.host": "remote", "foo": "bar"}))
+}
@@ -0,0 +1,291 @@
+package klconsul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader  = (*ServiceLoader)(nil)
+	_ konfig.Watcher = (*ServiceLoader)(nil)
+	// ErrNoServices is the error thrown when trying to create a ServiceLoader without services
+	ErrNoServices = errors.New("no services provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed ServiceLoader
+	ErrAlreadyClosed = errors.New("Consul service loader already closed")
+)
+
+const (
+	defaultServiceName     = "consul-service"
+	defaultServiceWaitTime = 5 * time.Minute
+	defaultServiceErrDelay = 5 * time.Second
+	// ServiceKeyPrefix is the prefix of the default key of a service
+	ServiceKeyPrefix = "service"
+	// ServiceAddressesKey is the key, under the key of a service, of the addresses of the healthy instances
+	ServiceAddressesKey = "addresses"
+)
+
+// ConsulHealth is an interface that consul client.Health implements. It is used to retrieve the healthy instances of services.
+type ConsulHealth interface {
+	Service(service, tag string, passingOnly bool, q *api.QueryOptions) ([]*api.ServiceEntry, *api.QueryMeta, error)
+}
+
+// Service is a Consul service to load
+type Service struct {
+	// Name is the name of the service in Consul
+	Name string
+	// Tag filters the instances of the service by tag, if empty instances are not filtered
+	Tag string
+	// Key is the key under which the service is added to the konfig.Store, default is "service.<Name>"
+	Key string
+}
+
+// ServiceConfig is the config of a ServiceLoader
+type ServiceConfig struct {
+	// Name is the name of the loader
+	Name string
+	// Client is the consul client
+	Client *api.Client
+	// StopOnFailure tells wether a load failure(after the retries) leads to closing the config and all registered closers
+	StopOnFailure bool
+	// Services is the list of services to load
+	Services []Service
+	// Watch tells if the loader should watch the services with blocking queries
+	Watch bool
+	// WaitTime is the maximum duration of a blocking query, default is 5 minutes
+	WaitTime time.Duration
+	// ErrDelay is the delay before retrying a blocking query when it fails, default is 5 seconds
+	ErrDelay time.Duration
+	// MaxRetry is the maximum number of times we can retry to load if it fails
+	MaxRetry int
+	// RetryDelay is the time between each retry when a load fails
+	RetryDelay time.Duration
+	// Debug sets debug mode on the loader
+	Debug bool
+	// Logger is used across this package to produce logs
+	Logger nlogger.Provider
+
+	healthClient ConsulHealth
+}
+
+// ServiceLoader is a konfig.Loader loading the addresses of the healthy instances of Consul services.
+// For each service, it sets the key "<Key>.addresses" to the sorted list of "host:port" of its instances passing their health checks.
+// When Watch is set in the config, it is also a konfig.Watcher sending an event when the healthy instances of a service change.
+type ServiceLoader struct {
+	cfg       *ServiceConfig
+	mut       *sync.Mutex
+	addrs     map[string][]string
+	watchChan chan struct{}
+	done      chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// NewServiceLoader returns a new ServiceLoader with the given config
+func NewServiceLoader(cfg *ServiceConfig) *ServiceLoader {
+	if cfg.Client == nil && cfg.healthClient == nil {
+		panic(errors.New("no consul client was provided"))
+	}
+	if len(cfg.Services) == 0 {
+		panic(ErrNoServices)
+	}
+	for i, s := range cfg.Services {
+		if s.Key == "" {
+			cfg.Services[i].Key = ServiceKeyPrefix + konfig.KeySep + s.Name
+		}
+	}
+	if cfg.WaitTime == 0 {
+		cfg.WaitTime = defaultServiceWaitTime
+	}
+	if cfg.ErrDelay == 0 {
+		cfg.ErrDelay = defaultServiceErrDelay
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultServiceLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultServiceName
+	}
+	if cfg.healthClient == nil {
+		cfg.healthClient = cfg.Client.Health()
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+
+	return &ServiceLoader{
+		cfg:       cfg,
+		mut:       &sync.Mutex{},
+		addrs:     make(map[string][]string, len(cfg.Services)),
+		watchChan: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Name returns the name of the loader
+func (l *ServiceLoader) Name() string { return l.cfg.Name }
+
+// Load implements konfig.Loader, it loads the addresses of the healthy instances of the services
+func (l *ServiceLoader) Load(s konfig.Values) error {
+	for _, svc := range l.cfg.Services {
+		var entries, _, err = l.cfg.healthClient.Service(svc.Name, svc.Tag, true, nil)
+		if err != nil {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryNetwork, err)
+		}
+
+		var addrs = serviceAddresses(entries)
+		l.setAddresses(svc, addrs)
+		s.Set(svc.Key+konfig.KeySep+ServiceAddressesKey, addrs)
+	}
+	return nil
+}
+
+// MaxRetry is the maximum number of time to retry when a load fails
+func (l *ServiceLoader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay is the delay between each retry
+func (l *ServiceLoader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *ServiceLoader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Start implements konfig.Watcher, it starts a blocking query for each service if Watch is set in the config
+func (l *ServiceLoader) Start() error {
+	if !l.cfg.Watch {
+		return nil
+	}
+	for _, svc := range l.cfg.Services {
+		go l.watch(svc)
+	}
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (l *ServiceLoader) Watch() <-chan struct{} {
+	return l.watchChan
+}
+
+// Done indicates wether the watcher is done or not
+func (l *ServiceLoader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close closes the watcher and cancels the blocking queries
+func (l *ServiceLoader) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+		return ErrAlreadyClosed
+	default:
+		close(l.done)
+	}
+	l.cancel()
+	return nil
+}
+
+// Err returns the watcher error, failing blocking queries are retried so it is always nil
+func (l *ServiceLoader) Err() error {
+	return nil
+}
+
+// watch runs blocking queries on the service and sends an event when its healthy instances change
+func (l *ServiceLoader) watch(svc Service) {
+	var index uint64
+	for {
+		var q = (&api.QueryOptions{
+			WaitIndex: index,
+			WaitTime:  l.cfg.WaitTime,
+		}).WithContext(l.ctx)
+
+		var entries, meta, err = l.cfg.healthClient.Service(svc.Name, svc.Tag, true, q)
+
+		select {
+		case <-l.done:
+			return
+		default:
+		}
+
+		if err != nil {
+			l.cfg.Logger.Get().Error(err.Error())
+
+			var t = time.NewTimer(l.cfg.ErrDelay)
+			select {
+			case <-t.C:
+			case <-l.done:
+				t.Stop()
+				return
+			}
+			continue
+		}
+
+		// the index going backwards means consul was reset, we restart from the beginning
+		if meta.LastIndex < index {
+			index = 0
+			continue
+		}
+		index = meta.LastIndex
+
+		if l.setAddresses(svc, serviceAddresses(entries)) {
+			if l.cfg.Debug {
+				l.cfg.Logger.Get().Debug(fmt.Sprintf("Instances of service %s changed, sending watch event", svc.Name))
+			}
+			select {
+			case l.watchChan <- struct{}{}:
+			default:
+				// an event is already pending
+			}
+		}
+	}
+}
+
+// setAddresses records the addresses of the service and returns wether they changed
+func (l *ServiceLoader) setAddresses(svc Service, addrs []string) bool {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	var prev, ok = l.addrs[svc.Key]
+	l.addrs[svc.Key] = addrs
+	return ok && !reflect.DeepEqual(prev, addrs)
+}
+
+// serviceAddresses returns the sorted "host:port" addresses of the entries.
+// The address of the service is used, or the address of the node if the service has none.
+func serviceAddresses(entries []*api.ServiceEntry) []string {
+	var addrs = make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Service == nil {
+			continue
+		}
+		var host = e.Service.Address
+		if host == "" && e.Node != nil {
+			host = e.Node.Address
+		}
+		addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(e.Service.Port)))
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func defaultServiceLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "CONSULSERVICELOADER | "))
+}
@@ -0,0 +1,156 @@
+package klconsul
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/consul/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func serviceEntry(nodeAddr, addr string, port int) *api.ServiceEntry {
+	return &api.ServiceEntry{
+		Node:    &api.Node{Address: nodeAddr},
+		Service: &api.AgentService{Address: addr, Port: port},
+	}
+}
+
+func newTestServiceLoader(t *testing.T, ctrl *gomock.Controller, cfg *ServiceConfig) (*ServiceLoader, *mocks.MockConsulHealth) {
+	var c, err = api.NewClient(&api.Config{Address: "http://localhost"})
+	require.Nil(t, err)
+
+	cfg.Client = c
+	var l = NewServiceLoader(cfg)
+
+	var hc = mocks.NewMockConsulHealth(ctrl)
+	l.cfg.healthClient = hc
+
+	return l, hc
+}
+
+func TestNewServiceLoader(t *testing.T) {
+	require.Panics(t, func() { NewServiceLoader(&ServiceConfig{Services: []Service{{Name: "payments"}}}) })
+
+	var c, _ = api.NewClient(&api.Config{Address: "http://localhost"})
+	require.Panics(t, func() { NewServiceLoader(&ServiceConfig{Client: c}) })
+
+	var l = NewServiceLoader(&ServiceConfig{
+		Client:   c,
+		Services: []Service{{Name: "payments"}, {Name: "orders", Key: "orders"}},
+	})
+	require.Equal(t, defaultServiceName, l.Name())
+	require.Equal(t, "service.payments", l.cfg.Services[0].Key)
+	require.Equal(t, "orders", l.cfg.Services[1].Key)
+}
+
+func TestServiceLoad(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		entries  []*api.ServiceEntry
+		err      error
+		expected konfig.Values
+	}{
+		{
+			name: "healthy instances",
+			entries: []*api.ServiceEntry{
+				serviceEntry("10.0.0.2", "", 8080),
+				serviceEntry("10.0.0.9", "10.0.0.1", 8080),
+			},
+			expected: konfig.Values{
+				"service.payments.addresses": []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+			},
+		},
+		{
+			name: "no instances",
+			expected: konfig.Values{
+				"service.payments.addresses": []string{},
+			},
+		},
+		{
+			name: "error",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var l, hc = newTestServiceLoader(t, ctrl, &ServiceConfig{
+				Services: []Service{{Name: "payments", Tag: "v1"}},
+			})
+			hc.EXPECT().Service("payments", "v1", true, nil).Return(testCase.entries, &api.QueryMeta{}, testCase.err)
+
+			var v = konfig.Values{}
+			var err = l.Load(v)
+			if testCase.err != nil {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryNetwork, konfig.ErrorCategoryOf(err))
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestServiceWatch(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var l, hc = newTestServiceLoader(t, ctrl, &ServiceConfig{
+		Services: []Service{{Name: "payments"}},
+		Watch:    true,
+	})
+
+	var entries = []*api.ServiceEntry{serviceEntry("", "10.0.0.1", 8080)}
+	var changed = []*api.ServiceEntry{serviceEntry("", "10.0.0.2", 8080)}
+
+	hc.EXPECT().Service("payments", "", true, nil).Return(entries, &api.QueryMeta{LastIndex: 1}, nil)
+	gomock.InOrder(
+		// same instances, no event
+		hc.EXPECT().Service("payments", "", true, gomock.Not(gomock.Nil())).DoAndReturn(
+			func(_, _ string, _ bool, q *api.QueryOptions) ([]*api.ServiceEntry, *api.QueryMeta, error) {
+				require.Equal(t, uint64(0), q.WaitIndex)
+				return entries, &api.QueryMeta{LastIndex: 1}, nil
+			},
+		),
+		hc.EXPECT().Service("payments", "", true, gomock.Not(gomock.Nil())).DoAndReturn(
+			func(_, _ string, _ bool, q *api.QueryOptions) ([]*api.ServiceEntry, *api.QueryMeta, error) {
+				require.Equal(t, uint64(1), q.WaitIndex)
+				return changed, &api.QueryMeta{LastIndex: 2}, nil
+			},
+		),
+		// blocks until the loader is closed
+		hc.EXPECT().Service("payments", "", true, gomock.Not(gomock.Nil())).DoAndReturn(
+			func(_, _ string, _ bool, q *api.QueryOptions) ([]*api.ServiceEntry, *api.QueryMeta, error) {
+				<-q.Context().Done()
+				return nil, nil, q.Context().Err()
+			},
+		),
+	)
+
+	require.Nil(t, l.Load(konfig.Values{}))
+	require.Nil(t, l.Start())
+
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+
+	var v = konfig.Values{}
+	hc.EXPECT().Service("payments", "", true, nil).Return(changed, &api.QueryMeta{LastIndex: 2}, nil)
+	require.Nil(t, l.Load(v))
+	require.Equal(t, []string{"10.0.0.2:8080"}, v["service.payments.addresses"])
+
+	require.Nil(t, l.Close())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+	// let the blocking query return
+	time.Sleep(10 * time.Millisecond)
+}
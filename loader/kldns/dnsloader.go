@@ -0,0 +1,242 @@
+// Package kldns provides a loader resolving DNS SRV records into lists of addresses.
+package kldns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/watcher/kwpoll"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader        = (*Loader)(nil)
+	_ konfig.ContextLoader = (*Loader)(nil)
+	// ErrNoRecords is the error thrown when trying to create a Loader without records
+	ErrNoRecords = errors.New("no records provided")
+	// ErrNoKey is the error thrown when trying to create a Loader with a record without a key
+	ErrNoKey = errors.New("no key provided for record")
+)
+
+const (
+	defaultName    = "dns"
+	defaultRate    = 30 * time.Second
+	defaultTimeout = 5 * time.Second
+)
+
+// Resolver resolves SRV records, net.Resolver implements it
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// TTLResolver is an optional interface a Resolver can implement to return the TTL of the records it resolves.
+// When the resolver implements it, the watcher polls the records before their TTL expires.
+type TTLResolver interface {
+	LookupSRVTTL(ctx context.Context, service, proto, name string) ([]*net.SRV, time.Duration, error)
+}
+
+// Record is a SRV record to resolve
+type Record struct {
+	// Service is the symbolic name of the service (e.g. "http"), if Service and Proto are empty, Name is resolved directly
+	Service string
+	// Proto is the protocol of the service (e.g. "tcp")
+	Proto string
+	// Name is the domain name of the record
+	Name string
+	// Key is the key the addresses of the record are set at in the konfig.Store
+	Key string
+}
+
+// Config is the config of a DNS Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Records are the SRV records to resolve
+	Records []Record
+	// Resolver is the resolver used to resolve the records, default is net.DefaultResolver
+	Resolver Resolver
+	// Timeout is the timeout of the resolution of a record, default is 5 seconds
+	Timeout time.Duration
+	// Watch sets wether the loader should poll the records and reload when the addresses change
+	Watch bool
+	// Rate is the polling rate, default is 30 seconds.
+	// If the Resolver implements TTLResolver, records are polled when the shortest TTL expires if it is shorter.
+	Rate time.Duration
+	// MaxRetry is the maximum number of times load can be retried
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// Debug sets the debug mode
+	Debug bool
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
+}
+
+// Loader is a konfig.Loader resolving SRV records.
+// For each record, it sets the key of the record to the list of "host:port" of its targets,
+// sorted by ascending priority, then descending weight, then target and port so that the order is deterministic.
+// When Watch is set in the config, it embeds a poll diff watcher triggering a reload when the addresses change.
+type Loader struct {
+	*kwpoll.PollWatcher
+	cfg *Config
+	mut *sync.Mutex
+	ttl time.Duration
+}
+
+// New creates a new Loader from the given config
+func New(cfg *Config) *Loader {
+	if len(cfg.Records) == 0 {
+		panic(ErrNoRecords)
+	}
+	for _, r := range cfg.Records {
+		if r.Key == "" {
+			panic(ErrNoKey)
+		}
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = net.DefaultResolver
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Rate == 0 {
+		cfg.Rate = defaultRate
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	var l = &Loader{
+		cfg: cfg,
+		mut: &sync.Mutex{},
+	}
+
+	if cfg.Watch {
+		var v = konfig.Values{}
+		if err := l.Load(v); err != nil {
+			cfg.Logger.Get().Error(err.Error())
+		}
+		l.PollWatcher = kwpoll.New(&kwpoll.Config{
+			Loader:    l,
+			Rater:     l,
+			InitValue: v,
+			Diff:      true,
+			Debug:     cfg.Debug,
+			Logger:    cfg.Logger,
+		})
+	}
+
+	return l
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Load implements konfig.Loader, it resolves the records and sets their addresses in the konfig.Values
+func (l *Loader) Load(s konfig.Values) error {
+	return l.LoadContext(context.Background(), s)
+}
+
+// LoadContext implements konfig.ContextLoader, resolutions are cancelled when the context is done
+func (l *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
+	var minTTL time.Duration
+	for _, r := range l.cfg.Records {
+		var srvs, ttl, err = l.lookup(ctx, r)
+		if err != nil {
+			var category = konfig.CategoryNetwork
+			if dnsErr, ok := err.(*net.DNSError); ok && !dnsErr.Temporary() && !dnsErr.Timeout() {
+				category = konfig.CategoryNotFound
+			}
+			return konfig.NewLoadError(l.cfg.Name, category, err)
+		}
+		if ttl > 0 && (minTTL == 0 || ttl < minTTL) {
+			minTTL = ttl
+		}
+		s.Set(r.Key, addresses(srvs))
+	}
+
+	l.mut.Lock()
+	l.ttl = minTTL
+	l.mut.Unlock()
+
+	return nil
+}
+
+func (l *Loader) lookup(ctx context.Context, r Record) ([]*net.SRV, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.cfg.Timeout)
+	defer cancel()
+
+	if tr, ok := l.cfg.Resolver.(TTLResolver); ok {
+		return tr.LookupSRVTTL(ctx, r.Service, r.Proto, r.Name)
+	}
+	var _, srvs, err = l.cfg.Resolver.LookupSRV(ctx, r.Service, r.Proto, r.Name)
+	return srvs, 0, err
+}
+
+// Time implements kwpoll.Rater, it returns the polling rate, or the shortest TTL of the records if it is shorter
+func (l *Loader) Time() time.Duration {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.ttl > 0 && l.ttl < l.cfg.Rate {
+		return l.ttl
+	}
+	return l.cfg.Rate
+}
+
+// MaxRetry returns the maximum number of times to retry a load when an error occurs
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay returns the delay between each load retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// addresses returns the "host:port" of the targets sorted deterministically
+func addresses(srvs []*net.SRV) []string {
+	var sorted = make([]*net.SRV, len(srvs))
+	copy(sorted, srvs)
+	sort.Slice(sorted, func(i, j int) bool {
+		var a, b = sorted[i], sorted[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Port < b.Port
+	})
+
+	var addrs = make([]string, len(sorted))
+	for i, srv := range sorted {
+		addrs[i] = net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port)))
+	}
+	return addrs
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "DNSLOADER | "))
+}
@@ -0,0 +1,162 @@
+package kldns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	mut  sync.Mutex
+	srvs map[string][]*net.SRV
+	err  error
+}
+
+func (f *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.err != nil {
+		return "", nil, f.err
+	}
+	return name, f.srvs["_"+service+"._"+proto+"."+name], nil
+}
+
+func (f *fakeResolver) set(name string, srvs []*net.SRV) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.srvs[name] = srvs
+}
+
+type fakeTTLResolver struct {
+	*fakeResolver
+	ttl time.Duration
+}
+
+func (f *fakeTTLResolver) LookupSRVTTL(ctx context.Context, service, proto, name string) ([]*net.SRV, time.Duration, error) {
+	var _, srvs, err = f.LookupSRV(ctx, service, proto, name)
+	return srvs, f.ttl, err
+}
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() { New(&Config{}) })
+	require.Panics(t, func() { New(&Config{Records: []Record{{Name: "example.com"}}}) })
+
+	var l = New(&Config{Records: []Record{{Name: "example.com", Key: "foo"}}})
+	require.Equal(t, defaultName, l.Name())
+	require.Equal(t, net.DefaultResolver, l.cfg.Resolver)
+	require.Equal(t, defaultRate, l.Time())
+}
+
+func TestLoad(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		srvs     []*net.SRV
+		err      error
+		expected konfig.Values
+		category konfig.ErrorCategory
+	}{
+		{
+			name: "sorted addresses",
+			srvs: []*net.SRV{
+				{Target: "c.example.com.", Port: 80, Priority: 20, Weight: 10},
+				{Target: "b.example.com.", Port: 80, Priority: 10, Weight: 5},
+				{Target: "a.example.com.", Port: 81, Priority: 10, Weight: 10},
+				{Target: "a.example.com.", Port: 80, Priority: 10, Weight: 10},
+			},
+			expected: konfig.Values{
+				"service.api": []string{
+					"a.example.com:80",
+					"a.example.com:81",
+					"b.example.com:80",
+					"c.example.com:80",
+				},
+			},
+		},
+		{
+			name:     "not found",
+			err:      &net.DNSError{Err: "no such host", Name: "example.com"},
+			category: konfig.CategoryNotFound,
+		},
+		{
+			name:     "network error",
+			err:      errors.New("connection refused"),
+			category: konfig.CategoryNetwork,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var r = &fakeResolver{
+				srvs: map[string][]*net.SRV{"_http._tcp.example.com": testCase.srvs},
+				err:  testCase.err,
+			}
+			var l = New(&Config{
+				Records: []Record{
+					{Service: "http", Proto: "tcp", Name: "example.com", Key: "service.api"},
+				},
+				Resolver: r,
+			})
+
+			var v = konfig.Values{}
+			var err = l.Load(v)
+			if testCase.err != nil {
+				require.NotNil(t, err)
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var r = &fakeTTLResolver{
+		fakeResolver: &fakeResolver{srvs: map[string][]*net.SRV{}},
+		ttl:          5 * time.Second,
+	}
+	var l = New(&Config{
+		Records:  []Record{{Name: "example.com", Key: "foo"}},
+		Resolver: r,
+	})
+
+	require.Equal(t, defaultRate, l.Time())
+	require.Nil(t, l.Load(konfig.Values{}))
+	require.Equal(t, 5*time.Second, l.Time())
+
+	r.ttl = time.Hour
+	require.Nil(t, l.Load(konfig.Values{}))
+	require.Equal(t, defaultRate, l.Time())
+}
+
+func TestWatch(t *testing.T) {
+	var r = &fakeTTLResolver{
+		fakeResolver: &fakeResolver{
+			srvs: map[string][]*net.SRV{
+				"_http._tcp.example.com": {{Target: "a.example.com.", Port: 80}},
+			},
+		},
+		ttl: 10 * time.Millisecond,
+	}
+	var l = New(&Config{
+		Records:  []Record{{Service: "http", Proto: "tcp", Name: "example.com", Key: "foo"}},
+		Resolver: r,
+		Watch:    true,
+	})
+	require.Nil(t, l.Start())
+	defer l.Close()
+
+	r.set("_http._tcp.example.com", []*net.SRV{{Target: "b.example.com.", Port: 80}})
+
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+}
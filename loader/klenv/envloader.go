@@ -1,17 +1,20 @@
 package klenv
 
 import (
+	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/watcher/kwpoll"
 	"github.com/lalamove/nui/nstrings"
 )
 
 var (
-	_ konfig.Loader = (*Loader)(nil)
+	_ konfig.Loader  = (*Loader)(nil)
+	_ konfig.Watcher = (*Loader)(nil)
 )
 
 const (
@@ -19,6 +22,16 @@ const (
 	defaultName = "env"
 )
 
+// Map groups the environment variables whose names start with a prefix into a single map[string]string value
+type Map struct {
+	// Prefix is the prefix of the names of the variables grouped in the map (e.g. "LABEL_"), it is stripped from the map keys
+	Prefix string
+	// Key is the key of the map in the konfig.Store (e.g. "labels"), the Prefix of the config is added to it
+	Key string
+	// Replacer is used to replace chars in the map keys, e.g. nstrings.ReplacerToLower
+	Replacer nstrings.Replacer
+}
+
 // Config is the config a an EnvLoader
 type Config struct {
 	// Name is the name of the loader
@@ -27,20 +40,44 @@ type Config struct {
 	StopOnFailure bool
 	// Regexp will load the environment variable if it matches the given regexp
 	Regexp string
+	// Include will load the environment variable only if it matches the given regexp
+	Include *regexp.Regexp
+	// Exclude will not load the environment variable if it matches the given regexp, it takes precedence over Regexp and Include
+	Exclude *regexp.Regexp
 	// Vars will load vars only present in the vars slice
 	Vars []string
 	// Prefix will add a prefix to the environment variables when adding them in the config store
 	Prefix string
 	// Replacer is used to replace chars in env vars keys
 	Replacer nstrings.Replacer
+	// NestDelimiter is the delimiter denoting nesting in env vars names (e.g. "__"), it is replaced by konfig.KeySep.
+	// The delimiter is replaced before the Replacer is applied and the Prefix is added.
+	NestDelimiter string
+	// Maps groups the variables whose names start with the prefix of a map into a single map[string]string value instead of individual keys,
+	// for sets of variables which cannot be enumerated ahead of time such as labels or tags.
+	// Grouped variables must match Regexp, Include and Exclude, NestDelimiter is not applied to the map keys.
+	// A map is set even if no variable matches it, a variable matching several maps is grouped in the first one.
+	Maps []Map
 	// MaxRetry is the maximum number of time the load method can be retried when it fails
 	MaxRetry int
 	// RetryDelay is the time betweel each retry
 	RetryDelay time.Duration
+	// File is the path of a file to read the environment from instead of the process environment,
+	// with one KEY=VALUE variable per line or NUL separated variables like /proc/self/environ.
+	// Empty lines and lines starting with # are ignored.
+	File string
+	// Watch sets wether the environment should be polled for changes.
+	// An event is sent only if the loaded variables changed, variables filtered out are ignored.
+	Watch bool
+	// Rater is the rater to pass to the poll watcher
+	Rater kwpoll.Rater
+	// Debug sets the debug mode of the poll watcher
+	Debug bool
 }
 
 // Loader is the structure representing the environment loader
 type Loader struct {
+	*kwpoll.PollWatcher
 	cfg *Config
 	r   *regexp.Regexp
 }
@@ -56,10 +93,26 @@ func New(cfg *Config) *Loader {
 		cfg.Name = defaultName
 	}
 
-	return &Loader{
-		cfg,
-		r,
+	var l = &Loader{
+		cfg: cfg,
+		r:   r,
+	}
+
+	if cfg.Watch {
+		var v = konfig.Values{}
+		if err := l.Load(v); err != nil {
+			panic(err)
+		}
+		l.PollWatcher = kwpoll.New(&kwpoll.Config{
+			Loader:    l,
+			Rater:     cfg.Rater,
+			InitValue: v,
+			Diff:      true,
+			Debug:     cfg.Debug,
+		})
 	}
+
+	return l
 }
 
 // Name returns the name of the loader
@@ -68,26 +121,58 @@ func (l *Loader) Name() string { return l.cfg.Name }
 // Load implements konfig.Loader, it loads environment variables into the konfig.Store
 // based on config passed to the loader
 func (l *Loader) Load(s konfig.Values) error {
+	var env, err = l.environ()
+	if err != nil {
+		var category = konfig.CategoryUnknown
+		if os.IsNotExist(err) {
+			category = konfig.CategoryNotFound
+		}
+		return konfig.NewLoadError(l.cfg.Name, category, err)
+	}
+
+	if len(l.cfg.Maps) > 0 {
+		env = l.loadMaps(env, s)
+	}
+
 	if l.cfg.Vars != nil && len(l.cfg.Vars) > 0 {
-		return l.loadVars(s)
+		return l.loadVars(env, s)
 	}
-	for _, v := range os.Environ() {
+	for _, v := range env {
 		var spl = strings.SplitN(v, sepEnvVar, 2)
-		// if has regex and key does not macth regexp we continue
-		if l.r != nil && !l.r.MatchString(spl[0]) {
+		if len(spl) < 2 || !l.match(spl[0]) {
 			continue
 		}
-		var k = spl[0]
-		if l.cfg.Replacer != nil {
-			k = l.cfg.Replacer.Replace(k)
-		}
-		k = l.cfg.Prefix + k
-		s.Set(k, spl[1])
+		s.Set(l.key(spl[0]), spl[1])
 	}
 
 	return nil
 }
 
+// environ returns the environment variables as KEY=VALUE strings,
+// from the process environment or from the env file if one is set
+func (l *Loader) environ() ([]string, error) {
+	if l.cfg.File == "" {
+		return os.Environ(), nil
+	}
+
+	var b, err = ioutil.ReadFile(l.cfg.File)
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	for _, line := range strings.FieldsFunc(string(b), func(r rune) bool {
+		return r == '\n' || r == '\x00'
+	}) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		env = append(env, line)
+	}
+	return env, nil
+}
+
 // MaxRetry returns the maximum number to retry a load when an error occurs
 func (l *Loader) MaxRetry() int {
 	return l.cfg.MaxRetry
@@ -98,18 +183,86 @@ func (l *Loader) RetryDelay() time.Duration {
 	return l.cfg.RetryDelay
 }
 
-func (l *Loader) loadVars(s konfig.Values) error {
-	for _, k := range l.cfg.Vars {
-		var v = os.Getenv(k)
-		if l.cfg.Replacer != nil {
-			k = l.cfg.Replacer.Replace(k)
+func (l *Loader) loadVars(env []string, s konfig.Values) error {
+	var vars = make(map[string]string, len(env))
+	for _, v := range env {
+		var spl = strings.SplitN(v, sepEnvVar, 2)
+		if len(spl) == 2 {
+			vars[spl[0]] = spl[1]
 		}
-		k = l.cfg.Prefix + k
-		s.Set(k, v)
+	}
+	for _, k := range l.cfg.Vars {
+		s.Set(l.key(k), vars[k])
 	}
 	return nil
 }
 
+// loadMaps sets the maps grouping the env vars and returns the env vars which are not grouped
+func (l *Loader) loadMaps(env []string, s konfig.Values) []string {
+	var maps = make([]map[string]string, len(l.cfg.Maps))
+	for i := range maps {
+		maps[i] = make(map[string]string)
+	}
+
+	var rest = make([]string, 0, len(env))
+	for _, v := range env {
+		var spl = strings.SplitN(v, sepEnvVar, 2)
+		var i = l.mapIndex(spl[0])
+		if i < 0 || len(spl) < 2 {
+			rest = append(rest, v)
+			continue
+		}
+		if !l.match(spl[0]) {
+			continue
+		}
+
+		var m = l.cfg.Maps[i]
+		var k = strings.TrimPrefix(spl[0], m.Prefix)
+		if m.Replacer != nil {
+			k = m.Replacer.Replace(k)
+		}
+		maps[i][k] = spl[1]
+	}
+
+	for i, m := range l.cfg.Maps {
+		s.Set(l.cfg.Prefix+m.Key, maps[i])
+	}
+	return rest
+}
+
+// mapIndex returns the index of the first map grouping the env var k, or -1 if none does
+func (l *Loader) mapIndex(k string) int {
+	for i, m := range l.cfg.Maps {
+		if strings.HasPrefix(k, m.Prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// match tells wether the env var k must be loaded
+func (l *Loader) match(k string) bool {
+	// if has regex and key does not macth regexp we continue
+	if l.r != nil && !l.r.MatchString(k) {
+		return false
+	}
+	if l.cfg.Include != nil && !l.cfg.Include.MatchString(k) {
+		return false
+	}
+	return l.cfg.Exclude == nil || !l.cfg.Exclude.MatchString(k)
+}
+
+// key returns the key in the konfig.Store of the env var k
+func (l *Loader) key(k string) string {
+	if l.cfg.NestDelimiter != "" {
+		k = strings.Replace(k, l.cfg.NestDelimiter, konfig.KeySep, -1)
+	}
+	if l.cfg.Replacer != nil {
+		k = l.cfg.Replacer.Replace(k)
+	}
+	return l.cfg.Prefix + k
+}
+
 // StopOnFailure returns wether a load failure should stop the config and the registered closers
 func (l *Loader) StopOnFailure() bool {
 	return l.cfg.StopOnFailure
@@ -1,11 +1,15 @@
 package klenv
 
 import (
+	"io/ioutil"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/watcher/kwpoll"
 	"github.com/lalamove/nui/nstrings"
 	"github.com/stretchr/testify/require"
 )
@@ -100,6 +104,73 @@ func TestEnvLoader(t *testing.T) {
 		},
 	)
 
+	t.Run(
+		"load env vars nest delimiter",
+		func(t *testing.T) {
+			os.Setenv("APP__DB__HOST", "localhost")
+			os.Setenv("APP__DB_NAME", "konfig")
+
+			var l = New(&Config{
+				Regexp:        "^APP__",
+				NestDelimiter: "__",
+				Replacer: nstrings.ReplacerFunc(func(s string) string {
+					return strings.ToLower(strings.TrimPrefix(s, "APP."))
+				}),
+				Prefix: "env.",
+			})
+
+			var v = konfig.Values{}
+			l.Load(v)
+
+			require.Equal(t, "localhost", v["env.db.host"])
+			require.Equal(t, "konfig", v["env.db_name"])
+
+			l = New(&Config{
+				Vars:          []string{"APP__DB__HOST"},
+				NestDelimiter: "__",
+			})
+
+			v = konfig.Values{}
+			l.Load(v)
+
+			require.Equal(t, "localhost", v["APP.DB.HOST"])
+		},
+	)
+
+	t.Run(
+		"load env vars include exclude",
+		func(t *testing.T) {
+			os.Setenv("APP_FOO", "foo")
+			os.Setenv("APP_BAR", "bar")
+			os.Setenv("APP_SECRET", "secret")
+
+			var l = New(&Config{
+				Include: regexp.MustCompile("^APP_"),
+				Exclude: regexp.MustCompile("^APP_(SECRET|BAR)$"),
+			})
+
+			var v = konfig.Values{}
+			l.Load(v)
+
+			require.Equal(t, "foo", v["APP_FOO"])
+			require.NotContains(t, v, "APP_BAR")
+			require.NotContains(t, v, "APP_SECRET")
+			require.NotContains(t, v, "PATH")
+
+			// exclude takes precedence over regexp
+			l = New(&Config{
+				Regexp:  "^APP_",
+				Exclude: regexp.MustCompile("^APP_SECRET$"),
+			})
+
+			v = konfig.Values{}
+			l.Load(v)
+
+			require.Equal(t, "bar", v["APP_BAR"])
+			require.NotContains(t, v, "APP_SECRET")
+		},
+	)
+
 	t.Run(
 		"new loader invalid regexp",
 		func(t *testing.T) {
@@ -132,3 +203,169 @@ func TestEnvLoader(t *testing.T) {
 		},
 	)
 }
+
+func TestEnvFile(t *testing.T) {
+	var f, err = ioutil.TempFile("", "klenv")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("# comment\nAPP_FOO=foo\n\nAPP_BAR=bar=baz\nOTHER=x\n")
+	require.Nil(t, err)
+	f.Close()
+
+	var l = New(&Config{
+		File:    f.Name(),
+		Include: regexp.MustCompile("^APP_"),
+	})
+
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"APP_FOO": "foo", "APP_BAR": "bar=baz"}, v)
+
+	l = New(&Config{
+		File: f.Name(),
+		Vars: []string{"OTHER"},
+	})
+
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"OTHER": "x"}, v)
+
+	l = New(&Config{
+		File: "/does/not/exist",
+	})
+	err = l.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, konfig.CategoryNotFound, konfig.ErrorCategoryOf(err))
+
+	// NUL separated environment
+	require.Nil(t, ioutil.WriteFile(f.Name(), []byte("APP_FOO=foo\x00APP_BAR=bar\x00"), 0644))
+	l = New(&Config{
+		File: f.Name(),
+	})
+
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"APP_FOO": "foo", "APP_BAR": "bar"}, v)
+}
+
+func TestEnvWatch(t *testing.T) {
+	var f, err = ioutil.TempFile("", "klenv")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+
+	require.Nil(t, ioutil.WriteFile(f.Name(), []byte("APP_FOO=foo\nOTHER=x\n"), 0644))
+
+	var l = New(&Config{
+		File:    f.Name(),
+		Include: regexp.MustCompile("^APP_"),
+		Watch:   true,
+		Rater:   kwpoll.Time(20 * time.Millisecond),
+	})
+	require.Nil(t, l.Start())
+	defer l.Close()
+
+	// a change of a filtered out var does not send an event
+	require.Nil(t, ioutil.WriteFile(f.Name(), []byte("APP_FOO=foo\nOTHER=y\n"), 0644))
+	select {
+	case <-l.Watch():
+		t.Fatal("unexpected watch event")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.Nil(t, ioutil.WriteFile(f.Name(), []byte("APP_FOO=bar\nOTHER=y\n"), 0644))
+	select {
+	case <-l.Watch():
+	case <-time.After(time.Second):
+		t.Fatal("expected watch event")
+	}
+}
+
+func TestEnvMaps(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		env      string
+		cfg      *Config
+		expected konfig.Values
+	}{
+		{
+			name: "groups vars into a map",
+			env:  "LABEL_TEAM=core\nLABEL_TIER=web\nPORT=8080\n",
+			cfg: &Config{
+				Maps: []Map{{Prefix: "LABEL_", Key: "labels", Replacer: nstrings.ReplacerToLower}},
+			},
+			expected: konfig.Values{
+				"labels": map[string]string{"team": "core", "tier": "web"},
+				"PORT":   "8080",
+			},
+		},
+		{
+			name: "empty map and config prefix",
+			env:  "PORT=8080\n",
+			cfg: &Config{
+				Prefix: "app.",
+				Maps:   []Map{{Prefix: "TAG_", Key: "tags"}},
+			},
+			expected: konfig.Values{
+				"app.tags": map[string]string{},
+				"app.PORT": "8080",
+			},
+		},
+		{
+			name: "filters and first matching map",
+			env:  "LABEL_TEAM=core\nLABEL_SECRET=x\nLABEL_X_ID=1\nPORT=8080\n",
+			cfg: &Config{
+				Exclude: regexp.MustCompile("SECRET"),
+				Maps: []Map{
+					{Prefix: "LABEL_X_", Key: "x"},
+					{Prefix: "LABEL_", Key: "labels"},
+				},
+			},
+			expected: konfig.Values{
+				"x":      map[string]string{"ID": "1"},
+				"labels": map[string]string{"TEAM": "core"},
+				"PORT":   "8080",
+			},
+		},
+		{
+			name: "nest delimiter is not applied to map keys",
+			env:  "LABEL_A__B=c\nDB__HOST=localhost\n",
+			cfg: &Config{
+				NestDelimiter: "__",
+				Maps:          []Map{{Prefix: "LABEL_", Key: "meta.labels"}},
+			},
+			expected: konfig.Values{
+				"meta.labels": map[string]string{"A__B": "c"},
+				"DB.HOST":     "localhost",
+			},
+		},
+		{
+			name: "with vars",
+			env:  "LABEL_TEAM=core\nPORT=8080\nHOST=localhost\n",
+			cfg: &Config{
+				Vars: []string{"PORT"},
+				Maps: []Map{{Prefix: "LABEL_", Key: "labels"}},
+			},
+			expected: konfig.Values{
+				"labels": map[string]string{"TEAM": "core"},
+				"PORT":   "8080",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var f, err = ioutil.TempFile("", "env")
+			require.Nil(t, err)
+			defer os.Remove(f.Name())
+			_, err = f.WriteString(testCase.env)
+			require.Nil(t, err)
+			f.Close()
+
+			testCase.cfg.File = f.Name()
+			var v = konfig.Values{}
+			require.Nil(t, New(testCase.cfg).Load(v))
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
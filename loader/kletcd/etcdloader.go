@@ -3,6 +3,9 @@ package kletcd
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/coreos/etcd/mvcc/mvccpb"
@@ -11,12 +14,17 @@ import (
 	"github.com/lalamove/konfig/watcher/kwpoll"
 	"github.com/lalamove/nui/ncontext"
 	"github.com/lalamove/nui/nstrings"
+	"github.com/spf13/cast"
 	"go.etcd.io/etcd/clientv3"
 )
 
 var (
-	defaultTimeout               = 5 * time.Second
-	_              konfig.Loader = (*Loader)(nil)
+	defaultTimeout                     = 5 * time.Second
+	_              konfig.Loader       = (*Loader)(nil)
+	_              konfig.ParserLoader = (*Loader)(nil)
+	_              konfig.KeySeparator = (*Loader)(nil)
+	_              konfig.Writer       = (*Loader)(nil)
+	_              konfig.BatchWriter  = (*Loader)(nil)
 )
 
 const (
@@ -48,6 +56,9 @@ type Config struct {
 	Prefix string
 	// Replacer is a Replacer for the key before adding to the konfig.Store
 	Replacer nstrings.Replacer
+	// Separator is the separator used in the etcd keys (e.g. "/"), the store replaces it with konfig.KeySep.
+	// It is applied after the Prefix and Replacer. If empty, keys are not modified.
+	Separator string
 	// Watch tells wether there should be a watcher with the loader
 	Watch bool
 	// Rater is the rater to pass to the poll watcher
@@ -113,13 +124,25 @@ func New(cfg *Config) *Loader {
 // Name returns the name of the loader
 func (l *Loader) Name() string { return l.cfg.Name }
 
+// Parsers implements konfig.ParserLoader, it returns the names of the parsers of the keys,
+// the keys without a parser are loaded as strings and are not listed
+func (l *Loader) Parsers() []string {
+	var p []string
+	for _, k := range l.cfg.Keys {
+		if k.Parser != nil {
+			p = append(p, parser.Name(k.Parser))
+		}
+	}
+	return p
+}
+
 // Load loads the values from the keys defined by the config in the konfig.Store
 func (l *Loader) Load(s konfig.Values) error {
 	for _, k := range l.cfg.Keys {
 
 		values, err := l.keyValue(k.Key)
 		if err != nil {
-			return err
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryNetwork, err)
 		}
 
 		for _, v := range values {
@@ -132,7 +155,7 @@ func (l *Loader) Load(s konfig.Values) error {
 			// else we just convert the value to a string
 			if k.Parser != nil {
 				if err := k.Parser.Parse(bytes.NewReader(v.Value), s); err != nil {
-					return err
+					return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err)
 				}
 			} else {
 				s.Set(configKey, string(v.Value))
@@ -168,7 +191,79 @@ func (l *Loader) keyValue(k string) ([]*mvccpb.KeyValue, error) {
 	return values.Kvs, nil
 }
 
+// Set implements konfig.Writer, it writes the value v to the etcd key loaded as the key k of the store.
+// Values are written as strings, keys with a Parser cannot be written.
+func (l *Loader) Set(k string, v interface{}) error {
+	var key, ok = l.etcdKey(k)
+	if !ok {
+		return fmt.Errorf(konfig.ErrNotWritableMsg, l.cfg.Name, k)
+	}
+
+	var ctx, cancel = l.cfg.Contexter.WithTimeout(
+		context.Background(),
+		l.cfg.Timeout,
+	)
+	defer cancel()
+
+	var _, err = l.cfg.kvClient.Put(ctx, key, cast.ToString(v))
+	return err
+}
+
+// SetMany implements konfig.BatchWriter, it writes the values in a single etcd transaction, all of them are written or none.
+func (l *Loader) SetMany(v konfig.Values) error {
+	var ops = make([]clientv3.Op, 0, len(v))
+	var keys = make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var key, ok = l.etcdKey(k)
+		if !ok {
+			return fmt.Errorf(konfig.ErrNotWritableMsg, l.cfg.Name, k)
+		}
+		ops = append(ops, clientv3.OpPut(key, cast.ToString(v[k])))
+	}
+
+	var ctx, cancel = l.cfg.Contexter.WithTimeout(
+		context.Background(),
+		l.cfg.Timeout,
+	)
+	defer cancel()
+
+	var _, err = l.cfg.kvClient.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+// etcdKey returns the etcd key loaded as the key k of the store, keys with a parser are not returned
+func (l *Loader) etcdKey(k string) (string, bool) {
+	for _, key := range l.cfg.Keys {
+		if key.Parser == nil && l.configKey(key.Key) == k {
+			return key.Key, true
+		}
+	}
+	return "", false
+}
+
+// configKey returns the key of the store of the etcd key k
+func (l *Loader) configKey(k string) string {
+	var configKey = l.cfg.Prefix + k
+	if l.cfg.Replacer != nil {
+		configKey = l.cfg.Replacer.Replace(configKey)
+	}
+	if l.cfg.Separator != "" {
+		configKey = strings.Replace(configKey, l.cfg.Separator, konfig.KeySep, -1)
+	}
+	return configKey
+}
+
 // StopOnFailure returns wether a load failure should stop the config and the registered closers
 func (l *Loader) StopOnFailure() bool {
 	return l.cfg.StopOnFailure
 }
+
+// Separator implements konfig.KeySeparator, it returns the separator used in the etcd keys
+func (l *Loader) Separator() string {
+	return l.cfg.Separator
+}
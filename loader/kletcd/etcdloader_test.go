@@ -3,6 +3,7 @@ package kletcd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -349,10 +350,85 @@ func TestLoaderMethods(t *testing.T) {
 		Client:        newClient(),
 		kvClient:      mockClient,
 		Keys:          []Key{{Key: "key1"}},
+		Separator:     "/",
 	})
 
 	require.True(t, l.StopOnFailure())
 	require.Equal(t, "etcdloader", l.Name())
+	require.Equal(t, "/", l.Separator())
 	require.Equal(t, 1, l.MaxRetry())
 	require.Equal(t, 10*time.Second, l.RetryDelay())
 }
+
+func TestSet(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mockClient = mocks.NewMockKV(ctrl)
+	var mockContexter = mocks.NewMockContexter(ctrl)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	mockContexter.EXPECT().
+		WithTimeout(context.Background(), 5*time.Second).
+		Return(ctx, context.CancelFunc(func() {}))
+	mockClient.EXPECT().Put(ctx, "app/max_conns", "20").Return(&clientv3.PutResponse{}, nil)
+
+	var l = New(&Config{
+		Client:    newClient(),
+		kvClient:  mockClient,
+		Keys:      []Key{{Key: "app/max_conns"}},
+		Separator: "/",
+		Contexter: mockContexter,
+	})
+
+	require.Nil(t, l.Set("app.max_conns", 20))
+	require.Equal(t, fmt.Errorf(konfig.ErrNotWritableMsg, "etcd", "foo"), l.Set("foo", "bar"))
+}
+
+// fakeTxn is a clientv3.Txn recording the operations of the transaction
+type fakeTxn struct {
+	ops []clientv3.Op
+	err error
+}
+
+func (f *fakeTxn) If(cs ...clientv3.Cmp) clientv3.Txn { return f }
+func (f *fakeTxn) Else(ops ...clientv3.Op) clientv3.Txn { return f }
+func (f *fakeTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	f.ops = append(f.ops, ops...)
+	return f
+}
+func (f *fakeTxn) Commit() (*clientv3.TxnResponse, error) {
+	return &clientv3.TxnResponse{Succeeded: f.err == nil}, f.err
+}
+
+func TestSetMany(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mockClient = mocks.NewMockKV(ctrl)
+	var mockContexter = mocks.NewMockContexter(ctrl)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var txn = &fakeTxn{}
+	mockContexter.EXPECT().
+		WithTimeout(context.Background(), 5*time.Second).
+		Return(ctx, context.CancelFunc(func() {}))
+	mockClient.EXPECT().Txn(ctx).Return(txn)
+
+	var l = New(&Config{
+		Client:    newClient(),
+		kvClient:  mockClient,
+		Keys:      []Key{{Key: "db/host"}, {Key: "db/port"}},
+		Separator: "/",
+		Contexter: mockContexter,
+	})
+
+	require.Nil(t, l.SetMany(konfig.Values{"db.host": "remote", "db.port": 5432}))
+	require.Equal(t, []clientv3.Op{clientv3.OpPut("db/host", "remote"), clientv3.OpPut("db/port", "5432")}, txn.ops)
+
+	require.Equal(t, fmt.Errorf(konfig.ErrNotWritableMsg, "etcd", "foo"), l.SetMany(konfig.Values{"foo": "bar"}))
+}
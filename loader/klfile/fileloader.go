@@ -1,8 +1,14 @@
 package klfile
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/lalamove/konfig"
@@ -13,7 +19,9 @@ import (
 )
 
 var (
-	_ konfig.Loader = (*Loader)(nil)
+	_ konfig.Loader       = (*Loader)(nil)
+	_ konfig.ParserLoader = (*Loader)(nil)
+	_ konfig.Writer       = (*Loader)(nil)
 	// ErrNoFiles is the error thrown when trying to create a file loader with no files in config
 	ErrNoFiles = errors.New("no files provided")
 	// ErrNoParser is the error thrown when trying to create a file loader with no parser
@@ -55,6 +63,12 @@ type Config struct {
 	// Rate is the kwfile polling rate
 	// Default is 10 seconds
 	Rate time.Duration
+	// MaxBytes is the maximum number of bytes read from each file, the load fails if a file is bigger.
+	// Default is parser.DefaultMaxBytes, if negative the size of files is not limited.
+	MaxBytes int64
+	// DuplicateKeyPolicy is the policy applied when a key is set by several files during a load.
+	// Default is konfig.DuplicateOverwrite, the value of the last file is kept.
+	DuplicateKeyPolicy konfig.DuplicateKeyPolicy
 }
 
 // Loader is the structure representring a file loader.
@@ -63,6 +77,9 @@ type Loader struct {
 	*kwfile.FileWatcher
 	cfg *Config
 	fs  nfs.FileSystem
+	mut *sync.Mutex
+	// files are the indexes in cfg.Files of the last file setting each key during the last load
+	files map[string]int
 }
 
 // New creates a new Loader fromt the Config cfg.
@@ -80,6 +97,9 @@ func New(cfg *Config) *Loader {
 		cfg.Logger = defaultLogger()
 	}
 
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = parser.DefaultMaxBytes
+	}
 	if cfg.Name == "" {
 		cfg.Name = defaultName
 	}
@@ -105,6 +125,7 @@ func New(cfg *Config) *Loader {
 		FileWatcher: fw,
 		cfg:         cfg,
 		fs:          nfs.OSFileSystem{},
+		mut:         &sync.Mutex{},
 	}
 }
 
@@ -147,6 +168,15 @@ func (f *Loader) WithWatcher() *Loader {
 // Name returns the name of the loader
 func (f *Loader) Name() string { return f.cfg.Name }
 
+// Parsers implements konfig.ParserLoader, it returns the names of the parsers of the files
+func (f *Loader) Parsers() []string {
+	var p = make([]string, len(f.cfg.Files))
+	for i, file := range f.cfg.Files {
+		p[i] = parser.Name(file.Parser)
+	}
+	return p
+}
+
 // MaxRetry implements konfig.Loader interface and returns the maximum number
 // of time Load method can be retried
 func (f *Loader) MaxRetry() int {
@@ -160,27 +190,122 @@ func (f *Loader) RetryDelay() time.Duration {
 
 // Load implements the konfig.Loader interface. It reads from the file and adds the data to the konfig.Store.
 func (f *Loader) Load(cfg konfig.Values) error {
-	for _, file := range f.cfg.Files {
+	var ks *konfig.KeySources
+	if f.cfg.DuplicateKeyPolicy != konfig.DuplicateOverwrite {
+		ks = konfig.NewKeySources(f.cfg.DuplicateKeyPolicy, f.cfg.Logger)
+	}
+
+	var files = make(map[string]int)
+	for i, file := range f.cfg.Files {
 		var fd, err = f.fs.Open(file.Path)
 		if err != nil {
-			return err
+			var category = konfig.CategoryUnknown
+			if os.IsNotExist(err) {
+				category = konfig.CategoryNotFound
+			}
+			return konfig.NewLoadError(f.cfg.Name, category, err)
 		}
 
-		// we parse the file
-		if err := file.Parser.Parse(fd, cfg); err != nil {
+		// we parse the file in its own values to know the file of each key
+		var fv = konfig.Values{}
+		if err := file.Parser.Parse(parser.MaxBytesReader(fd, f.cfg.MaxBytes), fv); err != nil {
 			fd.Close()
-			return err
+			return konfig.NewLoadError(f.cfg.Name, konfig.CategoryParse, err)
 		}
 		fd.Close()
+
+		for _, k := range sortedKeys(fv) {
+			files[k] = i
+			if ks == nil {
+				cfg.Set(k, fv[k])
+				continue
+			}
+			if err := ks.Set(cfg, file.Path, k, fv[k]); err != nil {
+				return konfig.NewLoadError(f.cfg.Name, konfig.CategoryParse, err)
+			}
+		}
 	}
+
+	f.mut.Lock()
+	f.files = files
+	f.mut.Unlock()
 	return nil
 }
 
+// Set implements konfig.Writer, it writes the value v of the key k back to the last file which set k during the last load.
+// The parser of the file must implement parser.Updater (e.g. kpyaml.Parser), which updates the value in place
+// keeping the comments and the key order of the file. The file is replaced atomically.
+// If no file set k or its parser is not a parser.Updater, it returns an error built with konfig.ErrNotWritableMsg.
+func (f *Loader) Set(k string, v interface{}) error {
+	f.mut.Lock()
+	var i, ok = f.files[k]
+	f.mut.Unlock()
+	if !ok {
+		return fmt.Errorf(konfig.ErrNotWritableMsg, f.cfg.Name, k)
+	}
+
+	var file = f.cfg.Files[i]
+	u, ok := file.Parser.(parser.Updater)
+	if !ok {
+		return fmt.Errorf(konfig.ErrNotWritableMsg, f.cfg.Name, k)
+	}
+
+	var fd, err = f.fs.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	err = u.Update(parser.MaxBytesReader(fd, f.cfg.MaxBytes), &b, konfig.Values{k: v})
+	fd.Close()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(file.Path, b.Bytes())
+}
+
+// writeFile replaces the file at path with the data b by writing a temporary file in the same directory and renaming it,
+// the mode of the file is kept
+func writeFile(path string, b []byte) error {
+	var info, err = os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 // StopOnFailure returns wether a load failure should stop the config and the registered closers
 func (f *Loader) StopOnFailure() bool {
 	return f.cfg.StopOnFailure
 }
 
+// sortedKeys returns the keys of v sorted, so that duplicate keys are reported in a stable order
+func sortedKeys(v konfig.Values) []string {
+	var keys = make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func defaultLogger() nlogger.Provider {
 	return nlogger.NewProvider(nlogger.New(os.Stdout, "FILEWATCHER | "))
 }
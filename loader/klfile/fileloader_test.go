@@ -2,7 +2,9 @@ package klfile
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -10,7 +12,9 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/lalamove/konfig"
 	"github.com/lalamove/konfig/mocks"
+	"github.com/lalamove/konfig/parser"
 	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/lalamove/konfig/parser/kpyaml"
 	"github.com/lalamove/nui/nfs"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +25,7 @@ func TestFileLoader(t *testing.T) {
 		fileName string
 		setUp    func(ctrl *gomock.Controller, fl *Loader)
 		err      bool
+		category konfig.ErrorCategory
 	}{
 		{
 			name:     "BasicNoErrorLoadOnce",
@@ -32,7 +37,7 @@ func TestFileLoader(t *testing.T) {
 				))
 				fs.EXPECT().Open("./test").Return(r, nil)
 				fl.fs = fs
-				fl.cfg.Files[0].Parser.(*mocks.MockParser).EXPECT().Parse(r, konfig.Values{}).Return(nil)
+				fl.cfg.Files[0].Parser.(*mocks.MockParser).EXPECT().Parse(gomock.Any(), konfig.Values{}).Return(nil)
 			},
 		},
 		{
@@ -45,6 +50,17 @@ func TestFileLoader(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name:     "ErrorFileNotFound",
+			fileName: "./test",
+			setUp: func(ctrl *gomock.Controller, fl *Loader) {
+				var fs = nfs.NewMockFileSystem(ctrl)
+				fs.EXPECT().Open("./test").Return(nil, os.ErrNotExist)
+				fl.fs = fs
+			},
+			err:      true,
+			category: konfig.CategoryNotFound,
+		},
 		{
 			name:     "ErrorInvalidFormat",
 			fileName: "./test",
@@ -60,10 +76,11 @@ func TestFileLoader(t *testing.T) {
 				fl.fs = fs
 				fl.cfg.Files[0].Parser.(*mocks.MockParser).
 					EXPECT().
-					Parse(r, konfig.Values{}).
+					Parse(gomock.Any(), konfig.Values{}).
 					Return(errors.New(""))
 			},
-			err: true,
+			err:      true,
+			category: konfig.CategoryParse,
 		},
 	}
 	for _, testCase := range testCases {
@@ -87,6 +104,7 @@ func TestFileLoader(t *testing.T) {
 			var err = fl.Load(v)
 			if testCase.err {
 				require.NotNil(t, err, "err should not be nil")
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
 				return
 			}
 			require.Nil(t, err, "err should be nil")
@@ -95,6 +113,83 @@ func TestFileLoader(t *testing.T) {
 
 }
 
+func TestMaxBytes(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var fs = nfs.NewMockFileSystem(ctrl)
+	fs.EXPECT().Open("./test").Return(
+		ioutil.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+		nil,
+	)
+
+	var fl = New(&Config{
+		Files: []File{
+			{
+				Path:   "./test",
+				Parser: kpjson.Parser,
+			},
+		},
+		MaxBytes: 5,
+	})
+	fl.fs = fs
+
+	var err = fl.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, parser.ErrMaxBytes, err.(*konfig.LoadError).Err)
+
+	require.Equal(t, parser.DefaultMaxBytes, New(&Config{Files: []File{{Path: "./test", Parser: kpjson.Parser}}}).cfg.MaxBytes)
+}
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		policy   konfig.DuplicateKeyPolicy
+		expected konfig.Values
+	}{
+		{
+			name:     "overwrite",
+			policy:   konfig.DuplicateOverwrite,
+			expected: konfig.Values{"foo": "baz", "bar": "foo"},
+		},
+		{
+			name:   "error",
+			policy: konfig.DuplicateError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var fs = nfs.NewMockFileSystem(ctrl)
+			fs.EXPECT().Open("./a.json").Return(ioutil.NopCloser(strings.NewReader(`{"foo":"bar"}`)), nil)
+			fs.EXPECT().Open("./b.json").Return(ioutil.NopCloser(strings.NewReader(`{"foo":"baz","bar":"foo"}`)), nil)
+
+			var fl = New(&Config{
+				Files: []File{
+					{Path: "./a.json", Parser: kpjson.Parser},
+					{Path: "./b.json", Parser: kpjson.Parser},
+				},
+				DuplicateKeyPolicy: testCase.policy,
+			})
+			fl.fs = fs
+
+			var v = konfig.Values{}
+			var err = fl.Load(v)
+			if testCase.expected == nil {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryParse, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), "Err key 'foo' is set by both './a.json' and './b.json'")
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
 func TestMaxRetryRetryDelay(t *testing.T) {
 	var ctrl = gomock.NewController(t)
 	defer ctrl.Finish()
@@ -206,3 +301,55 @@ func TestNewFileLoader(t *testing.T) {
 		},
 	)
 }
+
+func TestSet(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "klfile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var yamlPath, jsonPath = dir + "/config.yaml", dir + "/config.json"
+	require.Nil(t, ioutil.WriteFile(yamlPath, []byte("# the server\nserver:\n  host: localhost # local\n  port: 8080\n"), 0640))
+	require.Nil(t, ioutil.WriteFile(jsonPath, []byte(`{"name": "app"}`), 0644))
+
+	var fl = New(&Config{
+		Files: []File{
+			{Path: yamlPath, Parser: kpyaml.Parser},
+			{Path: jsonPath, Parser: kpjson.Parser},
+		},
+	})
+
+	// keys are written to the file they are loaded from once loaded
+	require.Equal(t, fmt.Sprintf(konfig.ErrNotWritableMsg, "file", "server.host"), fl.Set("server.host", "remote").Error())
+	require.Nil(t, fl.Load(konfig.Values{}))
+
+	require.Nil(t, fl.Set("server.host", "remote"))
+	var b, _ = ioutil.ReadFile(yamlPath)
+	require.Equal(t, "# the server\nserver:\n  host: remote # local\n  port: 8080\n", string(b))
+
+	info, err := os.Stat(yamlPath)
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0640), info.Mode())
+
+	var v = konfig.Values{}
+	require.Nil(t, fl.Load(v))
+	require.Equal(t, konfig.Values{"server.host": "remote", "server.port": 8080, "name": "app"}, v)
+
+	// the json parser cannot update files
+	require.Equal(t, fmt.Sprintf(konfig.ErrNotWritableMsg, "file", "name"), fl.Set("name", "other").Error())
+	require.Equal(t, fmt.Sprintf(konfig.ErrNotWritableMsg, "file", "foo"), fl.Set("foo", "bar").Error())
+
+	// no temporary file is left
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestParsers(t *testing.T) {
+	var l = New(&Config{
+		Files: []File{
+			{Path: "config.yml", Parser: kpyaml.Parser},
+			{Path: "config.json", Parser: kpjson.Parser},
+		},
+	})
+	require.Equal(t, []string{"yaml", "json"}, l.Parsers())
+}
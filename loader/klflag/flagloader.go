@@ -2,6 +2,7 @@ package klflag
 
 import (
 	"flag"
+	"strings"
 	"time"
 
 	"github.com/lalamove/konfig"
@@ -25,6 +26,11 @@ type Config struct {
 	Prefix string
 	// Replacer is a replacer to apply on flags to be added in the konfig.Store
 	Replacer nstrings.Replacer
+	// NestDelimiter is the delimiter denoting nesting in flag names, it is replaced by konfig.KeySep
+	// so that a flag maps onto a nested config key (e.g. with "-", --db-host becomes db.host).
+	// Flags using konfig.KeySep (e.g. --db.host) are always mapped onto nested keys.
+	// The delimiter is replaced before the Replacer is applied and the Prefix is added.
+	NestDelimiter string
 	// MaxRetry is the maximum number of times to retry
 	MaxRetry int
 	// RetryDelay is the delay between each retry
@@ -59,6 +65,9 @@ func (l *Loader) Name() string { return l.cfg.Name }
 func (l *Loader) Load(s konfig.Values) error {
 	l.cfg.FlagSet.VisitAll(func(f *flag.Flag) {
 		var n = f.Name
+		if l.cfg.NestDelimiter != "" {
+			n = strings.Replace(n, l.cfg.NestDelimiter, konfig.KeySep, -1)
+		}
 		if l.cfg.Replacer != nil {
 			n = l.cfg.Replacer.Replace(n)
 		}
@@ -51,6 +51,26 @@ func TestFlagLoader(t *testing.T) {
 		},
 	)
 
+	t.Run(
+		"nested flags",
+		func(t *testing.T) {
+			var fs = flag.NewFlagSet("foo", flag.ContinueOnError)
+			fs.String("db.host", "localhost", "usage")
+			fs.String("db-port", "5432", "usage")
+			require.Nil(t, fs.Parse([]string{"--db.host=x"}))
+
+			var loader = New(&Config{
+				FlagSet:       fs,
+				NestDelimiter: "-",
+			})
+
+			var v = konfig.Values{}
+
+			loader.Load(v)
+			require.Equal(t, konfig.Values{"db.host": "x", "db.port": "5432"}, v)
+		},
+	)
+
 	t.Run(
 		"default flag set",
 		func(t *testing.T) {
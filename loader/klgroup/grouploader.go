@@ -0,0 +1,192 @@
+// Package klgroup provides a loader reading its values from another konfig.Store,
+// typically another group, so that config pipelines can be composed between groups.
+package klgroup
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader  = (*Loader)(nil)
+	_ konfig.Watcher = (*Loader)(nil)
+	// ErrNoSource is the error thrown when trying to create a Loader without a source store
+	ErrNoSource = errors.New("No source store provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed Loader
+	ErrAlreadyClosed = errors.New("Group loader already closed")
+)
+
+const defaultName = "group"
+
+// Config is the config of a group Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Source is the store the values are read from
+	Source konfig.Store
+	// Transform is an optional function applied to the source values before they are loaded in the target store.
+	// It must not modify the values it receives, it must return new values instead.
+	Transform func(konfig.Values) (konfig.Values, error)
+	// Watch sets wether the loader should reload when the values of the source store change
+	Watch bool
+	// MaxRetry is the maximum number of times load can be retried
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// Debug sets the debug mode
+	Debug bool
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
+}
+
+// Loader is a konfig.Loader loading values from a source konfig.Store.
+// When Watch is set in the config, it is also a konfig.Watcher sending an event every time the values of the source store change.
+type Loader struct {
+	cfg       *Config
+	mut       *sync.Mutex
+	pv        konfig.Values
+	watchChan chan struct{}
+	done      chan struct{}
+}
+
+// New creates a new Loader from the given config
+func New(cfg *Config) *Loader {
+	if cfg.Source == nil {
+		panic(ErrNoSource)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	var l = &Loader{
+		cfg:       cfg,
+		mut:       &sync.Mutex{},
+		watchChan: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+
+	if cfg.Watch {
+		cfg.Source.RegisterHook(l.sourceChanged)
+	}
+
+	return l
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Load implements konfig.Loader, it loads the current values of the source store, transformed if a Transform is set.
+func (l *Loader) Load(s konfig.Values) error {
+	var v = l.cfg.Source.Snapshot()
+
+	l.mut.Lock()
+	l.pv = v
+	l.mut.Unlock()
+
+	if l.cfg.Transform != nil {
+		var err error
+		if v, err = l.cfg.Transform(v); err != nil {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryUnknown, err)
+		}
+	}
+
+	for k, vv := range v {
+		s.Set(k, vv)
+	}
+
+	return nil
+}
+
+// MaxRetry returns the maximum number of times to retry a load when an error occurs
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay returns the delay between each load retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Start implements konfig.Watcher, events are sent from the source store hooks so it does nothing.
+func (l *Loader) Start() error {
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (l *Loader) Watch() <-chan struct{} {
+	return l.watchChan
+}
+
+// Done indicates wether the watcher is done or not
+func (l *Loader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close closes the watcher, the loader stops sending events when the source store changes
+func (l *Loader) Close() error {
+	select {
+	case <-l.done:
+		return ErrAlreadyClosed
+	default:
+		close(l.done)
+	}
+	return nil
+}
+
+// Err returns the watcher error
+func (l *Loader) Err() error {
+	return nil
+}
+
+// sourceChanged is the hook registered on the source store.
+// It sends an event only if the values differ from the last loaded ones, which makes cycles between groups converge.
+// It must not block as it runs within the source store load.
+func (l *Loader) sourceChanged(s konfig.Store) error {
+	select {
+	case <-l.done:
+		return nil
+	default:
+	}
+
+	var v = s.Snapshot()
+
+	l.mut.Lock()
+	var changed = !reflect.DeepEqual(l.pv, v)
+	l.mut.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	if l.cfg.Debug {
+		l.cfg.Logger.Get().Debug("Source store changed, sending watch event")
+	}
+
+	select {
+	case l.watchChan <- struct{}{}:
+	default:
+		// an event is already pending
+	}
+
+	return nil
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "GROUPLOADER | "))
+}
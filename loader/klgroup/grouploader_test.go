@@ -0,0 +1,109 @@
+package klgroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLoader(t *testing.T) {
+	t.Run(
+		"panics no source",
+		func(t *testing.T) {
+			require.Panics(t, func() {
+				New(&Config{})
+			})
+		},
+	)
+
+	t.Run(
+		"load source values",
+		func(t *testing.T) {
+			var src = konfig.New(konfig.DefaultConfig())
+			src.Set("foo", "bar")
+			src.Set("bar", "foo")
+
+			var l = New(&Config{
+				Source: src,
+			})
+			require.Equal(t, defaultName, l.Name())
+
+			var v = konfig.Values{}
+			require.Nil(t, l.Load(v))
+			require.Equal(t, konfig.Values{"foo": "bar", "bar": "foo"}, v)
+		},
+	)
+
+	t.Run(
+		"load transformed values",
+		func(t *testing.T) {
+			var src = konfig.New(konfig.DefaultConfig())
+			src.Set("foo", "bar")
+
+			var l = New(&Config{
+				Source: src,
+				Transform: func(v konfig.Values) (konfig.Values, error) {
+					var nv = konfig.Values{}
+					for k, vv := range v {
+						nv["derived."+k] = vv
+					}
+					return nv, nil
+				},
+			})
+
+			var v = konfig.Values{}
+			require.Nil(t, l.Load(v))
+			require.Equal(t, konfig.Values{"derived.foo": "bar"}, v)
+		},
+	)
+
+	t.Run(
+		"transform error",
+		func(t *testing.T) {
+			var src = konfig.New(konfig.DefaultConfig())
+			src.Set("foo", "bar")
+
+			var l = New(&Config{
+				Source: src,
+				Transform: func(v konfig.Values) (konfig.Values, error) {
+					return nil, errors.New("err")
+				},
+			})
+
+			require.NotNil(t, l.Load(konfig.Values{}))
+		},
+	)
+
+	t.Run(
+		"reload on source change",
+		func(t *testing.T) {
+			var src = konfig.New(konfig.DefaultConfig())
+			src.Set("foo", "bar")
+
+			var target = konfig.New(konfig.DefaultConfig())
+			var l = New(&Config{
+				Source: src,
+				Watch:  true,
+			})
+			target.RegisterLoaderWatcher(l)
+			require.Nil(t, target.LoadWatch())
+			require.Equal(t, "bar", target.Get("foo"))
+
+			// source hooks ran without a change, no event is sent
+			require.Nil(t, src.RunHooks())
+			require.Equal(t, 0, len(l.watchChan))
+
+			src.Set("foo", "baz")
+			require.Nil(t, src.RunHooks())
+
+			time.Sleep(100 * time.Millisecond)
+			require.Equal(t, "baz", target.Get("foo"))
+
+			require.Nil(t, l.Close())
+			require.Equal(t, ErrAlreadyClosed, l.Close())
+		},
+	)
+}
@@ -1,18 +1,31 @@
 package klhttp
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/lalamove/konfig"
 	"github.com/lalamove/konfig/parser"
 	"github.com/lalamove/konfig/watcher/kwpoll"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.ContextLoader = (*Loader)(nil)
+	_ konfig.ParserLoader  = (*Loader)(nil)
 )
 
 var (
 	defaultRate = 10 * time.Second
+	// DefaultTimeout is the timeout of the default HTTP client, it includes reading the response body
+	DefaultTimeout = 30 * time.Second
 	// ErrNoSources is the error thrown when creating an Loader without sources
 	ErrNoSources = errors.New("No sources provided")
 )
@@ -47,8 +60,12 @@ type Config struct {
 	StopOnFailure bool
 	// Sources is a list of remote sources
 	Sources []Source
-	// Client is the client used to fetch the file, default is http.DefaultClient
+	// Client is the client used to fetch the file, it overrides HTTPClient.
 	Client Client
+	// HTTPClient is the HTTP client used to fetch the file if Client is not set,
+	// it can be used to set the transport (e.g. TLS config for mTLS, proxies, connection pooling) and the timeout of the requests.
+	// Default is a client with a timeout of DefaultTimeout.
+	HTTPClient *http.Client
 	// MaxRetry is the maximum number of retries when an error occurs
 	MaxRetry int
 	// RetryDelay is the delay between each retry
@@ -59,30 +76,72 @@ type Config struct {
 	Rater kwpoll.Rater
 	// Debug sets the debug mode
 	Debug bool
+	// MaxBytes is the maximum number of bytes read from each response body, the load fails if a body is bigger.
+	// Default is parser.DefaultMaxBytes, if negative the size of bodies is not limited.
+	MaxBytes int64
+	// AllowEmpty sets wether a source loading no keys is allowed. If false, the load fails when a source loads no keys.
+	AllowEmpty bool
+	// MergePatch sets wether the payloads of the sources are JSON Merge Patches (RFC 7386) applied over the values previously loaded from the source
+	// instead of full snapshots replacing them: a null value deletes its key and the keys it prefixes.
+	// The first payload is applied over no values, so sources should send the full config first.
+	MergePatch bool
+	// SSE sets wether the sources push their config with Server-Sent Events instead of being polled, it overrides Watch.
+	// The loader holds a connection open to each source and each event of type SSEEvent is a payload parsed with the parser of the source.
+	SSE bool
+	// SSEEvent is the type of the events carrying a payload, default is DefaultSSEEvent
+	SSEEvent string
+	// ReconnectDelay is the delay before reconnecting to a SSE source, it doubles after each failed attempt up to MaxReconnectDelay.
+	// A retry field sent by the source overrides it. Default is 1 second.
+	ReconnectDelay time.Duration
+	// MaxReconnectDelay is the maximum delay before reconnecting to a SSE source, default is 1 minute
+	MaxReconnectDelay time.Duration
+	// Backoff is the Backoff between the reconnections to a SSE source, it overrides ReconnectDelay and MaxReconnectDelay
+	Backoff konfig.Backoff
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
 }
 
 // Loader loads a configuration remotely
 type Loader struct {
 	*kwpoll.PollWatcher
 	cfg *Config
+	sse *sse
+	mut *sync.Mutex
+	// patched are the values of each source with the patches applied, when MergePatch is set
+	patched []konfig.Values
 }
 
 // New returns a new Loader with the given Config.
 func New(cfg *Config) *Loader {
 	if cfg.Client == nil {
-		cfg.Client = http.DefaultClient
+		if cfg.HTTPClient == nil {
+			cfg.HTTPClient = defaultHTTPClient()
+			// the body of an event stream is read as long as the connection is open
+			if cfg.SSE {
+				cfg.HTTPClient.Timeout = 0
+			}
+		}
+		cfg.Client = cfg.HTTPClient
 	}
 
 	if cfg.Sources == nil || len(cfg.Sources) == 0 {
 		panic(ErrNoSources)
 	}
 
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = parser.DefaultMaxBytes
+	}
 	if cfg.Name == "" {
 		cfg.Name = defaultName
 	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
 
 	var l = &Loader{
-		cfg: cfg,
+		cfg:     cfg,
+		mut:     &sync.Mutex{},
+		patched: make([]konfig.Values, len(cfg.Sources)),
 	}
 
 	for i, source := range cfg.Sources {
@@ -92,7 +151,18 @@ func New(cfg *Config) *Loader {
 		cfg.Sources[i] = source
 	}
 
-	if cfg.Watch {
+	if cfg.SSE {
+		if cfg.SSEEvent == "" {
+			cfg.SSEEvent = DefaultSSEEvent
+		}
+		if cfg.ReconnectDelay == 0 {
+			cfg.ReconnectDelay = defaultReconnectDelay
+		}
+		if cfg.MaxReconnectDelay == 0 {
+			cfg.MaxReconnectDelay = defaultMaxReconnectDelay
+		}
+		l.sse = newSSE(cfg.Sources)
+	} else if cfg.Watch {
 		var v = konfig.Values{}
 		var err = l.Load(v)
 		if err != nil {
@@ -113,20 +183,76 @@ func New(cfg *Config) *Loader {
 // Name returns the name of the loader
 func (r *Loader) Name() string { return r.cfg.Name }
 
+// Parsers implements konfig.ParserLoader, it returns the names of the parsers of the sources
+func (r *Loader) Parsers() []string {
+	var p = make([]string, len(r.cfg.Sources))
+	for i, source := range r.cfg.Sources {
+		p[i] = parser.Name(source.Parser)
+	}
+	return p
+}
+
 // Load loads the config from sources and parses the response
 func (r *Loader) Load(s konfig.Values) error {
-	for _, source := range r.cfg.Sources {
-		if b, err := source.Do(r.cfg.Client); err == nil {
-			if err := source.Parser.Parse(b, s); err != nil {
-				return err
+	return r.LoadContext(context.Background(), s)
+}
+
+// LoadContext implements konfig.ContextLoader, it loads the config from sources and parses the response.
+// Requests are cancelled when ctx is done.
+func (r *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
+	if r.sse != nil {
+		return r.loadSSE(ctx, s)
+	}
+
+	for i, source := range r.cfg.Sources {
+		if b, err := source.DoContext(ctx, r.cfg.Client); err == nil {
+			var v = konfig.Values{}
+			if err := source.Parser.Parse(parser.MaxBytesReader(b, r.cfg.MaxBytes), v); err != nil {
+				return konfig.NewLoadError(r.cfg.Name, konfig.CategoryParse, err)
+			}
+			if r.cfg.MergePatch {
+				// applying a merge patch twice has the same result, polling the same patch again does not change the values
+				r.mut.Lock()
+				v = mergePatch(r.patched[i], v)
+				r.patched[i] = v
+				r.mut.Unlock()
+			}
+			if len(v) == 0 && !r.cfg.AllowEmpty {
+				return konfig.NewLoadError(
+					r.cfg.Name,
+					konfig.CategoryNotFound,
+					fmt.Errorf(konfig.ErrEmptySourceMsg, source.URL),
+				)
+			}
+			for k, vv := range v {
+				s.Set(k, vv)
 			}
 		} else {
-			return err
+			// the load was aborted, it is not a failure of the source
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return konfig.NewLoadError(r.cfg.Name, errorCategory(err), err)
 		}
 	}
 	return nil
 }
 
+func errorCategory(err error) konfig.ErrorCategory {
+	var se, ok = err.(*StatusError)
+	if !ok {
+		return konfig.CategoryNetwork
+	}
+
+	switch se.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return konfig.CategoryAuth
+	case http.StatusNotFound:
+		return konfig.CategoryNotFound
+	}
+	return konfig.CategoryNetwork
+}
+
 // MaxRetry returns the MaxRetry config property, it implements the konfig.Loader interface
 func (r *Loader) MaxRetry() int {
 	return r.cfg.MaxRetry
@@ -141,3 +267,25 @@ func (r *Loader) RetryDelay() time.Duration {
 func (r *Loader) StopOnFailure() bool {
 	return r.cfg.StopOnFailure
 }
+
+// defaultHTTPClient returns the default HTTP client, its transport has the settings of http.DefaultTransport
+func defaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: DefaultTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "HTTPLOADER | "))
+}
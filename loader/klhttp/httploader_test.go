@@ -1,6 +1,7 @@
 package klhttp
 
 import (
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http"
@@ -11,6 +12,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/lalamove/konfig"
 	"github.com/lalamove/konfig/mocks"
+	"github.com/lalamove/konfig/parser"
+	"github.com/lalamove/konfig/parser/kpjson"
 	"github.com/lalamove/konfig/watcher/kwpoll"
 	"github.com/stretchr/testify/require"
 )
@@ -64,7 +67,8 @@ func TestLoad(t *testing.T) {
 				var p1 = mocks.NewMockParser(ctrl)
 
 				var hl = New(&Config{
-					Client: c,
+					Client:     c,
+					AllowEmpty: true,
 					Sources: []Source{
 						{
 							URL:    "http://source.com",
@@ -85,7 +89,7 @@ func TestLoad(t *testing.T) {
 					nil,
 				)
 
-				p1.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
+				p1.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
 
 				return hl
 			},
@@ -99,7 +103,8 @@ func TestLoad(t *testing.T) {
 				var p2 = mocks.NewMockParser(ctrl)
 
 				var hl = New(&Config{
-					Client: c,
+					Client:     c,
+					AllowEmpty: true,
 					Sources: []Source{
 						{
 							URL:    "http://source.com",
@@ -138,8 +143,8 @@ func TestLoad(t *testing.T) {
 					),
 				)
 
-				p1.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
-				p2.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
+				p1.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
+				p2.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
 
 				return hl
 			},
@@ -177,13 +182,14 @@ func TestLoad(t *testing.T) {
 					),
 				)
 
-				p1.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
-				p2.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
+				p1.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
+				p2.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
 
 				var hl = New(&Config{
-					Client: c,
-					Watch:  true,
-					Rater:  kwpoll.Time(100 * time.Millisecond),
+					Client:     c,
+					AllowEmpty: true,
+					Watch:      true,
+					Rater:      kwpoll.Time(100 * time.Millisecond),
 					Sources: []Source{
 						{
 							URL:    "http://source.com",
@@ -218,8 +224,8 @@ func TestLoad(t *testing.T) {
 					),
 				)
 
-				p1.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
-				p2.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
+				p1.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
+				p2.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
 
 				return hl
 			},
@@ -233,7 +239,8 @@ func TestLoad(t *testing.T) {
 				var p2 = mocks.NewMockParser(ctrl)
 
 				var hl = New(&Config{
-					Client: c,
+					Client:     c,
+					AllowEmpty: true,
 					Sources: []Source{
 						{
 							URL:    "http://source.com",
@@ -269,7 +276,7 @@ func TestLoad(t *testing.T) {
 					),
 				)
 
-				p1.EXPECT().Parse(r, konfig.Values{}).Times(1).Return(nil)
+				p1.EXPECT().Parse(gomock.Any(), konfig.Values{}).Times(1).Return(nil)
 
 				return hl
 			},
@@ -282,7 +289,8 @@ func TestLoad(t *testing.T) {
 				var p1 = mocks.NewMockParser(ctrl)
 
 				var hl = New(&Config{
-					Client: c,
+					Client:     c,
+					AllowEmpty: true,
 					Sources: []Source{
 						{
 							URL:        "http://source.com",
@@ -348,7 +356,46 @@ func TestNew(t *testing.T) {
 				},
 			})
 
-			require.Equal(t, http.DefaultClient, hl.cfg.Client)
+			require.NotNil(t, hl.cfg.HTTPClient)
+			require.Equal(t, DefaultTimeout, hl.cfg.HTTPClient.Timeout)
+			require.Equal(t, hl.cfg.HTTPClient, hl.cfg.Client)
+		},
+	)
+	t.Run(
+		"custom http client",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var p = mocks.NewMockParser(ctrl)
+			var c = &http.Client{Timeout: time.Second}
+
+			var hl = New(&Config{
+				Sources: []Source{
+					{
+						URL:    "http://url.com",
+						Parser: p,
+					},
+				},
+				HTTPClient: c,
+			})
+
+			require.Equal(t, c, hl.cfg.Client)
+
+			// Client overrides HTTPClient
+			var mc = mocks.NewMockClient(ctrl)
+			hl = New(&Config{
+				Sources: []Source{
+					{
+						URL:    "http://url.com",
+						Parser: p,
+					},
+				},
+				Client:     mc,
+				HTTPClient: c,
+			})
+
+			require.Equal(t, mc, hl.cfg.Client)
 		},
 	)
 	t.Run(
@@ -366,6 +413,209 @@ func TestNew(t *testing.T) {
 	)
 }
 
+func TestLoadContext(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var c = mocks.NewMockClient(ctrl)
+	var p = mocks.NewMockParser(ctrl)
+
+	var hl = New(&Config{
+		Client: c,
+		Sources: []Source{
+			{
+				URL:    "http://source.com",
+				Parser: p,
+			},
+		},
+	})
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	c.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		require.Equal(t, ctx, req.Context())
+		cancel()
+		return nil, req.Context().Err()
+	})
+
+	require.Equal(t, context.Canceled, hl.LoadContext(ctx, konfig.Values{}))
+}
+
+func TestLoadError(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		res      *http.Response
+		err      error
+		category konfig.ErrorCategory
+	}{
+		{
+			name:     "network error",
+			err:      errors.New("connection refused"),
+			category: konfig.CategoryNetwork,
+		},
+		{
+			name:     "unauthorized",
+			res:      &http.Response{StatusCode: http.StatusForbidden},
+			category: konfig.CategoryAuth,
+		},
+		{
+			name:     "not found",
+			res:      &http.Response{StatusCode: http.StatusNotFound},
+			category: konfig.CategoryNotFound,
+		},
+		{
+			name:     "server error",
+			res:      &http.Response{StatusCode: http.StatusInternalServerError},
+			category: konfig.CategoryNetwork,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = mocks.NewMockClient(ctrl)
+			var p = mocks.NewMockParser(ctrl)
+			var hl = New(&Config{
+				Name:   "http",
+				Client: c,
+				Sources: []Source{
+					{
+						URL:    "http://source.com",
+						Parser: p,
+					},
+				},
+			})
+
+			c.EXPECT().Do(gomock.Any()).Return(testCase.res, testCase.err)
+
+			var err = hl.Load(konfig.Values{})
+			require.IsType(t, &konfig.LoadError{}, err)
+			require.Equal(t, "http", err.(*konfig.LoadError).Loader)
+			require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+		})
+	}
+
+	t.Run("parse error", func(t *testing.T) {
+		var ctrl = gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var c = mocks.NewMockClient(ctrl)
+		var p = mocks.NewMockParser(ctrl)
+		var hl = New(&Config{
+			Client: c,
+			Sources: []Source{
+				{
+					URL:    "http://source.com",
+					Parser: p,
+				},
+			},
+		})
+
+		c.EXPECT().Do(gomock.Any()).Return(
+			&http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(``)),
+			},
+			nil,
+		)
+		p.EXPECT().Parse(gomock.Any(), konfig.Values{}).Return(errors.New(""))
+
+		require.Equal(t, konfig.CategoryParse, konfig.ErrorCategoryOf(hl.Load(konfig.Values{})))
+	})
+}
+
+func TestMaxBytes(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var c = mocks.NewMockClient(ctrl)
+	var hl = New(&Config{
+		Client: c,
+		Sources: []Source{
+			{
+				URL:    "http://source.com",
+				Parser: kpjson.Parser,
+			},
+		},
+		MaxBytes: 5,
+	})
+
+	c.EXPECT().Do(gomock.Any()).Return(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+		},
+		nil,
+	)
+
+	var err = hl.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, parser.ErrMaxBytes, err.(*konfig.LoadError).Err)
+}
+
+func TestAllowEmpty(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		allowEmpty bool
+		body       string
+		err        bool
+	}{
+		{
+			name: "empty source not allowed",
+			body: `{}`,
+			err:  true,
+		},
+		{
+			name:       "empty source allowed",
+			allowEmpty: true,
+			body:       `{}`,
+		},
+		{
+			name: "source with keys",
+			body: `{"foo":"bar"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = mocks.NewMockClient(ctrl)
+			var hl = New(&Config{
+				Client: c,
+				Sources: []Source{
+					{
+						URL:    "http://source.com",
+						Parser: kpjson.Parser,
+					},
+				},
+				AllowEmpty: testCase.allowEmpty,
+			})
+
+			c.EXPECT().Do(gomock.Any()).Return(
+				&http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(testCase.body)),
+				},
+				nil,
+			)
+
+			var err = hl.Load(konfig.Values{})
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryNotFound, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), "http://source.com")
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
 func TestLoaderMethods(t *testing.T) {
 
 	var ctrl = gomock.NewController(t)
@@ -0,0 +1,33 @@
+package klhttp
+
+import (
+	"strings"
+
+	"github.com/lalamove/konfig"
+)
+
+// mergePatch returns a copy of the values v patched with the JSON Merge Patch (RFC 7386) p, both flattened in dot.path notation by the parsers.
+// A nil value deletes its key and the keys it prefixes. Other values set their key and, as they replace the whole value at their key,
+// delete the keys they prefix and the keys prefixing them (e.g. {"db": {"host": "h"}} deletes a "db" string).
+// Nested objects of the patch are merged key by key as they are flattened, arrays are replaced.
+func mergePatch(v, p konfig.Values) konfig.Values {
+	var r = make(konfig.Values, len(v)+len(p))
+	for k, x := range v {
+		r[k] = x
+	}
+
+	for k, x := range p {
+		var prefix = k + konfig.KeySep
+		for rk := range r {
+			if strings.HasPrefix(rk, prefix) || strings.HasPrefix(k, rk+konfig.KeySep) {
+				delete(r, rk)
+			}
+		}
+		if x == nil {
+			delete(r, k)
+			continue
+		}
+		r[k] = x
+	}
+	return r
+}
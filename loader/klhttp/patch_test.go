@@ -0,0 +1,177 @@
+package klhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/lalamove/konfig/parser/kpyaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	var v = konfig.Values{
+		"foo":         "bar",
+		"db.host":     "localhost",
+		"db.port":     5432,
+		"db.replicas": []interface{}{"r1"},
+		"api":         "key",
+	}
+
+	var testCases = []struct {
+		name     string
+		patch    konfig.Values
+		expected konfig.Values
+	}{
+		{
+			name:  "add and replace",
+			patch: konfig.Values{"foo": "baz", "bar": "foo", "db.replicas": []interface{}{"r2"}},
+			expected: konfig.Values{
+				"foo":         "baz",
+				"bar":         "foo",
+				"db.host":     "localhost",
+				"db.port":     5432,
+				"db.replicas": []interface{}{"r2"},
+				"api":         "key",
+			},
+		},
+		{
+			name:     "null deletes a key",
+			patch:    konfig.Values{"db.port": nil, "missing": nil},
+			expected: konfig.Values{"foo": "bar", "db.host": "localhost", "db.replicas": []interface{}{"r1"}, "api": "key"},
+		},
+		{
+			name:     "null deletes an object",
+			patch:    konfig.Values{"db": nil},
+			expected: konfig.Values{"foo": "bar", "api": "key"},
+		},
+		{
+			name:     "a value replaces an object",
+			patch:    konfig.Values{"db": "postgres://localhost"},
+			expected: konfig.Values{"foo": "bar", "db": "postgres://localhost", "api": "key"},
+		},
+		{
+			name:  "an object replaces a value",
+			patch: konfig.Values{"api.key": "k", "api.secret": "s"},
+			expected: konfig.Values{
+				"foo":         "bar",
+				"db.host":     "localhost",
+				"db.port":     5432,
+				"db.replicas": []interface{}{"r1"},
+				"api.key":     "k",
+				"api.secret":  "s",
+			},
+		},
+		{
+			name:     "empty patch",
+			patch:    konfig.Values{},
+			expected: v,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, mergePatch(v, testCase.patch))
+		})
+	}
+}
+
+func TestLoadMergePatch(t *testing.T) {
+	var patches = []string{
+		`{"foo": "bar", "db": {"host": "localhost", "port": 5432}}`,
+		`{"db": {"port": null}, "bar": "baz"}`,
+		`{"db": {"port": null}, "bar": "baz"}`,
+		`{"db": null}`,
+	}
+	var expected = []konfig.Values{
+		{"foo": "bar", "db.host": "localhost", "db.port": float64(5432)},
+		{"foo": "bar", "db.host": "localhost", "bar": "baz"},
+		// polling the same patch again does not change the values
+		{"foo": "bar", "db.host": "localhost", "bar": "baz"},
+		{"foo": "bar", "bar": "baz"},
+	}
+
+	var i int
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, patches[i])
+		i++
+	}))
+	defer srv.Close()
+
+	var l = New(&Config{
+		Sources:    []Source{{URL: srv.URL, Parser: kpjson.Parser}},
+		MergePatch: true,
+	})
+
+	for _, e := range expected {
+		var v = konfig.Values{}
+		require.Nil(t, l.Load(v))
+		require.Equal(t, e, v)
+	}
+
+	t.Run(
+		"yaml",
+		func(t *testing.T) {
+			var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "db:\n  host: ~\nfoo: bar\n")
+			}))
+			defer srv.Close()
+
+			var l = New(&Config{
+				Sources:    []Source{{URL: srv.URL, Parser: kpyaml.Parser}},
+				MergePatch: true,
+			})
+			l.patched[0] = konfig.Values{"db.host": "localhost", "db.port": 5432}
+
+			var v = konfig.Values{}
+			require.Nil(t, l.Load(v))
+			require.Equal(t, konfig.Values{"db.port": 5432, "foo": "bar"}, v)
+		},
+	)
+}
+
+func TestSSEMergePatch(t *testing.T) {
+	var next = make(chan struct{})
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		var f = w.(http.Flusher)
+		fmt.Fprint(w, "data: {\"foo\":\"bar\",\"db\":{\"host\":\"localhost\"}}\n\n")
+		f.Flush()
+		<-next
+		// an empty patch changes nothing
+		fmt.Fprint(w, "data: {}\n\ndata: {\"db\":null,\"bar\":\"baz\"}\n\n")
+		f.Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	var l = New(&Config{
+		Sources:    []Source{{URL: srv.URL, Parser: kpjson.Parser}},
+		SSE:        true,
+		MergePatch: true,
+	})
+
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar", "db.host": "localhost"}, v)
+
+	require.Nil(t, l.Start())
+	defer l.Close()
+	next <- struct{}{}
+
+	// the watch events of both payloads can be sent as one
+	var deadline = time.Now().Add(2 * time.Second)
+	for {
+		waitEvent(t, l)
+		v = konfig.Values{}
+		require.Nil(t, l.Load(v))
+		if _, ok := v["bar"]; ok || time.Now().After(deadline) {
+			break
+		}
+	}
+	require.Equal(t, konfig.Values{"foo": "bar", "bar": "baz"}, v)
+}
@@ -1,13 +1,35 @@
 package klhttp
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+// StatusError is the error returned when a source responds with an unexpected status code
+type StatusError struct {
+	// URL is the url of the source
+	URL string
+	// StatusCode is the status code of the response
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf(
+		"Error while fetching config at %s, status code: %d",
+		e.URL,
+		e.StatusCode,
+	)
+}
+
 // Do makes an http request and sends the body to the parser
 func (s Source) Do(c Client) (io.Reader, error) {
+	return s.DoContext(context.Background(), c)
+}
+
+// DoContext makes an http request with the given context and sends the body to the parser
+func (s Source) DoContext(ctx context.Context, c Client) (io.Reader, error) {
 	var req, err = http.NewRequest(
 		s.Method,
 		s.URL,
@@ -16,6 +38,7 @@ func (s Source) Do(c Client) (io.Reader, error) {
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	// call the prepare method if there is one
 	if s.Prepare != nil {
@@ -33,11 +56,10 @@ func (s Source) Do(c Client) (io.Reader, error) {
 	if (s.StatusCode != 0 && res.StatusCode != s.StatusCode) ||
 		(res.StatusCode != http.StatusOK) {
 
-		return nil, fmt.Errorf(
-			"Error while fetching config at %s, status code: %d",
-			s.URL,
-			res.StatusCode,
-		)
+		return nil, &StatusError{
+			URL:        s.URL,
+			StatusCode: res.StatusCode,
+		}
 	}
 
 	return res.Body, nil
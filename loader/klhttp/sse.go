@@ -0,0 +1,469 @@
+package klhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+)
+
+var _ konfig.Watcher = (*Loader)(nil)
+
+const (
+	// DefaultSSEEvent is the default type of the Server-Sent Events carrying a payload, it is the type of events without event field
+	DefaultSSEEvent = "message"
+
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = time.Minute
+)
+
+var (
+	// ErrAlreadyClosed is the error returned when trying to close an already closed SSE Loader
+	ErrAlreadyClosed = errors.New("HTTP loader already closed")
+	// ErrNoEventMsg is the error message returned when loading a SSE source which sent no event yet
+	ErrNoEventMsg = "Err source '%s' sent no event"
+	// ErrEventTooLargeMsg is the error message returned when a Server-Sent Event is bigger than MaxBytes
+	ErrEventTooLargeMsg = "Err event of source '%s' exceeds the maximum of %d bytes"
+)
+
+// stream is the Server-Sent Events stream of a source
+type stream struct {
+	source Source
+	// values are the values of the last event, nil until the first event is received
+	values      konfig.Values
+	lastEventID string
+	retry       time.Duration
+	body        io.ReadCloser
+	br          *bufio.Reader
+}
+
+// event is a Server-Sent Event
+type event struct {
+	typ  string
+	data []byte
+}
+
+// sse holds the state of the loader in SSE mode, fields are guarded by mut
+type sse struct {
+	mut       *sync.Mutex
+	streams   []*stream
+	started   bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	watchChan chan struct{}
+	done      chan struct{}
+}
+
+func newSSE(sources []Source) *sse {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var s = &sse{
+		mut:       &sync.Mutex{},
+		streams:   make([]*stream, len(sources)),
+		ctx:       ctx,
+		cancel:    cancel,
+		watchChan: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	for i, source := range sources {
+		s.streams[i] = &stream{source: source}
+	}
+	return s
+}
+
+// Start implements konfig.Watcher. In SSE mode, it starts reading the events of the sources,
+// else it starts the poll watcher.
+func (r *Loader) Start() error {
+	if r.sse == nil {
+		return r.PollWatcher.Start()
+	}
+
+	r.sse.mut.Lock()
+	defer r.sse.mut.Unlock()
+	if !r.sse.started {
+		r.sse.started = true
+		for _, st := range r.sse.streams {
+			go r.watchStream(st)
+		}
+	}
+	return nil
+}
+
+// Watch implements konfig.Watcher, it returns the channel to which events are written
+func (r *Loader) Watch() <-chan struct{} {
+	if r.sse == nil {
+		return r.PollWatcher.Watch()
+	}
+	return r.sse.watchChan
+}
+
+// Done implements konfig.Watcher, it indicates wether the watcher is done or not
+func (r *Loader) Done() <-chan struct{} {
+	if r.sse == nil {
+		return r.PollWatcher.Done()
+	}
+	return r.sse.done
+}
+
+// Err implements konfig.Watcher, in SSE mode the loader reconnects on errors so it is always nil
+func (r *Loader) Err() error {
+	if r.sse == nil {
+		return r.PollWatcher.Err()
+	}
+	return nil
+}
+
+// Close implements konfig.Watcher, in SSE mode it closes the connections to the sources
+func (r *Loader) Close() error {
+	if r.sse == nil {
+		return r.PollWatcher.Close()
+	}
+
+	r.sse.mut.Lock()
+	defer r.sse.mut.Unlock()
+
+	select {
+	case <-r.sse.done:
+		return ErrAlreadyClosed
+	default:
+		close(r.sse.done)
+	}
+	r.sse.cancel()
+	return nil
+}
+
+// loadSSE loads the values of the last event of each source.
+// Before the watcher is started, a source which sent no event yet is connected to and its first event is awaited,
+// the connection is then kept for the watcher.
+func (r *Loader) loadSSE(ctx context.Context, s konfig.Values) error {
+	for _, st := range r.sse.streams {
+		r.sse.mut.Lock()
+		var values, started = st.values, r.sse.started
+		r.sse.mut.Unlock()
+
+		if values == nil && !started {
+			var err = r.firstEvent(ctx, st)
+			if err != nil {
+				return err
+			}
+			r.sse.mut.Lock()
+			values = st.values
+			r.sse.mut.Unlock()
+		}
+
+		if values == nil {
+			return konfig.NewLoadError(
+				r.cfg.Name,
+				konfig.CategoryNotFound,
+				fmt.Errorf(ErrNoEventMsg, st.source.URL),
+			)
+		}
+		for k, v := range values {
+			s.Set(k, v)
+		}
+	}
+	return nil
+}
+
+// firstEvent connects to the source of st and reads events until a payload is loaded, it returns when ctx is done
+func (r *Loader) firstEvent(ctx context.Context, st *stream) error {
+	var res = make(chan error, 1)
+	go func() {
+		if st.body == nil {
+			if err := r.connect(st); err != nil {
+				res <- konfig.NewLoadError(r.cfg.Name, errorCategory(err), err)
+				return
+			}
+		}
+		for {
+			var loaded, err = r.readEvent(st)
+			if err != nil {
+				r.sse.mut.Lock()
+				st.body.Close()
+				st.body = nil
+				r.sse.mut.Unlock()
+				res <- konfig.NewLoadError(r.cfg.Name, konfig.CategoryNetwork, err)
+				return
+			}
+			if loaded {
+				res <- nil
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-res:
+		return err
+	case <-ctx.Done():
+		// closing the connection interrupts the read
+		r.sse.mut.Lock()
+		if st.body != nil {
+			st.body.Close()
+		}
+		r.sse.mut.Unlock()
+		<-res
+		return ctx.Err()
+	}
+}
+
+// watchStream reads the events of the source of st until the loader is closed, reconnecting with backoff when the connection fails
+func (r *Loader) watchStream(st *stream) {
+	var attempt = 1
+	for {
+		if st.body == nil {
+			if err := r.connect(st); err != nil {
+				select {
+				case <-r.sse.done:
+					return
+				default:
+				}
+				r.cfg.Logger.Get().Error(err.Error())
+				if attempt = r.backoff(st, attempt); attempt < 0 {
+					return
+				}
+				continue
+			}
+		}
+
+		var loaded, err = r.readEvent(st)
+		if err != nil {
+			st.body.Close()
+			r.sse.mut.Lock()
+			st.body = nil
+			r.sse.mut.Unlock()
+
+			select {
+			case <-r.sse.done:
+				return
+			default:
+			}
+			if err != io.EOF {
+				r.cfg.Logger.Get().Error(err.Error())
+			}
+			if attempt = r.backoff(st, attempt); attempt < 0 {
+				return
+			}
+			continue
+		}
+
+		if !loaded {
+			continue
+		}
+		if attempt > 1 {
+			attempt = 1
+			if r.cfg.Backoff != nil {
+				r.cfg.Backoff.Reset()
+			}
+		}
+
+		if r.cfg.Debug {
+			r.cfg.Logger.Get().Debug("Event received, sending watch event")
+		}
+		select {
+		case r.sse.watchChan <- struct{}{}:
+		default:
+			// an event is already pending, the next load loads the last payloads
+		}
+	}
+}
+
+// backoff waits before the reconnection attempt and returns the next attempt, it returns a negative attempt if the loader is closed.
+// The delay is given by the Backoff of the config, by default it starts at the retry sent by the source or ReconnectDelay,
+// doubling up to MaxReconnectDelay.
+func (r *Loader) backoff(st *stream, attempt int) int {
+	var b = r.cfg.Backoff
+	if b == nil {
+		var initial = r.cfg.ReconnectDelay
+		if st.retry > 0 {
+			initial = st.retry
+		}
+		b = konfig.ExponentialBackoff{Initial: initial, Max: r.cfg.MaxReconnectDelay}
+	}
+
+	var t = time.NewTimer(b.NextDelay(attempt))
+	select {
+	case <-t.C:
+	case <-r.sse.done:
+		t.Stop()
+		return -1
+	}
+
+	if r.cfg.Debug {
+		r.cfg.Logger.Get().Debug("Reconnecting to " + st.source.URL)
+	}
+	return attempt + 1
+}
+
+// connect opens the event stream of the source of st, sending the id of the last event received to resume the stream
+func (r *Loader) connect(st *stream) error {
+	var req, err = http.NewRequest(st.source.Method, st.source.URL, st.source.Body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(r.sse.ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if st.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", st.lastEventID)
+	}
+	if st.source.Prepare != nil {
+		st.source.Prepare(req)
+	}
+
+	res, err := r.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	var statusCode = st.source.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	if res.StatusCode != statusCode {
+		res.Body.Close()
+		return &StatusError{
+			URL:        st.source.URL,
+			StatusCode: res.StatusCode,
+		}
+	}
+
+	r.sse.mut.Lock()
+	defer r.sse.mut.Unlock()
+	select {
+	case <-r.sse.done:
+		res.Body.Close()
+		return ErrAlreadyClosed
+	default:
+	}
+	st.body = res.Body
+	st.br = bufio.NewReader(res.Body)
+	return nil
+}
+
+// readEvent reads the next event of st and, if it is a payload, parses it and sets it as the values of st.
+// It returns true if the values of st were set. Payloads which fail to be parsed are logged and ignored.
+func (r *Loader) readEvent(st *stream) (bool, error) {
+	var e, err = r.nextEvent(st)
+	if err != nil {
+		return false, err
+	}
+	if e.typ != r.cfg.SSEEvent {
+		return false, nil
+	}
+
+	var v = konfig.Values{}
+	if err := st.source.Parser.Parse(bytes.NewReader(e.data), v); err != nil {
+		r.cfg.Logger.Get().Error(konfig.NewLoadError(r.cfg.Name, konfig.CategoryParse, err).Error())
+		return false, nil
+	}
+	if r.cfg.MergePatch {
+		r.sse.mut.Lock()
+		v = mergePatch(st.values, v)
+		r.sse.mut.Unlock()
+	}
+	if len(v) == 0 && !r.cfg.AllowEmpty {
+		r.cfg.Logger.Get().Error(
+			konfig.NewLoadError(r.cfg.Name, konfig.CategoryNotFound, fmt.Errorf(konfig.ErrEmptySourceMsg, st.source.URL)).Error(),
+		)
+		return false, nil
+	}
+
+	r.sse.mut.Lock()
+	st.values = v
+	r.sse.mut.Unlock()
+	return true, nil
+}
+
+// nextEvent reads lines from the stream of st until an event with data is dispatched.
+// The id of the last event and the retry delay sent by the source are recorded in st.
+func (r *Loader) nextEvent(st *stream) (event, error) {
+	var e = event{typ: DefaultSSEEvent}
+	var data []byte
+	var hasData bool
+	for {
+		// lines are limited to the size of a data line of MaxBytes
+		var line, err = readLine(st.br, r.cfg.MaxBytes+maxFieldBytes)
+		if err == errLineTooLarge {
+			return e, fmt.Errorf(ErrEventTooLargeMsg, st.source.URL, r.cfg.MaxBytes)
+		}
+		if err != nil {
+			return e, err
+		}
+
+		// an empty line dispatches the event
+		if len(line) == 0 {
+			if hasData {
+				e.data = data
+				return e, nil
+			}
+			e = event{typ: DefaultSSEEvent}
+			continue
+		}
+		// comments are used as keep alive
+		if line[0] == ':' {
+			continue
+		}
+
+		var field, value = line, []byte{}
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], bytes.TrimPrefix(line[i+1:], []byte(" "))
+		}
+
+		switch string(field) {
+		case "event":
+			e.typ = string(value)
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+			if r.cfg.MaxBytes >= 0 && int64(len(data)) > r.cfg.MaxBytes {
+				return e, fmt.Errorf(ErrEventTooLargeMsg, st.source.URL, r.cfg.MaxBytes)
+			}
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				st.lastEventID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(string(value)); err == nil {
+				st.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+var errLineTooLarge = errors.New("line too large")
+
+// maxFieldBytes is the size allowed for the field name and the line ending of a line on top of its value
+const maxFieldBytes = 16
+
+// readLine reads a line from br and returns it without its line ending, it returns errLineTooLarge if the line is bigger than max bytes
+func readLine(br *bufio.Reader, max int64) ([]byte, error) {
+	var line []byte
+	for {
+		var b, err = br.ReadSlice('\n')
+		line = append(line, b...)
+		if max >= 0 && int64(len(line)) > max {
+			return nil, errLineTooLarge
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+}
@@ -0,0 +1,144 @@
+package klhttp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/stretchr/testify/require"
+)
+
+func waitEvent(t *testing.T, l *Loader) {
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+}
+
+func TestSSE(t *testing.T) {
+	var next = make(chan struct{})
+	var ids = make(chan string, 10)
+	var conns int
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		ids <- r.Header.Get("Last-Event-ID")
+		conns++
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		var f = w.(http.Flusher)
+		if conns == 1 {
+			fmt.Fprint(w, ": keep alive\n\nretry: 10\nid: 1\ndata: {\"foo\":\"bar\"}\n\n")
+			f.Flush()
+			<-next
+			// other event types are ignored, data lines are joined with new lines
+			fmt.Fprint(w, "event: heartbeat\ndata: {}\n\nid: 2\ndata: {\"foo\":\ndata: \"baz\"}\n\n")
+			f.Flush()
+			return
+		}
+		fmt.Fprint(w, "data: {\"foo\":\"qux\"}\r\n\r\n")
+		f.Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	var l = New(&Config{
+		Sources:        []Source{{URL: srv.URL, Parser: kpjson.Parser}},
+		SSE:            true,
+		ReconnectDelay: time.Second,
+	})
+	require.Equal(t, DefaultSSEEvent, l.cfg.SSEEvent)
+	require.Equal(t, time.Duration(0), l.cfg.HTTPClient.Timeout)
+
+	// the first load waits for the first event
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar"}, v)
+	require.Equal(t, "", <-ids)
+
+	require.Nil(t, l.Start())
+	next <- struct{}{}
+	waitEvent(t, l)
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "baz"}, v)
+
+	// the loader reconnects after the retry sent by the source and resumes from the last event
+	require.Equal(t, "2", <-ids)
+	waitEvent(t, l)
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "qux"}, v)
+
+	require.Nil(t, l.Close())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+	select {
+	case <-l.Done():
+	default:
+		t.Fatal("watcher not done")
+	}
+}
+
+func TestSSELoadErrors(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	var l = New(&Config{
+		Sources: []Source{{URL: srv.URL + "/forbidden", Parser: kpjson.Parser}},
+		SSE:     true,
+	})
+	var err = l.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, konfig.CategoryAuth, konfig.ErrorCategoryOf(err))
+
+	// the load stops waiting for the first event when the context is done
+	l = New(&Config{
+		Sources: []Source{{URL: srv.URL, Parser: kpjson.Parser}},
+		SSE:     true,
+	})
+	var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, l.LoadContext(ctx, konfig.Values{}))
+
+	// once started, a source which sent no event fails the load
+	require.Nil(t, l.Start())
+	err = l.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, konfig.CategoryNotFound, konfig.ErrorCategoryOf(err))
+	require.Nil(t, l.Close())
+}
+
+func TestNextEvent(t *testing.T) {
+	var l = New(&Config{
+		Sources:  []Source{{URL: "http://url.com", Parser: kpjson.Parser}},
+		SSE:      true,
+		MaxBytes: 10,
+	})
+	var st = l.sse.streams[0]
+
+	st.br = bufio.NewReader(strings.NewReader("retry: 500\nid: 42\nevent: update\ndata: {}\n\n"))
+	var e, err = l.nextEvent(st)
+	require.Nil(t, err)
+	require.Equal(t, event{typ: "update", data: []byte("{}")}, e)
+	require.Equal(t, "42", st.lastEventID)
+	require.Equal(t, 500*time.Millisecond, st.retry)
+
+	st.br = bufio.NewReader(strings.NewReader("data: 0123456789a\n\n"))
+	_, err = l.nextEvent(st)
+	require.NotNil(t, err)
+	require.Equal(t, fmt.Sprintf(ErrEventTooLargeMsg, "http://url.com", 10), err.Error())
+}
@@ -0,0 +1,301 @@
+// Package kljsonl provides a loader reading a JSON Lines stream, each line is an update merged into the values of the loader.
+package kljsonl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader       = (*Loader)(nil)
+	_ konfig.ParserLoader = (*Loader)(nil)
+	_ konfig.Watcher      = (*Loader)(nil)
+	// ErrNoReader is the error thrown when trying to create a JSON Lines loader with no reader
+	ErrNoReader = errors.New("no reader provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed Loader
+	ErrAlreadyClosed = errors.New("JSON Lines loader already closed")
+	// ErrLineTooLargeMsg is the error message logged when a line is bigger than MaxBytes
+	ErrLineTooLargeMsg = "Err line exceeds the maximum of %d bytes"
+)
+
+const (
+	defaultName         = "jsonl"
+	defaultPollInterval = time.Second
+)
+
+// Config is the config of a JSON Lines Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Reader is the stream the lines are read from
+	Reader io.Reader
+	// Parser is the parser used to parse each line, default is kpjson.Parser
+	Parser parser.Parser
+	// Follow sets wether the reader is read again after EOF, like tail -f, to read the lines appended to a file.
+	// A partial line at EOF is buffered until it is complete. If false, the watcher stops at EOF.
+	Follow bool
+	// PollInterval is the delay before reading again after EOF when Follow is set, default is 1 second
+	PollInterval time.Duration
+	// MaxBytes is the maximum size of a line, bigger lines are logged and ignored.
+	// Default is parser.DefaultMaxBytes.
+	MaxBytes int64
+	// MaxRetry is the maximum number of times load can be retried
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// Debug sets the debug mode
+	Debug bool
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
+}
+
+// Loader is a konfig.Loader loading the values of a JSON Lines stream, each line is a JSON object merged into the values of the previous lines.
+// It is also a konfig.Watcher: once started, it reads the stream line by line and sends an event for every line successfully parsed,
+// the next line is read once the store loaded the previous one so that each line fires its own reload.
+// Lines which fail to be parsed are logged and ignored.
+type Loader struct {
+	cfg       *Config
+	mut       *sync.Mutex
+	values    konfig.Values
+	err       error
+	watchChan chan struct{}
+	loaded    chan struct{}
+	done      chan struct{}
+}
+
+// New creates a new Loader from the given config
+func New(cfg *Config) *Loader {
+	if cfg.Reader == nil {
+		panic(ErrNoReader)
+	}
+	if cfg.Parser == nil {
+		cfg.Parser = kpjson.Parser
+	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = parser.DefaultMaxBytes
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	return &Loader{
+		cfg:       cfg,
+		mut:       &sync.Mutex{},
+		values:    konfig.Values{},
+		watchChan: make(chan struct{}),
+		loaded:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Parsers implements konfig.ParserLoader, it returns the name of the parser of the lines
+func (l *Loader) Parsers() []string { return []string{parser.Name(l.cfg.Parser)} }
+
+// Load implements konfig.Loader, it loads the values of the lines read so far.
+// Before the first line is read, it loads no values.
+func (l *Loader) Load(s konfig.Values) error {
+	l.mut.Lock()
+	for k, v := range l.values {
+		s.Set(k, v)
+	}
+	l.mut.Unlock()
+
+	select {
+	case l.loaded <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// MaxRetry returns the maximum number of times to retry a load when an error occurs
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay returns the delay between each load retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Start implements konfig.Watcher, it starts reading lines from the stream
+func (l *Loader) Start() error {
+	go l.watch()
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (l *Loader) Watch() <-chan struct{} {
+	return l.watchChan
+}
+
+// Done indicates wether the watcher is done or not, it is done once closed or when the stream ends
+func (l *Loader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close closes the watcher, the reader is not closed
+func (l *Loader) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+		return ErrAlreadyClosed
+	default:
+		close(l.done)
+	}
+	return nil
+}
+
+// Err returns the error which stopped the watcher, it is nil if the stream ended or the watcher was closed
+func (l *Loader) Err() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.err
+}
+
+func (l *Loader) watch() {
+	var br = bufio.NewReader(l.cfg.Reader)
+	var line []byte
+	var tooLarge bool
+	for {
+		var b, err = br.ReadSlice('\n')
+		if !tooLarge {
+			line = append(line, b...)
+		}
+		if int64(len(bytes.TrimRight(line, "\r\n"))) > l.cfg.MaxBytes {
+			if !tooLarge {
+				l.cfg.Logger.Get().Error(fmt.Sprintf(ErrLineTooLargeMsg, l.cfg.MaxBytes))
+			}
+			tooLarge, line = true, nil
+		}
+
+		switch err {
+		case bufio.ErrBufferFull:
+			// the line continues
+			continue
+		case nil:
+			// the line is complete
+			var complete = line
+			var skip = tooLarge
+			line, tooLarge = nil, false
+			if !skip && !l.line(complete) {
+				return
+			}
+			continue
+		case io.EOF:
+			if !l.cfg.Follow {
+				if !tooLarge {
+					l.line(line)
+				}
+				l.stop(nil)
+				return
+			}
+			// the partial line is buffered until it is complete
+			var t = time.NewTimer(l.cfg.PollInterval)
+			select {
+			case <-t.C:
+			case <-l.done:
+				t.Stop()
+				return
+			}
+		default:
+			l.cfg.Logger.Get().Error(err.Error())
+			l.stop(err)
+			return
+		}
+	}
+}
+
+// line parses the line, merges its values into the values to load, sends a watch event and waits for the values to be loaded.
+// It returns false if the loader is closed.
+func (l *Loader) line(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return true
+	}
+
+	var v = konfig.Values{}
+	if err := l.cfg.Parser.Parse(bytes.NewReader(line), v); err != nil {
+		l.cfg.Logger.Get().Error(konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err).Error())
+		return true
+	}
+
+	l.mut.Lock()
+	var values = make(konfig.Values, len(l.values)+len(v))
+	for k, vv := range l.values {
+		values[k] = vv
+	}
+	for k, vv := range v {
+		values[k] = vv
+	}
+	l.values = values
+	l.mut.Unlock()
+
+	if l.cfg.Debug {
+		l.cfg.Logger.Get().Debug("Line received, sending watch event")
+	}
+
+	// we drop the acknowledgement of a load which did not come from this line
+	select {
+	case <-l.loaded:
+	default:
+	}
+
+	select {
+	case l.watchChan <- struct{}{}:
+	case <-l.done:
+		return false
+	}
+
+	select {
+	case <-l.loaded:
+		return true
+	case <-l.done:
+		return false
+	}
+}
+
+// stop closes the watcher with the error err
+func (l *Loader) stop(err error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+	default:
+		l.err = err
+		close(l.done)
+	}
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "JSONLLOADER | "))
+}
@@ -0,0 +1,129 @@
+package kljsonl
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+// waitLoad waits for a watch event and loads the values like the store does
+func waitLoad(t *testing.T, l *Loader) konfig.Values {
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	return v
+}
+
+func TestNew(t *testing.T) {
+	require.PanicsWithValue(t, ErrNoReader, func() { New(&Config{}) })
+
+	var l = New(&Config{Reader: strings.NewReader("")})
+	require.Equal(t, defaultName, l.Name())
+	require.Equal(t, defaultPollInterval, l.cfg.PollInterval)
+	require.NotNil(t, l.cfg.Parser)
+}
+
+func TestStream(t *testing.T) {
+	var r, w = io.Pipe()
+	var l = New(&Config{Reader: r})
+
+	// no line read yet
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{}, v)
+
+	require.Nil(t, l.Start())
+
+	go func() {
+		// invalid and empty lines are ignored
+		w.Write([]byte("{\n\n{\"foo\":\"bar\",\"nested\":{\"a\":1}}\n{\"nested\":{\"b\":2}}\n"))
+		w.Write([]byte("{\"foo\":"))
+		w.Write([]byte("\"baz\"}\n"))
+		w.Close()
+	}()
+
+	// each line is loaded by its own reload
+	require.Equal(t, konfig.Values{"foo": "bar", "nested.a": float64(1)}, waitLoad(t, l))
+	require.Equal(t, konfig.Values{"foo": "bar", "nested.a": float64(1), "nested.b": float64(2)}, waitLoad(t, l))
+	require.Equal(t, konfig.Values{"foo": "baz", "nested.a": float64(1), "nested.b": float64(2)}, waitLoad(t, l))
+
+	// the watcher stops at the end of the stream
+	select {
+	case <-l.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher not done")
+	}
+	require.Nil(t, l.Err())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+}
+
+func TestFollow(t *testing.T) {
+	var f, err = ioutil.TempFile("", "kljsonl")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	rf, err := os.Open(f.Name())
+	require.Nil(t, err)
+	defer rf.Close()
+
+	var l = New(&Config{
+		Reader:       rf,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+	})
+	require.Nil(t, l.Start())
+	defer l.Close()
+
+	_, err = f.WriteString("{\"foo\":\"bar\"}\n{\"foo\":")
+	require.Nil(t, err)
+	require.Equal(t, konfig.Values{"foo": "bar"}, waitLoad(t, l))
+
+	// the partial line is buffered until it is complete
+	select {
+	case <-l.Watch():
+		t.Fatal("unexpected watch event")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = f.WriteString("\"baz\"}\n")
+	require.Nil(t, err)
+	require.Equal(t, konfig.Values{"foo": "baz"}, waitLoad(t, l))
+
+	require.Nil(t, l.Close())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+}
+
+func TestLineTooLarge(t *testing.T) {
+	var l = New(&Config{
+		Reader:   strings.NewReader("{\"foo\":\"" + strings.Repeat("a", 5000) + "\"}\n{\"foo\":\"bar\"}"),
+		MaxBytes: 100,
+	})
+	require.Nil(t, l.Start())
+
+	// the line too large is ignored, the last line is loaded at EOF
+	require.Equal(t, konfig.Values{"foo": "bar"}, waitLoad(t, l))
+	<-l.Done()
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read error") }
+
+func TestReadError(t *testing.T) {
+	var l = New(&Config{Reader: errReader{}})
+	require.Nil(t, l.Start())
+	<-l.Done()
+	require.Equal(t, errors.New("read error"), l.Err())
+}
@@ -0,0 +1,226 @@
+package klk8s
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// watch events can be large, the scanner buffer must fit a whole object
+	maxEventSize = 4 << 20
+)
+
+var (
+	// ErrNotInCluster is the error returned by InClusterClient when the process does not run in a Kubernetes pod
+	ErrNotInCluster = errors.New("Err not running in a Kubernetes cluster, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+)
+
+// Object is a ConfigMap or a Secret returned by the Kubernetes API
+type Object struct {
+	Metadata struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	// Data are the values of the object, base64 encoded for Secrets
+	Data map[string]string `json:"data"`
+	// BinaryData are the base64 encoded binary values of a ConfigMap
+	BinaryData map[string]string `json:"binaryData"`
+}
+
+// WatchEvent is an event of a watch on an object
+type WatchEvent struct {
+	// Type is the type of the event: ADDED, MODIFIED, DELETED or ERROR
+	Type string
+	// Object is the object, it is nil for ERROR events
+	Object *Object
+	// Err is the error of an ERROR event
+	Err error
+}
+
+// StatusError is the error returned when the Kubernetes API responds with an unexpected status code
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Err kubernetes API returned status code %d: %s", e.Code, e.Message)
+}
+
+// Client is the interface used to get and watch ConfigMaps and Secrets, APIClient implements it.
+// It can be implemented with client-go to reuse its transport and authentication.
+type Client interface {
+	// Get returns the object of the resource
+	Get(ctx context.Context, r Resource) (*Object, error)
+	// Watch watches the object of the resource from the resource version rv.
+	// The channel is closed when the watch ends, the watch is stopped when ctx is done.
+	Watch(ctx context.Context, r Resource, rv string) (<-chan WatchEvent, error)
+}
+
+// APIClient is a Client calling the Kubernetes REST API
+type APIClient struct {
+	// Host is the URL of the API server, e.g. https://10.0.0.1:443
+	Host string
+	// Token is the bearer token used to authenticate, if TokenFile is set the token is read from the file on each request
+	Token string
+	// TokenFile is the path of a file containing the bearer token, it is read on each request so rotated tokens are picked up
+	TokenFile string
+	// HTTPClient is the HTTP client used to send requests, default is http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// InClusterClient returns an APIClient authenticating with the service account of the pod the process runs in
+func InClusterClient() (*APIClient, error) {
+	var host, port = os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+
+	var ca, err = ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	var pool = x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+
+	var transport = http.DefaultTransport.(*http.Transport)
+	return &APIClient{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		TokenFile: serviceAccountDir + "/token",
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:               transport.Proxy,
+				DialContext:         transport.DialContext,
+				TLSHandshakeTimeout: transport.TLSHandshakeTimeout,
+				TLSClientConfig:     &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// Get implements Client, it gets the object of the resource
+func (c *APIClient) Get(ctx context.Context, r Resource) (*Object, error) {
+	var res, err = c.do(ctx, r.path()+"/"+url.PathEscape(r.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var o Object
+	if err := json.NewDecoder(res.Body).Decode(&o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Watch implements Client, it watches the object of the resource from the resource version rv
+func (c *APIClient) Watch(ctx context.Context, r Resource, rv string) (<-chan WatchEvent, error) {
+	var q = url.Values{}
+	q.Set("watch", "true")
+	q.Set("fieldSelector", "metadata.name="+r.Name)
+	if rv != "" {
+		q.Set("resourceVersion", rv)
+	}
+
+	var res, err = c.do(ctx, r.path()+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var events = make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		defer res.Body.Close()
+
+		var sc = bufio.NewScanner(res.Body)
+		sc.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+		for sc.Scan() {
+			var e struct {
+				Type   string          `json:"type"`
+				Object json.RawMessage `json:"object"`
+			}
+			if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+				return
+			}
+
+			var we = WatchEvent{Type: e.Type}
+			if e.Type == "ERROR" {
+				var st struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				}
+				json.Unmarshal(e.Object, &st)
+				we.Err = &StatusError{Code: st.Code, Message: st.Message}
+			} else {
+				we.Object = &Object{}
+				if err := json.Unmarshal(e.Object, we.Object); err != nil {
+					return
+				}
+			}
+
+			select {
+			case events <- we:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (c *APIClient) do(ctx context.Context, path string) (*http.Response, error) {
+	var req, err = http.NewRequest(http.MethodGet, strings.TrimSuffix(c.Host, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	var token = c.Token
+	if c.TokenFile != "" {
+		var b, err = ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var hc = c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		var st struct {
+			Message string `json:"message"`
+		}
+		var b, _ = ioutil.ReadAll(res.Body)
+		if json.Unmarshal(b, &st) != nil || st.Message == "" {
+			st.Message = strings.TrimSpace(string(b))
+		}
+		return nil, &StatusError{Code: res.StatusCode, Message: st.Message}
+	}
+	return res, nil
+}
@@ -0,0 +1,368 @@
+// Package klk8s provides a loader fetching Kubernetes ConfigMaps and Secrets from the Kubernetes API.
+package klk8s
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader        = (*Loader)(nil)
+	_ konfig.ContextLoader = (*Loader)(nil)
+	_ konfig.Watcher       = (*Loader)(nil)
+	// ErrNoResources is the error thrown when trying to create a Loader without resources
+	ErrNoResources = errors.New("no resources provided")
+	// ErrNoClient is the error thrown when trying to create a Loader without a client
+	ErrNoClient = errors.New("no kubernetes client provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed Loader
+	ErrAlreadyClosed = errors.New("Kubernetes loader already closed")
+	// ErrKindMsg is the error message thrown when trying to create a Loader with a resource of an unsupported kind
+	ErrKindMsg = "Err unsupported kind '%s', kind must be ConfigMap or Secret"
+	// ErrDecodeMsg is the error message returned when a value of a Secret cannot be decoded
+	ErrDecodeMsg = "Err decoding key '%s' of %s %s/%s: %v"
+)
+
+const (
+	// KindConfigMap is the kind of a ConfigMap resource
+	KindConfigMap = "ConfigMap"
+	// KindSecret is the kind of a Secret resource
+	KindSecret = "Secret"
+
+	defaultName      = "k8s"
+	defaultNamespace = "default"
+	defaultErrDelay  = 5 * time.Second
+)
+
+// Resource is a ConfigMap or a Secret to load
+type Resource struct {
+	// Kind is the kind of the resource, KindConfigMap or KindSecret
+	Kind string
+	// Namespace is the namespace of the resource, default is "default"
+	Namespace string
+	// Name is the name of the resource
+	Name string
+	// Prefix is prepended to the keys of the data of the resource, e.g. "db." loads the key "host" as "db.host"
+	Prefix string
+}
+
+func (r Resource) path() string {
+	var kind = "configmaps"
+	if r.Kind == KindSecret {
+		kind = "secrets"
+	}
+	return "/api/v1/namespaces/" + url.PathEscape(r.Namespace) + "/" + kind
+}
+
+// Config is the config of a Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a load failure(after the retries) leads to closing the config and all registered closers
+	StopOnFailure bool
+	// Client is the client used to get and watch the resources, for example the APIClient returned by InClusterClient
+	Client Client
+	// Resources are the ConfigMaps and Secrets to load
+	Resources []Resource
+	// Watch tells if the loader should watch the resources with the watch API and reload when they change
+	Watch bool
+	// ErrDelay is the delay before restarting a watch when it fails, default is 5 seconds
+	ErrDelay time.Duration
+	// MaxRetry is the maximum number of times we can retry to load if it fails
+	MaxRetry int
+	// RetryDelay is the time between each retry when a load fails
+	RetryDelay time.Duration
+	// Debug sets debug mode on the loader
+	Debug bool
+	// Logger is used across this package to produce logs
+	Logger nlogger.Provider
+}
+
+// Loader is a konfig.Loader loading the data of Kubernetes ConfigMaps and Secrets.
+// For each resource, each key of its data is set in the store prefixed with the Prefix of the resource.
+// Values of Secrets and binary values of ConfigMaps are base64 decoded, binary values of ConfigMaps are set as []byte.
+// When Watch is set in the config, it is also a konfig.Watcher using the watch API of each resource to send an event when its data change, without polling.
+type Loader struct {
+	cfg       *Config
+	mut       *sync.Mutex
+	versions  map[int]string
+	data      map[int]konfig.Values
+	watchChan chan struct{}
+	done      chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// New returns a new Loader with the given config
+func New(cfg *Config) *Loader {
+	if cfg.Client == nil {
+		panic(ErrNoClient)
+	}
+	if len(cfg.Resources) == 0 {
+		panic(ErrNoResources)
+	}
+	for i, r := range cfg.Resources {
+		if r.Kind != KindConfigMap && r.Kind != KindSecret {
+			panic(fmt.Errorf(ErrKindMsg, r.Kind))
+		}
+		if r.Namespace == "" {
+			cfg.Resources[i].Namespace = defaultNamespace
+		}
+	}
+	if cfg.ErrDelay == 0 {
+		cfg.ErrDelay = defaultErrDelay
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+
+	return &Loader{
+		cfg:       cfg,
+		mut:       &sync.Mutex{},
+		versions:  make(map[int]string, len(cfg.Resources)),
+		data:      make(map[int]konfig.Values, len(cfg.Resources)),
+		watchChan: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Load implements konfig.Loader, it loads the data of the resources
+func (l *Loader) Load(s konfig.Values) error {
+	return l.LoadContext(context.Background(), s)
+}
+
+// LoadContext implements konfig.ContextLoader, requests are cancelled when ctx is done
+func (l *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
+	for i, r := range l.cfg.Resources {
+		var o, err = l.cfg.Client.Get(ctx, r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return konfig.NewLoadError(l.cfg.Name, errorCategory(err), err)
+		}
+
+		v, err := values(r, o)
+		if err != nil {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err)
+		}
+		l.setObject(i, o.Metadata.ResourceVersion, v)
+
+		for k, vv := range v {
+			s.Set(k, vv)
+		}
+	}
+	return nil
+}
+
+// MaxRetry is the maximum number of time to retry when a load fails
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay is the delay between each retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Start implements konfig.Watcher, it starts a watch for each resource if Watch is set in the config
+func (l *Loader) Start() error {
+	if !l.cfg.Watch {
+		return nil
+	}
+	for i := range l.cfg.Resources {
+		go l.watch(i)
+	}
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (l *Loader) Watch() <-chan struct{} {
+	return l.watchChan
+}
+
+// Done indicates wether the watcher is done or not
+func (l *Loader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close closes the watcher and stops the watches
+func (l *Loader) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+		return ErrAlreadyClosed
+	default:
+		close(l.done)
+	}
+	l.cancel()
+	return nil
+}
+
+// Err returns the watcher error, failing watches are restarted so it is always nil
+func (l *Loader) Err() error {
+	return nil
+}
+
+// watch watches the resource at index i and sends an event when its data change.
+// The watch starts from the resource version of the last load, it is restarted from the last event when the API server closes it.
+func (l *Loader) watch(i int) {
+	var r = l.cfg.Resources[i]
+	for {
+		var events, err = l.cfg.Client.Watch(l.ctx, r, l.version(i))
+		if err == nil {
+			err = l.handleEvents(i, events)
+		}
+
+		select {
+		case <-l.done:
+			return
+		default:
+		}
+
+		if err == nil {
+			// the API server closed the watch, we restart it
+			continue
+		}
+
+		l.cfg.Logger.Get().Error(err.Error())
+		if se, ok := err.(*StatusError); ok && se.Code == http.StatusGone {
+			// the resource version is too old, we watch from the current version
+			l.setVersion(i, "")
+		}
+
+		var t = time.NewTimer(l.cfg.ErrDelay)
+		select {
+		case <-t.C:
+		case <-l.done:
+			t.Stop()
+			return
+		}
+	}
+}
+
+// handleEvents reads the events of a watch until it ends and returns the error of an ERROR event
+func (l *Loader) handleEvents(i int, events <-chan WatchEvent) error {
+	var r = l.cfg.Resources[i]
+	for e := range events {
+		if e.Err != nil {
+			return e.Err
+		}
+		if e.Object == nil {
+			continue
+		}
+
+		var v konfig.Values
+		if e.Type != "DELETED" {
+			var err error
+			if v, err = values(r, e.Object); err != nil {
+				l.cfg.Logger.Get().Error(err.Error())
+			}
+		}
+
+		if l.setObject(i, e.Object.Metadata.ResourceVersion, v) {
+			if l.cfg.Debug {
+				l.cfg.Logger.Get().Debug(fmt.Sprintf("%s %s/%s changed, sending watch event", r.Kind, r.Namespace, r.Name))
+			}
+			select {
+			case l.watchChan <- struct{}{}:
+			default:
+				// an event is already pending
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Loader) version(i int) string {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.versions[i]
+}
+
+func (l *Loader) setVersion(i int, rv string) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.versions[i] = rv
+}
+
+// setObject records the version and the values of the resource at index i and returns wether the values changed
+func (l *Loader) setObject(i int, rv string, v konfig.Values) bool {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.versions[i] = rv
+	var prev, ok = l.data[i]
+	l.data[i] = v
+	return ok && !reflect.DeepEqual(prev, v)
+}
+
+// values returns the values of the data of the object o of the resource r
+func values(r Resource, o *Object) (konfig.Values, error) {
+	var v = make(konfig.Values, len(o.Data)+len(o.BinaryData))
+	for k, d := range o.Data {
+		if r.Kind != KindSecret {
+			v.Set(r.Prefix+k, d)
+			continue
+		}
+		var b, err = base64.StdEncoding.DecodeString(d)
+		if err != nil {
+			return nil, fmt.Errorf(ErrDecodeMsg, k, r.Kind, r.Namespace, r.Name, err)
+		}
+		v.Set(r.Prefix+k, string(b))
+	}
+	for k, d := range o.BinaryData {
+		var b, err = base64.StdEncoding.DecodeString(d)
+		if err != nil {
+			return nil, fmt.Errorf(ErrDecodeMsg, k, r.Kind, r.Namespace, r.Name, err)
+		}
+		v.Set(r.Prefix+k, b)
+	}
+	return v, nil
+}
+
+func errorCategory(err error) konfig.ErrorCategory {
+	var se, ok = err.(*StatusError)
+	if !ok {
+		return konfig.CategoryNetwork
+	}
+
+	switch se.Code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return konfig.CategoryAuth
+	case http.StatusNotFound:
+		return konfig.CategoryNotFound
+	}
+	return konfig.CategoryNetwork
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "K8SLOADER | "))
+}
@@ -0,0 +1,255 @@
+package klk8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+func object(rv string, data, binaryData map[string]string) *Object {
+	var o = &Object{Data: data, BinaryData: binaryData}
+	o.Metadata.ResourceVersion = rv
+	return o
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// fakeClient is a Client serving objects from memory, watches receive the events sent on the events channel
+type fakeClient struct {
+	mut     sync.Mutex
+	objects map[string]*Object
+	err     error
+	events  chan WatchEvent
+	watches chan string
+}
+
+func (f *fakeClient) Get(ctx context.Context, r Resource) (*Object, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var o, ok = f.objects[r.Kind+"/"+r.Namespace+"/"+r.Name]
+	if !ok {
+		return nil, &StatusError{Code: http.StatusNotFound, Message: "not found"}
+	}
+	return o, nil
+}
+
+func (f *fakeClient) Watch(ctx context.Context, r Resource, rv string) (<-chan WatchEvent, error) {
+	f.watches <- rv
+	var events = make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case e := <-f.events:
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+				if e.Err != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func TestNew(t *testing.T) {
+	var c = &fakeClient{}
+	require.PanicsWithValue(t, ErrNoClient, func() {
+		New(&Config{Resources: []Resource{{Kind: KindConfigMap, Name: "app"}}})
+	})
+	require.PanicsWithValue(t, ErrNoResources, func() {
+		New(&Config{Client: c})
+	})
+	require.Panics(t, func() {
+		New(&Config{Client: c, Resources: []Resource{{Kind: "Pod", Name: "app"}}})
+	})
+
+	var l = New(&Config{Client: c, Resources: []Resource{{Kind: KindConfigMap, Name: "app"}}})
+	require.Equal(t, defaultName, l.Name())
+	require.Equal(t, defaultNamespace, l.cfg.Resources[0].Namespace)
+	require.Equal(t, defaultErrDelay, l.cfg.ErrDelay)
+}
+
+func TestLoad(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		objects   map[string]*Object
+		err       error
+		expected  konfig.Values
+		errString string
+		category  konfig.ErrorCategory
+	}{
+		{
+			name: "configmap and secret",
+			objects: map[string]*Object{
+				"ConfigMap/default/app": object("1", map[string]string{"host": "localhost"}, map[string]string{"cert": b64("\x00\x01")}),
+				"Secret/prod/db":        object("2", map[string]string{"password": b64("secret")}, nil),
+			},
+			expected: konfig.Values{
+				"host":        "localhost",
+				"cert":        []byte{0x00, 0x01},
+				"db.password": "secret",
+			},
+		},
+		{
+			name: "not found",
+			objects: map[string]*Object{
+				"ConfigMap/default/app": object("1", map[string]string{"host": "localhost"}, nil),
+			},
+			category: konfig.CategoryNotFound,
+		},
+		{
+			name:     "forbidden",
+			err:      &StatusError{Code: http.StatusForbidden, Message: "forbidden"},
+			category: konfig.CategoryAuth,
+		},
+		{
+			name: "invalid secret",
+			objects: map[string]*Object{
+				"ConfigMap/default/app": object("1", nil, nil),
+				"Secret/prod/db":        object("2", map[string]string{"password": "!!!"}, nil),
+			},
+			category:  konfig.CategoryParse,
+			errString: "Err decoding key 'password' of Secret prod/db",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var l = New(&Config{
+				Client: &fakeClient{objects: testCase.objects, err: testCase.err},
+				Resources: []Resource{
+					{Kind: KindConfigMap, Name: "app"},
+					{Kind: KindSecret, Namespace: "prod", Name: "db", Prefix: "db."},
+				},
+			})
+
+			var v = konfig.Values{}
+			var err = l.Load(v)
+			if testCase.expected == nil {
+				require.NotNil(t, err)
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), testCase.errString)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var c = &fakeClient{
+		objects: map[string]*Object{
+			"ConfigMap/default/app": object("1", map[string]string{"host": "localhost"}, nil),
+		},
+		events:  make(chan WatchEvent),
+		watches: make(chan string, 10),
+	}
+	var l = New(&Config{
+		Client:    c,
+		Resources: []Resource{{Kind: KindConfigMap, Name: "app"}},
+		Watch:     true,
+		ErrDelay:  time.Millisecond,
+	})
+
+	require.Nil(t, l.Load(konfig.Values{}))
+	require.Nil(t, l.Start())
+
+	// the watch starts from the version of the load
+	require.Equal(t, "1", <-c.watches)
+
+	// same data, no event
+	c.events <- WatchEvent{Type: "MODIFIED", Object: object("2", map[string]string{"host": "localhost"}, nil)}
+	c.events <- WatchEvent{Type: "MODIFIED", Object: object("3", map[string]string{"host": "remote"}, nil)}
+
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+	select {
+	case <-l.Watch():
+		t.Fatal("unexpected watch event")
+	default:
+	}
+
+	// a gone error restarts the watch from the current version
+	c.events <- WatchEvent{Type: "ERROR", Err: &StatusError{Code: http.StatusGone, Message: "too old resource version"}}
+	require.Equal(t, "", <-c.watches)
+
+	require.Nil(t, l.Close())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+}
+
+func TestAPIClient(t *testing.T) {
+	var cm = object("5", map[string]string{"host": "localhost"}, nil)
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","message":"Unauthorized"}`)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/default/configmaps/app":
+			json.NewEncoder(w).Encode(cm)
+		case r.URL.Path == "/api/v1/namespaces/default/configmaps" && r.URL.Query().Get("watch") == "true":
+			require.Equal(t, "metadata.name=app", r.URL.Query().Get("fieldSelector"))
+			require.Equal(t, "5", r.URL.Query().Get("resourceVersion"))
+			var o = object("6", map[string]string{"host": "remote"}, nil)
+			var b, _ = json.Marshal(o)
+			fmt.Fprintf(w, "{\"type\":\"MODIFIED\",\"object\":%s}\n", b)
+			fmt.Fprint(w, "{\"type\":\"ERROR\",\"object\":{\"kind\":\"Status\",\"code\":410,\"message\":\"too old\"}}\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","message":"configmaps \"other\" not found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	var c = &APIClient{Host: srv.URL, Token: "token"}
+	var r = Resource{Kind: KindConfigMap, Namespace: "default", Name: "app"}
+
+	var o, err = c.Get(context.Background(), r)
+	require.Nil(t, err)
+	require.Equal(t, cm, o)
+
+	_, err = c.Get(context.Background(), Resource{Kind: KindConfigMap, Namespace: "default", Name: "other"})
+	require.Equal(t, &StatusError{Code: http.StatusNotFound, Message: `configmaps "other" not found`}, err)
+
+	events, err := c.Watch(context.Background(), r, "5")
+	require.Nil(t, err)
+
+	var e = <-events
+	require.Equal(t, "MODIFIED", e.Type)
+	require.Equal(t, map[string]string{"host": "remote"}, e.Object.Data)
+
+	e = <-events
+	require.Equal(t, "ERROR", e.Type)
+	require.Equal(t, &StatusError{Code: http.StatusGone, Message: "too old"}, e.Err)
+
+	_, ok := <-events
+	require.False(t, ok)
+
+	_, err = (&APIClient{Host: srv.URL}).Get(context.Background(), r)
+	require.Equal(t, &StatusError{Code: http.StatusUnauthorized, Message: "Unauthorized"}, err)
+}
@@ -0,0 +1,165 @@
+// Package klreader provides a loader reading its values once from an io.Reader, by default os.Stdin.
+package klreader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+)
+
+var (
+	_ konfig.Loader       = (*Loader)(nil)
+	_ konfig.ParserLoader = (*Loader)(nil)
+	// ErrNoParser is the error thrown when trying to create a reader loader with no parser
+	ErrNoParser = errors.New("no parser provided")
+	// ErrNoInput is the error returned when loading from an empty input or a terminal and the input is required
+	ErrNoInput = errors.New("Err no input to read from")
+)
+
+const defaultName = "reader"
+
+// Config is the config of a reader Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Reader is the reader the values are read from. Default is os.Stdin.
+	Reader io.Reader
+	// Parser is the parser used to parse the values read
+	Parser parser.Parser
+	// ReadTerminal sets wether the loader should read from the reader if it is a terminal.
+	// By default a terminal is treated as an empty input so that the load does not block waiting for input.
+	ReadTerminal bool
+	// Required sets wether an empty input (or a terminal) makes the load fail with ErrNoInput.
+	// By default an empty input loads no values.
+	Required bool
+	// MaxRetry is the maximum number of times load can be retried.
+	// A load is retried only if reading the input failed before any byte was read, other read errors are permanent.
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// MaxBytes is the maximum number of bytes read, the load fails if the input is bigger.
+	// Default is parser.DefaultMaxBytes, if negative the size of the input is not limited.
+	MaxBytes int64
+}
+
+// Loader is a konfig.Loader reading its values from an io.Reader.
+// The reader is read until EOF on the first load only, the next loads parse the same input again.
+// If the read fails before any byte was read, the next load reads the reader again,
+// else the input is lost and the next loads fail with the same error.
+type Loader struct {
+	cfg  *Config
+	mut  sync.Mutex
+	read bool
+	data []byte
+	err  error
+}
+
+// New creates a new Loader from the given config
+func New(cfg *Config) *Loader {
+	if cfg.Parser == nil {
+		panic(ErrNoParser)
+	}
+	if cfg.Reader == nil {
+		cfg.Reader = os.Stdin
+	}
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = parser.DefaultMaxBytes
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	return &Loader{
+		cfg: cfg,
+	}
+}
+
+// NewStdinLoader returns a new Loader with the name n parsing os.Stdin with the parser p
+func NewStdinLoader(n string, p parser.Parser) *Loader {
+	return New(&Config{
+		Name:   n,
+		Parser: p,
+	})
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Parsers implements konfig.ParserLoader, it returns the name of the parser of the data read
+func (l *Loader) Parsers() []string { return []string{parser.Name(l.cfg.Parser)} }
+
+// Load implements konfig.Loader, it parses the input and adds the values to the konfig.Values.
+func (l *Loader) Load(s konfig.Values) error {
+	l.mut.Lock()
+	if !l.read {
+		l.readInput()
+	}
+	var data, err = l.data, l.err
+	l.mut.Unlock()
+
+	if err != nil {
+		return konfig.NewLoadError(l.cfg.Name, konfig.CategoryUnknown, err)
+	}
+
+	if len(data) == 0 {
+		if l.cfg.Required {
+			return konfig.NewLoadError(l.cfg.Name, konfig.CategoryNotFound, ErrNoInput)
+		}
+		return nil
+	}
+
+	if err := l.cfg.Parser.Parse(bytes.NewReader(data), s); err != nil {
+		return konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err)
+	}
+
+	return nil
+}
+
+// readInput reads the input until EOF, unless it is a terminal which should not be read.
+// The input is marked as read unless the read failed before any byte was read, so that the read can be retried.
+// It must be called with the loader mutex locked.
+func (l *Loader) readInput() {
+	if !l.cfg.ReadTerminal && isTerminal(l.cfg.Reader) {
+		l.read = true
+		return
+	}
+	l.data, l.err = ioutil.ReadAll(parser.MaxBytesReader(l.cfg.Reader, l.cfg.MaxBytes))
+	l.read = l.err == nil || len(l.data) > 0
+}
+
+// MaxRetry returns the maximum number of times to retry a load when an error occurs
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay returns the delay between each load retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// isTerminal tells wether r is a file connected to a terminal
+func isTerminal(r io.Reader) bool {
+	var f, ok = r.(*os.File)
+	if !ok {
+		return false
+	}
+	var fi, err = f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,187 @@
+package klreader
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/stretchr/testify/require"
+)
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() { New(&Config{}) })
+
+	var l = NewStdinLoader("stdin", kpjson.Parser)
+	require.Equal(t, "stdin", l.Name())
+	require.Equal(t, os.Stdin, l.cfg.Reader)
+}
+
+func TestLoad(t *testing.T) {
+	var devNull, err = os.Open(os.DevNull)
+	require.Nil(t, err)
+	defer devNull.Close()
+
+	var testCases = []struct {
+		name     string
+		cfg      *Config
+		expected konfig.Values
+		category konfig.ErrorCategory
+		err      bool
+	}{
+		{
+			name: "parses the input",
+			cfg: &Config{
+				Reader: strings.NewReader(`{"foo":"bar"}`),
+			},
+			expected: konfig.Values{"foo": "bar"},
+		},
+		{
+			name: "empty input",
+			cfg: &Config{
+				Reader: strings.NewReader(""),
+			},
+			expected: konfig.Values{},
+		},
+		{
+			name: "empty input required",
+			cfg: &Config{
+				Reader:   strings.NewReader(""),
+				Required: true,
+			},
+			err:      true,
+			category: konfig.CategoryNotFound,
+		},
+		{
+			name: "terminal not read",
+			cfg: &Config{
+				Reader:   devNull,
+				Required: true,
+			},
+			err:      true,
+			category: konfig.CategoryNotFound,
+		},
+		{
+			name: "parse error",
+			cfg: &Config{
+				Reader: strings.NewReader(`{`),
+			},
+			err:      true,
+			category: konfig.CategoryParse,
+		},
+		{
+			name: "read error",
+			cfg: &Config{
+				Reader: errReader{},
+			},
+			err:      true,
+			category: konfig.CategoryUnknown,
+		},
+		{
+			name: "max bytes",
+			cfg: &Config{
+				Reader:   strings.NewReader(`{"foo":"bar"}`),
+				MaxBytes: 2,
+			},
+			err:      true,
+			category: konfig.CategoryUnknown,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.cfg.Parser = kpjson.Parser
+			var l = New(testCase.cfg)
+
+			var v = konfig.Values{}
+			var err = l.Load(v)
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+// flakyReader fails its first read, then reads r
+type flakyReader struct {
+	r      *strings.Reader
+	failed bool
+}
+
+func (f *flakyReader) Read(b []byte) (int, error) {
+	if !f.failed {
+		f.failed = true
+		return 0, errors.New("read error")
+	}
+	return f.r.Read(b)
+}
+
+// partialReader reads some bytes then fails
+type partialReader struct {
+	read bool
+}
+
+func (p *partialReader) Read(b []byte) (int, error) {
+	if !p.read {
+		p.read = true
+		return copy(b, `{"foo"`), nil
+	}
+	return 0, errors.New("read error")
+}
+
+func TestLoadReadError(t *testing.T) {
+	t.Run(
+		"retried when no byte was read",
+		func(t *testing.T) {
+			var l = New(&Config{
+				Reader: &flakyReader{r: strings.NewReader(`{"foo":"bar"}`)},
+				Parser: kpjson.Parser,
+			})
+
+			require.NotNil(t, l.Load(konfig.Values{}))
+
+			var v = konfig.Values{}
+			require.Nil(t, l.Load(v))
+			require.Equal(t, konfig.Values{"foo": "bar"}, v)
+		},
+	)
+
+	t.Run(
+		"permanent when bytes were read",
+		func(t *testing.T) {
+			var l = New(&Config{
+				Reader: &partialReader{},
+				Parser: kpjson.Parser,
+			})
+
+			var err = l.Load(konfig.Values{})
+			require.NotNil(t, err)
+			require.Equal(t, err, l.Load(konfig.Values{}))
+		},
+	)
+}
+
+func TestReload(t *testing.T) {
+	var l = New(&Config{
+		Reader: strings.NewReader(`{"foo":"bar"}`),
+		Parser: kpjson.Parser,
+	})
+
+	for i := 0; i < 2; i++ {
+		var v = konfig.Values{}
+		require.Nil(t, l.Load(v))
+		require.Equal(t, konfig.Values{"foo": "bar"}, v)
+	}
+}
@@ -0,0 +1,331 @@
+// Package klsocket provides a loader receiving config frames pushed over a Unix socket or a named pipe.
+package klsocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	"github.com/lalamove/nui/nlogger"
+)
+
+var (
+	_ konfig.Loader       = (*Loader)(nil)
+	_ konfig.ParserLoader = (*Loader)(nil)
+	_ konfig.Watcher      = (*Loader)(nil)
+	// ErrNoAddress is the error thrown when trying to create a socket loader without an address or a Dial function
+	ErrNoAddress = errors.New("no address provided")
+	// ErrNoParser is the error thrown when trying to create a socket loader with no parser
+	ErrNoParser = errors.New("no parser provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed Loader
+	ErrAlreadyClosed = errors.New("Socket loader already closed")
+	// ErrFrameTooLargeMsg is the error message returned when a frame is bigger than MaxBytes
+	ErrFrameTooLargeMsg = "Err frame of %d bytes exceeds the maximum of %d bytes"
+)
+
+// Framing is the way frames are delimited in the stream
+type Framing int
+
+const (
+	// FramingNewline delimits frames with a new line, empty lines are ignored
+	FramingNewline Framing = iota
+	// FramingLengthPrefix prefixes each frame with its length as a 4 bytes big endian unsigned integer
+	FramingLengthPrefix
+)
+
+const (
+	defaultName           = "socket"
+	defaultNetwork        = "unix"
+	defaultReconnectDelay = time.Second
+)
+
+// Config is the config of a socket Loader
+type Config struct {
+	// Name is the name of the loader
+	Name string
+	// StopOnFailure tells wether a failure to load configs should closed the config and all registered closers
+	StopOnFailure bool
+	// Network is the network of the address, default is "unix"
+	Network string
+	// Address is the address to connect to, the path of the socket for a Unix socket
+	Address string
+	// Dial opens the stream frames are read from, it overrides Network and Address.
+	// To read from a named pipe, open the pipe with os.Open.
+	Dial func() (io.ReadCloser, error)
+	// Framing is the way frames are delimited, default is FramingNewline
+	Framing Framing
+	// Parser is the parser used to parse each frame
+	Parser parser.Parser
+	// MaxBytes is the maximum size of a frame, the connection is closed and reopened if a frame is bigger.
+	// Default is parser.DefaultMaxBytes.
+	MaxBytes int64
+	// ReconnectDelay is the delay before reconnecting when the stream is closed or fails, default is 1 second
+	ReconnectDelay time.Duration
+	// MaxRetry is the maximum number of times load can be retried
+	MaxRetry int
+	// RetryDelay is the delay between each retry
+	RetryDelay time.Duration
+	// Debug sets the debug mode
+	Debug bool
+	// Logger is the logger used to print messages
+	Logger nlogger.Provider
+}
+
+// Loader is a konfig.Loader loading the values of the last frame received on a stream.
+// It is also a konfig.Watcher: once started, it connects to the stream, reconnecting when it is closed,
+// and sends an event for every frame successfully parsed.
+// Frames which fail to be parsed are logged and ignored.
+type Loader struct {
+	cfg       *Config
+	mut       *sync.Mutex
+	values    konfig.Values
+	conn      io.ReadCloser
+	watchChan chan struct{}
+	done      chan struct{}
+}
+
+// New creates a new Loader from the given config
+func New(cfg *Config) *Loader {
+	if cfg.Address == "" && cfg.Dial == nil {
+		panic(ErrNoAddress)
+	}
+	if cfg.Parser == nil {
+		panic(ErrNoParser)
+	}
+	if cfg.Network == "" {
+		cfg.Network = defaultNetwork
+	}
+	if cfg.Dial == nil {
+		cfg.Dial = func() (io.ReadCloser, error) {
+			return net.Dial(cfg.Network, cfg.Address)
+		}
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = parser.DefaultMaxBytes
+	}
+	if cfg.ReconnectDelay == 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+
+	return &Loader{
+		cfg:       cfg,
+		mut:       &sync.Mutex{},
+		watchChan: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// Name returns the name of the loader
+func (l *Loader) Name() string { return l.cfg.Name }
+
+// Parsers implements konfig.ParserLoader, it returns the name of the parser of the frames
+func (l *Loader) Parsers() []string { return []string{parser.Name(l.cfg.Parser)} }
+
+// Load implements konfig.Loader, it loads the values of the last frame received.
+// Before the first frame is received, it loads no values.
+func (l *Loader) Load(s konfig.Values) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	for k, v := range l.values {
+		s.Set(k, v)
+	}
+	return nil
+}
+
+// MaxRetry returns the maximum number of times to retry a load when an error occurs
+func (l *Loader) MaxRetry() int {
+	return l.cfg.MaxRetry
+}
+
+// RetryDelay returns the delay between each load retry
+func (l *Loader) RetryDelay() time.Duration {
+	return l.cfg.RetryDelay
+}
+
+// StopOnFailure returns wether a load failure should stop the config and the registered closers
+func (l *Loader) StopOnFailure() bool {
+	return l.cfg.StopOnFailure
+}
+
+// Start implements konfig.Watcher, it starts reading frames from the stream
+func (l *Loader) Start() error {
+	go l.watch()
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (l *Loader) Watch() <-chan struct{} {
+	return l.watchChan
+}
+
+// Done indicates wether the watcher is done or not
+func (l *Loader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close closes the watcher and the stream
+func (l *Loader) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+		return ErrAlreadyClosed
+	default:
+		close(l.done)
+	}
+
+	if l.conn != nil {
+		return l.conn.Close()
+	}
+	return nil
+}
+
+// Err returns the watcher error, the watcher reconnects on errors so it is always nil
+func (l *Loader) Err() error {
+	return nil
+}
+
+func (l *Loader) watch() {
+	for {
+		var conn, err = l.cfg.Dial()
+		if err != nil {
+			l.cfg.Logger.Get().Error(err.Error())
+		} else if l.setConn(conn) {
+			if err := l.readFrames(conn); err != nil {
+				l.cfg.Logger.Get().Error(err.Error())
+			}
+			conn.Close()
+		} else {
+			conn.Close()
+			return
+		}
+
+		var t = time.NewTimer(l.cfg.ReconnectDelay)
+		select {
+		case <-t.C:
+		case <-l.done:
+			t.Stop()
+			return
+		}
+
+		if l.cfg.Debug {
+			l.cfg.Logger.Get().Debug("Reconnecting to " + l.cfg.Address)
+		}
+	}
+}
+
+// setConn sets the current connection so that Close can interrupt it, it returns false if the loader is closed
+func (l *Loader) setConn(conn io.ReadCloser) bool {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	select {
+	case <-l.done:
+		return false
+	default:
+	}
+	l.conn = conn
+	return true
+}
+
+// readFrames reads the frames from r until it fails or is closed
+func (l *Loader) readFrames(r io.Reader) error {
+	var br = bufio.NewReader(r)
+	for {
+		var frame, err = l.readFrame(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case <-l.done:
+				return nil
+			default:
+			}
+			return err
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		l.frame(frame)
+	}
+}
+
+func (l *Loader) readFrame(br *bufio.Reader) ([]byte, error) {
+	if l.cfg.Framing == FramingLengthPrefix {
+		var n uint32
+		if err := binary.Read(br, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		if int64(n) > l.cfg.MaxBytes {
+			return nil, fmt.Errorf(ErrFrameTooLargeMsg, n, l.cfg.MaxBytes)
+		}
+		var frame = make([]byte, n)
+		if _, err := io.ReadFull(br, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	var frame []byte
+	for {
+		var line, isPrefix, err = br.ReadLine()
+		if err != nil {
+			if err == io.EOF && len(frame) > 0 {
+				return frame, nil
+			}
+			return nil, err
+		}
+		frame = append(frame, line...)
+		if int64(len(frame)) > l.cfg.MaxBytes {
+			return nil, fmt.Errorf(ErrFrameTooLargeMsg, len(frame), l.cfg.MaxBytes)
+		}
+		if !isPrefix {
+			return bytes.TrimSpace(frame), nil
+		}
+	}
+}
+
+// frame parses the frame, sets its values as the values to load and sends a watch event
+func (l *Loader) frame(frame []byte) {
+	var v = konfig.Values{}
+	if err := l.cfg.Parser.Parse(bytes.NewReader(frame), v); err != nil {
+		l.cfg.Logger.Get().Error(konfig.NewLoadError(l.cfg.Name, konfig.CategoryParse, err).Error())
+		return
+	}
+
+	l.mut.Lock()
+	l.values = v
+	l.mut.Unlock()
+
+	if l.cfg.Debug {
+		l.cfg.Logger.Get().Debug("Frame received, sending watch event")
+	}
+
+	select {
+	case l.watchChan <- struct{}{}:
+	default:
+		// an event is already pending, the next load loads the last frame
+	}
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "SOCKETLOADER | "))
+}
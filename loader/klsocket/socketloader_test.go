@@ -0,0 +1,133 @@
+package klsocket
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/stretchr/testify/require"
+)
+
+func waitEvent(t *testing.T, l *Loader) {
+	select {
+	case <-l.Watch():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event received")
+	}
+}
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() { New(&Config{Parser: kpjson.Parser}) })
+	require.Panics(t, func() { New(&Config{Address: "/tmp/konfig.sock"}) })
+
+	var l = New(&Config{Address: "/tmp/konfig.sock", Parser: kpjson.Parser})
+	require.Equal(t, defaultName, l.Name())
+	require.Equal(t, defaultNetwork, l.cfg.Network)
+	require.Equal(t, defaultReconnectDelay, l.cfg.ReconnectDelay)
+}
+
+func TestUnixSocket(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "klsocket")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "konfig.sock")
+	ln, err := net.Listen("unix", path)
+	require.Nil(t, err)
+	defer ln.Close()
+
+	var l = New(&Config{
+		Address:        path,
+		Parser:         kpjson.Parser,
+		ReconnectDelay: 10 * time.Millisecond,
+	})
+
+	// no frame received yet
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{}, v)
+
+	require.Nil(t, l.Start())
+	defer l.Close()
+
+	conn, err := ln.Accept()
+	require.Nil(t, err)
+
+	// invalid frames are ignored
+	_, err = conn.Write([]byte("{\n\n{\"foo\":\"bar\"}\n"))
+	require.Nil(t, err)
+	waitEvent(t, l)
+
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar"}, v)
+
+	// the loader reconnects when the socket is closed
+	conn.Close()
+	conn, err = ln.Accept()
+	require.Nil(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("{\"foo\":\"baz\"}\n"))
+	require.Nil(t, err)
+	waitEvent(t, l)
+
+	v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "baz"}, v)
+
+	require.Nil(t, l.Close())
+	require.Equal(t, ErrAlreadyClosed, l.Close())
+	select {
+	case <-l.Done():
+	default:
+		t.Fatal("loader should be done")
+	}
+}
+
+func TestLengthPrefix(t *testing.T) {
+	var r, w = io.Pipe()
+	var l = New(&Config{
+		Dial: func() (io.ReadCloser, error) {
+			return r, nil
+		},
+		Framing:  FramingLengthPrefix,
+		Parser:   kpjson.Parser,
+		MaxBytes: 32,
+	})
+	require.Nil(t, l.Start())
+	defer l.Close()
+
+	var frame = []byte("{\"foo\":\n\"bar\"}")
+	require.Nil(t, binary.Write(w, binary.BigEndian, uint32(len(frame))))
+	_, err := w.Write(frame)
+	require.Nil(t, err)
+	waitEvent(t, l)
+
+	var v = konfig.Values{}
+	require.Nil(t, l.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar"}, v)
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	var l = New(&Config{
+		Address:  "/tmp/konfig.sock",
+		Framing:  FramingLengthPrefix,
+		Parser:   kpjson.Parser,
+		MaxBytes: 2,
+	})
+
+	var b = []byte{0, 0, 0, 3, 'a', 'b', 'c'}
+	require.NotNil(t, l.readFrames(bytes.NewReader(b)))
+
+	l.cfg.Framing = FramingNewline
+	require.NotNil(t, l.readFrames(bytes.NewReader([]byte("abc\n"))))
+}
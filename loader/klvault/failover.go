@@ -0,0 +1,82 @@
+package klvault
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+func (vl *Loader) setClients() {
+	vl.clients = append([]*vault.Client{vl.cfg.Client}, vl.cfg.Clients...)
+	vl.logicalClients = make([]LogicalClient, len(vl.clients))
+	vl.logicalClients[0] = vl.logicalClient
+	for i, c := range vl.cfg.Clients {
+		vl.logicalClients[i+1] = c.Logical()
+	}
+}
+
+// ActiveClient returns the vault client currently used to read secrets
+func (vl *Loader) ActiveClient() *vault.Client {
+	vl.mut.Lock()
+	defer vl.mut.Unlock()
+	return vl.clients[vl.active]
+}
+
+// read reads the secret at key with the active client.
+// On connection errors it fails over to the next clients until one succeeds or all clients were tried.
+func (vl *Loader) read(key string, token string) (*vault.Secret, error) {
+	var s, err = vl.activeLogicalClient().Read(key)
+	for i := 1; i < len(vl.clients) && err != nil && isConnectionError(err); i++ {
+		s, err = vl.failover(token).Read(key)
+	}
+	return s, err
+}
+
+// list lists the secret keys at key with the active client, it fails over on connection errors like read
+func (vl *Loader) list(key string, token string) (*vault.Secret, error) {
+	var s, err = vl.activeLogicalClient().List(key)
+	for i := 1; i < len(vl.clients) && err != nil && isConnectionError(err); i++ {
+		s, err = vl.failover(token).List(key)
+	}
+	return s, err
+}
+
+// activeLogicalClient returns the logical client of the active client,
+// loads and renewals reading secrets concurrently it is read under the loader mutex
+func (vl *Loader) activeLogicalClient() LogicalClient {
+	vl.mut.Lock()
+	defer vl.mut.Unlock()
+	return vl.logicalClient
+}
+
+// failover makes the next client the active one and returns its logical client
+func (vl *Loader) failover(token string) LogicalClient {
+	vl.mut.Lock()
+	vl.active = (vl.active + 1) % len(vl.clients)
+	var i, c, lc = vl.active, vl.clients[vl.active], vl.logicalClients[vl.active]
+	vl.logicalClient = lc
+	vl.mut.Unlock()
+
+	c.SetToken(token)
+
+	vl.cfg.Logger.Get().Warn(
+		fmt.Sprintf("Failing over to vault client %d (%s)", i, c.Address()),
+	)
+	return lc
+}
+
+// isConnectionError tells wether err is an error reaching the vault server.
+// Errors of cancelled loads, errors returned by the server and errors decoding its responses are not connection errors,
+// timeouts are.
+func isConnectionError(err error) bool {
+	switch e := err.(type) {
+	case *url.Error:
+		return e.Err != context.Canceled
+	case net.Error:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package klvault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var connRefused = &url.Error{
+	Op:  "Get",
+	URL: "http://primary:8200/v1/secret/db",
+	Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+}
+
+func TestIsConnectionError(t *testing.T) {
+	require.True(t, isConnectionError(connRefused))
+	require.True(t, isConnectionError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}))
+
+	require.False(t, isConnectionError(errors.New("Error making API request. Code: 500")))
+	require.False(t, isConnectionError(&json.SyntaxError{}))
+	require.False(t, isConnectionError(context.Canceled))
+	require.False(t, isConnectionError(&url.Error{Op: "Get", URL: "http://primary:8200", Err: context.Canceled}))
+}
+
+func TestFailover(t *testing.T) {
+	var newClient = func(address string) *vault.Client {
+		var cfg = vault.DefaultConfig()
+		cfg.Address = address
+		var c, _ = vault.NewClient(cfg)
+		return c
+	}
+
+	var testCases = []struct {
+		name     string
+		setUp    func(primary, standby *mocks.MockLogicalClient)
+		active   string
+		err      bool
+		expected konfig.Values
+	}{
+		{
+			name: "primary succeeds",
+			setUp: func(primary, standby *mocks.MockLogicalClient) {
+				primary.EXPECT().Read("/secret/db").Return(
+					&vault.Secret{Data: map[string]interface{}{"foo": "primary"}},
+					nil,
+				)
+			},
+			active:   "http://primary:8200",
+			expected: konfig.Values{"foo": "primary"},
+		},
+		{
+			name: "connection error fails over to standby",
+			setUp: func(primary, standby *mocks.MockLogicalClient) {
+				primary.EXPECT().Read("/secret/db").Return(nil, connRefused)
+				standby.EXPECT().Read("/secret/db").Return(
+					&vault.Secret{Data: map[string]interface{}{"foo": "standby"}},
+					nil,
+				)
+			},
+			active:   "http://standby:8200",
+			expected: konfig.Values{"foo": "standby"},
+		},
+		{
+			name: "server error does not fail over",
+			setUp: func(primary, standby *mocks.MockLogicalClient) {
+				primary.EXPECT().Read("/secret/db").Return(nil, errors.New("Error making API request. Code: 500"))
+			},
+			active: "http://primary:8200",
+			err:    true,
+		},
+		{
+			name: "all clients fail",
+			setUp: func(primary, standby *mocks.MockLogicalClient) {
+				primary.EXPECT().Read("/secret/db").Return(nil, connRefused)
+				standby.EXPECT().Read("/secret/db").Return(nil, connRefused)
+			},
+			active: "http://standby:8200",
+			err:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var vl = New(&Config{
+				Client:       newClient("http://primary:8200"),
+				Clients:      []*vault.Client{newClient("http://standby:8200")},
+				Secrets:      []Secret{{Key: "/secret/db"}},
+				AuthProvider: aP,
+			})
+
+			var primary = mocks.NewMockLogicalClient(ctrl)
+			var standby = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = primary
+			vl.logicalClients = []LogicalClient{primary, standby}
+			testCase.setUp(primary, standby)
+
+			var v = konfig.Values{}
+			var err = vl.Load(v)
+			require.Equal(t, testCase.active, vl.ActiveClient().Address())
+			require.Equal(t, "DUMMYTOKEN", vl.ActiveClient().Token())
+			if testCase.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestFailoverConcurrentReads(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var standbyClient, _ = vault.NewClient(vault.DefaultConfig())
+	var vl = New(&Config{
+		Client:       c,
+		Clients:      []*vault.Client{standbyClient},
+		Secrets:      []Secret{{Key: "/secret/db"}},
+		AuthProvider: mocks.NewMockAuthProvider(ctrl),
+	})
+
+	var primary = mocks.NewMockLogicalClient(ctrl)
+	var standby = mocks.NewMockLogicalClient(ctrl)
+	vl.logicalClient = primary
+	vl.logicalClients = []LogicalClient{primary, standby}
+	primary.EXPECT().Read("/secret/db").Return(nil, connRefused).AnyTimes()
+	standby.EXPECT().Read("/secret/db").Return(nil, connRefused).AnyTimes()
+
+	// reads of a load and of a renewal fail over concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				vl.read("/secret/db", "DUMMYTOKEN")
+			}
+		}()
+	}
+	wg.Wait()
+}
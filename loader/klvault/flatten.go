@@ -0,0 +1,36 @@
+package klvault
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lalamove/konfig"
+)
+
+// flatten returns the data where nested maps and arrays are flattened into keys joined with konfig.KeySep
+func flatten(data map[string]interface{}) map[string]interface{} {
+	var r = make(map[string]interface{}, len(data))
+	for k, v := range data {
+		flattenValue(r, k, v)
+	}
+	return r
+}
+
+func flattenValue(r map[string]interface{}, k string, v interface{}) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for kk, vv := range vt {
+			flattenValue(r, k+konfig.KeySep+kk, vv)
+		}
+	case map[interface{}]interface{}:
+		for kk, vv := range vt {
+			flattenValue(r, k+konfig.KeySep+fmt.Sprintf("%v", kk), vv)
+		}
+	case []interface{}:
+		for i, vv := range vt {
+			flattenValue(r, k+konfig.KeySep+strconv.Itoa(i), vv)
+		}
+	default:
+		r[k] = v
+	}
+}
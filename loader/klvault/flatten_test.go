@@ -0,0 +1,80 @@
+package klvault
+
+import (
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	var data = map[string]interface{}{
+		"user": "konfig",
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"replicas": []interface{}{
+				"r1",
+				map[string]interface{}{"host": "r2"},
+			},
+		},
+	}
+
+	var testCases = []struct {
+		name     string
+		flatten  bool
+		expected konfig.Values
+	}{
+		{
+			name:    "flatten",
+			flatten: true,
+			expected: konfig.Values{
+				"db_user":               "konfig",
+				"db_db.host":            "localhost",
+				"db_db.replicas.0":      "r1",
+				"db_db.replicas.1.host": "r2",
+			},
+		},
+		{
+			name: "no flatten",
+			expected: konfig.Values{
+				"db_user": "konfig",
+				"db_db":   data["db"],
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client: c,
+				Secrets: []Secret{
+					{
+						Key:        "/dummy/secret/path",
+						KeysPrefix: "db_",
+						Flatten:    testCase.flatten,
+					},
+				},
+				AuthProvider: aP,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{Data: data}, nil)
+
+			var v = konfig.Values{}
+			require.Nil(t, vl.Load(v))
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
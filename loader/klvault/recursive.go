@@ -0,0 +1,114 @@
+package klvault
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lalamove/konfig"
+)
+
+const (
+	kvDataSegment     = "data"
+	kvMetadataSegment = "metadata"
+)
+
+var (
+	// ErrKVv2PathMsg is the error message thrown when creating a Loader with a KV version 2 secret whose key is not a data path
+	ErrKVv2PathMsg = "Err secret '%s' is not a KV version 2 data path"
+)
+
+// checkKVv2 returns an error if the secret is in a KV version 2 engine and its key has no data segment
+func (s Secret) checkKVv2() error {
+	if !s.KVv2 {
+		return nil
+	}
+	if _, ok := kvMetadataPath(s.Key); !ok {
+		return fmt.Errorf(ErrKVv2PathMsg, s.Key)
+	}
+	return nil
+}
+
+// kvMetadataPath returns the metadata path of a KV version 2 data path, the first data segment of the path being replaced with metadata
+// (e.g. secret/data/app is listed at secret/metadata/app)
+func kvMetadataPath(p string) (string, bool) {
+	var segments = strings.Split(p, "/")
+	for i, s := range segments {
+		if s == kvDataSegment {
+			segments[i] = kvMetadataSegment
+			return strings.Join(segments, "/"), true
+		}
+	}
+	return "", false
+}
+
+// secretPaths returns the paths of the secrets to read for the secret.
+// If the secret is recursive, it returns the sorted paths of all the secrets under its key, else its key.
+func (vl *Loader) secretPaths(secret Secret, token string) ([]string, error) {
+	if !secret.Recursive {
+		return []string{secret.Key}, nil
+	}
+
+	var base = strings.TrimSuffix(secret.Key, "/")
+	var listBase = base
+	if secret.KVv2 {
+		listBase, _ = kvMetadataPath(base)
+	}
+
+	var paths, err = vl.walk(listBase, base, token, nil)
+	if err != nil {
+		return nil, konfig.NewLoadError(vl.cfg.Name, errorCategory(err), vl.redactError(err, secret.Key))
+	}
+	if len(paths) == 0 && !vl.cfg.AllowEmpty {
+		return nil, konfig.NewLoadError(
+			vl.cfg.Name,
+			konfig.CategoryNotFound,
+			fmt.Errorf(ErrSecretNotFoundMsg, vl.redactPath(secret.Key)),
+		)
+	}
+	return paths, nil
+}
+
+// walk lists the keys at listPath and appends the paths of the secrets to paths, prefixed with readPath.
+// Keys ending with a slash are sub paths, they are listed recursively.
+func (vl *Loader) walk(listPath, readPath, token string, paths []string) ([]string, error) {
+	var s, err = vl.list(listPath, token)
+	if err != nil {
+		return nil, err
+	}
+	// nothing is listed under the path
+	if s == nil {
+		return paths, nil
+	}
+
+	var keys, _ = s.Data["keys"].([]interface{})
+	var names = make([]string, 0, len(keys))
+	for _, k := range keys {
+		if name, ok := k.(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			name = strings.TrimSuffix(name, "/")
+			if paths, err = vl.walk(listPath+"/"+name, readPath+"/"+name, token, paths); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		paths = append(paths, readPath+"/"+name)
+	}
+	return paths, nil
+}
+
+// pathKeysPrefix returns the prefix of the keys of the secret at path p under a recursive secret,
+// the segments of p under the key of the secret joined with konfig.KeySep (e.g. db/creds is prefixed with db.creds.)
+func pathKeysPrefix(secret Secret, p string) string {
+	if !secret.Recursive {
+		return ""
+	}
+	var rel = strings.TrimPrefix(p, strings.TrimSuffix(secret.Key, "/")+"/")
+	return strings.Replace(rel, "/", konfig.KeySep, -1) + konfig.KeySep
+}
@@ -0,0 +1,167 @@
+package klvault
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func listed(keys ...interface{}) *vault.Secret {
+	return &vault.Secret{Data: map[string]interface{}{"keys": keys}}
+}
+
+func TestRecursiveSecret(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		secret     Secret
+		allowEmpty bool
+		setUp      func(lC *mocks.MockLogicalClient)
+		expected   konfig.Values
+		metadata   []string
+		errString  string
+		category   konfig.ErrorCategory
+	}{
+		{
+			name:   "KV version 1 tree",
+			secret: Secret{Key: "secret/app/", Recursive: true, KeysPrefix: "vault."},
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().List("secret/app").Return(listed("db/", "api"), nil)
+				lC.EXPECT().List("secret/app/db").Return(listed("creds", "replica/"), nil)
+				lC.EXPECT().List("secret/app/db/replica").Return(listed("creds"), nil)
+				lC.EXPECT().Read("secret/app/api").Return(&vault.Secret{Data: map[string]interface{}{"key": "a"}}, nil)
+				lC.EXPECT().Read("secret/app/db/creds").Return(&vault.Secret{Data: map[string]interface{}{"user": "u", "pass": "p"}}, nil)
+				lC.EXPECT().Read("secret/app/db/replica/creds").Return(&vault.Secret{Data: map[string]interface{}{"user": "r"}}, nil)
+			},
+			expected: konfig.Values{
+				"vault.api.key":               "a",
+				"vault.db.creds.user":         "u",
+				"vault.db.creds.pass":         "p",
+				"vault.db.replica.creds.user": "r",
+			},
+			metadata: []string{"secret/app/api", "secret/app/db/creds", "secret/app/db/replica/creds"},
+		},
+		{
+			name:   "KV version 2 tree",
+			secret: Secret{Key: "secret/data/app", Recursive: true, KVv2: true},
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().List("secret/metadata/app").Return(listed("db", "old", "mq/"), nil)
+				lC.EXPECT().List("secret/metadata/app/mq").Return(listed("creds"), nil)
+				lC.EXPECT().Read("secret/data/app/db").Return(&vault.Secret{Data: map[string]interface{}{
+					"data":     map[string]interface{}{"user": "u"},
+					"metadata": map[string]interface{}{"version": 2},
+				}}, nil)
+				// deleted secrets are still listed
+				lC.EXPECT().Read("secret/data/app/old").Return(&vault.Secret{Data: map[string]interface{}{
+					"data":     nil,
+					"metadata": map[string]interface{}{"deletion_time": "2019-01-01T00:00:00Z"},
+				}}, nil)
+				lC.EXPECT().Read("secret/data/app/mq/creds").Return(&vault.Secret{Data: map[string]interface{}{
+					"data": map[string]interface{}{"pass": "p"},
+				}}, nil)
+			},
+			expected: konfig.Values{"db.user": "u", "mq.creds.pass": "p"},
+			metadata: []string{"secret/data/app/db", "secret/data/app/mq/creds"},
+		},
+		{
+			name:   "KV version 2 secret",
+			secret: Secret{Key: "secret/data/app", KVv2: true},
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().Read("secret/data/app").Return(&vault.Secret{Data: map[string]interface{}{
+					"data": map[string]interface{}{"user": "u"},
+				}}, nil)
+			},
+			expected: konfig.Values{"user": "u"},
+			metadata: []string{"secret/data/app"},
+		},
+		{
+			name:   "nothing listed",
+			secret: Secret{Key: "secret/app", Recursive: true},
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().List("secret/app").Return(nil, nil)
+			},
+			expected:  konfig.Values{},
+			errString: "Err secret 'secret/app' not found",
+			category:  konfig.CategoryNotFound,
+		},
+		{
+			name:       "nothing listed allowed",
+			secret:     Secret{Key: "secret/app", Recursive: true},
+			allowEmpty: true,
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().List("secret/app").Return(listed(), nil)
+			},
+			expected: konfig.Values{},
+			metadata: []string{},
+		},
+		{
+			name:   "list error",
+			secret: Secret{Key: "secret/app", Recursive: true},
+			setUp: func(lC *mocks.MockLogicalClient) {
+				lC.EXPECT().List("secret/app").Return(listed("db/"), nil)
+				lC.EXPECT().List("secret/app/db").Return(nil, errors.New("Code: 403. Errors: permission denied"))
+			},
+			expected:  konfig.Values{},
+			errString: "permission denied",
+			category:  konfig.CategoryAuth,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client:       c,
+				Secrets:      []Secret{testCase.secret},
+				AuthProvider: aP,
+				AllowEmpty:   testCase.allowEmpty,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			testCase.setUp(lC)
+
+			var v = konfig.Values{}
+			var err = vl.Load(v)
+			require.Equal(t, testCase.expected, v)
+			if testCase.errString != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.errString)
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+				return
+			}
+			require.Nil(t, err)
+
+			var metadata = make([]string, 0, len(testCase.metadata))
+			for k := range vl.LastMetadata() {
+				metadata = append(metadata, k)
+			}
+			require.ElementsMatch(t, testCase.metadata, metadata)
+		})
+	}
+}
+
+func TestKVv2Path(t *testing.T) {
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var _, err = NewE(&Config{
+		Client:       c,
+		Secrets:      []Secret{{Key: "secret/app", KVv2: true, Recursive: true}},
+		AuthProvider: mocks.NewMockAuthProvider(gomock.NewController(t)),
+	})
+	require.Equal(t, "Err secret 'secret/app' is not a KV version 2 data path", err.Error())
+
+	var p, ok = kvMetadataPath("kv/team/data/app/db")
+	require.True(t, ok)
+	require.Equal(t, "kv/team/metadata/app/db", p)
+}
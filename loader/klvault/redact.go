@@ -0,0 +1,41 @@
+package klvault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// redactedPathLen is the number of hexadecimal characters of the hash kept in redacted paths
+const redactedPathLen = 12
+
+// redactPath returns the path, or its hash if paths are redacted
+func (vl *Loader) redactPath(path string) string {
+	if !vl.cfg.RedactPaths {
+		return path
+	}
+	return hashPath(path)
+}
+
+// redactError returns an error with the message of err where the secret path is replaced with its hash if paths are redacted.
+// Errors of the vault client never contain secret values as they are returned before any data is read,
+// but they contain the URL of the request which includes the secret path.
+func (vl *Loader) redactError(err error, path string) error {
+	if !vl.cfg.RedactPaths {
+		return err
+	}
+
+	var msg = err.Error()
+	var trimmed = strings.Trim(path, "/")
+	if trimmed == "" {
+		return err
+	}
+
+	return errors.New(strings.Replace(msg, trimmed, hashPath(path), -1))
+}
+
+func hashPath(path string) string {
+	var h = sha256.Sum256([]byte(strings.Trim(path, "/")))
+	return "sha256:" + hex.EncodeToString(h[:])[:redactedPathLen]
+}
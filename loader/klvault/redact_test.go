@@ -0,0 +1,72 @@
+package klvault
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactPaths(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		redact   bool
+		secret   *vault.Secret
+		err      error
+		contains string
+		excludes string
+	}{
+		{
+			name:     "read error redacted",
+			redact:   true,
+			err:      errors.New("Error making API request.\n\nURL: GET https://vault:8200/v1/secret/tenant-42/db\nCode: 500."),
+			contains: "URL: GET https://vault:8200/v1/" + hashPath("/secret/tenant-42/db"),
+			excludes: "tenant-42",
+		},
+		{
+			name:     "not found redacted",
+			redact:   true,
+			contains: hashPath("/secret/tenant-42/db"),
+			excludes: "tenant-42",
+		},
+		{
+			name:     "read error not redacted",
+			err:      errors.New("URL: GET https://vault:8200/v1/secret/tenant-42/db"),
+			contains: "secret/tenant-42/db",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client:       c,
+				Secrets:      []Secret{{Key: "/secret/tenant-42/db"}},
+				AuthProvider: aP,
+				RedactPaths:  testCase.redact,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			lC.EXPECT().Read("/secret/tenant-42/db").Return(testCase.secret, testCase.err)
+
+			var err = vl.Load(konfig.Values{})
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), testCase.contains)
+			if testCase.excludes != "" {
+				require.NotContains(t, err.Error(), testCase.excludes)
+			}
+		})
+	}
+}
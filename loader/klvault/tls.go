@@ -0,0 +1,79 @@
+package klvault
+
+import (
+	"errors"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+var (
+	// ErrClientAndTLSConfig is the error thrown when trying to create a Loader with both a vault.Client and a TLSConfig
+	ErrClientAndTLSConfig = errors.New("A vault client and a TLS config cannot be both provided")
+	// ErrInsecureWithCA is the error thrown when a TLSConfig disables verification and sets a CA
+	ErrInsecureWithCA = errors.New("TLS config cannot be insecure and set a CA")
+	// ErrCACertAndCAPath is the error thrown when a TLSConfig sets both a CA cert and a CA path
+	ErrCACertAndCAPath = errors.New("TLS config cannot set both a CA cert and a CA path")
+	// ErrClientCertKey is the error thrown when a TLSConfig sets only one of the client cert and the client key
+	ErrClientCertKey = errors.New("TLS config must set both the client cert and the client key")
+)
+
+// TLSConfig is the TLS config used to build the vault client
+type TLSConfig struct {
+	// CACert is the path to a PEM-encoded CA cert file used to verify the vault server certificate
+	CACert string
+	// CAPath is the path to a directory of PEM-encoded CA cert files used to verify the vault server certificate
+	CAPath string
+	// ClientCert is the path to the PEM-encoded client certificate for mTLS
+	ClientCert string
+	// ClientKey is the path to the PEM-encoded client private key for mTLS
+	ClientKey string
+	// ServerName is the SNI host used when connecting via TLS
+	ServerName string
+	// Insecure disables the verification of the vault server certificate
+	Insecure bool
+}
+
+// Validate checks that the options of the TLS config are not mutually exclusive
+func (t *TLSConfig) Validate() error {
+	if t.Insecure && (t.CACert != "" || t.CAPath != "") {
+		return ErrInsecureWithCA
+	}
+	if t.CACert != "" && t.CAPath != "" {
+		return ErrCACertAndCAPath
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return ErrClientCertKey
+	}
+	return nil
+}
+
+// NewClient creates a vault client for the given address using the given TLS config.
+// If address is empty, the default vault address is used (VAULT_ADDR or https://127.0.0.1:8200).
+// The returned client can be shared with auth providers requiring a client.
+func NewClient(address string, tlsCfg *TLSConfig) (*vault.Client, error) {
+	var cfg = vault.DefaultConfig()
+	if cfg.Error != nil {
+		return nil, cfg.Error
+	}
+	if address != "" {
+		cfg.Address = address
+	}
+
+	if tlsCfg != nil {
+		if err := tlsCfg.Validate(); err != nil {
+			return nil, err
+		}
+		if err := cfg.ConfigureTLS(&vault.TLSConfig{
+			CACert:        tlsCfg.CACert,
+			CAPath:        tlsCfg.CAPath,
+			ClientCert:    tlsCfg.ClientCert,
+			ClientKey:     tlsCfg.ClientKey,
+			TLSServerName: tlsCfg.ServerName,
+			Insecure:      tlsCfg.Insecure,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return vault.NewClient(cfg)
+}
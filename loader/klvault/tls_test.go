@@ -0,0 +1,149 @@
+package klvault
+
+import (
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfigValidate(t *testing.T) {
+	var testCases = []struct {
+		name string
+		cfg  *TLSConfig
+		err  error
+	}{
+		{
+			name: "valid",
+			cfg: &TLSConfig{
+				CACert:     "ca.pem",
+				ClientCert: "cert.pem",
+				ClientKey:  "key.pem",
+				ServerName: "vault.internal",
+			},
+		},
+		{
+			name: "insecure with CA",
+			cfg: &TLSConfig{
+				CAPath:   "/etc/certs",
+				Insecure: true,
+			},
+			err: ErrInsecureWithCA,
+		},
+		{
+			name: "CA cert and CA path",
+			cfg: &TLSConfig{
+				CACert: "ca.pem",
+				CAPath: "/etc/certs",
+			},
+			err: ErrCACertAndCAPath,
+		},
+		{
+			name: "client cert without key",
+			cfg: &TLSConfig{
+				ClientCert: "cert.pem",
+			},
+			err: ErrClientCertKey,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.err, testCase.cfg.Validate())
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run(
+		"builds client from TLS config",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+			var aP = mocks.NewMockAuthProvider(ctrl)
+
+			var vl = New(&Config{
+				Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+				AuthProvider: aP,
+				Address:      "https://vault.internal:8200",
+				TLSConfig: &TLSConfig{
+					Insecure: true,
+				},
+			})
+
+			require.NotNil(t, vl.cfg.Client)
+			require.Equal(t, "https://vault.internal:8200", vl.cfg.Client.Address())
+		},
+	)
+
+	t.Run(
+		"client and TLS config panics",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+
+			require.PanicsWithValue(
+				t,
+				ErrClientAndTLSConfig,
+				func() {
+					New(&Config{
+						Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+						AuthProvider: aP,
+						Client:       c,
+						TLSConfig:    &TLSConfig{},
+					})
+				},
+			)
+		},
+	)
+
+	t.Run(
+		"invalid TLS config panics",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+			var aP = mocks.NewMockAuthProvider(ctrl)
+
+			require.PanicsWithValue(
+				t,
+				ErrInsecureWithCA,
+				func() {
+					New(&Config{
+						Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+						AuthProvider: aP,
+						TLSConfig: &TLSConfig{
+							CACert:   "ca.pem",
+							Insecure: true,
+						},
+					})
+				},
+			)
+		},
+	)
+
+	t.Run(
+		"missing CA cert file panics",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+			var aP = mocks.NewMockAuthProvider(ctrl)
+
+			require.Panics(
+				t,
+				func() {
+					New(&Config{
+						Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+						AuthProvider: aP,
+						TLSConfig: &TLSConfig{
+							CACert: "/i/do/not/exist.pem",
+						},
+					})
+				},
+			)
+		},
+	)
+}
@@ -0,0 +1,152 @@
+package klvault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+)
+
+var (
+	_ konfig.Loader        = (*TransitLoader)(nil)
+	_ konfig.ContextLoader = (*TransitLoader)(nil)
+)
+
+var (
+	// ErrNoLoader is the error thrown when trying to create a TransitLoader without a loader
+	ErrNoLoader = errors.New("No loader provided")
+	// ErrNoTransitKey is the error thrown when trying to create a TransitLoader without a transit key
+	ErrNoTransitKey = errors.New("No transit key given")
+	// ErrNoPlaintext is the error returned when a transit decrypt response has no plaintext
+	ErrNoPlaintext = errors.New("No plaintext in transit decrypt response")
+	// ErrDecryptMsg is the error message returned when a value cannot be decrypted
+	ErrDecryptMsg = "Err decrypting key '%s': %v"
+)
+
+const (
+	defaultTransitMount  = "transit"
+	defaultTransitPrefix = "vault:v"
+)
+
+// TransitConfig is the config of a TransitLoader
+type TransitConfig struct {
+	// Client is the vault client used to decrypt values
+	Client *vault.Client
+	// AuthProvider is an optional vault auth provider, if set a token is fetched before decrypting values.
+	// If not set, the token of the client is used.
+	AuthProvider AuthProvider
+	// Mount is the mount path of the transit engine, default is "transit"
+	Mount string
+	// Key is the name of the transit key
+	Key string
+	// Prefix is the prefix of the values to decrypt, default is "vault:v" which matches transit ciphertexts
+	Prefix string
+}
+
+// TransitLoader wraps a konfig.Loader, values loaded by the wrapped loader starting with the configured prefix
+// are decrypted with vault's transit engine and replaced with their plaintext.
+type TransitLoader struct {
+	konfig.Loader
+	cfg           *TransitConfig
+	logicalClient LogicalClient
+}
+
+// NewTransitLoader creates a new TransitLoader wrapping the loader l with the given config cfg
+func NewTransitLoader(l konfig.Loader, cfg *TransitConfig) *TransitLoader {
+	if l == nil {
+		panic(ErrNoLoader)
+	}
+	if cfg.Client == nil {
+		panic(ErrNoClient)
+	}
+	if cfg.Key == "" {
+		panic(ErrNoTransitKey)
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = defaultTransitMount
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultTransitPrefix
+	}
+
+	return &TransitLoader{
+		Loader:        l,
+		cfg:           cfg,
+		logicalClient: cfg.Client.Logical(),
+	}
+}
+
+// Load implements konfig.Loader interface.
+// It loads the values of the wrapped loader and decrypts the values matching the prefix before setting them in cs.
+func (tl *TransitLoader) Load(cs konfig.Values) error {
+	return tl.LoadContext(context.Background(), cs)
+}
+
+// LoadContext implements konfig.ContextLoader, it loads the values like Load,
+// the context of the load cycle is forwarded to the wrapped loader if it is a konfig.ContextLoader.
+func (tl *TransitLoader) LoadContext(ctx context.Context, cs konfig.Values) error {
+	var v = konfig.Values{}
+	if cl, ok := tl.Loader.(konfig.ContextLoader); ok {
+		if err := cl.LoadContext(ctx, v); err != nil {
+			return err
+		}
+	} else if err := tl.Loader.Load(v); err != nil {
+		return err
+	}
+
+	var authenticated bool
+	for k, vv := range v {
+		var str, ok = vv.(string)
+		if !ok || !strings.HasPrefix(str, tl.cfg.Prefix) {
+			cs.Set(k, vv)
+			continue
+		}
+
+		if !authenticated && tl.cfg.AuthProvider != nil {
+			var token, _, err = tl.cfg.AuthProvider.Token()
+			if err != nil {
+				return konfig.NewLoadError(tl.Name(), konfig.CategoryAuth, err)
+			}
+			tl.cfg.Client.SetToken(token)
+			authenticated = true
+		}
+
+		var plaintext, category, err = tl.decrypt(str)
+		if err != nil {
+			return konfig.NewLoadError(tl.Name(), category, fmt.Errorf(ErrDecryptMsg, k, err))
+		}
+		cs.Set(k, plaintext)
+	}
+
+	return nil
+}
+
+func (tl *TransitLoader) decrypt(ciphertext string) (string, konfig.ErrorCategory, error) {
+	var s, err = tl.logicalClient.Write(
+		tl.cfg.Mount+"/decrypt/"+tl.cfg.Key,
+		map[string]interface{}{
+			"ciphertext": ciphertext,
+		},
+	)
+	if err != nil {
+		return "", errorCategory(err), err
+	}
+	if s == nil || s.Data == nil {
+		return "", konfig.CategoryParse, ErrNoPlaintext
+	}
+
+	var b64, ok = s.Data["plaintext"].(string)
+	if !ok {
+		return "", konfig.CategoryParse, ErrNoPlaintext
+	}
+
+	var b []byte
+	if b, err = base64.StdEncoding.DecodeString(b64); err != nil {
+		return "", konfig.CategoryParse, err
+	}
+	return string(b), konfig.CategoryUnknown, nil
+}
@@ -0,0 +1,239 @@
+package klvault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitLoader(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		setUp    func(ctrl *gomock.Controller) *TransitLoader
+		asserts  func(t *testing.T, cfg konfig.Values)
+		err      bool
+		category konfig.ErrorCategory
+	}{
+		{
+			name: "DecryptPrefixedValues",
+			setUp: func(ctrl *gomock.Controller) *TransitLoader {
+				var l = mocks.NewMockLoader(ctrl)
+				l.EXPECT().Load(konfig.Values{}).DoAndReturn(func(v konfig.Values) error {
+					v.Set("db.pass", "vault:v1:abcd")
+					v.Set("db.host", "localhost")
+					v.Set("db.port", 5432)
+					return nil
+				})
+
+				var aP = mocks.NewMockAuthProvider(ctrl)
+				aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+				var tl = NewTransitLoader(l, &TransitConfig{
+					Client:       c,
+					AuthProvider: aP,
+					Key:          "app",
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				tl.logicalClient = lC
+				lC.EXPECT().Write(
+					"transit/decrypt/app",
+					map[string]interface{}{"ciphertext": "vault:v1:abcd"},
+				).Return(
+					&vault.Secret{
+						Data: map[string]interface{}{
+							"plaintext": base64.StdEncoding.EncodeToString([]byte("secret")),
+						},
+					},
+					nil,
+				)
+
+				return tl
+			},
+			asserts: func(t *testing.T, cfg konfig.Values) {
+				require.Equal(t, "secret", cfg["db.pass"])
+				require.Equal(t, "localhost", cfg["db.host"])
+				require.Equal(t, 5432, cfg["db.port"])
+			},
+		},
+		{
+			name: "CustomMountAndPrefix",
+			setUp: func(ctrl *gomock.Controller) *TransitLoader {
+				var l = mocks.NewMockLoader(ctrl)
+				l.EXPECT().Load(konfig.Values{}).DoAndReturn(func(v konfig.Values) error {
+					v.Set("token", "enc:vault:v1:abcd")
+					return nil
+				})
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+				var tl = NewTransitLoader(l, &TransitConfig{
+					Client: c,
+					Mount:  "secrets/transit",
+					Key:    "app",
+					Prefix: "enc:",
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				tl.logicalClient = lC
+				lC.EXPECT().Write(
+					"secrets/transit/decrypt/app",
+					map[string]interface{}{"ciphertext": "enc:vault:v1:abcd"},
+				).Return(
+					&vault.Secret{
+						Data: map[string]interface{}{
+							"plaintext": base64.StdEncoding.EncodeToString([]byte("secret")),
+						},
+					},
+					nil,
+				)
+
+				return tl
+			},
+			asserts: func(t *testing.T, cfg konfig.Values) {
+				require.Equal(t, "secret", cfg["token"])
+			},
+		},
+		{
+			name:     "ErrorDecrypting",
+			err:      true,
+			category: konfig.CategoryAuth,
+			setUp: func(ctrl *gomock.Controller) *TransitLoader {
+				var l = mocks.NewMockLoader(ctrl)
+				l.EXPECT().Load(konfig.Values{}).DoAndReturn(func(v konfig.Values) error {
+					v.Set("db.pass", "vault:v1:abcd")
+					return nil
+				})
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+				var tl = NewTransitLoader(l, &TransitConfig{
+					Client: c,
+					Key:    "app",
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				tl.logicalClient = lC
+				lC.EXPECT().Write(gomock.Any(), gomock.Any()).Return(nil, errors.New("Code: 403. Errors: permission denied"))
+				l.EXPECT().Name().Return("file")
+
+				return tl
+			},
+		},
+		{
+			name:     "ErrorNoPlaintext",
+			err:      true,
+			category: konfig.CategoryParse,
+			setUp: func(ctrl *gomock.Controller) *TransitLoader {
+				var l = mocks.NewMockLoader(ctrl)
+				l.EXPECT().Load(konfig.Values{}).DoAndReturn(func(v konfig.Values) error {
+					v.Set("db.pass", "vault:v1:abcd")
+					return nil
+				})
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+				var tl = NewTransitLoader(l, &TransitConfig{
+					Client: c,
+					Key:    "app",
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				tl.logicalClient = lC
+				lC.EXPECT().Write(gomock.Any(), gomock.Any()).Return(&vault.Secret{}, nil)
+				l.EXPECT().Name().Return("file")
+
+				return tl
+			},
+		},
+		{
+			name: "ErrorLoading",
+			err:  true,
+			setUp: func(ctrl *gomock.Controller) *TransitLoader {
+				var l = mocks.NewMockLoader(ctrl)
+				l.EXPECT().Load(konfig.Values{}).Return(errors.New(""))
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+				return NewTransitLoader(l, &TransitConfig{
+					Client: c,
+					Key:    "app",
+				})
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+			var tl = testCase.setUp(ctrl)
+			var c = konfig.Values{}
+
+			var err = tl.Load(c)
+			if testCase.err {
+				require.NotNil(t, err, "err should not be nil")
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
+				return
+			}
+
+			require.Nil(t, err, "err should be nil")
+			testCase.asserts(t, c)
+		})
+	}
+}
+
+type contextLoader struct {
+	konfig.Loader
+	ctx context.Context
+}
+
+func (c *contextLoader) LoadContext(ctx context.Context, v konfig.Values) error {
+	c.ctx = ctx
+	v.Set("db.host", "localhost")
+	return nil
+}
+
+func TestTransitLoaderContext(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Load of the wrapped loader is not expected
+	var l = &contextLoader{Loader: mocks.NewMockLoader(ctrl)}
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var tl = NewTransitLoader(l, &TransitConfig{Client: c, Key: "app"})
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var v = konfig.Values{}
+	require.Nil(t, tl.LoadContext(ctx, v))
+	require.Equal(t, ctx, l.ctx)
+	require.Equal(t, konfig.Values{"db.host": "localhost"}, v)
+}
+
+func TestNewTransitLoader(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+	var l = mocks.NewMockLoader(ctrl)
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+
+	require.PanicsWithValue(t, ErrNoLoader, func() {
+		NewTransitLoader(nil, &TransitConfig{Client: c, Key: "app"})
+	})
+	require.PanicsWithValue(t, ErrNoClient, func() {
+		NewTransitLoader(l, &TransitConfig{Key: "app"})
+	})
+	require.PanicsWithValue(t, ErrNoTransitKey, func() {
+		NewTransitLoader(l, &TransitConfig{Client: c})
+	})
+
+	var tl = NewTransitLoader(l, &TransitConfig{Client: c, Key: "app"})
+	require.Equal(t, "transit", tl.cfg.Mount)
+	require.Equal(t, "vault:v", tl.cfg.Prefix)
+}
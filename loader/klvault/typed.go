@@ -0,0 +1,85 @@
+package klvault
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/spf13/cast"
+)
+
+// Types of the typed fields of a secret
+const (
+	TypeBool     = "bool"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeDuration = "duration"
+	// TypeBytes decodes base64 encoded fields to []byte, for binary values such as keys and certificates
+	TypeBytes = "bytes"
+)
+
+var (
+	// ErrUnsupportedFieldTypeMsg is the error message thrown when creating a Loader with a typed field of an unsupported type
+	ErrUnsupportedFieldTypeMsg = "Err unsupported type '%s' for field '%s'"
+	// ErrFieldTypeMsg is the error message returned when a typed field cannot be converted to its type,
+	// the conversion error is not included as it contains the value of the field
+	ErrFieldTypeMsg = "Err field '%s' of secret '%s' cannot be converted to %s"
+)
+
+var fieldConverters = map[string]func(interface{}) (interface{}, error){
+	TypeBool: func(v interface{}) (interface{}, error) {
+		return cast.ToBoolE(v)
+	},
+	TypeInt: func(v interface{}) (interface{}, error) {
+		return cast.ToIntE(v)
+	},
+	TypeFloat: func(v interface{}) (interface{}, error) {
+		return cast.ToFloat64E(v)
+	},
+	TypeDuration: func(v interface{}) (interface{}, error) {
+		return cast.ToDurationE(v)
+	},
+	TypeBytes: func(v interface{}) (interface{}, error) {
+		if b, ok := v.([]byte); ok {
+			return b, nil
+		}
+		var str, err = cast.ToStringE(v)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.DecodeString(str)
+	},
+}
+
+// checkTypedFields returns an error if a typed field of the secret has an unsupported type
+func (s Secret) checkTypedFields() error {
+	for f, t := range s.TypedFields {
+		if _, ok := fieldConverters[t]; !ok {
+			return fmt.Errorf(ErrUnsupportedFieldTypeMsg, t, f)
+		}
+	}
+	return nil
+}
+
+// typedFields returns a copy of data, read from the path p, where the typed fields of the secret are converted to their type.
+// Typed fields missing in data are ignored.
+func (vl *Loader) typedFields(secret Secret, p string, data map[string]interface{}) (map[string]interface{}, error) {
+	if len(secret.TypedFields) == 0 {
+		return data, nil
+	}
+
+	var r = make(map[string]interface{}, len(data))
+	for k, v := range data {
+		var t, ok = secret.TypedFields[k]
+		if !ok {
+			r[k] = v
+			continue
+		}
+
+		var cv, err = fieldConverters[t](v)
+		if err != nil {
+			return nil, fmt.Errorf(ErrFieldTypeMsg, k, vl.redactPath(p), t)
+		}
+		r[k] = cv
+	}
+	return r, nil
+}
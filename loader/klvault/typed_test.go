@@ -0,0 +1,128 @@
+package klvault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedFields(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		typedFields map[string]string
+		data        map[string]interface{}
+		expected    konfig.Values
+		err         bool
+	}{
+		{
+			name: "converts typed fields",
+			typedFields: map[string]string{
+				"enabled": TypeBool,
+				"port":    TypeInt,
+				"ratio":   TypeFloat,
+				"timeout": TypeDuration,
+				"missing": TypeInt,
+				"key":     TypeBytes,
+			},
+			data: map[string]interface{}{
+				"enabled": "true",
+				"port":    "5432",
+				"ratio":   "0.5",
+				"timeout": "5s",
+				"user":    "konfig",
+				"key":     "/wD+",
+			},
+			expected: konfig.Values{
+				"enabled": true,
+				"port":    5432,
+				"ratio":   0.5,
+				"timeout": 5 * time.Second,
+				"user":    "konfig",
+				"key":     []byte{0xff, 0x00, 0xfe},
+			},
+		},
+		{
+			name: "conversion failure",
+			typedFields: map[string]string{
+				"port": TypeInt,
+			},
+			data: map[string]interface{}{
+				"port": "hunter2-secret",
+			},
+			err: true,
+		},
+		{
+			name: "invalid base64",
+			typedFields: map[string]string{
+				"port": TypeBytes,
+			},
+			data: map[string]interface{}{
+				"port": "hunter2 secret!",
+			},
+			err: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client: c,
+				Secrets: []Secret{
+					{
+						Key:         "/dummy/secret/path",
+						TypedFields: testCase.typedFields,
+					},
+				},
+				AuthProvider: aP,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{Data: testCase.data}, nil)
+
+			var v = konfig.Values{}
+			var err = vl.Load(v)
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryParse, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), "'port'")
+				// the value of the secret is not leaked
+				require.NotContains(t, err.Error(), testCase.data["port"])
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestTypedFieldsUnsupportedType(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	require.Panics(t, func() {
+		New(&Config{
+			Client: c,
+			Secrets: []Secret{
+				{
+					Key:         "/dummy/secret/path",
+					TypedFields: map[string]string{"port": "uint"},
+				},
+			},
+			AuthProvider: mocks.NewMockAuthProvider(ctrl),
+		})
+	})
+}
@@ -1,12 +1,16 @@
 package klvault
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	vault "github.com/hashicorp/vault/api"
 	"github.com/lalamove/konfig"
 	"github.com/lalamove/konfig/watcher/kwpoll"
@@ -14,7 +18,11 @@ import (
 	"github.com/lalamove/nui/nstrings"
 )
 
-var _ konfig.Loader = (*Loader)(nil)
+var (
+	_ konfig.Loader        = (*Loader)(nil)
+	_ konfig.ContextLoader = (*Loader)(nil)
+	_ konfig.MaxAgeLoader  = (*Loader)(nil)
+)
 
 var (
 	defaultTTL = 45 * time.Minute
@@ -24,6 +32,10 @@ var (
 	ErrNoAuthProvider = errors.New("No auth provider given")
 	// ErrNoSecretKey is the error thrown when trying to create a Loader without a SecretKey
 	ErrNoSecretKey = errors.New("No secret key given")
+	// ErrSecretNotFoundMsg is the error message returned when a secret does not exist in vault
+	ErrSecretNotFoundMsg = "Err secret '%s' not found"
+	// ErrSecretFieldNotFoundMsg is the error message returned when a field listed in Secret.Fields does not exist in the secret
+	ErrSecretFieldNotFoundMsg = "Err field '%s' not found in secret '%s'"
 )
 
 const defaultName = "vault"
@@ -33,6 +45,7 @@ type LogicalClient interface {
 	Read(key string) (*vault.Secret, error)
 	Write(key string, data map[string]interface{}) (*vault.Secret, error)
 	ReadWithData(key string, data map[string][]string) (*vault.Secret, error)
+	List(key string) (*vault.Secret, error)
 }
 
 // Secret is a secret to load
@@ -43,6 +56,36 @@ type Secret struct {
 	KeysPrefix string
 	// Replacer transforms vault secret's keys
 	Replacer nstrings.Replacer
+	// Fields is the list of fields of the secret to load, other fields are ignored.
+	// If a listed field is not in the secret, the load fails. If empty, all fields are loaded.
+	Fields []string
+	// TypedFields maps fields of the secret to the type they are converted to when loaded,
+	// supported types are TypeBool, TypeInt, TypeFloat and TypeDuration. The load fails if a field cannot be converted.
+	TypedFields map[string]string
+	// Flatten sets wether nested maps in the secret data are flattened into keys joined with konfig.KeySep
+	// (e.g. {"db": {"host": "localhost"}} is loaded as "db.host"), the elements of arrays being indexed (e.g. "hosts.0").
+	// If false, nested maps and arrays are loaded as is.
+	Flatten bool
+	// Recursive sets wether Key is a path prefix under which all the secrets are loaded, listing its sub paths recursively.
+	// The keys of each secret are prefixed with its path under Key, the segments being joined with konfig.KeySep
+	// (e.g. the field "user" of the secret Key/db/creds is loaded as "db.creds.user").
+	Recursive bool
+	// KVv2 sets wether the secret is in a KV version 2 engine, whose data is read from the data field of the secret.
+	// Key must be a data path (e.g. secret/data/app), recursive secrets are listed at the matching metadata path (e.g. secret/metadata/app),
+	// the secrets deleted but still listed in the metadata are skipped.
+	KVv2 bool
+}
+
+// Metadata is the metadata of a secret read from vault
+type Metadata struct {
+	// RequestID is the ID of the vault request
+	RequestID string
+	// LeaseID is the ID of the secret lease
+	LeaseID string
+	// LeaseDuration is the duration of the secret lease
+	LeaseDuration time.Duration
+	// Renewable tells wether the secret lease is renewable
+	Renewable bool
 }
 
 // Config is the config for the Loader
@@ -55,8 +98,22 @@ type Config struct {
 	Secrets []Secret
 	// AuthProvider is the vault auth provider
 	AuthProvider AuthProvider
+	// AuthProviders is a list of vault auth providers tried in order until one returns a token.
+	// If AuthProvider is also set, it is tried first.
+	AuthProviders []AuthProvider
 	// Client is the vault client for the vault loader
 	Client *vault.Client
+	// Clients is a list of standby vault clients. If reading a secret with the active client fails with a connection error,
+	// the loader fails over to the next client. Client is the first active client.
+	Clients []*vault.Client
+	// Address is the address of the vault server used when no Client is provided
+	Address string
+	// Addresses is a list of addresses of standby vault servers used when no Client is provided,
+	// a client is built for each address with TLSConfig and added to Clients.
+	Addresses []string
+	// TLSConfig is the TLS config used to build the vault client when no Client is provided.
+	// It cannot be set along with Client.
+	TLSConfig *TLSConfig
 	// MaxRetry is the maximum number of times the load method can be retried
 	MaxRetry int
 	// RetryDelay is the time between each retry
@@ -67,6 +124,16 @@ type Config struct {
 	Logger nlogger.Provider
 	// Renew sets wether the vault loader should renew it self
 	Renew bool
+	// ChangedOnly sets wether a renewal reloads the store only if the data of a secret changed.
+	// The secrets are read on each renewal and compared with the data of the last read, if none changed the store is not reloaded and hooks are not run.
+	ChangedOnly bool
+	// DuplicateKeyPolicy is the policy applied when a key is set by several secrets during a load.
+	// Default is konfig.DuplicateOverwrite, the value of the last secret is kept.
+	DuplicateKeyPolicy konfig.DuplicateKeyPolicy
+	// RedactPaths sets wether secret paths should be replaced with their hash in the errors returned by the loader
+	RedactPaths bool
+	// AllowEmpty sets wether a secret with no data is allowed. If false, the load fails when a secret has no data.
+	AllowEmpty bool
 }
 
 // Loader is the structure representing a Loader
@@ -76,18 +143,63 @@ type Loader struct {
 	logicalClient LogicalClient
 	mut           *sync.Mutex
 	ttl           time.Duration
+	maxAge        time.Duration
+	metadata      map[string]Metadata
+	// data are the values of each secret of the last successful load, by secret key
+	data map[string]konfig.Values
+	// pending are the values read by the last renewal, they are loaded by the next Load instead of reading the secrets again
+	pending   konfig.Values
+	watchChan chan struct{}
+	// clients are the vault clients, the active one is clients[active]
+	clients        []*vault.Client
+	logicalClients []LogicalClient
+	active         int
 }
 
 // New creates a new Loader with the given config
 func New(cfg *Config) *Loader {
+	var vl, err = NewE(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return vl
+}
+
+// NewE creates a new Loader from the given config like New, but it returns an error instead of panicking if the config is invalid
+func NewE(cfg *Config) (*Loader, error) {
 	if cfg.Secrets == nil || len(cfg.Secrets) == 0 {
-		panic(ErrNoSecretKey)
+		return nil, ErrNoSecretKey
+	}
+	if cfg.AuthProvider == nil && len(cfg.AuthProviders) == 0 {
+		return nil, ErrNoAuthProvider
 	}
-	if cfg.AuthProvider == nil {
-		panic(ErrNoAuthProvider)
+	for _, secret := range cfg.Secrets {
+		if err := secret.checkTypedFields(); err != nil {
+			return nil, err
+		}
+		if err := secret.checkKVv2(); err != nil {
+			return nil, err
+		}
+	}
+	if (cfg.Client != nil || len(cfg.Clients) > 0) && cfg.TLSConfig != nil {
+		return nil, ErrClientAndTLSConfig
 	}
 	if cfg.Client == nil {
-		panic(ErrNoClient)
+		if cfg.TLSConfig == nil {
+			return nil, ErrNoClient
+		}
+		var c, err = NewClient(cfg.Address, cfg.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Client = c
+
+		for _, address := range cfg.Addresses {
+			if c, err = NewClient(address, cfg.TLSConfig); err != nil {
+				return nil, err
+			}
+			cfg.Clients = append(cfg.Clients, c)
+		}
 	}
 	if cfg.Logger == nil {
 		cfg.Logger = defaultLogger()
@@ -101,6 +213,7 @@ func New(cfg *Config) *Loader {
 		mut:           &sync.Mutex{},
 		ttl:           defaultTTL,
 	}
+	vl.setClients()
 
 	var pw *kwpoll.PollWatcher
 	if cfg.Renew {
@@ -113,8 +226,11 @@ func New(cfg *Config) *Loader {
 		)
 	}
 	vl.PollWatcher = pw
+	if cfg.Renew && cfg.ChangedOnly {
+		vl.watchChan = make(chan struct{})
+	}
 
-	return vl
+	return vl, nil
 }
 
 // Name returns the name of the loader
@@ -134,6 +250,31 @@ func (vl *Loader) RetryDelay() time.Duration {
 // It fetches a token from the auth provider and sets the token in the vault client.
 // Then it loads the secret and assigns it values to the konfig.Store.
 func (vl *Loader) Load(cs konfig.Values) error {
+	return vl.LoadContext(context.Background(), cs)
+}
+
+// LoadContext implements konfig.ContextLoader interface, it loads the secrets like Load
+// and stops before reading the next secret when ctx is done.
+// The values read by a renewal are kept for the load following it when ctx is the context of a konfig.DryRun.
+func (vl *Loader) LoadContext(ctx context.Context, cs konfig.Values) error {
+	// the secrets were read by the renewal
+	vl.mut.Lock()
+	var pending = vl.pending
+	if !konfig.IsDryRun(ctx) {
+		vl.pending = nil
+	}
+	vl.mut.Unlock()
+	if pending != nil {
+		for k, v := range pending {
+			cs.Set(k, v)
+		}
+		return nil
+	}
+	return vl.readSecrets(ctx, cs)
+}
+
+// readSecrets reads the secrets and sets their data in cs
+func (vl *Loader) readSecrets(ctx context.Context, cs konfig.Values) error {
 	if vl.cfg.Debug {
 		vl.cfg.Logger.Get().Debug(
 			"Loading vault config",
@@ -141,57 +282,285 @@ func (vl *Loader) Load(cs konfig.Values) error {
 	}
 	// everytime we load we get a new token
 	// maybe we could improve implementation to use a shorter ticker and check if config if different, if yes, reload it
-	var token, ttl, err = vl.cfg.AuthProvider.Token()
+	var token, ttl, err = vl.token()
 	if err != nil {
 		vl.cfg.Logger.Get().Error(err.Error())
 
-		return err
+		return konfig.NewLoadError(vl.cfg.Name, konfig.CategoryAuth, err)
 	}
 	// we set the token in the client
-	vl.cfg.Client.SetToken(token)
+	vl.ActiveClient().SetToken(token)
 
 	var leaseDuration = int(ttl / time.Second)
+	var metadata = make(map[string]Metadata, len(vl.cfg.Secrets))
+	var secretsData = make(map[string]konfig.Values, len(vl.cfg.Secrets))
+	var ks = konfig.NewKeySources(vl.cfg.DuplicateKeyPolicy, vl.cfg.Logger)
 	for _, secret := range vl.cfg.Secrets {
-		// we fetch our secret
-		var s *vault.Secret
-		s, err = vl.logicalClient.Read(secret.Key)
-		if err != nil {
+		if err = ctx.Err(); err != nil {
 			return err
 		}
 
-		if vl.cfg.Debug {
-			vl.cfg.Logger.Get().Debug(
-				fmt.Sprintf("Got secret, expiring in: %d", s.LeaseDuration),
-			)
+		var paths []string
+		if paths, err = vl.secretPaths(secret, token); err != nil {
+			return err
 		}
 
-		// if the current secret lease is smaller than the previous smaller lease
-		// or there is no previous lease
-		if s.LeaseDuration != 0 && (leaseDuration == 0 || s.LeaseDuration < leaseDuration) {
-			leaseDuration = s.LeaseDuration
-		}
+		for _, p := range paths {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			// we fetch our secret
+			var s *vault.Secret
+			s, err = vl.read(p, token)
+			if err != nil {
+				return konfig.NewLoadError(vl.cfg.Name, errorCategory(err), vl.redactError(err, p))
+			}
+			if s == nil {
+				return konfig.NewLoadError(
+					vl.cfg.Name,
+					konfig.CategoryNotFound,
+					fmt.Errorf(ErrSecretNotFoundMsg, vl.redactPath(p)),
+				)
+			}
+
+			var sd = s.Data
+			if secret.KVv2 {
+				sd, _ = s.Data["data"].(map[string]interface{})
+				// the secret was deleted but is still listed in the metadata
+				if sd == nil && secret.Recursive {
+					continue
+				}
+			}
+
+			if len(sd) == 0 && !vl.cfg.AllowEmpty {
+				return konfig.NewLoadError(
+					vl.cfg.Name,
+					konfig.CategoryNotFound,
+					fmt.Errorf(konfig.ErrEmptySourceMsg, vl.redactPath(p)),
+				)
+			}
+
+			if vl.cfg.Debug {
+				vl.cfg.Logger.Get().Debug(
+					fmt.Sprintf("Got secret, expiring in: %d", s.LeaseDuration),
+				)
+			}
 
-		// we set our data on the config store
-		for k, v := range s.Data {
-			var nK = secret.KeysPrefix + k
-			if secret.Replacer != nil {
-				nK = secret.Replacer.Replace(nK)
+			metadata[p] = Metadata{
+				RequestID:     s.RequestID,
+				LeaseID:       s.LeaseID,
+				LeaseDuration: time.Duration(s.LeaseDuration) * time.Second,
+				Renewable:     s.Renewable,
 			}
-			cs.Set(nK, v)
+
+			// if the current secret lease is smaller than the previous smaller lease
+			// or there is no previous lease
+			if s.LeaseDuration != 0 && (leaseDuration == 0 || s.LeaseDuration < leaseDuration) {
+				leaseDuration = s.LeaseDuration
+			}
+
+			var data, missing, ok = secretFields(sd, secret.Fields)
+			if !ok {
+				return konfig.NewLoadError(
+					vl.cfg.Name,
+					konfig.CategoryNotFound,
+					fmt.Errorf(ErrSecretFieldNotFoundMsg, missing, vl.redactPath(p)),
+				)
+			}
+
+			if data, err = vl.typedFields(secret, p, data); err != nil {
+				return konfig.NewLoadError(vl.cfg.Name, konfig.CategoryParse, err)
+			}
+
+			if secret.Flatten {
+				data = flatten(data)
+			}
+
+			// we set our data on the config store
+			var sv = make(konfig.Values, len(data))
+			for k, v := range data {
+				var nK = secret.KeysPrefix + pathKeysPrefix(secret, p) + k
+				if secret.Replacer != nil {
+					nK = secret.Replacer.Replace(nK)
+				}
+				sv.Set(nK, v)
+				if err = ks.Set(cs, vl.redactPath(p), nK, v); err != nil {
+					return konfig.NewLoadError(vl.cfg.Name, konfig.CategoryParse, err)
+				}
+			}
+			secretsData[p] = sv
 		}
 	}
 
+	vl.mut.Lock()
+	vl.metadata = metadata
+	vl.data = secretsData
+	vl.mut.Unlock()
+
 	// reset the ttl for renewal
 	vl.resetTTL(ttl, time.Duration(leaseDuration)*time.Second)
 	return nil
 }
 
+// Start implements konfig.Watcher, it starts the renewal.
+// With ChangedOnly, the secrets are read on each renewal and a watch event is sent only if the data of a secret changed.
+func (vl *Loader) Start() error {
+	if err := vl.PollWatcher.Start(); err != nil {
+		return err
+	}
+	if vl.watchChan != nil {
+		go vl.watchChanged()
+	}
+	return nil
+}
+
+// Watch implements konfig.Watcher, it returns the channel to which renewal events are written
+func (vl *Loader) Watch() <-chan struct{} {
+	if vl.watchChan != nil {
+		return vl.watchChan
+	}
+	return vl.PollWatcher.Watch()
+}
+
+// watchChanged reads the secrets on each renewal and sends a watch event if their data changed
+func (vl *Loader) watchChanged() {
+	for {
+		select {
+		case <-vl.PollWatcher.Done():
+			return
+		case <-vl.PollWatcher.Watch():
+			if !vl.renew() {
+				continue
+			}
+			select {
+			case vl.watchChan <- struct{}{}:
+			case <-vl.PollWatcher.Done():
+				return
+			}
+		}
+	}
+}
+
+// renew reads the secrets and returns wether the store must be reloaded, that is if the data of a secret changed.
+// If the read fails, the store is reloaded so that the failure is reported by the load.
+func (vl *Loader) renew() bool {
+	vl.mut.Lock()
+	var prev = vl.data
+	vl.mut.Unlock()
+
+	var v = konfig.Values{}
+	if err := vl.readSecrets(context.Background(), v); err != nil {
+		vl.cfg.Logger.Get().Error(err.Error())
+		return true
+	}
+
+	vl.mut.Lock()
+	defer vl.mut.Unlock()
+
+	// a secret was added or removed under a recursive secret
+	var changed = len(prev) != len(vl.data)
+	for k, sv := range vl.data {
+		if !reflect.DeepEqual(prev[k], sv) {
+			changed = true
+			if vl.cfg.Debug {
+				vl.cfg.Logger.Get().Debug(fmt.Sprintf("Secret %s changed", vl.redactPath(k)))
+			}
+		}
+	}
+	if changed {
+		vl.pending = v
+	}
+	return changed
+}
+
+// secretFields returns the fields of data listed in fields, or data if fields is empty.
+// If a field is missing, it returns the name of the field and false.
+func secretFields(data map[string]interface{}, fields []string) (map[string]interface{}, string, bool) {
+	if len(fields) == 0 {
+		return data, "", true
+	}
+	var r = make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		var v, ok = data[f]
+		if !ok {
+			return nil, f, false
+		}
+		r[f] = v
+	}
+	return r, "", true
+}
+
+// LastMetadata returns the metadata of the secrets read during the last successful load, by secret key.
+// It returns nil if no load succeeded yet.
+func (vl *Loader) LastMetadata() map[string]Metadata {
+	vl.mut.Lock()
+	defer vl.mut.Unlock()
+
+	if vl.metadata == nil {
+		return nil
+	}
+
+	var m = make(map[string]Metadata, len(vl.metadata))
+	for k, v := range vl.metadata {
+		m[k] = v
+	}
+	return m
+}
+
+// token returns a token from the first auth provider returning one without error.
+// If all providers fail, the returned error aggregates the errors of all providers.
+func (vl *Loader) token() (string, time.Duration, error) {
+	var providers = vl.cfg.AuthProviders
+	if vl.cfg.AuthProvider != nil {
+		providers = append([]AuthProvider{vl.cfg.AuthProvider}, providers...)
+	}
+
+	var multiErr *multierror.Error
+	for i, p := range providers {
+		var token, ttl, err = p.Token()
+		if err == nil {
+			if vl.cfg.Debug && i > 0 {
+				vl.cfg.Logger.Get().Debug(
+					fmt.Sprintf("Got token from auth provider %d", i),
+				)
+			}
+			return token, ttl, nil
+		}
+		multiErr = multierror.Append(multiErr, err)
+	}
+
+	return "", 0, multiErr.ErrorOrNil()
+}
+
+// errorCategory returns the category of an error returned by the vault client.
+// The vault api does not expose typed errors, permission errors are recognized by their status code in the message.
+func errorCategory(err error) konfig.ErrorCategory {
+	var msg = err.Error()
+	if strings.Contains(msg, "Code: 403") || strings.Contains(msg, "Code: 401") {
+		return konfig.CategoryAuth
+	}
+	return konfig.CategoryNetwork
+}
+
 // Time returns the TTL of the vault loader
 // It is used in the ticker watcher a source.
 func (vl *Loader) Time() time.Duration {
 	return vl.ttl
 }
 
+// MaxAge implements konfig.MaxAgeLoader. When the loader renews itself, it returns the shortest of the TTL of the token
+// and the lease durations of the secrets of the last successful load, so that the store is stale once the token expired without a successful renewal.
+// It returns zero if the loader does not renew itself or never loaded.
+func (vl *Loader) MaxAge() time.Duration {
+	if !vl.cfg.Renew {
+		return 0
+	}
+	vl.mut.Lock()
+	defer vl.mut.Unlock()
+	return vl.maxAge
+}
+
 // StopOnFailure returns wether a load failure should stop the config and the registered closers
 func (vl *Loader) StopOnFailure() bool {
 	return vl.cfg.StopOnFailure
@@ -202,8 +571,9 @@ func (vl *Loader) resetTTL(tokenTTL, secretTTL time.Duration) {
 	if secretTTL < tokenTTL {
 		ttl = secretTTL
 	}
-	ttl = (ttl * 75) / 100
 	vl.mut.Lock()
+	vl.maxAge = ttl
+	ttl = (ttl * 75) / 100
 	if ttl != vl.ttl {
 		vl.ttl = ttl
 	}
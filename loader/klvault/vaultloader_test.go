@@ -1,7 +1,9 @@
 package klvault
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -15,10 +17,11 @@ import (
 
 func TestVaultLoader(t *testing.T) {
 	var testCases = []struct {
-		name    string
-		setUp   func(ctrl *gomock.Controller) *Loader
-		asserts func(t *testing.T, vl *Loader, cfg konfig.Values)
-		err     bool
+		name     string
+		setUp    func(ctrl *gomock.Controller) *Loader
+		asserts  func(t *testing.T, vl *Loader, cfg konfig.Values)
+		err      bool
+		category konfig.ErrorCategory
 	}{
 		{
 			name: "BasicNoError",
@@ -45,6 +48,9 @@ func TestVaultLoader(t *testing.T) {
 				vl.logicalClient = lC
 				lC.EXPECT().Read("/dummy/secret/path").Return(
 					&vault.Secret{
+						RequestID: "request",
+						LeaseID:   "lease",
+						Renewable: true,
 						Data: map[string]interface{}{
 							"FOO": "BAR",
 						},
@@ -81,11 +87,27 @@ func TestVaultLoader(t *testing.T) {
 					"FOO",
 					cfg["BAR"],
 				)
+				require.Equal(
+					t,
+					map[string]Metadata{
+						"/dummy/secret/path": {
+							RequestID:     "request",
+							LeaseID:       "lease",
+							LeaseDuration: 2 * time.Hour,
+							Renewable:     true,
+						},
+						"/dummy/secret/path2": {
+							LeaseDuration: 1 * time.Hour,
+						},
+					},
+					vl.LastMetadata(),
+				)
 			},
 		},
 		{
-			name: "ErrorOnAuthProvider",
-			err:  true,
+			name:     "ErrorOnAuthProvider",
+			err:      true,
+			category: konfig.CategoryAuth,
 			setUp: func(ctrl *gomock.Controller) *Loader {
 				var aP = mocks.NewMockAuthProvider(ctrl)
 				aP.EXPECT().Token().Return(
@@ -107,8 +129,9 @@ func TestVaultLoader(t *testing.T) {
 			asserts: func(t *testing.T, vl *Loader, cfg konfig.Values) {},
 		},
 		{
-			name: "ErrorFetchingSecret",
-			err:  true,
+			name:     "ErrorFetchingSecret",
+			err:      true,
+			category: konfig.CategoryNetwork,
 			setUp: func(ctrl *gomock.Controller) *Loader {
 				var aP = mocks.NewMockAuthProvider(ctrl)
 				aP.EXPECT().Token().Return(
@@ -136,6 +159,106 @@ func TestVaultLoader(t *testing.T) {
 			},
 			asserts: func(t *testing.T, vl *Loader, cfg konfig.Values) {},
 		},
+		{
+			name:     "ErrorSecretNotFound",
+			err:      true,
+			category: konfig.CategoryNotFound,
+			setUp: func(ctrl *gomock.Controller) *Loader {
+				var aP = mocks.NewMockAuthProvider(ctrl)
+				aP.EXPECT().Token().Return(
+					"DUMMYTOKEN",
+					1*time.Hour,
+					nil,
+				)
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+
+				var vl = New(&Config{
+					Client:       c,
+					Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+					AuthProvider: aP,
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				vl.logicalClient = lC
+				lC.EXPECT().Read("/dummy/secret/path").Return(nil, nil)
+
+				return vl
+			},
+			asserts: func(t *testing.T, vl *Loader, cfg konfig.Values) {},
+		},
+		{
+			name: "FallbackAuthProvider",
+			setUp: func(ctrl *gomock.Controller) *Loader {
+				var aP = mocks.NewMockAuthProvider(ctrl)
+				aP.EXPECT().Token().Return(
+					"",
+					time.Duration(0),
+					errors.New("k8s auth unavailable"),
+				)
+				var aP2 = mocks.NewMockAuthProvider(ctrl)
+				aP2.EXPECT().Token().Return(
+					"DUMMYTOKEN",
+					1*time.Hour,
+					nil,
+				)
+				var aP3 = mocks.NewMockAuthProvider(ctrl)
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+
+				var vl = New(&Config{
+					Client:        c,
+					Secrets:       []Secret{{Key: "/dummy/secret/path"}},
+					AuthProviders: []AuthProvider{aP, aP2, aP3},
+				})
+
+				var lC = mocks.NewMockLogicalClient(ctrl)
+				vl.logicalClient = lC
+				lC.EXPECT().Read("/dummy/secret/path").Return(
+					&vault.Secret{
+						Data: map[string]interface{}{
+							"FOO": "BAR",
+						},
+					},
+					nil,
+				)
+
+				return vl
+			},
+			asserts: func(t *testing.T, vl *Loader, cfg konfig.Values) {
+				require.Equal(t, "DUMMYTOKEN", vl.cfg.Client.Token())
+				require.Equal(t, "BAR", cfg["FOO"])
+			},
+		},
+		{
+			name:     "ErrorOnAllAuthProviders",
+			err:      true,
+			category: konfig.CategoryAuth,
+			setUp: func(ctrl *gomock.Controller) *Loader {
+				var aP = mocks.NewMockAuthProvider(ctrl)
+				aP.EXPECT().Token().Return(
+					"",
+					time.Duration(0),
+					errors.New("k8s"),
+				)
+				var aP2 = mocks.NewMockAuthProvider(ctrl)
+				aP2.EXPECT().Token().Return(
+					"",
+					time.Duration(0),
+					errors.New("approle"),
+				)
+
+				var c, _ = vault.NewClient(vault.DefaultConfig())
+
+				return New(&Config{
+					Client:        c,
+					Secrets:       []Secret{{Key: "/dummy/secret/path"}},
+					AuthProvider:  aP,
+					AuthProviders: []AuthProvider{aP2},
+				})
+			},
+			asserts: func(t *testing.T, vl *Loader, cfg konfig.Values) {},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -150,6 +273,7 @@ func TestVaultLoader(t *testing.T) {
 
 			if testCase.err {
 				require.NotNil(t, err, "err should not be nil")
+				require.Equal(t, testCase.category, konfig.ErrorCategoryOf(err))
 				return
 			}
 
@@ -165,18 +289,21 @@ func TestResetTTL(t *testing.T) {
 		tokenTTL    time.Duration
 		secretTTL   time.Duration
 		expectedTTL time.Duration
+		maxAge      time.Duration
 	}{
 		{
 			name:        "token TTL is smaller than secret TTL",
 			tokenTTL:    1 * time.Hour,
 			secretTTL:   2 * time.Hour,
 			expectedTTL: 45 * time.Minute,
+			maxAge:      1 * time.Hour,
 		},
 		{
 			name:        "token TTL is smaller than secret TTL",
 			tokenTTL:    1 * time.Hour,
 			secretTTL:   30 * time.Minute,
 			expectedTTL: 1350 * time.Second,
+			maxAge:      30 * time.Minute,
 		},
 	}
 
@@ -185,10 +312,17 @@ func TestResetTTL(t *testing.T) {
 			testCase.name,
 			func(t *testing.T) {
 				var vl = &Loader{
+					cfg: &Config{Renew: true},
 					mut: &sync.Mutex{},
 				}
+				require.Equal(t, time.Duration(0), vl.MaxAge())
 				vl.resetTTL(testCase.tokenTTL, testCase.secretTTL)
 				require.Equal(t, testCase.expectedTTL, vl.ttl)
+				require.Equal(t, testCase.maxAge, vl.MaxAge())
+
+				// without renewal the values are not expected to be reloaded
+				vl.cfg.Renew = false
+				require.Equal(t, time.Duration(0), vl.MaxAge())
 			},
 		)
 	}
@@ -280,6 +414,218 @@ func TestNew(t *testing.T) {
 	)
 }
 
+func TestNewE(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+	var aP = mocks.NewMockAuthProvider(ctrl)
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+
+	var testCases = []struct {
+		name string
+		cfg  *Config
+		err  error
+	}{
+		{
+			name: "no secret key",
+			cfg:  &Config{},
+			err:  ErrNoSecretKey,
+		},
+		{
+			name: "no auth provider",
+			cfg:  &Config{Secrets: []Secret{{Key: "/dummy/secret/path"}}},
+			err:  ErrNoAuthProvider,
+		},
+		{
+			name: "no vault client",
+			cfg:  &Config{Secrets: []Secret{{Key: "/dummy/secret/path"}}, AuthProvider: aP},
+			err:  ErrNoClient,
+		},
+		{
+			name: "unsupported typed field",
+			cfg: &Config{
+				Secrets:      []Secret{{Key: "/dummy/secret/path", TypedFields: map[string]string{"port": "uuid"}}},
+				AuthProvider: aP,
+				Client:       c,
+			},
+			err: fmt.Errorf(ErrUnsupportedFieldTypeMsg, "uuid", "port"),
+		},
+		{
+			name: "valid config",
+			cfg: &Config{
+				Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+				AuthProvider: aP,
+				Client:       c,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var vl, err = NewE(testCase.cfg)
+			if testCase.err != nil {
+				require.Equal(t, testCase.err, err)
+				require.Nil(t, vl)
+				return
+			}
+			require.Nil(t, err)
+			require.NotNil(t, vl)
+		})
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var aP = mocks.NewMockAuthProvider(ctrl)
+	aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var vl = New(&Config{
+		Client: c,
+		Secrets: []Secret{
+			{Key: "/dummy/secret/path"},
+			{Key: "/dummy/secret/path2"},
+		},
+		AuthProvider: aP,
+	})
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	// the second secret is not read once the context is cancelled
+	var lC = mocks.NewMockLogicalClient(ctrl)
+	vl.logicalClient = lC
+	lC.EXPECT().Read("/dummy/secret/path").DoAndReturn(func(string) (*vault.Secret, error) {
+		cancel()
+		return &vault.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil
+	})
+
+	require.Equal(t, context.Canceled, vl.LoadContext(ctx, konfig.Values{}))
+}
+
+func TestAllowEmpty(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		allowEmpty bool
+		err        bool
+	}{
+		{
+			name: "empty secret not allowed",
+			err:  true,
+		},
+		{
+			name:       "empty secret allowed",
+			allowEmpty: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client:       c,
+				Secrets:      []Secret{{Key: "/dummy/secret/path"}},
+				AuthProvider: aP,
+				AllowEmpty:   testCase.allowEmpty,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{}, nil)
+
+			var err = vl.Load(konfig.Values{})
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryNotFound, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), "/dummy/secret/path")
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestSecretFields(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		fields   []string
+		expected konfig.Values
+		err      bool
+	}{
+		{
+			name: "all fields",
+			expected: konfig.Values{
+				"user":     "konfig",
+				"password": "s3cr3t",
+				"internal": "x",
+			},
+		},
+		{
+			name:   "subset of fields",
+			fields: []string{"user", "password"},
+			expected: konfig.Values{
+				"user":     "konfig",
+				"password": "s3cr3t",
+			},
+		},
+		{
+			name:   "missing field",
+			fields: []string{"user", "host"},
+			err:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var aP = mocks.NewMockAuthProvider(ctrl)
+			aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+			var c, _ = vault.NewClient(vault.DefaultConfig())
+			var vl = New(&Config{
+				Client: c,
+				Secrets: []Secret{
+					{
+						Key:    "/dummy/secret/path",
+						Fields: testCase.fields,
+					},
+				},
+				AuthProvider: aP,
+			})
+
+			var lC = mocks.NewMockLogicalClient(ctrl)
+			vl.logicalClient = lC
+			lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{
+				Data: map[string]interface{}{
+					"user":     "konfig",
+					"password": "s3cr3t",
+					"internal": "x",
+				},
+			}, nil)
+
+			var v = konfig.Values{}
+			var err = vl.Load(v)
+			if testCase.err {
+				require.NotNil(t, err)
+				require.Equal(t, konfig.CategoryNotFound, konfig.ErrorCategoryOf(err))
+				require.Contains(t, err.Error(), "'host'")
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
 func TestMaxRetryRetryDelay(t *testing.T) {
 	var ctrl = gomock.NewController(t)
 	defer ctrl.Finish()
@@ -301,3 +647,86 @@ func TestMaxRetryRetryDelay(t *testing.T) {
 	require.Equal(t, 1, vl.MaxRetry())
 	require.Equal(t, 1*time.Second, vl.RetryDelay())
 }
+
+func TestChangedOnly(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var aP = mocks.NewMockAuthProvider(ctrl)
+	aP.EXPECT().Token().AnyTimes().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var vl = New(&Config{
+		Client: c,
+		Secrets: []Secret{
+			{Key: "/dummy/secret/path"},
+			{Key: "/dummy/secret/path2"},
+		},
+		AuthProvider: aP,
+		Renew:        true,
+		ChangedOnly:  true,
+	})
+	require.NotNil(t, vl.watchChan)
+	require.Equal(t, (<-chan struct{})(vl.watchChan), vl.Watch())
+
+	var lC = mocks.NewMockLogicalClient(ctrl)
+	vl.logicalClient = lC
+	gomock.InOrder(
+		lC.EXPECT().Read("/dummy/secret/path").Times(2).Return(&vault.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil),
+		lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{Data: map[string]interface{}{"foo": "baz"}}, nil),
+	)
+	lC.EXPECT().Read("/dummy/secret/path2").Times(3).Return(&vault.Secret{Data: map[string]interface{}{"bar": "foo"}}, nil)
+
+	var v = konfig.Values{}
+	require.Nil(t, vl.Load(v))
+	require.Equal(t, konfig.Values{"foo": "bar", "bar": "foo"}, v)
+
+	// the data did not change
+	require.False(t, vl.renew())
+	require.Nil(t, vl.pending)
+
+	// the first secret changed, the next load uses the values read by the renewal
+	require.True(t, vl.renew())
+
+	// a dry run does not consume the values read by the renewal
+	var s = konfig.New(konfig.DefaultConfig())
+	s.RegisterLoader(vl)
+	var r = s.DryRun(context.Background())
+	require.Empty(t, r.Errors)
+	require.Equal(t, konfig.Values{"foo": "baz", "bar": "foo"}, r.Values)
+	require.NotNil(t, vl.pending)
+
+	v = konfig.Values{}
+	require.Nil(t, vl.Load(v))
+	require.Equal(t, konfig.Values{"foo": "baz", "bar": "foo"}, v)
+	require.Nil(t, vl.pending)
+}
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var aP = mocks.NewMockAuthProvider(ctrl)
+	aP.EXPECT().Token().Return("DUMMYTOKEN", 1*time.Hour, nil)
+
+	var c, _ = vault.NewClient(vault.DefaultConfig())
+	var vl = New(&Config{
+		Client: c,
+		Secrets: []Secret{
+			{Key: "/dummy/secret/path"},
+			{Key: "/dummy/secret/path2"},
+		},
+		AuthProvider:       aP,
+		DuplicateKeyPolicy: konfig.DuplicateError,
+	})
+
+	var lC = mocks.NewMockLogicalClient(ctrl)
+	vl.logicalClient = lC
+	lC.EXPECT().Read("/dummy/secret/path").Return(&vault.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil)
+	lC.EXPECT().Read("/dummy/secret/path2").Return(&vault.Secret{Data: map[string]interface{}{"foo": "baz"}}, nil)
+
+	var err = vl.Load(konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, konfig.CategoryParse, konfig.ErrorCategoryOf(err))
+	require.Contains(t, err.Error(), "Err key 'foo' is set by both '/dummy/secret/path' and '/dummy/secret/path2'")
+}
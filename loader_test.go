@@ -1,6 +1,7 @@
 package konfig
 
 import (
+	"context"
 	"errors"
 	"testing"
 	time "time"
@@ -54,7 +55,7 @@ func TestLoaderHooksRun(t *testing.T) {
 			}
 			var err = loaderHooks.Run(Instance())
 			require.NotNil(t, err, "err should not be nil")
-			require.Equal(t, 1, i, "one hook should have run")
+			require.Equal(t, 6, i, "all hooks should have run")
 		},
 	)
 }
@@ -185,7 +186,7 @@ func TestLoaderLoadRetry(t *testing.T) {
 				reset()
 				var c = instance()
 				c.cfg.NoExitOnError = true
-				var err = c.loaderLoadRetry(testCase.build(ctrl), 0)
+				var err = c.loaderLoadRetry(context.Background(), testCase.build(ctrl), 0)
 				if testCase.err {
 					require.NotNil(t, err, "err should not be nil")
 					return
@@ -242,10 +243,10 @@ func TestLoaderLoadRetryStrictKeys(t *testing.T) {
 		},
 	}
 
-	var err = c.loaderLoadRetry(wl, 0)
+	var err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.NotNil(t, err, "err should not be nil")
 }
 
@@ -331,3 +332,273 @@ func TestLoaderLoadWatch(t *testing.T) {
 		)
 	}
 }
+
+func TestReload(t *testing.T) {
+	t.Run(
+		"reload all loaders",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			}
+			RegisterLoader(l)
+			require.Nil(t, Load())
+			require.Equal(t, "bar", MustString("foo"))
+
+			l.DataToLoad[0][1] = "baz"
+			require.Nil(t, Reload())
+			require.Equal(t, "baz", MustString("foo"))
+
+			l.err = true
+			require.NotNil(t, Reload())
+			require.Equal(t, "baz", MustString("foo"))
+		},
+	)
+
+	t.Run(
+		"reload loader by name",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			}
+			RegisterLoader(l)
+			require.Nil(t, Load())
+
+			l.DataToLoad[0][1] = "baz"
+			require.Nil(t, ReloadLoader("dummy"))
+			require.Equal(t, "baz", MustString("foo"))
+
+			require.Equal(t, ErrLoaderNotFound, ReloadLoader("nope"))
+		},
+	)
+
+	t.Run(
+		"reload loader keeps other loaders precedence",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l1 = &OwnerLoader{
+				name: "l1",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "bar"}, {"bar", "foo"}, {"baz", "l1"}},
+				},
+			}
+			var l2 = &OwnerLoader{
+				name: "l2",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "baz"}, {"qux", "l2"}},
+				},
+			}
+			var l3 = &OwnerLoader{
+				name: "l3",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"bar", "l3"}},
+				},
+			}
+			RegisterLoader(l1)
+			RegisterLoader(l2)
+			RegisterLoader(l3)
+			require.Nil(t, Load())
+
+			// l1 does not override the keys of the loaders registered after it
+			l1.DataToLoad = [][2]string{{"foo", "qux"}, {"bar", "qux"}, {"baz", "qux"}}
+			require.Nil(t, ReloadLoader("l1"))
+			require.Equal(t, "baz", MustString("foo"))
+			require.Equal(t, "l3", MustString("bar"))
+			require.Equal(t, "qux", MustString("baz"))
+			require.Equal(t, "l2", MustString("qux"))
+
+			// a key not loaded anymore by l2 is restored from l1
+			l2.DataToLoad = [][2]string{{"qux", "l2"}}
+			require.Nil(t, ReloadLoader("l2"))
+			require.Equal(t, "qux", MustString("foo"))
+			require.Equal(t, "l1", Source("foo"))
+			// the restored key is not added to the values of l2
+			require.Equal(t, []LayerValue{{Loader: "l1", Value: "qux", Effective: true}}, Explain("foo"))
+
+			// a key not loaded by any loader anymore is removed
+			l2.DataToLoad = nil
+			require.Nil(t, ReloadLoader("l2"))
+			require.Nil(t, Get("qux"))
+			require.Equal(t, "l3", MustString("bar"))
+		},
+	)
+
+	t.Run(
+		"concurrent reloads",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(&DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			})
+			require.Nil(t, Load())
+
+			var done = make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				go func() {
+					done <- Reload()
+				}()
+			}
+			for i := 0; i < 10; i++ {
+				require.Nil(t, <-done)
+			}
+			require.Equal(t, "bar", MustString("foo"))
+		},
+	)
+}
+
+func TestEnabledIf(t *testing.T) {
+	t.Run(
+		"skips disabled loaders",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var enabled bool
+			RegisterLoader(&DummyLoader{
+				DataToLoad: [][2]string{{"foo", "bar"}},
+			})
+			RegisterLoader(&DummyLoader{
+				DataToLoad: [][2]string{{"debug", "true"}},
+			}).EnabledIf(func() bool {
+				return enabled
+			})
+
+			require.Nil(t, Load())
+			require.Equal(t, "bar", Get("foo"))
+			require.False(t, Exists("debug"))
+
+			// enable at runtime
+			enabled = true
+			require.Nil(t, Reload())
+			require.Equal(t, "true", Get("debug"))
+
+			// disabling removes the values of the loader
+			enabled = false
+			require.Nil(t, Reload())
+			require.False(t, Exists("debug"))
+			require.Equal(t, "bar", Get("foo"))
+		},
+	)
+
+	t.Run(
+		"disabled watchers are not started",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reset()
+			Init(DefaultConfig())
+
+			// no expectations on the mocks, any call fails the test
+			RegisterLoaderWatcher(
+				NewLoaderWatcher(NewMockLoader(ctrl), NewMockWatcher(ctrl)),
+			).EnabledIf(func() bool {
+				return false
+			})
+
+			require.Nil(t, LoadWatch())
+		},
+	)
+}
+
+type ContextDummyLoader struct {
+	DummyLoader
+	block bool
+	ctx   context.Context
+}
+
+func (d *ContextDummyLoader) LoadContext(ctx context.Context, s Values) error {
+	d.ctx = ctx
+	if d.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return d.Load(s)
+}
+
+func TestLoadContext(t *testing.T) {
+	t.Run(
+		"context loader is cancelled",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &ContextDummyLoader{block: true}
+			RegisterLoader(l)
+
+			var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			require.Equal(t, context.DeadlineExceeded, LoadContext(ctx))
+			require.NotNil(t, l.ctx)
+		},
+	)
+
+	t.Run(
+		"loaders not started once context is done",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reset()
+			Init(DefaultConfig())
+
+			// Load is not expected on the mock, calling it fails the test
+			var l = NewMockLoader(ctrl)
+			l.EXPECT().StopOnFailure().Return(false)
+			RegisterLoader(l)
+
+			var ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+
+			require.Equal(t, context.Canceled, LoadContext(ctx))
+		},
+	)
+
+	t.Run(
+		"retries stop once context is done",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(&DummyLoader{
+				err:        true,
+				maxRetry:   10,
+				retryDelay: time.Hour,
+			})
+
+			var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			require.Equal(t, context.DeadlineExceeded, LoadContext(ctx))
+		},
+	)
+
+	t.Run(
+		"context cancelled after load",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &ContextDummyLoader{
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "bar"}},
+				},
+			}
+			RegisterLoader(l)
+
+			require.Nil(t, Load())
+			require.Equal(t, "bar", Get("foo"))
+			require.Equal(t, context.Canceled, l.ctx.Err())
+		},
+	)
+}
@@ -0,0 +1,104 @@
+package konfig
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// ErrorCategory is the category of a load failure
+type ErrorCategory int
+
+const (
+	// CategoryUnknown is the category of failures which are not categorized
+	CategoryUnknown ErrorCategory = iota
+	// CategoryAuth is the category of authentication and authorization failures
+	CategoryAuth
+	// CategoryNetwork is the category of failures to reach a remote source
+	CategoryNetwork
+	// CategoryParse is the category of failures to parse the loaded data
+	CategoryParse
+	// CategoryNotFound is the category of failures when the data to load does not exist
+	CategoryNotFound
+)
+
+var categoryNames = map[ErrorCategory]string{
+	CategoryUnknown:  "unknown",
+	CategoryAuth:     "auth",
+	CategoryNetwork:  "network",
+	CategoryParse:    "parse",
+	CategoryNotFound: "not found",
+}
+
+func (c ErrorCategory) String() string {
+	if n, ok := categoryNames[c]; ok {
+		return n
+	}
+	return categoryNames[CategoryUnknown]
+}
+
+// ErrEmptySourceMsg is the error message returned by the built-in loaders when a source loads no keys
+// and empty sources are not allowed
+var ErrEmptySourceMsg = "Err source '%s' loaded no keys"
+
+// LoadError is the error returned by the built-in loaders when they fail to load.
+// It carries the name of the loader and the category of the failure, so that callers can branch on it
+// (e.g. fail fast on auth failures, retry on network failures).
+type LoadError struct {
+	// Loader is the name of the loader which failed
+	Loader string
+	// Category is the category of the failure
+	Category ErrorCategory
+	// Err is the underlying error
+	Err error
+}
+
+// NewLoadError returns a new LoadError for the loader with the given name
+func NewLoadError(loader string, category ErrorCategory, err error) *LoadError {
+	return &LoadError{
+		Loader:   loader,
+		Category: category,
+		Err:      err,
+	}
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("Err loader '%s' failed (%s): %v", e.Loader, e.Category, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategoryOf returns the category of err if it is or wraps a *LoadError, else it returns CategoryUnknown.
+// Wrapped errors are unwrapped through their Unwrap or Cause method.
+// For a *multierror.Error, it returns the category of the first of its errors which has one.
+func ErrorCategoryOf(err error) ErrorCategory {
+	for err != nil {
+		switch e := err.(type) {
+		case *LoadError:
+			return e.Category
+		case *multierror.Error:
+			for _, me := range e.Errors {
+				if c := ErrorCategoryOf(me); c != CategoryUnknown {
+					return c
+				}
+			}
+			return CategoryUnknown
+		}
+		err = unwrapError(err)
+	}
+	return CategoryUnknown
+}
+
+// unwrapError returns the error wrapped by err through its Unwrap or Cause method, or nil if it does not wrap an error
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package konfig
+
+import (
+	"errors"
+	"testing"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+type wrapError struct {
+	err error
+}
+
+func (e wrapError) Error() string {
+	return "reload: " + e.err.Error()
+}
+
+func (e wrapError) Unwrap() error {
+	return e.err
+}
+
+type causeError struct {
+	err error
+}
+
+func (e causeError) Error() string {
+	return "reload: " + e.err.Error()
+}
+
+func (e causeError) Cause() error {
+	return e.err
+}
+
+func TestLoadError(t *testing.T) {
+	var cause = errors.New("connection refused")
+	var err error = NewLoadError("vault", CategoryNetwork, cause)
+
+	require.Equal(t, "Err loader 'vault' failed (network): connection refused", err.Error())
+	require.Equal(t, cause, err.(*LoadError).Unwrap())
+	require.Equal(t, CategoryNetwork, ErrorCategoryOf(err))
+	require.Equal(t, CategoryUnknown, ErrorCategoryOf(cause))
+	require.Equal(t, CategoryUnknown, ErrorCategoryOf(nil))
+
+	// wrapped errors
+	require.Equal(t, CategoryNetwork, ErrorCategoryOf(wrapError{err}))
+	require.Equal(t, CategoryNetwork, ErrorCategoryOf(causeError{wrapError{err}}))
+	require.Equal(t, CategoryUnknown, ErrorCategoryOf(wrapError{cause}))
+	require.Equal(
+		t,
+		CategoryNetwork,
+		ErrorCategoryOf(multierror.Append(cause, wrapError{err})),
+	)
+	require.Equal(t, "unknown", ErrorCategory(42).String())
+}
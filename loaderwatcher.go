@@ -1,5 +1,11 @@
 package konfig
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
 // LoaderWatcher is an interface that implements both loader and watcher
 type LoaderWatcher interface {
 	Loader
@@ -14,6 +20,34 @@ type loaderWatcher struct {
 	s           *store
 	metrics     *loaderMetrics
 	loaderHooks LoaderHooks
+	// loadMut serializes the loads of the loader
+	loadMut sync.Mutex
+	// enabled tells wether the loader is enabled, it is evaluated on each load
+	enabled func() bool
+	// transforms are the transforms applied to the values of the loader
+	transforms []Transform
+	// changedKeys are the keys changed by the last load of the loader, it is guarded by loadMut
+	changedKeys []string
+	// unregistered tells wether the loader was removed from the store, it is guarded by loadMut
+	unregistered bool
+	// statusMut guards the status fields below
+	statusMut sync.Mutex
+	// lastErr is the last panic of the watcher goroutine
+	lastErr error
+	// failed tells wether the watcher failed permanently
+	failed bool
+	// lastLoad is the start time of the last load
+	lastLoad time.Time
+	// lastLoadDuration is the duration of the last load, retries included
+	lastLoadDuration time.Duration
+	// lastLoadErr is the error of the last load
+	lastLoadErr error
+	// lastSuccess is the end time of the last successful load
+	lastSuccess time.Time
+	// degraded tells wether the loader failed during the initial load and did not succeed since
+	degraded bool
+	// failureMode is the behaviour of the store when the loader fails during the initial load
+	failureMode FailureMode
 }
 
 // NewLoaderWatcher creates a new LoaderWatcher from a Loader and a Watcher
@@ -38,3 +72,41 @@ func (c *store) newLoaderWatcher(l Loader, w Watcher, loaderHooks LoaderHooks) *
 
 	return lw
 }
+
+func (lw *loaderWatcher) setLoadStatus(start time.Time, d time.Duration, err error) {
+	lw.statusMut.Lock()
+	lw.lastLoad = start
+	lw.lastLoadDuration = d
+	lw.lastLoadErr = err
+	if err == nil {
+		lw.degraded = false
+		lw.lastSuccess = start.Add(d)
+	}
+	lw.statusMut.Unlock()
+}
+
+func (lw *loaderWatcher) isEnabled() bool {
+	return lw.enabled == nil || lw.enabled()
+}
+
+// loadContext loads the loader with the given context if it implements ContextLoader.
+// Other loaders are not loaded if the context is already done.
+func (lw *loaderWatcher) loadContext(ctx context.Context, v Values) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cl, ok := contextLoader(lw.Loader); ok {
+		return cl.LoadContext(ctx, v)
+	}
+	return lw.Load(v)
+}
+
+func contextLoader(l Loader) (ContextLoader, bool) {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return contextLoader(lt.Loader)
+	case ContextLoader:
+		return lt, true
+	}
+	return nil, false
+}
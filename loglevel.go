@@ -0,0 +1,23 @@
+package konfig
+
+import (
+	"github.com/spf13/cast"
+)
+
+// BindLogLevel binds the key k of the global store to a log level.
+// See Store.BindLogLevel.
+func BindLogLevel(k string, set func(string) error) Store {
+	return instance().BindLogLevel(k, set)
+}
+
+// BindLogLevel registers a hook calling set with the value of the key k converted to a string after every load,
+// so that the log level can be changed without a restart. If the key is not set, set is not called.
+// set is a function setting the level of the logging library, if it returns an error the hook fails with it.
+func (c *store) BindLogLevel(k string, set func(string) error) Store {
+	return c.RegisterHook(func(s Store) error {
+		if !s.Exists(k) {
+			return nil
+		}
+		return set(cast.ToString(s.Get(k)))
+	})
+}
@@ -0,0 +1,37 @@
+package konfig
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindLogLevel(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+
+	var lvl = "warn"
+	c.BindLogLevel("log.level", func(l string) error {
+		if l == "nope" {
+			return errors.New("invalid level")
+		}
+		lvl = l
+		return nil
+	})
+
+	var l = &MapLoader{name: "l", values: Values{}}
+	c.RegisterLoader(l)
+
+	// the key is absent, the level is kept
+	require.Nil(t, c.Load())
+	require.Equal(t, "warn", lvl)
+
+	l.values = Values{"log.level": "debug"}
+	require.Nil(t, c.Load())
+	require.Equal(t, "debug", lvl)
+
+	l.values = Values{"log.level": "nope"}
+	require.NotNil(t, c.Load())
+	require.Equal(t, "debug", lvl)
+}
@@ -0,0 +1,95 @@
+package konfig
+
+import "reflect"
+
+// MergeFunc is a function merging the values src of a loader over the values dst of the store.
+// It must not modify the maps and slices it reads from dst and src, it must set new values in dst instead.
+type MergeFunc func(dst, src Values)
+
+// MergeReplace sets the values of src in dst, replacing existing values. It is the default merge function of the store.
+func MergeReplace(dst, src Values) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// MergeDeep sets the values of src in dst, nested maps being merged recursively.
+// Other values, slices included, are replaced.
+func MergeDeep(dst, src Values) {
+	for k, v := range src {
+		dst[k] = mergeValue(dst[k], v, false)
+	}
+}
+
+// MergeAppend sets the values of src in dst like MergeDeep, slices of the same type being appended instead of replaced.
+// []byte values are binary values, they are replaced.
+func MergeAppend(dst, src Values) {
+	for k, v := range src {
+		dst[k] = mergeValue(dst[k], v, true)
+	}
+}
+
+func mergeValue(dv interface{}, sv interface{}, appendSlices bool) interface{} {
+	switch st := sv.(type) {
+	case map[string]interface{}:
+		if dt, ok := dv.(map[string]interface{}); ok {
+			var r = make(map[string]interface{}, len(dt)+len(st))
+			for k, v := range dt {
+				r[k] = v
+			}
+			for k, v := range st {
+				r[k] = mergeValue(r[k], v, appendSlices)
+			}
+			return r
+		}
+		return sv
+	case map[interface{}]interface{}:
+		if dt, ok := dv.(map[interface{}]interface{}); ok {
+			var r = make(map[interface{}]interface{}, len(dt)+len(st))
+			for k, v := range dt {
+				r[k] = v
+			}
+			for k, v := range st {
+				r[k] = mergeValue(r[k], v, appendSlices)
+			}
+			return r
+		}
+		return sv
+	case []byte:
+		// binary values are not appended
+		return sv
+	}
+
+	if !appendSlices || dv == nil {
+		return sv
+	}
+
+	var drv, srv = reflect.ValueOf(dv), reflect.ValueOf(sv)
+	if drv.Kind() != reflect.Slice || drv.Type() != srv.Type() {
+		return sv
+	}
+
+	var r = reflect.MakeSlice(drv.Type(), 0, drv.Len()+srv.Len())
+	r = reflect.AppendSlice(r, drv)
+	return reflect.AppendSlice(r, srv).Interface()
+}
+
+// previousValue returns the value of the key k loaded by the last loader registered before wl loading it
+func (c *store) previousValue(wl *loaderWatcher, k string) (interface{}, bool) {
+	var found bool
+	var wls = c.loaders()
+	for i := len(wls) - 1; i >= 0; i-- {
+		var owl = wls[i]
+		if owl == wl {
+			found = true
+			continue
+		}
+		if !found {
+			continue
+		}
+		if v, ok := owl.values[k]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,132 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MapLoader struct {
+	DummyLoader
+	name   string
+	values Values
+}
+
+func (m *MapLoader) Name() string {
+	return m.name
+}
+
+func (m *MapLoader) Load(v Values) error {
+	for k, vv := range m.values {
+		v.Set(k, vv)
+	}
+	return nil
+}
+
+func TestMergeFuncs(t *testing.T) {
+	var dst = func() Values {
+		return Values{
+			"list": []interface{}{"a"},
+			"map": map[string]interface{}{
+				"foo": "bar",
+				"sub": map[string]interface{}{"a": 1},
+			},
+			"str": "foo",
+			"bin": []byte{0xff, 0x00},
+		}
+	}
+	var src = Values{
+		"list": []interface{}{"b"},
+		"map": map[string]interface{}{
+			"bar": "baz",
+			"sub": map[string]interface{}{"b": 2},
+		},
+		"str": "bar",
+		"bin": []byte{0xfe},
+	}
+
+	var testCases = []struct {
+		name     string
+		merge    MergeFunc
+		expected Values
+	}{
+		{
+			name:  "replace",
+			merge: MergeReplace,
+			expected: Values{
+				"list": []interface{}{"b"},
+				"map": map[string]interface{}{
+					"bar": "baz",
+					"sub": map[string]interface{}{"b": 2},
+				},
+				"str": "bar",
+				"bin": []byte{0xfe},
+			},
+		},
+		{
+			name:  "deep",
+			merge: MergeDeep,
+			expected: Values{
+				"list": []interface{}{"b"},
+				"map": map[string]interface{}{
+					"foo": "bar",
+					"bar": "baz",
+					"sub": map[string]interface{}{"a": 1, "b": 2},
+				},
+				"str": "bar",
+				"bin": []byte{0xfe},
+			},
+		},
+		{
+			name:  "append",
+			merge: MergeAppend,
+			expected: Values{
+				"list": []interface{}{"a", "b"},
+				"map": map[string]interface{}{
+					"foo": "bar",
+					"bar": "baz",
+					"sub": map[string]interface{}{"a": 1, "b": 2},
+				},
+				"str": "bar",
+				"bin": []byte{0xfe},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var d = dst()
+			var m = d["map"]
+			testCase.merge(d, src)
+			require.Equal(t, testCase.expected, d)
+			// the merged maps are not modified
+			require.Equal(t, dst()["map"], m)
+		})
+	}
+}
+
+func TestStoreMerge(t *testing.T) {
+	var c = New(&Config{Merge: MergeAppend})
+
+	var l1 = &MapLoader{
+		name:   "l1",
+		values: Values{"hosts": []string{"a"}},
+	}
+	var l2 = &MapLoader{
+		name:   "l2",
+		values: Values{"hosts": []string{"b"}},
+	}
+	c.RegisterLoader(l1)
+	c.RegisterLoader(l2)
+
+	require.Nil(t, c.Load())
+	require.Equal(t, []string{"a", "b"}, c.Get("hosts"))
+
+	// reloading a loader merges over the values of the previous loaders again
+	require.Nil(t, c.ReloadLoader("l2"))
+	require.Equal(t, []string{"a", "b"}, c.Get("hosts"))
+
+	l2.values = Values{"hosts": []string{"c"}}
+	require.Nil(t, c.ReloadLoader("l2"))
+	require.Equal(t, []string{"a", "c"}, c.Get("hosts"))
+}
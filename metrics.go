@@ -1,12 +1,27 @@
 package konfig
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 var (
 	// MetricsConfigReload is the label for the prometheus counter for loader reload
 	MetricsConfigReload = "konfig_loader_reload"
 	// MetricsConfigReloadDuration is the label for the prometheus summary vector for loader reload duration
 	MetricsConfigReloadDuration = "konfig_loader_reload_duration"
+	// MetricsLoadAttempts is the label for the prometheus counter vector for loader load attempts, retries included
+	MetricsLoadAttempts = "konfig_loader_attempts_total"
+)
+
+var (
+	// ErrMetricsLabelMsg is the error message returned when a constant label of the MetricsOptions is invalid
+	ErrMetricsLabelMsg = "Err invalid metrics label name '%s'"
+	// ErrMetricsNameMsg is the error message returned when the namespace or the subsystem of the MetricsOptions make an invalid metric name
+	ErrMetricsNameMsg = "Err invalid metrics name '%s'"
 )
 
 const (
@@ -14,17 +29,57 @@ const (
 	metricsFailureLabel = "failure"
 )
 
+var (
+	metricsLabelRegexp = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+	metricsNameRegexp  = regexp.MustCompile("^[a-zA-Z_:][a-zA-Z0-9_:]*$")
+	// metricsLabels are the labels set by the store, they cannot be constant labels
+	metricsLabels = []string{"result", "store", "loader"}
+)
+
+// MetricsOptions are the options of the metrics of a store
+type MetricsOptions struct {
+	// Namespace is prepended to the names of the metrics, e.g. "myapp" names the reload counter "myapp_konfig_loader_reload"
+	Namespace string
+	// Subsystem is prepended to the names of the metrics after the Namespace
+	Subsystem string
+	// ConstLabels are labels with a fixed value added to all the metrics, e.g. {"env": "production"}
+	ConstLabels map[string]string
+}
+
+// validate returns an error if a constant label or the names of the metrics are invalid
+func (o MetricsOptions) validate() error {
+	for _, name := range []string{MetricsConfigReload, MetricsConfigReloadDuration, MetricsLoadAttempts} {
+		var fqName = prometheus.BuildFQName(o.Namespace, o.Subsystem, name)
+		if !metricsNameRegexp.MatchString(fqName) {
+			return fmt.Errorf(ErrMetricsNameMsg, fqName)
+		}
+	}
+	for l := range o.ConstLabels {
+		if !metricsLabelRegexp.MatchString(l) || strings.HasPrefix(l, "__") {
+			return fmt.Errorf(ErrMetricsLabelMsg, l)
+		}
+		for _, vl := range metricsLabels {
+			if l == vl {
+				return fmt.Errorf(ErrMetricsLabelMsg, l)
+			}
+		}
+	}
+	return nil
+}
+
 // LoaderMetrics is the structure holding the promtheus metrics objects
 type loaderMetrics struct {
 	configReloadSuccess  prometheus.Counter
 	configReloadFailure  prometheus.Counter
 	configReloadDuration prometheus.Observer
+	loadAttempts         prometheus.Counter
 }
 
 func (lw *loaderWatcher) setMetrics() {
 	var (
 		configReloadCounterVec         = lw.s.metrics[MetricsConfigReload].(*prometheus.CounterVec)
 		configReloadDurationSummaryVec = lw.s.metrics[MetricsConfigReloadDuration].(*prometheus.SummaryVec)
+		loadAttemptsCounterVec         = lw.s.metrics[MetricsLoadAttempts].(*prometheus.CounterVec)
 	)
 
 	lw.metrics = &loaderMetrics{
@@ -45,35 +100,93 @@ func (lw *loaderWatcher) setMetrics() {
 				lw.s.name,
 				lw.Name(),
 			),
+		loadAttempts: loadAttemptsCounterVec.
+			WithLabelValues(
+				lw.s.name,
+				lw.Name(),
+			),
 	}
 }
 
+// deleteMetrics removes the metrics of the loader wl
+func (c *store) deleteMetrics(wl *loaderWatcher) {
+	c.metrics[MetricsConfigReload].(*prometheus.CounterVec).DeleteLabelValues(metricsSuccessLabel, c.name, wl.Name())
+	c.metrics[MetricsConfigReload].(*prometheus.CounterVec).DeleteLabelValues(metricsFailureLabel, c.name, wl.Name())
+	c.metrics[MetricsConfigReloadDuration].(*prometheus.SummaryVec).DeleteLabelValues(c.name, wl.Name())
+	c.metrics[MetricsLoadAttempts].(*prometheus.CounterVec).DeleteLabelValues(c.name, wl.Name())
+}
+
+// initMetrics creates the metrics of the store, it panics if the MetricsOptions are invalid
 func (c *store) initMetrics() {
+	var o = c.cfg.MetricsOptions
+	if err := o.validate(); err != nil {
+		panic(err)
+	}
+
 	c.metrics = map[string]prometheus.Collector{
 		MetricsConfigReload: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: MetricsConfigReload,
-				Help: "Number of config loader reload",
+				Namespace:   o.Namespace,
+				Subsystem:   o.Subsystem,
+				Name:        MetricsConfigReload,
+				Help:        "Number of config loader reload",
+				ConstLabels: o.ConstLabels,
 			},
 			[]string{"result", "store", "loader"},
 		),
 		MetricsConfigReloadDuration: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
-				Name:       MetricsConfigReloadDuration,
-				Help:       "Histogram for the config reload duration",
-				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+				Namespace:   o.Namespace,
+				Subsystem:   o.Subsystem,
+				Name:        MetricsConfigReloadDuration,
+				Help:        "Histogram for the config reload duration",
+				Objectives:  map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+				ConstLabels: o.ConstLabels,
+			},
+			[]string{"store", "loader"},
+		),
+		MetricsLoadAttempts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   o.Namespace,
+				Subsystem:   o.Subsystem,
+				Name:        MetricsLoadAttempts,
+				Help:        "Number of config loader load attempts, retries included",
+				ConstLabels: o.ConstLabels,
 			},
 			[]string{"store", "loader"},
 		),
 	}
 }
 
+// registerMetrics registers the metrics with the registerer of the config.
+// If identical metrics are already registered, for example by another store, the registered metrics are used instead.
 func (c *store) registerMetrics() error {
-	for _, metric := range c.metrics {
-		var err = prometheus.Register(metric)
-		if err != nil && err != err.(prometheus.AlreadyRegisteredError) {
+	var r = c.cfg.MetricsRegisterer
+	if r == nil {
+		r = prometheus.DefaultRegisterer
+	}
+
+	var existing bool
+	for name, metric := range c.metrics {
+		var err = r.Register(metric)
+		if err == nil {
+			continue
+		}
+		var are, ok = err.(prometheus.AlreadyRegisteredError)
+		if !ok {
 			return err
 		}
+		if are.ExistingCollector != metric {
+			c.metrics[name] = are.ExistingCollector
+			existing = true
+		}
+	}
+
+	// the metrics of the loaders are recreated from the registered metrics
+	if existing {
+		for _, wl := range c.loaders() {
+			wl.setMetrics()
+		}
 	}
 	return nil
 }
@@ -1 +1,199 @@
 package konfig
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	t.Run(
+		"registers with the given registerer",
+		func(t *testing.T) {
+			var r = prometheus.NewRegistry()
+			var c = New(&Config{Name: "s1", Metrics: true, MetricsRegisterer: r, NoExitOnError: true})
+			c.RegisterLoader(&MapLoader{name: "l1", values: Values{"foo": "bar"}})
+
+			require.Nil(t, c.Watch())
+			require.Nil(t, c.Reload())
+
+			var mfs, err = r.Gather()
+			require.Nil(t, err)
+			require.Len(t, mfs, 3)
+			require.Equal(t, MetricsLoadAttempts, mfs[0].GetName())
+			require.Equal(t, MetricsConfigReload, mfs[1].GetName())
+		},
+	)
+
+	t.Run(
+		"stores share a registerer",
+		func(t *testing.T) {
+			var r = prometheus.NewRegistry()
+			var c1 = New(&Config{Name: "s1", Metrics: true, MetricsRegisterer: r, NoExitOnError: true})
+			c1.RegisterLoader(&MapLoader{name: "l1", values: Values{"foo": "bar"}})
+			var c2 = New(&Config{Name: "s2", Metrics: true, MetricsRegisterer: r, NoExitOnError: true})
+			c2.RegisterLoader(&MapLoader{name: "l2", values: Values{"foo": "bar"}})
+
+			require.Nil(t, c1.Watch())
+			require.Nil(t, c2.Watch())
+			require.Nil(t, c1.Reload())
+			require.Nil(t, c2.Reload())
+
+			var mfs, err = r.Gather()
+			require.Nil(t, err)
+
+			var stores = map[string]float64{}
+			for _, mf := range mfs {
+				if mf.GetName() != MetricsConfigReload {
+					continue
+				}
+				for _, m := range mf.GetMetric() {
+					var labels = map[string]string{}
+					for _, l := range m.GetLabel() {
+						labels[l.GetName()] = l.GetValue()
+					}
+					if labels["result"] == metricsSuccessLabel {
+						stores[labels["store"]] = m.GetCounter().GetValue()
+					}
+				}
+			}
+			require.Equal(t, map[string]float64{"s1": 1, "s2": 1}, stores)
+		},
+	)
+
+	t.Run(
+		"invalid registration",
+		func(t *testing.T) {
+			var r = prometheus.NewRegistry()
+			r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: MetricsConfigReload, Help: "other"}))
+
+			var c = New(&Config{Metrics: true, MetricsRegisterer: r, NoExitOnError: true})
+			c.RegisterLoader(&MapLoader{name: "l1", values: Values{"foo": "bar"}})
+			require.NotNil(t, c.Watch())
+		},
+	)
+}
+
+func TestMetricsOptions(t *testing.T) {
+	t.Run(
+		"namespace and constant labels",
+		func(t *testing.T) {
+			var r = prometheus.NewRegistry()
+			var c = New(&Config{
+				Metrics:           true,
+				MetricsRegisterer: r,
+				MetricsOptions: MetricsOptions{
+					Namespace:   "myapp",
+					Subsystem:   "config",
+					ConstLabels: map[string]string{"env": "production"},
+				},
+				NoExitOnError: true,
+			})
+			c.RegisterLoader(&MapLoader{name: "l1", values: Values{"foo": "bar"}})
+
+			require.Nil(t, c.Watch())
+			require.Nil(t, c.Reload())
+
+			var mfs, err = r.Gather()
+			require.Nil(t, err)
+			require.Len(t, mfs, 3)
+			require.Equal(t, "myapp_config_"+MetricsLoadAttempts, mfs[0].GetName())
+			require.Equal(t, "myapp_config_"+MetricsConfigReload, mfs[1].GetName())
+			require.Equal(t, "myapp_config_"+MetricsConfigReloadDuration, mfs[2].GetName())
+
+			for _, mf := range mfs {
+				for _, m := range mf.GetMetric() {
+					var labels = map[string]string{}
+					for _, l := range m.GetLabel() {
+						labels[l.GetName()] = l.GetValue()
+					}
+					require.Equal(t, "production", labels["env"])
+				}
+			}
+		},
+	)
+
+	var testCases = []struct {
+		name    string
+		options MetricsOptions
+		err     string
+	}{
+		{
+			name:    "invalid label",
+			options: MetricsOptions{ConstLabels: map[string]string{"my-env": "production"}},
+			err:     "Err invalid metrics label name 'my-env'",
+		},
+		{
+			name:    "reserved label",
+			options: MetricsOptions{ConstLabels: map[string]string{"__env": "production"}},
+			err:     "Err invalid metrics label name '__env'",
+		},
+		{
+			name:    "label of the store",
+			options: MetricsOptions{ConstLabels: map[string]string{"loader": "vault"}},
+			err:     "Err invalid metrics label name 'loader'",
+		},
+		{
+			name:    "invalid namespace",
+			options: MetricsOptions{Namespace: "my-app"},
+			err:     "Err invalid metrics name 'my-app_konfig_loader_reload'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var cfg = &Config{Metrics: true, MetricsOptions: testCase.options}
+
+			var _, err = NewE(cfg)
+			require.NotNil(t, err)
+			require.Equal(t, testCase.err, err.Error())
+
+			require.Panics(t, func() {
+				New(cfg)
+			})
+		})
+	}
+}
+
+type FlakyLoader struct {
+	MapLoader
+	failures int
+}
+
+func (f *FlakyLoader) Load(v Values) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("connection refused")
+	}
+	return f.MapLoader.Load(v)
+}
+
+func (f *FlakyLoader) MaxRetry() int {
+	return 3
+}
+
+func TestLoadAttemptsMetric(t *testing.T) {
+	var r = prometheus.NewRegistry()
+	var c = New(&Config{Name: "s1", Metrics: true, MetricsRegisterer: r, NoExitOnError: true})
+	c.RegisterLoader(&FlakyLoader{MapLoader: MapLoader{name: "vault", values: Values{"foo": "bar"}}, failures: 2})
+
+	require.Nil(t, c.LoadWatch())
+	require.Nil(t, c.Reload())
+
+	var mfs, err = r.Gather()
+	require.Nil(t, err)
+	require.Equal(t, MetricsLoadAttempts, mfs[0].GetName())
+	require.Len(t, mfs[0].GetMetric(), 1)
+
+	// 2 failures and a success on load, then a success on reload
+	var m = mfs[0].GetMetric()[0]
+	require.Equal(t, float64(4), m.GetCounter().GetValue())
+
+	var labels = map[string]string{}
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	require.Equal(t, map[string]string{"store": "s1", "loader": "vault"}, labels)
+}
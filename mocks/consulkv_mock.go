@@ -48,3 +48,35 @@ func (mr *MockConsulKVMockRecorder) Get(key, q interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockConsulKV)(nil).Get), key, q)
 }
+
+// Put mocks base method
+func (m *MockConsulKV) Put(p *api.KVPair, q *api.WriteOptions) (*api.WriteMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", p, q)
+	ret0, _ := ret[0].(*api.WriteMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Put indicates an expected call of Put
+func (mr *MockConsulKVMockRecorder) Put(p, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockConsulKV)(nil).Put), p, q)
+}
+
+// Txn mocks base method
+func (m *MockConsulKV) Txn(txn api.KVTxnOps, q *api.QueryOptions) (bool, *api.KVTxnResponse, *api.QueryMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Txn", txn, q)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(*api.KVTxnResponse)
+	ret2, _ := ret[2].(*api.QueryMeta)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// Txn indicates an expected call of Txn
+func (mr *MockConsulKVMockRecorder) Txn(txn, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Txn", reflect.TypeOf((*MockConsulKV)(nil).Txn), txn, q)
+}
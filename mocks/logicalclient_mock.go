@@ -77,3 +77,18 @@ func (mr *MockLogicalClientMockRecorder) ReadWithData(key, data interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWithData", reflect.TypeOf((*MockLogicalClient)(nil).ReadWithData), key, data)
 }
+
+// List mocks base method
+func (m *MockLogicalClient) List(key string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", key)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockLogicalClientMockRecorder) List(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLogicalClient)(nil).List), key)
+}
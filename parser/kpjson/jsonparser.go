@@ -2,6 +2,7 @@ package kpjson
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 
 	"github.com/lalamove/konfig"
@@ -9,18 +10,57 @@ import (
 	"github.com/lalamove/konfig/parser/kpmap"
 )
 
+// DefaultRootKey is the default key top level arrays are stored under
+const DefaultRootKey = "root"
+
+// ErrInvalidRoot is the error returned when the top level value of a JSON document is neither an object nor an array
+var ErrInvalidRoot = errors.New("Err JSON document must be an object or an array")
+
+// Config is the config of a JSON parser
+type Config struct {
+	// RootKey is the key a top level array is stored under, default is DefaultRootKey
+	RootKey string
+}
+
 // Parser parses the given json io.Reader and adds values in dot.path notation into the konfig.Store
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
-	// unmarshal the JSON into  map[string]interface{}
-	var dec = json.NewDecoder(r)
-
-	var d = make(map[string]interface{})
-	var err = dec.Decode(&d)
-	if err != nil {
-		return err
+var Parser = New(&Config{})
+
+// New creates a new JSON parser with the given config.
+// A top level object is flattened in dot.path notation, a top level array is stored as is under the RootKey.
+func New(cfg *Config) parser.Parser {
+	if cfg.RootKey == "" {
+		cfg.RootKey = DefaultRootKey
 	}
 
-	kpmap.PopFlatten(d, s)
+	return jsonParser{parser.Func(func(r io.Reader, s konfig.Values) error {
+		// unmarshal the JSON into  map[string]interface{} or []interface{}
+		var dec = json.NewDecoder(r)
+
+		var d interface{}
+		var err = dec.Decode(&d)
+		if err != nil {
+			return err
+		}
+
+		switch dt := d.(type) {
+		case map[string]interface{}:
+			kpmap.PopFlatten(dt, s)
+		case []interface{}:
+			s.Set(cfg.RootKey, dt)
+		case nil:
+			// null document, nothing to load
+		default:
+			return ErrInvalidRoot
+		}
+
+		return nil
+	})}
+}
+
+// jsonParser is a JSON parser.Parser which implements parser.Namer
+type jsonParser struct {
+	parser.Func
+}
 
-	return nil
-})
+// Name implements parser.Namer
+func (jsonParser) Name() string { return "json" }
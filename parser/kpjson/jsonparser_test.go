@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
 	"github.com/stretchr/testify/require"
 )
 
@@ -107,3 +108,58 @@ func TestParserErr(t *testing.T) {
 	)
 	require.NotNil(t, err)
 }
+
+func TestTopLevelArray(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		parser   parser.Parser
+		json     string
+		expected konfig.Values
+		err      error
+	}{
+		{
+			name:   "default root key",
+			parser: Parser,
+			json:   `[{"name":"svc1"},{"name":"svc2"}]`,
+			expected: konfig.Values{
+				"root": []interface{}{
+					map[string]interface{}{"name": "svc1"},
+					map[string]interface{}{"name": "svc2"},
+				},
+			},
+		},
+		{
+			name:   "custom root key",
+			parser: New(&Config{RootKey: "services"}),
+			json:   `["svc1","svc2"]`,
+			expected: konfig.Values{
+				"services": []interface{}{"svc1", "svc2"},
+			},
+		},
+		{
+			name:     "null document",
+			parser:   Parser,
+			json:     `null`,
+			expected: konfig.Values{},
+		},
+		{
+			name:   "scalar document",
+			parser: Parser,
+			json:   `"foo"`,
+			err:    ErrInvalidRoot,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var v = konfig.Values{}
+			var err = testCase.parser.Parse(strings.NewReader(testCase.json), v)
+			if testCase.err != nil {
+				require.Equal(t, testCase.err, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
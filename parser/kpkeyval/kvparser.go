@@ -46,6 +46,9 @@ func New(cfg *Config) *Parser {
 	}
 }
 
+// Name implements parser.Namer
+func (k *Parser) Name() string { return "keyval" }
+
 // Parse implement the fileloader.Parser interface
 func (k *Parser) Parse(r io.Reader, cfg konfig.Values) error {
 	var scanner = bufio.NewScanner(r)
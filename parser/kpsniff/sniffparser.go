@@ -0,0 +1,165 @@
+// Package kpsniff provides a parser selecting the parser of the data by sniffing its first bytes.
+package kpsniff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/lalamove/konfig/parser/kptoml"
+	"github.com/lalamove/konfig/parser/kpyaml"
+)
+
+var _ parser.Parser = (*Parser)(nil)
+
+const (
+	// FormatJSON is the format of JSON data
+	FormatJSON = "json"
+	// FormatYAML is the format of YAML data
+	FormatYAML = "yaml"
+	// FormatTOML is the format of TOML data
+	FormatTOML = "toml"
+
+	// DefaultSniffBytes is the default number of bytes inspected to select the parser
+	DefaultSniffBytes = 512
+)
+
+var (
+	// ErrNoDefault is the error thrown when trying to create a Parser with a default format without parser
+	ErrNoDefault = errors.New("no parser provided for the default format")
+	// ErrNoParserMsg is the error message returned when the sniffed format has no parser
+	ErrNoParserMsg = "Err no parser for format '%s'"
+)
+
+var (
+	tomlTable  = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_\-."' ]+\s*\]\]?\s*(#.*)?$`)
+	tomlKeyVal = regexp.MustCompile(`^[A-Za-z0-9_\-."']+\s*=`)
+	yamlKeyVal = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#:{}\[\],&*!|>%@` + "`" + `][^:#]*?)\s*:(\s|$)`)
+)
+
+// Config is the config of a sniffing Parser
+type Config struct {
+	// Parsers are the parsers by format, default are kpjson.Parser, kpyaml.Parser and kptoml.Parser
+	Parsers map[string]parser.Parser
+	// Default is the format used when the format of the data cannot be sniffed, default is FormatYAML
+	Default string
+	// SniffBytes is the number of bytes inspected, default is DefaultSniffBytes
+	SniffBytes int
+	// OnSelect is called with the format selected for each parse
+	OnSelect func(format string)
+}
+
+// Parser is a parser.Parser inspecting the first bytes of the data to select the parser of its format:
+// JSON, YAML or TOML. It falls back to the Default format if the format cannot be sniffed.
+type Parser struct {
+	cfg  *Config
+	mut  *sync.Mutex
+	last string
+}
+
+// New creates a new sniffing Parser from the given config, it panics if there is no parser for the default format
+func New(cfg *Config) *Parser {
+	if cfg.Parsers == nil {
+		cfg.Parsers = map[string]parser.Parser{
+			FormatJSON: kpjson.Parser,
+			FormatYAML: kpyaml.Parser,
+			FormatTOML: kptoml.Parser,
+		}
+	}
+	if cfg.Default == "" {
+		cfg.Default = FormatYAML
+	}
+	if _, ok := cfg.Parsers[cfg.Default]; !ok {
+		panic(ErrNoDefault)
+	}
+	if cfg.SniffBytes <= 0 {
+		cfg.SniffBytes = DefaultSniffBytes
+	}
+
+	return &Parser{
+		cfg: cfg,
+		mut: &sync.Mutex{},
+	}
+}
+
+// Name implements parser.Namer
+func (p *Parser) Name() string { return "sniff" }
+
+// Parse implements parser.Parser, it sniffs the format of the data read from r and parses it into s with the parser of the format
+func (p *Parser) Parse(r io.Reader, s konfig.Values) error {
+	var b = make([]byte, p.cfg.SniffBytes)
+	var n, err = io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	b = b[:n]
+
+	var format = Sniff(b)
+	if format == "" {
+		format = p.cfg.Default
+	}
+	var ps, ok = p.cfg.Parsers[format]
+	if !ok {
+		return fmt.Errorf(ErrNoParserMsg, format)
+	}
+
+	p.mut.Lock()
+	p.last = format
+	p.mut.Unlock()
+	if p.cfg.OnSelect != nil {
+		p.cfg.OnSelect(format)
+	}
+
+	return ps.Parse(io.MultiReader(bytes.NewReader(b), r), s)
+}
+
+// Format returns the format selected by the last parse, it returns an empty string if nothing was parsed
+func (p *Parser) Format() string {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.last
+}
+
+// Sniff returns the format of the data b: FormatJSON, FormatYAML or FormatTOML.
+// It inspects the first significant line, blank lines and comments are skipped.
+// It returns an empty string if the format cannot be sniffed.
+func Sniff(b []byte) string {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+
+	for len(b) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line, b = b[:i], b[i+1:]
+		} else {
+			line, b = b, nil
+		}
+		line = bytes.TrimSpace(line)
+
+		switch {
+		case len(line) == 0, line[0] == '#':
+			continue
+		case line[0] == '{':
+			return FormatJSON
+		case line[0] == '[':
+			if tomlTable.Match(line) {
+				return FormatTOML
+			}
+			return FormatJSON
+		case bytes.HasPrefix(line, []byte("---")), bytes.HasPrefix(line, []byte("%YAML")),
+			line[0] == '-' && (len(line) == 1 || line[1] == ' '):
+			return FormatYAML
+		case tomlKeyVal.Match(line):
+			return FormatTOML
+		case yamlKeyVal.Match(line):
+			return FormatYAML
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package kpsniff
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniff(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{name: "json object", data: "\n  {\"foo\": \"bar\"}", expected: FormatJSON},
+		{name: "json array", data: `[1, 2, 3]`, expected: FormatJSON},
+		{name: "json with bom", data: "\xef\xbb\xbf{}", expected: FormatJSON},
+		{name: "yaml map", data: "# comment\nfoo: bar\n", expected: FormatYAML},
+		{name: "yaml quoted key", data: `"foo bar": 1`, expected: FormatYAML},
+		{name: "yaml document", data: "---\nfoo: bar", expected: FormatYAML},
+		{name: "yaml list", data: "- foo\n- bar", expected: FormatYAML},
+		{name: "yaml directive", data: "%YAML 1.2\n---\nfoo: bar", expected: FormatYAML},
+		{name: "toml key value", data: "# comment\n\nfoo = \"bar:baz\"", expected: FormatTOML},
+		{name: "toml table", data: "[server]\nhost = \"localhost\"", expected: FormatTOML},
+		{name: "toml array of tables", data: "[[servers]] # comment\nhost = \"localhost\"", expected: FormatTOML},
+		{name: "empty", data: "\n# comment only\n", expected: ""},
+		{name: "unknown", data: "foo bar", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, Sniff([]byte(testCase.data)))
+		})
+	}
+}
+
+func TestParser(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		data     string
+		format   string
+		expected konfig.Values
+	}{
+		{
+			name:     "json",
+			data:     `{"foo": {"bar": "baz"}}`,
+			format:   FormatJSON,
+			expected: konfig.Values{"foo.bar": "baz"},
+		},
+		{
+			name:     "yaml",
+			data:     "foo:\n  bar: baz",
+			format:   FormatYAML,
+			expected: konfig.Values{"foo.bar": "baz"},
+		},
+		{
+			name:     "toml",
+			data:     "[foo]\nbar = \"baz\"",
+			format:   FormatTOML,
+			expected: konfig.Values{"foo.bar": "baz"},
+		},
+		{
+			name:     "data longer than the sniffed bytes",
+			data:     "foo = \"" + strings.Repeat("a", 2*DefaultSniffBytes) + "\"",
+			format:   FormatTOML,
+			expected: konfig.Values{"foo": strings.Repeat("a", 2*DefaultSniffBytes)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var selected string
+			var p = New(&Config{
+				OnSelect: func(format string) {
+					selected = format
+				},
+			})
+			require.Equal(t, "", p.Format())
+
+			var v = konfig.Values{}
+			require.Nil(t, p.Parse(strings.NewReader(testCase.data), v))
+			require.Equal(t, testCase.expected, v)
+			require.Equal(t, testCase.format, p.Format())
+			require.Equal(t, testCase.format, selected)
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	var called bool
+	var p = New(&Config{
+		Parsers: map[string]parser.Parser{
+			"keyval": parser.Func(func(r io.Reader, s konfig.Values) error {
+				called = true
+				return nil
+			}),
+		},
+		Default: "keyval",
+	})
+
+	require.Nil(t, p.Parse(strings.NewReader("foo bar"), konfig.Values{}))
+	require.True(t, called)
+	require.Equal(t, "keyval", p.Format())
+
+	// the sniffed format has no parser
+	var err = p.Parse(strings.NewReader(`{"foo": "bar"}`), konfig.Values{})
+	require.NotNil(t, err)
+	require.Equal(t, "Err no parser for format 'json'", err.Error())
+
+	require.PanicsWithValue(t, ErrNoDefault, func() {
+		New(&Config{Default: "ini"})
+	})
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read error") }
+
+func TestReadError(t *testing.T) {
+	var p = New(&Config{})
+	require.NotNil(t, p.Parse(errReader{}, konfig.Values{}))
+}
@@ -0,0 +1,177 @@
+// Package kpsops provides a parser wrapper decrypting files encrypted with Mozilla sops before parsing them.
+package kpsops
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+)
+
+var _ parser.Parser = (*Parser)(nil)
+
+const (
+	// FormatJSON is the sops format of JSON files
+	FormatJSON = "json"
+	// FormatYAML is the sops format of YAML files
+	FormatYAML = "yaml"
+	// FormatDotenv is the sops format of dotenv files
+	FormatDotenv = "dotenv"
+
+	// MetadataKey is the key under which sops stores its metadata in encrypted files
+	MetadataKey = "sops"
+
+	defaultCommand = "sops"
+)
+
+var (
+	// ErrNoParser is the error thrown when trying to create a Parser without a parser
+	ErrNoParser = errors.New("no parser provided")
+	// ErrNotEncrypted is the error returned when the data has no sops metadata and RequireEncrypted is set
+	ErrNotEncrypted = errors.New("Err data is not encrypted with sops")
+	// ErrFormatMsg is the error message thrown when trying to create a Parser with an unsupported format
+	ErrFormatMsg = "Err unsupported sops format '%s'"
+	// ErrDecryptMsg is the error message returned when sops fails to decrypt the data
+	ErrDecryptMsg = "Err decrypting sops data: %v"
+)
+
+// DecryptFunc decrypts sops encrypted data in the given format and returns the plaintext.
+// It has the signature of decrypt.Data from the sops library (go.mozilla.org/sops/v3/decrypt),
+// which can be used directly to decrypt in process.
+type DecryptFunc func(data []byte, format string) ([]byte, error)
+
+// Config is the config of a sops Parser
+type Config struct {
+	// Parser is the parser of the decrypted data, it must parse the Format
+	Parser parser.Parser
+	// Format is the format of the encrypted data: FormatJSON, FormatYAML or FormatDotenv, default is FormatYAML
+	Format string
+	// Decrypt decrypts the data, default is CommandDecrypt("sops", nil)
+	// which runs the sops binary with the key sources configured in the environment
+	Decrypt DecryptFunc
+	// RequireEncrypted tells wether data without sops metadata is rejected with ErrNotEncrypted.
+	// If false, such data is passed as is to the Parser.
+	RequireEncrypted bool
+}
+
+// Parser is a parser.Parser decrypting sops encrypted data before passing the plaintext to the configured parser
+type Parser struct {
+	cfg *Config
+}
+
+// New creates a new sops Parser from the given config
+func New(cfg *Config) *Parser {
+	if cfg.Parser == nil {
+		panic(ErrNoParser)
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatYAML
+	}
+	switch cfg.Format {
+	case FormatJSON, FormatYAML, FormatDotenv:
+	default:
+		panic(fmt.Errorf(ErrFormatMsg, cfg.Format))
+	}
+	if cfg.Decrypt == nil {
+		cfg.Decrypt = CommandDecrypt(defaultCommand, nil)
+	}
+
+	return &Parser{
+		cfg: cfg,
+	}
+}
+
+// Name implements parser.Namer, it is the name of the configured parser prefixed with sops (e.g. "sops+yaml")
+func (p *Parser) Name() string { return "sops+" + parser.Name(p.cfg.Parser) }
+
+// Parse implements parser.Parser, it decrypts the data read from r if it has sops metadata
+// and parses the plaintext with the configured parser into s
+func (p *Parser) Parse(r io.Reader, s konfig.Values) error {
+	var b, err = ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if !Encrypted(b, p.cfg.Format) {
+		if p.cfg.RequireEncrypted {
+			return ErrNotEncrypted
+		}
+		return p.cfg.Parser.Parse(bytes.NewReader(b), s)
+	}
+
+	pt, err := p.cfg.Decrypt(b, p.cfg.Format)
+	if err != nil {
+		return fmt.Errorf(ErrDecryptMsg, err)
+	}
+
+	return p.cfg.Parser.Parse(bytes.NewReader(pt), s)
+}
+
+// Encrypted tells wether the data in the given format contains sops metadata
+func Encrypted(b []byte, format string) bool {
+	switch format {
+	case FormatJSON:
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return false
+		}
+		_, ok := m[MetadataKey]
+		return ok
+	case FormatYAML:
+		// sops writes its metadata as a top level mapping
+		return hasLinePrefix(b, MetadataKey+":")
+	case FormatDotenv:
+		// sops writes its metadata as keys prefixed with "sops_"
+		return hasLinePrefix(b, MetadataKey+"_")
+	}
+	return false
+}
+
+func hasLinePrefix(b []byte, prefix string) bool {
+	var sc = bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), len(b)+1)
+	for sc.Scan() {
+		if strings.HasPrefix(sc.Text(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandDecrypt returns a DecryptFunc running the sops binary at path to decrypt the data.
+// env is added to the environment of the process, it is used to configure the key sources,
+// for example "SOPS_AGE_KEY_FILE=/etc/keys/age.txt", "AWS_PROFILE=config" or "VAULT_ADDR=https://vault:8200".
+func CommandDecrypt(path string, env []string) DecryptFunc {
+	return func(data []byte, format string) ([]byte, error) {
+		var cmd = exec.Command(
+			path,
+			"--decrypt",
+			"--input-type", format,
+			"--output-type", format,
+			"/dev/stdin",
+		)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdin = bytes.NewReader(data)
+
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("%v: %s", err, msg)
+			}
+			return nil, err
+		}
+		return stdout.Bytes(), nil
+	}
+}
@@ -0,0 +1,172 @@
+package kpsops
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser/kpjson"
+	"github.com/lalamove/konfig/parser/kpyaml"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptedYAML = `db:
+    password: ENC[AES256_GCM,data:4kA=,iv:a,tag:b,type:str]
+sops:
+    age:
+        - recipient: age1xyz
+    mac: ENC[AES256_GCM,data:c,iv:d,tag:e,type:str]
+    version: 3.7.3
+`
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() { New(&Config{}) })
+	require.Panics(t, func() { New(&Config{Parser: kpyaml.Parser, Format: "ini"}) })
+
+	var p = New(&Config{Parser: kpyaml.Parser})
+	require.Equal(t, FormatYAML, p.cfg.Format)
+	require.NotNil(t, p.cfg.Decrypt)
+}
+
+func TestEncrypted(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		data      string
+		format    string
+		encrypted bool
+	}{
+		{name: "yaml encrypted", data: encryptedYAML, format: FormatYAML, encrypted: true},
+		{name: "yaml plain", data: "db:\n    sops: foo\n", format: FormatYAML},
+		{name: "json encrypted", data: `{"db":"ENC[...]","sops":{"mac":"ENC[...]"}}`, format: FormatJSON, encrypted: true},
+		{name: "json nested sops key", data: `{"db":{"sops":"foo"}}`, format: FormatJSON},
+		{name: "json invalid", data: `{"sops"`, format: FormatJSON},
+		{name: "dotenv encrypted", data: "DB=ENC[...]\nsops_mac=ENC[...]\n", format: FormatDotenv, encrypted: true},
+		{name: "dotenv plain", data: "DB=foo\n", format: FormatDotenv},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.encrypted, Encrypted([]byte(testCase.data), testCase.format))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		data     string
+		cfg      *Config
+		decrypt  DecryptFunc
+		expected konfig.Values
+		err      error
+	}{
+		{
+			name: "encrypted yaml",
+			data: encryptedYAML,
+			cfg:  &Config{Parser: kpyaml.Parser},
+			decrypt: func(data []byte, format string) ([]byte, error) {
+				return []byte("db:\n    password: secret\n"), nil
+			},
+			expected: konfig.Values{"db.password": "secret"},
+		},
+		{
+			name: "encrypted json",
+			data: `{"db":{"password":"ENC[...]"},"sops":{"mac":"ENC[...]"}}`,
+			cfg:  &Config{Parser: kpjson.Parser, Format: FormatJSON},
+			decrypt: func(data []byte, format string) ([]byte, error) {
+				return []byte(`{"db":{"password":"secret"}}`), nil
+			},
+			expected: konfig.Values{"db.password": "secret"},
+		},
+		{
+			name: "plain data passed as is",
+			data: "db:\n    password: plain\n",
+			cfg:  &Config{Parser: kpyaml.Parser},
+			decrypt: func(data []byte, format string) ([]byte, error) {
+				return nil, errors.New("should not be called")
+			},
+			expected: konfig.Values{"db.password": "plain"},
+		},
+		{
+			name: "plain data rejected",
+			data: "db:\n    password: plain\n",
+			cfg:  &Config{Parser: kpyaml.Parser, RequireEncrypted: true},
+			decrypt: func(data []byte, format string) ([]byte, error) {
+				return nil, errors.New("should not be called")
+			},
+			expected: konfig.Values{},
+			err:      ErrNotEncrypted,
+		},
+		{
+			name: "decrypt error",
+			data: encryptedYAML,
+			cfg:  &Config{Parser: kpyaml.Parser},
+			decrypt: func(data []byte, format string) ([]byte, error) {
+				return nil, errors.New("no key")
+			},
+			expected: konfig.Values{},
+			err:      errors.New("Err decrypting sops data: no key"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var format string
+			testCase.cfg.Decrypt = func(data []byte, f string) ([]byte, error) {
+				format = f
+				require.Equal(t, testCase.data, string(data))
+				return testCase.decrypt(data, f)
+			}
+
+			var p = New(testCase.cfg)
+			var v = konfig.Values{}
+			var err = p.Parse(strings.NewReader(testCase.data), v)
+			if testCase.err != nil {
+				require.Equal(t, testCase.err, err)
+			} else {
+				require.Nil(t, err)
+			}
+			if format != "" {
+				require.Equal(t, testCase.cfg.Format, format)
+			}
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
+
+func TestCommandDecrypt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+
+	var dir, err = ioutil.TempDir("", "kpsops")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// fake sops binary printing its arguments and the key file from the environment
+	var bin = filepath.Join(dir, "sops")
+	require.Nil(t, ioutil.WriteFile(
+		bin,
+		[]byte("#!/bin/sh\nif [ -z \"$SOPS_AGE_KEY_FILE\" ]; then echo 'no key' >&2; exit 1; fi\necho \"key: $SOPS_AGE_KEY_FILE\"\necho \"args: $*\"\n"),
+		0700,
+	))
+
+	var b []byte
+	b, err = CommandDecrypt(bin, []string{"SOPS_AGE_KEY_FILE=/keys/age.txt"})([]byte(encryptedYAML), FormatYAML)
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		"key: /keys/age.txt\nargs: --decrypt --input-type yaml --output-type yaml /dev/stdin\n",
+		string(b),
+	)
+
+	os.Unsetenv("SOPS_AGE_KEY_FILE")
+	_, err = CommandDecrypt(bin, nil)([]byte(encryptedYAML), FormatYAML)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "no key")
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // Parser parses the given json io.Reader and adds values in dot.path notation into the konfig.Store
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
+var Parser parser.Parser = tomlParser{parser.Func(func(r io.Reader, s konfig.Values) error {
 	// unmarshal the JSON into  map[string]interface{}
 	var d = make(map[string]interface{})
 	var _, err = toml.DecodeReader(r, &d)
@@ -21,4 +21,12 @@ var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
 	kpmap.PopFlatten(d, s)
 
 	return nil
-})
+})}
+
+// tomlParser is a TOML parser.Parser which implements parser.Namer
+type tomlParser struct {
+	parser.Func
+}
+
+// Name implements parser.Namer
+func (tomlParser) Name() string { return "toml" }
@@ -0,0 +1,177 @@
+package kpyaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var _ parser.Updater = yamlParser{}
+
+// defaultIndent is the indentation of updated documents when it cannot be detected
+const defaultIndent = 2
+
+// ErrNotMapping is the error returned when updating a key whose parent is not a mapping in the document
+var ErrNotMapping = errors.New("Err YAML parent of the key is not a mapping")
+
+// Update reads the YAML document from r, sets the values v in place and writes the updated document to w.
+// Keys of v are in dot.path notation like the keys set by the parser. The comments, the key order and the structure of the document are kept,
+// only the values of the keys of v are replaced. Keys which are not in the document are appended to their parent mapping,
+// the missing parent mappings are created.
+// If the stream contains multiple documents, a key is updated in the last document containing it, or added to the last document.
+// The indentation of the document is kept, other formatting (e.g. blank lines) may be normalized.
+func Update(r io.Reader, w io.Writer, v konfig.Values) error {
+	var b, err = ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var docs []*yamlv3.Node
+	var dec = yamlv3.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &yamlv3.Node{Kind: yamlv3.DocumentNode})
+	}
+
+	// keys are updated in a stable order so that added keys are appended sorted
+	var keys = make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var nv yamlv3.Node
+		if err := nv.Encode(v[k]); err != nil {
+			return err
+		}
+
+		var updated bool
+		for i := len(docs) - 1; i >= 0 && !updated; i-- {
+			if node := lookup(root(docs[i]), k); node != nil {
+				replace(node, &nv)
+				updated = true
+			}
+		}
+		if !updated {
+			if err := insert(root(docs[len(docs)-1]), k, &nv); err != nil {
+				return err
+			}
+		}
+	}
+
+	var enc = yamlv3.NewEncoder(w)
+	enc.SetIndent(indent(b))
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// root returns the root mapping of the document, it creates it if the document is empty
+func root(doc *yamlv3.Node) *yamlv3.Node {
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"})
+	}
+	return doc.Content[0]
+}
+
+// lookup returns the value node of the key k in dot.path notation in the mapping m, it returns nil if it is not found.
+// Mapping keys containing dots are matched as a whole.
+func lookup(m *yamlv3.Node, k string) *yamlv3.Node {
+	if m.Kind == yamlv3.AliasNode {
+		m = m.Alias
+	}
+	if m.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		var key, value = m.Content[i].Value, m.Content[i+1]
+		if key == k {
+			return value
+		}
+		if strings.HasPrefix(k, key+konfig.KeySep) {
+			if node := lookup(value, k[len(key)+len(konfig.KeySep):]); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// insert appends the key k in dot.path notation with the value nv to the mapping m, creating the missing parent mappings
+func insert(m *yamlv3.Node, k string, nv *yamlv3.Node) error {
+	var segments = strings.Split(k, konfig.KeySep)
+	for _, s := range segments[:len(segments)-1] {
+		var child *yamlv3.Node
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == s {
+				child = m.Content[i+1]
+				break
+			}
+		}
+		if child == nil {
+			child = &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, scalar(s), child)
+		}
+		if child.Kind == yamlv3.AliasNode {
+			child = child.Alias
+		}
+		if child.Kind != yamlv3.MappingNode {
+			return ErrNotMapping
+		}
+		m = child
+	}
+	m.Content = append(m.Content, scalar(segments[len(segments)-1]), nv)
+	return nil
+}
+
+// replace replaces the value of the node with the value of nv, keeping the comments, the anchor and the style of the node if the kind of the value is unchanged
+func replace(node, nv *yamlv3.Node) {
+	if node.Kind == yamlv3.ScalarNode && nv.Kind == yamlv3.ScalarNode && node.ShortTag() == nv.ShortTag() {
+		node.Value = nv.Value
+		return
+	}
+
+	var head, line, foot, anchor = node.HeadComment, node.LineComment, node.FootComment, node.Anchor
+	// flow sequences and mappings stay flow
+	var style = nv.Style
+	if node.Kind == nv.Kind && node.Kind != yamlv3.ScalarNode {
+		style = node.Style
+	}
+	*node = *nv
+	node.HeadComment, node.LineComment, node.FootComment, node.Anchor, node.Style = head, line, foot, anchor, style
+}
+
+func scalar(s string) *yamlv3.Node {
+	return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// indent returns the indentation of the first indented line of the document b, or defaultIndent
+func indent(b []byte) int {
+	for _, line := range strings.Split(string(b), "\n") {
+		var trimmed = strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return n
+		}
+	}
+	return defaultIndent
+}
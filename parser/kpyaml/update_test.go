@@ -0,0 +1,126 @@
+package kpyaml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		doc      string
+		values   konfig.Values
+		expected string
+		err      bool
+	}{
+		{
+			name: "updates values in place",
+			doc: `# the server
+server:
+  # the host
+  host: localhost # local
+  port: 8080
+name: "app"
+`,
+			values: konfig.Values{"server.host": "remote", "name": "other"},
+			expected: `# the server
+server:
+  # the host
+  host: remote # local
+  port: 8080
+name: "other"
+`,
+		},
+		{
+			name: "replaces a value of another type",
+			doc: `list: [1, 2] # numbers
+port: "8080"
+`,
+			values: konfig.Values{"list": []interface{}{3}, "port": 9090},
+			expected: `list: [3] # numbers
+port: 9090
+`,
+		},
+		{
+			name: "adds missing keys",
+			doc: `server:
+    host: localhost
+`,
+			values: konfig.Values{"server.port": 8080, "db.name": "app"},
+			expected: `server:
+    host: localhost
+    port: 8080
+db:
+    name: app
+`,
+		},
+		{
+			name: "keys with dots",
+			doc: `labels:
+  app.kubernetes.io/name: app
+`,
+			values: konfig.Values{"labels.app.kubernetes.io/name": "other"},
+			expected: `labels:
+  app.kubernetes.io/name: other
+`,
+		},
+		{
+			name:     "empty document",
+			doc:      ``,
+			values:   konfig.Values{"foo": "bar"},
+			expected: "foo: bar\n",
+		},
+		{
+			name: "multiple documents",
+			doc: `foo: bar
+---
+foo: baz
+bar: foo
+`,
+			values: konfig.Values{"foo": "qux"},
+			expected: `foo: bar
+---
+foo: qux
+bar: foo
+`,
+		},
+		{
+			name:   "parent is not a mapping",
+			doc:    "foo: bar\n",
+			values: konfig.Values{"foo.bar": "baz"},
+			err:    true,
+		},
+		{
+			name:   "invalid document",
+			doc:    "foo: [bar",
+			values: konfig.Values{"foo": "baz"},
+			err:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var b bytes.Buffer
+			var err = Parser.(parser.Updater).Update(strings.NewReader(testCase.doc), &b, testCase.values)
+			if testCase.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, b.String())
+
+			// the updated document parses to the updated values
+			var v = konfig.Values{}
+			require.Nil(t, Parser.Parse(&b, v))
+			for k, vv := range testCase.values {
+				require.Contains(t, v, k)
+				require.EqualValues(t, vv, v[k])
+			}
+		})
+	}
+}
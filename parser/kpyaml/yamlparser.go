@@ -1,25 +1,177 @@
 package kpyaml
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"io/ioutil"
 
 	"github.com/lalamove/konfig"
 	"github.com/lalamove/konfig/parser"
 	"github.com/lalamove/konfig/parser/kpmap"
 	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
-// Parser is the YAML Parser it implements parser.Parser
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
-	var dec = yaml.NewDecoder(r)
+const (
+	// DefaultMaxDepth is the default maximum depth of a YAML document once anchors are resolved
+	DefaultMaxDepth = 64
+	// DefaultMaxNodes is the default maximum number of nodes of a YAML document once anchors are resolved
+	DefaultMaxNodes = 1000000
+)
+
+var (
+	// ErrMaxDepth is the error returned when a YAML document is deeper than the maximum depth once anchors are resolved
+	ErrMaxDepth = errors.New("Err YAML document exceeds the maximum depth")
+	// ErrMaxNodes is the error returned when a YAML document has more nodes than the maximum number of nodes once anchors are resolved
+	ErrMaxNodes = errors.New("Err YAML document exceeds the maximum number of nodes")
+)
+
+// Config is the config of a YAML parser
+type Config struct {
+	// MaxDepth is the maximum depth of a document once anchors and aliases are resolved, default is DefaultMaxDepth
+	MaxDepth int
+	// MaxNodes is the maximum number of maps, sequences and scalars of a document once anchors and aliases are resolved,
+	// default is DefaultMaxNodes. It guards against billion-laughs-style anchor expansion.
+	MaxNodes int
+}
+
+// Parser is the YAML Parser it implements parser.Parser and parser.Updater, it uses the default limits.
+var Parser = New(&Config{})
+
+// New creates a new YAML parser with the given config.
+// Anchors and aliases are fully resolved into concrete values before documents are flattened.
+// If the stream contains multiple documents separated by "---", they are merged in order,
+// keys of later documents override keys of earlier documents.
+// The returned parser also implements parser.Updater, see Update.
+func New(cfg *Config) parser.Parser {
+	if cfg.MaxDepth == 0 {
+		cfg.MaxDepth = DefaultMaxDepth
+	}
+	if cfg.MaxNodes == 0 {
+		cfg.MaxNodes = DefaultMaxNodes
+	}
+
+	return yamlParser{parser.Func(func(r io.Reader, s konfig.Values) error {
+		var b, err = ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		// the limits are checked on the nodes of the documents before their aliases are expanded by the decoder
+		if err := checkLimits(b, cfg); err != nil {
+			return err
+		}
+
+		var dec = yaml.NewDecoder(bytes.NewReader(b))
+		for i := 0; ; i++ {
+			var d = make(map[string]interface{})
+			var err = dec.Decode(&d)
+			if err == io.EOF && i > 0 {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			kpmap.PopFlatten(d, s)
+		}
+	})}
+}
+
+// yamlParser is a YAML parser.Parser which implements parser.Updater and parser.Namer
+type yamlParser struct {
+	parser.Func
+}
 
-	var d = make(map[string]interface{})
-	var err = dec.Decode(&d)
-	if err != nil {
-		return err
+// Name implements parser.Namer
+func (yamlParser) Name() string { return "yaml" }
+
+// Update implements parser.Updater, see Update
+func (yamlParser) Update(r io.Reader, w io.Writer, v konfig.Values) error {
+	return Update(r, w, v)
+}
+
+// checkLimits parses the documents of b into nodes and returns an error if a document exceeds a limit once its aliases are resolved.
+// The size of the anchored nodes is computed once, so that the nodes an alias expands to are counted without expanding them.
+// If a document cannot be parsed, the documents left are not checked and the error is left to the decoder.
+func checkLimits(b []byte, cfg *Config) error {
+	var dec = yamlv3.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); err != nil {
+			return nil
+		}
+
+		var l = limits{cfg: cfg, sizes: make(map[*yamlv3.Node]nodeSize)}
+		for _, n := range doc.Content {
+			var size, err = l.size(n)
+			if err != nil {
+				return err
+			}
+			if size.nodes > cfg.MaxNodes {
+				return ErrMaxNodes
+			}
+		}
 	}
+}
+
+// nodeSize is the number of nodes and the depth of a node once its aliases are resolved
+type nodeSize struct {
+	nodes int
+	depth int
+}
 
-	kpmap.PopFlatten(d, s)
+type limits struct {
+	cfg   *Config
+	sizes map[*yamlv3.Node]nodeSize
+	// visiting are the nodes being sized, an alias to one of them is recursive
+	visiting map[*yamlv3.Node]bool
+}
+
+// size returns the size of the node n, maps, sequences and scalars are counted, the keys of maps are not
+func (l *limits) size(n *yamlv3.Node) (nodeSize, error) {
+	if n.Kind == yamlv3.AliasNode {
+		n = n.Alias
+	}
+	if size, ok := l.sizes[n]; ok {
+		return size, nil
+	}
+	if l.visiting[n] {
+		return nodeSize{}, ErrMaxDepth
+	}
+	if l.visiting == nil {
+		l.visiting = make(map[*yamlv3.Node]bool)
+	}
+	l.visiting[n] = true
+	defer delete(l.visiting, n)
+
+	var children = n.Content
+	if n.Kind == yamlv3.MappingNode {
+		children = make([]*yamlv3.Node, 0, len(n.Content)/2)
+		for i := 1; i < len(n.Content); i += 2 {
+			children = append(children, n.Content[i])
+		}
+	}
+
+	var size = nodeSize{nodes: 1}
+	for _, c := range children {
+		var cs, err = l.size(c)
+		if err != nil {
+			return nodeSize{}, err
+		}
+		size.nodes += cs.nodes
+		if size.nodes > l.cfg.MaxNodes {
+			return nodeSize{}, ErrMaxNodes
+		}
+		if cs.depth+1 > size.depth {
+			size.depth = cs.depth + 1
+		}
+		if size.depth > l.cfg.MaxDepth {
+			return nodeSize{}, ErrMaxDepth
+		}
+	}
 
-	return nil
-})
+	l.sizes[n] = size
+	return size, nil
+}
@@ -108,6 +108,118 @@ bar:
 	}
 }
 
+func TestYAMLParserMultiDoc(t *testing.T) {
+	var v = konfig.Values{}
+	var err = Parser.Parse(
+		strings.NewReader(`foo: bar
+nested:
+  a: 1
+  b: 2
+---
+nested:
+  b: 3
+  c: 4
+---
+list:
+- 1
+- 2
+`),
+		v,
+	)
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		konfig.Values{
+			"foo":      "bar",
+			"nested.a": 1,
+			"nested.b": 3,
+			"nested.c": 4,
+			"list":     []interface{}{1, 2},
+		},
+		v,
+	)
+
+	err = Parser.Parse(
+		strings.NewReader("foo: bar\n---\ninvalid"),
+		konfig.Values{},
+	)
+	require.NotNil(t, err)
+}
+
+func TestYAMLParserAnchors(t *testing.T) {
+	var v = konfig.Values{}
+	var err = Parser.Parse(
+		strings.NewReader(`defaults: &defaults
+  timeout: 5
+  hosts: &hosts
+  - a
+  - b
+db:
+  <<: *defaults
+  name: db
+cache:
+  hosts: *hosts
+  settings: *defaults
+`),
+		v,
+	)
+	require.Nil(t, err)
+	require.Equal(t, 5, v["db.timeout"])
+	require.Equal(t, []interface{}{"a", "b"}, v["db.hosts"])
+	require.Equal(t, "db", v["db.name"])
+	require.Equal(t, []interface{}{"a", "b"}, v["cache.hosts"])
+	require.Equal(t, 5, v["cache.settings.timeout"])
+}
+
+func TestYAMLParserLimits(t *testing.T) {
+	var err = New(&Config{MaxNodes: 10}).Parse(
+		strings.NewReader(`a: &a ["lol","lol","lol"]
+b: &b [*a,*a,*a]
+c: [*b,*b,*b]
+`),
+		konfig.Values{},
+	)
+	require.Equal(t, ErrMaxNodes, err)
+
+	err = New(&Config{MaxDepth: 2}).Parse(
+		strings.NewReader("a:\n  b:\n    c:\n      d: 1"),
+		konfig.Values{},
+	)
+	require.Equal(t, ErrMaxDepth, err)
+
+	// billion laughs
+	err = Parser.Parse(
+		strings.NewReader(`a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`),
+		konfig.Values{},
+	)
+	// the limit is reached before the aliases are expanded
+	require.Equal(t, ErrMaxNodes, err)
+
+	// aliases are counted in depth
+	err = New(&Config{MaxDepth: 2}).Parse(
+		strings.NewReader("a: &a\n  b: 1\nc:\n  d: *a\n"),
+		konfig.Values{},
+	)
+	require.Equal(t, ErrMaxDepth, err)
+
+	var v = konfig.Values{}
+	err = New(&Config{MaxDepth: 3, MaxNodes: 6}).Parse(
+		strings.NewReader("a: &a\n  b: 1\nc:\n  d: *a\n"),
+		v,
+	)
+	require.Nil(t, err)
+	require.Equal(t, konfig.Values{"a.b": 1, "c.d.b": 1}, v)
+}
+
 func TestParserErr(t *testing.T) {
 	var err = Parser.Parse(
 		strings.NewReader(
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"errors"
+	"io"
+)
+
+// DefaultMaxBytes is the default maximum number of bytes loaders read from a source, 10MB
+const DefaultMaxBytes int64 = 10 << 20
+
+// ErrMaxBytes is the error returned when reading more than the maximum number of bytes from a source
+var ErrMaxBytes = errors.New("Err source exceeds the maximum number of bytes")
+
+// MaxBytesReader returns a reader reading from r which returns ErrMaxBytes if r contains more than n bytes.
+// If n is negative, r is returned as is.
+func MaxBytesReader(r io.Reader, n int64) io.Reader {
+	if n < 0 {
+		return r
+	}
+	return &maxBytesReader{r: r, n: n}
+}
+
+type maxBytesReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *maxBytesReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// we read one more byte than allowed to know if the limit is exceeded
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	var n, err = l.r.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		l.err = err
+		return n, err
+	}
+
+	n = int(l.n)
+	l.n = 0
+	l.err = ErrMaxBytes
+	return n, l.err
+}
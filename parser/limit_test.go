@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBytesReader(t *testing.T) {
+	var testCases = []struct {
+		name string
+		data string
+		n    int64
+		err  error
+	}{
+		{
+			name: "under the limit",
+			data: "foo=bar",
+			n:    10,
+		},
+		{
+			name: "exactly the limit",
+			data: "foo=bar",
+			n:    7,
+		},
+		{
+			name: "over the limit",
+			data: "foo=bar",
+			n:    6,
+			err:  ErrMaxBytes,
+		},
+		{
+			name: "no limit",
+			data: "foo=bar",
+			n:    -1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var b, err = ioutil.ReadAll(MaxBytesReader(strings.NewReader(testCase.data), testCase.n))
+			require.Equal(t, testCase.err, err)
+			if err == nil {
+				require.Equal(t, testCase.data, string(b))
+			}
+		})
+	}
+}
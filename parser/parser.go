@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/lalamove/konfig"
@@ -13,6 +14,27 @@ type Parser interface {
 	Parse(io.Reader, konfig.Values) error
 }
 
+// Updater is implemented by parsers able to write values back to a document of their format.
+// Update reads the document from r, sets the values v in place and writes the updated document to w,
+// keeping the structure, the comments and the key order of the document.
+type Updater interface {
+	Update(r io.Reader, w io.Writer, v konfig.Values) error
+}
+
+// Namer is implemented by parsers having a name, it is used by Name
+type Namer interface {
+	Name() string
+}
+
+// Name returns the name of the parser p if it implements Namer (e.g. "json" for kpjson.Parser), else its Go type.
+// Loaders use it to implement konfig.ParserLoader.
+func Name(p Parser) string {
+	if n, ok := p.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
 // Func is a function implementing the Parser interface
 type Func func(io.Reader, konfig.Values) error
 
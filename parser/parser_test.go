@@ -17,3 +17,15 @@ func TestParserFunc(t *testing.T) {
 	f.Parse(nil, nil)
 	require.Equal(t, true, ran)
 }
+
+type namedParser struct {
+	Func
+}
+
+func (namedParser) Name() string { return "named" }
+
+func TestName(t *testing.T) {
+	var f = Func(func(r io.Reader, s konfig.Values) error { return nil })
+	require.Equal(t, "named", Name(namedParser{f}))
+	require.Equal(t, "parser.Func", Name(f))
+}
@@ -0,0 +1,222 @@
+package konfig
+
+import (
+	"fmt"
+	"sort"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+var (
+	// ErrNoOwnerMsg is the error message returned by Persist when no loader is responsible for the key
+	ErrNoOwnerMsg = "Err no loader responsible for key '%s'"
+	// ErrNotWritableMsg is the error message returned when a loader cannot write the key to its source
+	ErrNotWritableMsg = "Err loader '%s' cannot write key '%s'"
+)
+
+// Writer is an optional interface a Loader can implement to write values back to its source.
+// A Writer which cannot write a key, for example because the key is parsed from a larger document, returns an error built with ErrNotWritableMsg.
+type Writer interface {
+	// Set writes the value v of the key k of the store to the source of the loader
+	Set(k string, v interface{}) error
+}
+
+// BatchWriter is an optional interface a Writer can implement to write several values at once.
+// Backends supporting transactions (e.g. Consul and etcd) write all the values or none of them.
+type BatchWriter interface {
+	// SetMany writes the values v, keyed by keys of the store, to the source of the loader
+	SetMany(v Values) error
+}
+
+// Persist writes the value of the key k to the source of the loader responsible for it in the global store.
+// See Store.Persist.
+func Persist(k string, v interface{}) error {
+	return instance().Persist(k, v)
+}
+
+// Persist writes the value v of the key k to the source of the loader responsible for the key (see Source) and sets it in the store.
+// The loader must implement Writer, else an error built with ErrNotWritableMsg is returned.
+// The write is serialized with the loads of the loader. Once written, the value is set as a value of the loader,
+// keeping the precedence of the loaders and the provenance of the key, until it is replaced by the value loaded from the source on the next load.
+// If the write fails, the store is left untouched.
+// As it writes the store and waits for the load of the loader, it must not be called from hooks or WatchField callbacks, it would deadlock.
+func (c *store) Persist(k string, v interface{}) error {
+	c.stateMut.RLock()
+	var wl = c.owner(k)
+	c.stateMut.RUnlock()
+
+	if wl == nil {
+		return fmt.Errorf(ErrNoOwnerMsg, k)
+	}
+	var w, ok = loaderWriter(wl.Loader)
+	if !ok {
+		return fmt.Errorf(ErrNotWritableMsg, wl.Name(), k)
+	}
+
+	wl.loadMut.Lock()
+	defer wl.loadMut.Unlock()
+
+	if wl.unregistered {
+		return ErrLoaderNotFound
+	}
+
+	if err := w.Set(k, v); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+		return err
+	}
+
+	return c.setPersisted(wl, Values{k: v})
+}
+
+// PersistMany writes the values to the sources of the loaders responsible for their keys in the global store.
+// See Store.PersistMany.
+func PersistMany(values map[string]interface{}) error {
+	return instance().PersistMany(values)
+}
+
+// PersistMany writes the values to the sources of the loaders responsible for their keys and sets them in the store, like Persist.
+// Keys are grouped by loader, nothing is written if a key has no loader or its loader does not implement Writer.
+// The values of a loader implementing BatchWriter are written with a single SetMany, atomically if its backend supports transactions,
+// the values of other loaders are written key by key.
+// If a write fails, the values already written are rolled back on a best-effort basis by writing back the previous values of the loaders,
+// keys the loaders did not load before cannot be removed. Writes across several loaders are never atomic.
+// The returned error aggregates the error of the write and the errors of the rollback, the store is updated only if all the writes succeed.
+// Like Persist, it must not be called from hooks or WatchField callbacks.
+func (c *store) PersistMany(values map[string]interface{}) error {
+	var keys = make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c.stateMut.RLock()
+	var groups = make(map[*loaderWatcher]Values)
+	for _, k := range keys {
+		var wl = c.owner(k)
+		if wl == nil {
+			c.stateMut.RUnlock()
+			return fmt.Errorf(ErrNoOwnerMsg, k)
+		}
+		if _, ok := loaderWriter(wl.Loader); !ok {
+			c.stateMut.RUnlock()
+			return fmt.Errorf(ErrNotWritableMsg, wl.Name(), k)
+		}
+		if groups[wl] == nil {
+			groups[wl] = make(Values)
+		}
+		groups[wl][k] = values[k]
+	}
+	// loaders are locked in registration order
+	var wls = make([]*loaderWatcher, 0, len(groups))
+	for _, wl := range c.WatcherLoaders {
+		if _, ok := groups[wl]; ok {
+			wls = append(wls, wl)
+		}
+	}
+	c.stateMut.RUnlock()
+
+	for _, wl := range wls {
+		wl.loadMut.Lock()
+		defer wl.loadMut.Unlock()
+
+		if wl.unregistered {
+			return ErrLoaderNotFound
+		}
+	}
+
+	var written = make([]Values, 0, len(wls))
+	for _, wl := range wls {
+		var w, _ = loaderWriter(wl.Loader)
+		var wx, err = writeValues(w, groups[wl])
+		written = append(written, wx)
+		if err == nil {
+			continue
+		}
+		c.cfg.Logger.Get().Error(err.Error())
+
+		// we roll back what was written, the failing loader included
+		var multiErr *multierror.Error
+		for j, x := range written {
+			if rerr := c.rollback(wls[j], x); rerr != nil {
+				c.cfg.Logger.Get().Error(rerr.Error())
+				multiErr = multierror.Append(multiErr, rerr)
+			}
+		}
+		if multiErr != nil {
+			return multierror.Append(err, multiErr.Errors...)
+		}
+		return err
+	}
+
+	for _, wl := range wls {
+		if err := c.setPersisted(wl, groups[wl]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeValues writes the values x with the writer w and returns the values written
+func writeValues(w Writer, x Values) (Values, error) {
+	if bw, ok := w.(BatchWriter); ok {
+		if err := bw.SetMany(x); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+
+	var keys = make([]string, 0, len(x))
+	for k := range x {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var wx = make(Values, len(x))
+	for _, k := range keys {
+		if err := w.Set(k, x[k]); err != nil {
+			return wx, err
+		}
+		wx[k] = x[k]
+	}
+	return wx, nil
+}
+
+// rollback writes back the previous values of the keys of x written by the loader wl
+func (c *store) rollback(wl *loaderWatcher, x Values) error {
+	var prev = make(Values, len(x))
+	for k := range x {
+		if v, ok := wl.values[k]; ok {
+			prev[k] = v
+		}
+	}
+	if len(prev) == 0 {
+		return nil
+	}
+
+	var w, _ = loaderWriter(wl.Loader)
+	var _, err = writeValues(w, prev)
+	return err
+}
+
+// setPersisted sets the values x written by the loader wl in the store, wl.loadMut must be locked
+func (c *store) setPersisted(wl *loaderWatcher, x Values) error {
+	var nx = make(Values, len(wl.values)+len(x))
+	for k, v := range wl.values {
+		nx[k] = v
+	}
+	for k, v := range x {
+		nx[k] = v
+	}
+
+	return nx.loadLoader(wl, wl.values, c)
+}
+
+func loaderWriter(l Loader) (Writer, bool) {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return loaderWriter(lt.Loader)
+	case Writer:
+		return lt, true
+	}
+	return nil, false
+}
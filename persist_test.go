@@ -0,0 +1,161 @@
+package konfig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type WriterLoader struct {
+	MapLoader
+	err error
+}
+
+func (w *WriterLoader) Set(k string, v interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.values[k] = v
+	return nil
+}
+
+func TestPersist(t *testing.T) {
+	t.Run(
+		"writes to the owning loader",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var w = &WriterLoader{MapLoader: MapLoader{name: "consul", values: Values{"max_conns": 10}}}
+			RegisterLoader(&MapLoader{name: "file", values: Values{"max_conns": 5, "host": "localhost"}})
+			RegisterLoader(w)
+			require.Nil(t, Load())
+
+			require.Nil(t, Persist("max_conns", 20))
+			require.Equal(t, 20, w.values["max_conns"])
+			require.Equal(t, 20, Get("max_conns"))
+			require.Equal(t, "consul", Source("max_conns"))
+
+			// the value is the value of the loader, it is kept on reload
+			require.Nil(t, ReloadLoader("file"))
+			require.Equal(t, 20, Get("max_conns"))
+			require.Nil(t, Reload())
+			require.Equal(t, 20, Get("max_conns"))
+		},
+	)
+
+	t.Run(
+		"errors",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var w = &WriterLoader{MapLoader: MapLoader{name: "consul", values: Values{"max_conns": 10}}}
+			RegisterLoader(&MapLoader{name: "file", values: Values{"host": "localhost"}})
+			RegisterLoader(w)
+			require.Nil(t, Load())
+
+			require.Equal(t, fmt.Errorf(ErrNoOwnerMsg, "foo"), Persist("foo", "bar"))
+			require.Equal(t, fmt.Errorf(ErrNotWritableMsg, "file", "host"), Persist("host", "remote"))
+
+			Set("bar", "baz")
+			require.Equal(t, fmt.Errorf(ErrNoOwnerMsg, "bar"), Persist("bar", "qux"))
+
+			// a failed write leaves the store untouched
+			w.err = errors.New("connection refused")
+			require.Equal(t, w.err, Persist("max_conns", 20))
+			require.Equal(t, 10, Get("max_conns"))
+		},
+	)
+}
+
+type BatchWriterLoader struct {
+	WriterLoader
+	batches int
+}
+
+func (b *BatchWriterLoader) SetMany(v Values) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.batches++
+	for k, vv := range v {
+		b.values[k] = vv
+	}
+	return nil
+}
+
+func TestPersistMany(t *testing.T) {
+	t.Run(
+		"writes grouped by loader",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var w = &WriterLoader{MapLoader: MapLoader{name: "file", values: Values{"host": "localhost", "port": 80}}}
+			var bw = &BatchWriterLoader{WriterLoader: WriterLoader{MapLoader: MapLoader{name: "consul", values: Values{"db.host": "db1", "db.port": 5432}}}}
+			RegisterLoader(w)
+			RegisterLoader(bw)
+			require.Nil(t, Load())
+
+			require.Nil(t, PersistMany(map[string]interface{}{
+				"host":    "remote",
+				"db.host": "db2",
+				"db.port": 5433,
+			}))
+			require.Equal(t, 1, bw.batches)
+			require.Equal(t, Values{"host": "remote", "port": 80}, w.values)
+			require.Equal(t, Values{"db.host": "db2", "db.port": 5433}, bw.values)
+			require.Equal(t, "remote", Get("host"))
+			require.Equal(t, "db2", Get("db.host"))
+			require.Equal(t, 5433, Get("db.port"))
+		},
+	)
+
+	t.Run(
+		"nothing is written if a key cannot be persisted",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var bw = &BatchWriterLoader{WriterLoader: WriterLoader{MapLoader: MapLoader{name: "consul", values: Values{"db.host": "db1"}}}}
+			RegisterLoader(&MapLoader{name: "env", values: Values{"host": "localhost"}})
+			RegisterLoader(bw)
+			require.Nil(t, Load())
+
+			require.Equal(
+				t,
+				fmt.Errorf(ErrNotWritableMsg, "env", "host"),
+				PersistMany(map[string]interface{}{"db.host": "db2", "host": "remote"}),
+			)
+			require.Equal(t, 0, bw.batches)
+			require.Equal(t, "db1", Get("db.host"))
+		},
+	)
+
+	t.Run(
+		"rolls back on failure",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var w = &WriterLoader{MapLoader: MapLoader{name: "file", values: Values{"host": "localhost"}}}
+			var bw = &BatchWriterLoader{WriterLoader: WriterLoader{MapLoader: MapLoader{name: "consul", values: Values{"db.host": "db1"}}}}
+			RegisterLoader(w)
+			RegisterLoader(bw)
+			require.Nil(t, Load())
+
+			bw.err = errors.New("transaction rolled back")
+			var err = PersistMany(map[string]interface{}{"host": "remote", "db.host": "db2"})
+			require.Equal(t, bw.err, err)
+
+			// the write of the first loader was rolled back
+			require.Equal(t, "localhost", w.values["host"])
+			require.Equal(t, "db1", bw.values["db.host"])
+			require.Equal(t, "localhost", Get("host"))
+			require.Equal(t, "db1", Get("db.host"))
+		},
+	)
+}
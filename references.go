@@ -0,0 +1,128 @@
+package konfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	referencePrefix = "${ref:"
+	referenceSuffix = "}"
+)
+
+var (
+	// ErrReferenceCycleMsg is the error message returned when references reference each other in a cycle
+	ErrReferenceCycleMsg = "Err cyclic reference: %s"
+	// ErrUnresolvedReferenceMsg is the error message returned when a value references a key which does not exist
+	ErrUnresolvedReferenceMsg = "Err key '%s' references key '%s' which does not exist"
+	// ErrReferenceMsg is the error message returned when a reference is not terminated
+	ErrReferenceMsg = "Err invalid reference for key '%s': %s"
+)
+
+// isReference tells wether v is a value containing references
+func isReference(v interface{}) bool {
+	var str, ok = v.(string)
+	return ok && strings.Contains(str, referencePrefix)
+}
+
+// setReferences returns a copy of the references refs updated with the values x loaded over the values ox
+func setReferences(refs map[string]string, ox Values, x Values) map[string]string {
+	var nrefs = make(map[string]string, len(refs))
+	for k, v := range refs {
+		if _, ok := ox[k]; !ok {
+			nrefs[k] = v
+		}
+	}
+	for k, v := range x {
+		if isReference(v) {
+			nrefs[k] = v.(string)
+		}
+	}
+	return nrefs
+}
+
+// referenceResolver resolves the references of a store
+type referenceResolver struct {
+	m     s
+	refs  map[string]string
+	state map[string]bool
+	r     Values
+}
+
+// resolveReferences resolves the references refs using the values in m and sets the results in m.
+// Values referencing other values containing references are resolved after them. It returns the resolved values.
+func resolveReferences(m s, refs map[string]string) (Values, error) {
+	var rr = &referenceResolver{
+		m:     m,
+		refs:  refs,
+		state: make(map[string]bool, len(refs)),
+		r:     make(Values, len(refs)),
+	}
+
+	for k := range refs {
+		if err := rr.resolve(k, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return rr.r, nil
+}
+
+func (rr *referenceResolver) resolve(k string, stack []string) error {
+	var resolving, ok = rr.state[k]
+	if ok && !resolving {
+		return nil
+	}
+
+	stack = append(stack, k)
+	if resolving {
+		return fmt.Errorf(ErrReferenceCycleMsg, strings.Join(stack, " -> "))
+	}
+	rr.state[k] = true
+
+	var str = rr.refs[k]
+	var v interface{}
+	var b strings.Builder
+	for {
+		var i = strings.Index(str, referencePrefix)
+		if i < 0 {
+			b.WriteString(str)
+			break
+		}
+		var j = strings.Index(str[i:], referenceSuffix)
+		if j < 0 {
+			return fmt.Errorf(ErrReferenceMsg, k, str[i:])
+		}
+
+		var ref = str[i+len(referencePrefix) : i+j]
+		// the references of the referenced value are resolved first
+		if _, ok := rr.refs[ref]; ok {
+			if err := rr.resolve(ref, stack); err != nil {
+				return err
+			}
+		}
+		var rv, ok = rr.m[ref]
+		if !ok {
+			return fmt.Errorf(ErrUnresolvedReferenceMsg, k, ref)
+		}
+
+		// a value made of a single reference keeps the type of the referenced value
+		if i == 0 && j+len(referenceSuffix) == len(str) && b.Len() == 0 {
+			v = rv
+			break
+		}
+
+		b.WriteString(str[:i])
+		b.WriteString(fmt.Sprint(rv))
+		str = str[i+j+len(referenceSuffix):]
+	}
+	if v == nil {
+		v = b.String()
+	}
+
+	rr.m[k] = v
+	rr.r[k] = v
+	rr.state[k] = false
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func referenceConfig() *Config {
+	var cfg = DefaultConfig()
+	cfg.References = true
+	return cfg
+}
+
+func TestResolveReferences(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		m         s
+		refs      map[string]string
+		expected  Values
+		errString string
+	}{
+		{
+			name:     "whole value keeps the type",
+			m:        s{"port": 8080, "db.port": "${ref:port}"},
+			refs:     map[string]string{"db.port": "${ref:port}"},
+			expected: Values{"db.port": 8080},
+		},
+		{
+			name:     "embedded references",
+			m:        s{"host": "localhost", "port": 8080, "url": "http://${ref:host}:${ref:port}/api"},
+			refs:     map[string]string{"url": "http://${ref:host}:${ref:port}/api"},
+			expected: Values{"url": "http://localhost:8080/api"},
+		},
+		{
+			name: "chained references",
+			m:    s{"host": "localhost", "db.host": "${ref:host}", "db.url": "postgres://${ref:db.host}"},
+			refs: map[string]string{"db.host": "${ref:host}", "db.url": "postgres://${ref:db.host}"},
+			expected: Values{
+				"db.host": "localhost",
+				"db.url":  "postgres://localhost",
+			},
+		},
+		{
+			name:      "cycle",
+			m:         s{"a": "${ref:b}", "b": "x${ref:a}"},
+			refs:      map[string]string{"a": "${ref:b}", "b": "x${ref:a}"},
+			errString: "Err cyclic reference: ",
+		},
+		{
+			name:      "self reference",
+			m:         s{"a": "${ref:a}"},
+			refs:      map[string]string{"a": "${ref:a}"},
+			errString: "Err cyclic reference: a -> a",
+		},
+		{
+			name:      "unresolved",
+			m:         s{"url": "http://${ref:host}"},
+			refs:      map[string]string{"url": "http://${ref:host}"},
+			errString: "Err key 'url' references key 'host' which does not exist",
+		},
+		{
+			name:      "not terminated",
+			m:         s{"url": "http://${ref:host"},
+			refs:      map[string]string{"url": "http://${ref:host"},
+			errString: "Err invalid reference for key 'url': ${ref:host",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var r, err = resolveReferences(testCase.m, testCase.refs)
+			if testCase.errString != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.errString)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, r)
+			for k, v := range testCase.expected {
+				require.Equal(t, v, testCase.m[k])
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	t.Run(
+		"resolved across loaders and on reload",
+		func(t *testing.T) {
+			reset()
+			Init(referenceConfig())
+
+			var l = &MapLoader{name: "a", values: Values{"db.host": "localhost", "db.port": 5432}}
+			RegisterLoader(l)
+			RegisterLoader(&MapLoader{name: "b", values: Values{
+				"cache.host": "${ref:db.host}",
+				"cache.port": "${ref:db.port}",
+			}})
+
+			require.Nil(t, Load())
+			require.Equal(t, "localhost", Get("cache.host"))
+			require.Equal(t, 5432, Get("cache.port"))
+
+			l.values = Values{"db.host": "example.com", "db.port": 5433}
+			require.Nil(t, Load())
+			require.Equal(t, "example.com", Get("cache.host"))
+			require.Equal(t, 5433, Get("cache.port"))
+		},
+	)
+
+	t.Run(
+		"disabled by default",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"host": "localhost", "url": "${ref:host}"}})
+
+			require.Nil(t, Load())
+			require.Equal(t, "${ref:host}", Get("url"))
+		},
+	)
+
+	t.Run(
+		"unresolved reference fails the load",
+		func(t *testing.T) {
+			reset()
+			Init(referenceConfig())
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"url": "${ref:host}"}})
+
+			var err = Load()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "Err key 'url' references key 'host' which does not exist")
+			require.Nil(t, Get("url"))
+		},
+	)
+
+	t.Run(
+		"set directly",
+		func(t *testing.T) {
+			reset()
+			Init(referenceConfig())
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"host": "localhost", "url": "${ref:host}"}})
+			require.Nil(t, Load())
+
+			Set("url", "example.com")
+			Set("host", "remote")
+			require.Equal(t, "example.com", Get("url"))
+		},
+	)
+
+	t.Run(
+		"templates see resolved references",
+		func(t *testing.T) {
+			reset()
+			var cfg = referenceConfig()
+			cfg.Templates = true
+			Init(cfg)
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{
+				"host":    "localhost",
+				"db.host": "${ref:host}",
+				"db.url":  "postgres://{{.db.host}}",
+			}})
+
+			require.Nil(t, Load())
+			require.Equal(t, "postgres://localhost", Get("db.url"))
+		},
+	)
+
+	t.Run(
+		"bound value",
+		func(t *testing.T) {
+			type Config struct {
+				Port int `konfig:"cache.port"`
+			}
+
+			reset()
+			Init(referenceConfig())
+			Bind(Config{})
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"db.port": 5432, "cache.port": "${ref:db.port}"}})
+
+			require.Nil(t, Load())
+			require.Equal(t, 5432, Value().(Config).Port)
+		},
+	)
+}
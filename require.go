@@ -0,0 +1,132 @@
+package konfig
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrRequireTypeMsg is the error message returned when a required value cannot be converted to the requested type
+var ErrRequireTypeMsg = "Err config '%s' cannot be converted to %s: %v"
+
+// Require gets the config k from the global store.
+// See Store.Require.
+func Require(k string) (interface{}, error) {
+	return instance().Require(k)
+}
+
+// Require gets the config k, it returns an error if the key does not exist.
+func (c *store) Require(k string) (interface{}, error) {
+	var m = c.m.Load().(s)
+	if v, ok := m[k]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf(ErrConfigNotFoundMsg, k)
+}
+
+// RequireString gets the config k from the global store and converts it to a string.
+// See Store.RequireString.
+func RequireString(k string) (string, error) {
+	return instance().RequireString(k)
+}
+
+// RequireString gets the config k and converts it to a string,
+// it returns an error if the key does not exist or the value cannot be converted.
+func (c *store) RequireString(k string) (string, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return "", err
+	}
+	str, err := cast.ToStringE(v)
+	if err != nil {
+		return "", fmt.Errorf(ErrRequireTypeMsg, k, "string", err)
+	}
+	return str, nil
+}
+
+// RequireInt gets the config k from the global store and converts it to an int.
+// See Store.RequireInt.
+func RequireInt(k string) (int, error) {
+	return instance().RequireInt(k)
+}
+
+// RequireInt gets the config k and converts it to an int,
+// it returns an error if the key does not exist or the value cannot be converted.
+func (c *store) RequireInt(k string) (int, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return 0, err
+	}
+	i, err := cast.ToIntE(v)
+	if err != nil {
+		return 0, fmt.Errorf(ErrRequireTypeMsg, k, "int", err)
+	}
+	return i, nil
+}
+
+// RequireFloat gets the config k from the global store and converts it to a float64.
+// See Store.RequireFloat.
+func RequireFloat(k string) (float64, error) {
+	return instance().RequireFloat(k)
+}
+
+// RequireFloat gets the config k and converts it to a float64,
+// it returns an error if the key does not exist or the value cannot be converted.
+func (c *store) RequireFloat(k string) (float64, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	f, err := cast.ToFloat64E(v)
+	if err != nil {
+		return 0, fmt.Errorf(ErrRequireTypeMsg, k, "float64", err)
+	}
+	return f, nil
+}
+
+// RequireBool gets the config k from the global store and converts it to a bool.
+// See Store.RequireBool.
+func RequireBool(k string) (bool, error) {
+	return instance().RequireBool(k)
+}
+
+// RequireBool gets the config k and converts it to a bool, strings are parsed with the BoolTokens of the config,
+// it returns an error if the key does not exist or the value is not a boolean.
+func (c *store) RequireBool(k string) (bool, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return false, err
+	}
+	b, err := c.bools.parse(v)
+	if err != nil {
+		return false, fmt.Errorf(ErrRequireTypeMsg, k, "bool", err)
+	}
+	return b, nil
+}
+
+// RequireDuration gets the config k from the global store and converts it to a time.Duration.
+// See Store.RequireDuration.
+func RequireDuration(k string) (time.Duration, error) {
+	return instance().RequireDuration(k)
+}
+
+// RequireDuration gets the config k and converts it to a time.Duration,
+// it returns an error if the key does not exist or the value cannot be converted.
+func (c *store) RequireDuration(k string) (time.Duration, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	d, err := cast.ToDurationE(v)
+	if err != nil {
+		return 0, fmt.Errorf(ErrRequireTypeMsg, k, "time.Duration", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,112 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequire(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		value    interface{}
+		set      bool
+		get      func() (interface{}, error)
+		expected interface{}
+		err      string
+	}{
+		{
+			name:     "Require",
+			value:    "bar",
+			set:      true,
+			get:      func() (interface{}, error) { return Require("foo") },
+			expected: "bar",
+		},
+		{
+			name: "RequireMissing",
+			get:  func() (interface{}, error) { return Require("foo") },
+			err:  "'foo' not found",
+		},
+		{
+			name:     "RequireString",
+			value:    1,
+			set:      true,
+			get:      func() (interface{}, error) { return RequireString("foo") },
+			expected: "1",
+		},
+		{
+			name:  "RequireStringWrongType",
+			value: []string{"bar"},
+			set:   true,
+			get:   func() (interface{}, error) { return RequireString("foo") },
+			err:   "'foo' cannot be converted to string",
+		},
+		{
+			name: "RequireStringMissing",
+			get:  func() (interface{}, error) { return RequireString("foo") },
+			err:  "'foo' not found",
+		},
+		{
+			name:     "RequireInt",
+			value:    "8080",
+			set:      true,
+			get:      func() (interface{}, error) { return RequireInt("foo") },
+			expected: 8080,
+		},
+		{
+			name:  "RequireIntWrongType",
+			value: "bar",
+			set:   true,
+			get:   func() (interface{}, error) { return RequireInt("foo") },
+			err:   "'foo' cannot be converted to int",
+		},
+		{
+			name:     "RequireFloat",
+			value:    "0.5",
+			set:      true,
+			get:      func() (interface{}, error) { return RequireFloat("foo") },
+			expected: 0.5,
+		},
+		{
+			name:     "RequireBool",
+			value:    "true",
+			set:      true,
+			get:      func() (interface{}, error) { return RequireBool("foo") },
+			expected: true,
+		},
+		{
+			name:  "RequireBoolWrongType",
+			value: "bar",
+			set:   true,
+			get:   func() (interface{}, error) { return RequireBool("foo") },
+			err:   "'foo' cannot be converted to bool",
+		},
+		{
+			name:     "RequireDuration",
+			value:    "5s",
+			set:      true,
+			get:      func() (interface{}, error) { return RequireDuration("foo") },
+			expected: 5 * time.Second,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			if testCase.set {
+				Set("foo", testCase.value)
+			}
+
+			var v, err = testCase.get()
+			if testCase.err != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, v)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package konfig
+
+import (
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// Reset returns the global store to a pristine state.
+// See Store.Reset.
+func Reset() error {
+	return instance().Reset()
+}
+
+// Reset returns the store to the state of a new store created with its config, e.g. between test cases.
+// The watchers of the store are closed, which stops their goroutines, and the closers registered in the store are closed.
+// Loaders are unregistered once their in-flight load ends, they are not loaded anymore once it returns.
+// Values, the bound value, hooks, derived keys, typed keys, codecs, aliases, strict and expected keys are cleared
+// and the groups of the store are reset and removed.
+// The Errors and Events channels are kept so that their listeners keep receiving new errors and events.
+// It must not be called concurrently with Load or LoadWatch, reloads triggered by watchers are waited for.
+// It returns the errors of closing the watchers and the closers as a multierror.Error, the store is reset even if it is not nil.
+func (c *store) Reset() error {
+	c.mut.Lock()
+	c.stateMut.RLock()
+	var wls = c.WatcherLoaders
+	var watcherClosers = c.WatcherClosers
+	c.stateMut.RUnlock()
+	var closers = c.Closers
+	var groups = c.groups
+	c.mut.Unlock()
+
+	c.stopCoalescing()
+
+	// closing the watchers stops the watch goroutines of the loaders
+	var errs error
+	if err := watcherClosers.Close(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	for _, wl := range wls {
+		// we wait for the current load of the loader
+		wl.loadMut.Lock()
+		wl.unregistered = true
+		wl.loadMut.Unlock()
+
+		if c.cfg.Metrics {
+			c.deleteMetrics(wl)
+		}
+	}
+
+	if err := closers.Close(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	for _, g := range groups {
+		if err := g.Reset(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	groupHooksMut.Lock()
+	c.hookTargets = nil
+	groupHooksMut.Unlock()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.m.Store(make(s))
+	c.groups = make(map[string]*store)
+	c.v = nil
+	c.strictKeys = nil
+	c.expected = nil
+	c.loaded = false
+	c.hooks = nil
+	c.hookDefs = nil
+	c.initHooks = nil
+	c.initialized = false
+	c.derived = nil
+	c.types = nil
+	c.codecs = nil
+	c.aliases = nil
+	c.aliasGroups = nil
+	c.templates = nil
+	c.refs = nil
+	c.setHookChanged(nil)
+	c.Closers = make(Closers, 0, 10)
+
+	c.stateMut.Lock()
+	c.sources = make(map[string]provenance)
+	c.changed = make(map[string]time.Time)
+	c.WatcherLoaders = make([]*loaderWatcher, 0, 10)
+	c.WatcherClosers = make(Closers, 0, 10)
+	c.stateMut.Unlock()
+
+	return errs
+}
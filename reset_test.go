@@ -0,0 +1,151 @@
+package konfig
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestReset(t *testing.T) {
+	t.Run(
+		"returns the store to a pristine state",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			type App struct {
+				Name string `konfig:"name"`
+			}
+
+			reset()
+			Init(DefaultConfig())
+
+			var done = make(chan struct{})
+			var mockW = NewMockWatcher(ctrl)
+			mockW.EXPECT().Start().Return(nil)
+			mockW.EXPECT().Done().AnyTimes().Return(done)
+			mockW.EXPECT().Watch().AnyTimes().Return(nil)
+			mockW.EXPECT().Err().AnyTimes().Return(nil)
+			mockW.EXPECT().Close().DoAndReturn(func() error {
+				close(done)
+				return nil
+			})
+
+			var mockL = NewMockLoader(ctrl)
+			mockL.EXPECT().Name().AnyTimes().Return("watched")
+			mockL.EXPECT().Load(gomock.Any()).Return(nil)
+
+			var hooks int
+			var closed bool
+			Bind(App{})
+			RegisterType("port", 0)
+			RegisterAlias("old", "name")
+			RegisterHook(func(Store) error {
+				hooks++
+				return nil
+			})
+			RegisterCloser(closerFunc(func() error {
+				closed = true
+				return nil
+			}))
+			RegisterLoader(&MapLoader{name: "a", values: Values{"name": "a", "port": "80"}})
+			RegisterLoaderWatcher(NewLoaderWatcher(mockL, mockW))
+			Group("g").Set("foo", "bar")
+
+			require.Nil(t, LoadWatch())
+			require.Equal(t, 80, Get("port"))
+			require.Equal(t, 2, hooks)
+
+			require.Nil(t, Reset())
+			require.True(t, closed)
+			require.Empty(t, Snapshot())
+			require.Empty(t, instance().WatcherLoaders)
+			require.Empty(t, instance().WatcherClosers)
+			require.Empty(t, instance().Closers)
+			require.Equal(t, "", Source("name"))
+			require.Panics(t, func() { Value() })
+			require.False(t, Group("g").Exists("foo"))
+
+			RegisterLoader(&MapLoader{name: "b", values: Values{"old": "b", "port": "8080"}})
+			require.Nil(t, Load())
+			require.Equal(t, Values{"old": "b", "port": "8080"}, Snapshot())
+			require.Equal(t, 2, hooks)
+		},
+	)
+
+	t.Run(
+		"waits for the in-flight load of a loader",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &BlockingLoader{
+				MapLoader: MapLoader{name: "a", values: Values{"foo": "a"}},
+				started:   make(chan struct{}),
+				release:   make(chan struct{}),
+			}
+			RegisterLoader(l)
+
+			// a reload triggered by a watcher
+			var loaded = make(chan error)
+			go func() {
+				loaded <- ReloadLoader("a")
+			}()
+			<-l.started
+
+			var resetDone = make(chan error)
+			go func() {
+				resetDone <- Reset()
+			}()
+
+			close(l.release)
+			require.Nil(t, <-loaded)
+			require.Nil(t, <-resetDone)
+			require.False(t, Exists("foo"))
+		},
+	)
+
+	t.Run(
+		"group reset",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			Set("foo", "bar")
+			var g = Group("g")
+			g.RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "g"}})
+			require.Nil(t, g.Load())
+
+			require.Nil(t, g.Reset())
+			require.False(t, g.Exists("foo"))
+			require.Empty(t, g.(*store).WatcherLoaders)
+			require.Equal(t, "bar", Get("foo"))
+			require.Equal(t, g, Group("g"))
+		},
+	)
+
+	t.Run(
+		"closer errors",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var errClose = errors.New("close")
+			RegisterCloser(closerFunc(func() error { return errClose }))
+			Set("foo", "bar")
+
+			var err = Reset()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "close")
+			require.False(t, Exists("foo"))
+		},
+	)
+}
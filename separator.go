@@ -0,0 +1,33 @@
+package konfig
+
+import "strings"
+
+// KeySeparator is an optional interface a Loader can implement to declare the separator used between key segments
+// in the source it loads (e.g. "/" for Consul). The store replaces it with KeySep in the keys written by the loader,
+// so "db/host" is stored as "db.host". An empty separator leaves the keys untouched.
+type KeySeparator interface {
+	Separator() string
+}
+
+func loaderSeparator(l Loader) string {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return loaderSeparator(lt.Loader)
+	case KeySeparator:
+		return lt.Separator()
+	}
+	return ""
+}
+
+// normalizeKeys returns the values v with the separator sep replaced by KeySep in the keys.
+// If sep is empty or already KeySep, v is returned as is.
+func normalizeKeys(v Values, sep string) Values {
+	if sep == "" || sep == KeySep {
+		return v
+	}
+	var nv = make(Values, len(v))
+	for k, vv := range v {
+		nv[strings.Replace(k, sep, KeySep, -1)] = vv
+	}
+	return nv
+}
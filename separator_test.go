@@ -0,0 +1,70 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SeparatorLoader struct {
+	DummyLoader
+	sep string
+}
+
+func (s *SeparatorLoader) Separator() string {
+	return s.sep
+}
+
+func TestSeparator(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		sep      string
+		data     [][2]string
+		expected Values
+	}{
+		{
+			name: "slash separator",
+			sep:  "/",
+			data: [][2]string{{"db/host", "localhost"}, {"db/port", "5432"}},
+			expected: Values{
+				"db.host": "localhost",
+				"db.port": "5432",
+			},
+		},
+		{
+			name: "multi char separator",
+			sep:  "__",
+			data: [][2]string{{"db__host", "localhost"}, {"db_name", "test"}},
+			expected: Values{
+				"db.host": "localhost",
+				"db_name": "test",
+			},
+		},
+		{
+			name: "empty separator",
+			data: [][2]string{{"db/host", "localhost"}},
+			expected: Values{
+				"db/host": "localhost",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &SeparatorLoader{
+				DummyLoader: DummyLoader{DataToLoad: testCase.data},
+				sep:         testCase.sep,
+			}
+			RegisterLoader(l)
+
+			require.Nil(t, Load())
+			for k, v := range testCase.expected {
+				require.Equal(t, v, Get(k))
+			}
+			require.Len(t, instance().m.Load().(s), len(testCase.expected))
+		})
+	}
+}
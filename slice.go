@@ -0,0 +1,178 @@
+package konfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// DefaultListSeparator is the separator string values are split on by IntSlice and FloatSlice when Config.ListSeparator is not set
+const DefaultListSeparator = ","
+
+var (
+	// ErrSliceElementMsg is the error message returned when an element of a slice cannot be converted
+	ErrSliceElementMsg = "Err element %d of slice: %v"
+)
+
+// MustFloatSlice gets the config k and tries to convert it to a []float64
+// it panics if it fails.
+func MustFloatSlice(k string) []float64 {
+	return instance().MustFloatSlice(k)
+}
+func (c *store) MustFloatSlice(k string) []float64 {
+	var f, _ = toFloatSlice(c.MustGet(k), c.cfg.ListSeparator)
+	return f
+}
+
+// FloatSlice gets the config k and converts it to a []float64.
+// It returns the zero value if it doesn't find the config or if an element cannot be converted.
+func FloatSlice(k string) []float64 {
+	return instance().FloatSlice(k)
+}
+func (c *store) FloatSlice(k string) []float64 {
+	var f, _ = toFloatSlice(c.Get(k), c.cfg.ListSeparator)
+	return f
+}
+
+// RequireIntSlice gets the config k from the global store and converts it to a []int.
+// See Store.RequireIntSlice.
+func RequireIntSlice(k string) ([]int, error) {
+	return instance().RequireIntSlice(k)
+}
+
+// RequireIntSlice gets the config k and converts it to a []int,
+// it returns an error if the key does not exist or an element cannot be converted.
+func (c *store) RequireIntSlice(k string) ([]int, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return nil, err
+	}
+	i, err := toIntSlice(v, c.cfg.ListSeparator)
+	if err != nil {
+		return nil, fmt.Errorf(ErrRequireTypeMsg, k, "[]int", err)
+	}
+	return i, nil
+}
+
+// RequireFloatSlice gets the config k from the global store and converts it to a []float64.
+// See Store.RequireFloatSlice.
+func RequireFloatSlice(k string) ([]float64, error) {
+	return instance().RequireFloatSlice(k)
+}
+
+// RequireFloatSlice gets the config k and converts it to a []float64,
+// it returns an error if the key does not exist or an element cannot be converted.
+func (c *store) RequireFloatSlice(k string) ([]float64, error) {
+	var v, err = c.Require(k)
+	if err != nil {
+		return nil, err
+	}
+	f, err := toFloatSlice(v, c.cfg.ListSeparator)
+	if err != nil {
+		return nil, fmt.Errorf(ErrRequireTypeMsg, k, "[]float64", err)
+	}
+	return f, nil
+}
+
+// ListCodec returns a Codec splitting string values on sep into a []string, see SplitList.
+// Slices are converted to a []string, it lets keys use their own separator, e.g. RegisterCodec("hosts", ListCodec(";")).
+func ListCodec(sep string) Codec {
+	return func(v interface{}) (interface{}, error) {
+		return toStringSlice(v, sep)
+	}
+}
+
+// SplitList splits str on sep and trims the elements, empty elements are dropped so that "a,,b," and "a, b" are both split to ["a" "b"]
+// and an empty string has no elements.
+func SplitList(str, sep string) []string {
+	var parts = strings.Split(str, sep)
+	var elems = make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			elems = append(elems, p)
+		}
+	}
+	return elems
+}
+
+// toStringSlice converts v to a []string, if sep is not empty strings are split on sep with SplitList
+func toStringSlice(v interface{}, sep string) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if str, ok := v.(string); ok && sep != "" {
+		return SplitList(str, sep), nil
+	}
+	return cast.ToStringSliceE(v)
+}
+
+// toIntSlice converts v to a []int, each element is converted like Int does
+func toIntSlice(v interface{}, sep string) ([]int, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case []int:
+		return vv, nil
+	}
+	var elems, err = sliceElements(v, sep)
+	if err != nil {
+		return nil, err
+	}
+	var r = make([]int, len(elems))
+	for i, e := range elems {
+		if r[i], err = cast.ToIntE(e); err != nil {
+			return nil, fmt.Errorf(ErrSliceElementMsg, i, err)
+		}
+	}
+	return r, nil
+}
+
+// toFloatSlice converts v to a []float64, each element is converted like Float does
+func toFloatSlice(v interface{}, sep string) ([]float64, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case []float64:
+		return vv, nil
+	}
+	var elems, err = sliceElements(v, sep)
+	if err != nil {
+		return nil, err
+	}
+	var r = make([]float64, len(elems))
+	for i, e := range elems {
+		if r[i], err = cast.ToFloat64E(e); err != nil {
+			return nil, fmt.Errorf(ErrSliceElementMsg, i, err)
+		}
+	}
+	return r, nil
+}
+
+// sliceElements returns the elements of the slice or array v.
+// Strings, such as the values of env loaders, are split on sep with SplitList, or on DefaultListSeparator if sep is empty.
+func sliceElements(v interface{}, sep string) ([]interface{}, error) {
+	if str, ok := v.(string); ok {
+		if sep == "" {
+			sep = DefaultListSeparator
+		}
+		var parts = SplitList(str, sep)
+		var elems = make([]interface{}, len(parts))
+		for i, p := range parts {
+			elems[i] = p
+		}
+		return elems, nil
+	}
+
+	var rv = reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var elems = make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		return elems, nil
+	}
+	return nil, fmt.Errorf("unable to cast %#v of type %T to a slice", v, v)
+}
@@ -0,0 +1,172 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToIntSlice(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		value     interface{}
+		expected  []int
+		errString string
+	}{
+		{name: "ints", value: []int{1, 2}, expected: []int{1, 2}},
+		{name: "interfaces", value: []interface{}{1, "2", 3.0}, expected: []int{1, 2, 3}},
+		{name: "strings", value: []string{"8080", "8081"}, expected: []int{8080, 8081}},
+		{name: "comma separated", value: "8080, 8081,8082", expected: []int{8080, 8081, 8082}},
+		{name: "empty string", value: "", expected: []int{}},
+		{name: "empty elements", value: "1,,2, ", expected: []int{1, 2}},
+		{name: "invalid element", value: "8080,http", errString: "Err element 1 of slice: "},
+		{name: "not a slice", value: 1, errString: "unable to cast 1 of type int to a slice"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var i, err = toIntSlice(testCase.value, "")
+			if testCase.errString != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.errString)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, i)
+		})
+	}
+}
+
+func TestToFloatSlice(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		value     interface{}
+		expected  []float64
+		errString string
+	}{
+		{name: "floats", value: []float64{0.5, 1}, expected: []float64{0.5, 1}},
+		{name: "interfaces", value: []interface{}{1, "0.25"}, expected: []float64{1, 0.25}},
+		{name: "comma separated", value: "0.5,0.9, 0.99", expected: []float64{0.5, 0.9, 0.99}},
+		{name: "invalid element", value: []string{"0.5", "high"}, errString: "Err element 1 of slice: "},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var f, err = toFloatSlice(testCase.value, "")
+			if testCase.errString != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.errString)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, f)
+		})
+	}
+}
+
+func TestSliceGetters(t *testing.T) {
+	reset()
+	Set("ports", "8080,8081")
+	Set("thresholds", []interface{}{0.5, "0.9"})
+	Set("invalid", "8080,http")
+
+	require.Equal(t, []int{8080, 8081}, IntSlice("ports"))
+	require.Equal(t, []int{8080, 8081}, MustIntSlice("ports"))
+	require.Equal(t, []float64{0.5, 0.9}, FloatSlice("thresholds"))
+	require.Equal(t, []float64{0.5, 0.9}, MustFloatSlice("thresholds"))
+	require.Nil(t, IntSlice("invalid"))
+	require.Nil(t, FloatSlice("nope"))
+	require.Panics(t, func() { MustFloatSlice("nope") })
+
+	var i, err = RequireIntSlice("ports")
+	require.Nil(t, err)
+	require.Equal(t, []int{8080, 8081}, i)
+
+	_, err = RequireIntSlice("invalid")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Err config 'invalid' cannot be converted to []int: Err element 1 of slice")
+
+	f, err := RequireFloatSlice("thresholds")
+	require.Nil(t, err)
+	require.Equal(t, []float64{0.5, 0.9}, f)
+
+	_, err = RequireFloatSlice("nope")
+	require.NotNil(t, err)
+}
+
+func TestSplitList(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		str      string
+		sep      string
+		expected []string
+	}{
+		{name: "comma", str: "a,b,c", sep: ",", expected: []string{"a", "b", "c"}},
+		{name: "trimmed", str: " a , b ", sep: ",", expected: []string{"a", "b"}},
+		{name: "empty elements", str: "a,,b,", sep: ",", expected: []string{"a", "b"}},
+		{name: "empty string", str: "", sep: ",", expected: []string{}},
+		{name: "multi char separator", str: "a::b", sep: "::", expected: []string{"a", "b"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, SplitList(testCase.str, testCase.sep))
+		})
+	}
+}
+
+func TestListSeparator(t *testing.T) {
+	t.Run(
+		"not set",
+		func(t *testing.T) {
+			reset()
+			Set("hosts", "a,b c")
+			require.Equal(t, []string{"a,b", "c"}, StringSlice("hosts"))
+		},
+	)
+
+	t.Run(
+		"set in the config",
+		func(t *testing.T) {
+			type Config struct {
+				Hosts []string `konfig:"hosts"`
+				Ports []int    `konfig:"ports"`
+			}
+
+			reset()
+			var cfg = DefaultConfig()
+			cfg.ListSeparator = ";"
+			Init(cfg)
+			Bind(Config{})
+			RegisterType("typed", []string{})
+
+			RegisterLoader(&MapLoader{name: "env", values: Values{
+				"hosts": "a; b;;c",
+				"ports": "80;443",
+				"typed": "x;y",
+			}})
+			require.Nil(t, Load())
+
+			require.Equal(t, []string{"a", "b", "c"}, StringSlice("hosts"))
+			require.Equal(t, []string{"a", "b", "c"}, MustStringSlice("hosts"))
+			require.Equal(t, []int{80, 443}, IntSlice("ports"))
+			require.Equal(t, []string{"x", "y"}, Get("typed"))
+			require.Equal(t, Config{Hosts: []string{"a", "b", "c"}, Ports: []int{80, 443}}, Value())
+		},
+	)
+
+	t.Run(
+		"codec per key",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			RegisterCodec("paths", ListCodec(":"))
+
+			RegisterLoader(&MapLoader{name: "env", values: Values{"paths": "/bin:/usr/bin"}})
+			require.Nil(t, Load())
+
+			require.Equal(t, []string{"/bin", "/usr/bin"}, Get("paths"))
+			require.Equal(t, []string{"/bin", "/usr/bin"}, StringSlice("paths"))
+		},
+	)
+}
@@ -0,0 +1,112 @@
+package konfig
+
+import (
+	"strings"
+	"time"
+)
+
+// provenance is the record of the last write of a key
+type provenance struct {
+	// wl is the loader which wrote the key, it is nil if the key was set directly on the store
+	wl *loaderWatcher
+	at time.Time
+}
+
+// KeyOwner is an optional interface a Loader can implement to declare the keys it owns.
+// A declared key owns itself and all the keys it prefixes followed by KeySep (e.g. "db" owns "db.host").
+// Loaders that can't enumerate their keys can return nil.
+type KeyOwner interface {
+	Keys() []string
+}
+
+// Source returns the name of the loader responsible for the key k in the global store.
+// See Store.Source.
+func Source(k string) string {
+	return instance().Source(k)
+}
+
+// Source returns the name of the loader responsible for the key k.
+// If loaders declare the key through the KeyOwner interface, it returns the last registered one, as it has the highest precedence.
+// Else, it returns the name of the loader which last wrote the key.
+// If no loader is responsible for the key, it returns an empty string.
+// It does not lock the store, so it can be called from hooks.
+func (c *store) Source(k string) string {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	if wl := c.owner(k); wl != nil {
+		return wl.Name()
+	}
+	return ""
+}
+
+// owner returns the loader responsible for the key k or nil, it must be called with the state mutex locked
+func (c *store) owner(k string) *loaderWatcher {
+	for i := len(c.WatcherLoaders) - 1; i >= 0; i-- {
+		var wl = c.WatcherLoaders[i]
+		for _, ok := range loaderKeys(wl) {
+			if k == ok || strings.HasPrefix(k, ok+KeySep) {
+				return wl
+			}
+		}
+	}
+
+	if p, ok := c.sources[k]; ok && p.wl != nil {
+		return p.wl
+	}
+
+	return nil
+}
+
+// Provenance returns the name of the loader which last wrote the key k in the global store and the time of the write.
+// See Store.Provenance.
+func Provenance(k string) (string, time.Time, bool) {
+	return instance().Provenance(k)
+}
+
+// Provenance returns the name of the loader which last wrote the key k and the time of the write.
+// If the key was set directly on the store with Set, the loader name is empty.
+// ok is false if the key is not set in the store. It does not lock the store, so it can be called from hooks.
+func (c *store) Provenance(k string) (loader string, at time.Time, ok bool) {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	var p provenance
+	if p, ok = c.sources[k]; !ok {
+		return "", time.Time{}, false
+	}
+	if p.wl != nil {
+		loader = p.wl.Name()
+	}
+
+	return loader, p.at, true
+}
+
+// setSources records the loader wl as the last writer of the keys in x, and removes it from the keys in ox which are not in x anymore.
+// Keys in restored are recorded as written by the loader they were restored from.
+// It must be called with the state mutex locked.
+func (c *store) setSources(wl *loaderWatcher, ox Values, x Values, restored map[string]*loaderWatcher) {
+	var now = time.Now()
+	for k := range ox {
+		if _, ok := x[k]; !ok && c.sources[k].wl == wl {
+			delete(c.sources, k)
+		}
+	}
+	for k := range x {
+		if owl, ok := restored[k]; ok {
+			c.sources[k] = provenance{wl: owl, at: now}
+			continue
+		}
+		c.sources[k] = provenance{wl: wl, at: now}
+	}
+}
+
+func loaderKeys(l Loader) []string {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return loaderKeys(lt.Loader)
+	case KeyOwner:
+		return lt.Keys()
+	}
+	return nil
+}
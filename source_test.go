@@ -0,0 +1,142 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type OwnerLoader struct {
+	DummyLoader
+	name string
+	keys []string
+}
+
+func (o *OwnerLoader) Name() string {
+	return o.name
+}
+
+func (o *OwnerLoader) Keys() []string {
+	return o.keys
+}
+
+func TestSource(t *testing.T) {
+	t.Run(
+		"last writer",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l1 = &OwnerLoader{
+				name: "l1",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "bar"}, {"bar", "foo"}},
+				},
+			}
+			var l2 = &OwnerLoader{
+				name: "l2",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"foo", "baz"}},
+				},
+			}
+			RegisterLoader(l1)
+			RegisterLoader(l2)
+
+			require.Nil(t, Load())
+			require.Equal(t, "l2", Source("foo"))
+			require.Equal(t, "l1", Source("bar"))
+			require.Equal(t, "", Source("nope"))
+
+			// the key is not written anymore by the loader
+			l1.DataToLoad = [][2]string{{"foo", "bar"}}
+			require.Nil(t, Load())
+			require.Equal(t, "", Source("bar"))
+
+			// set directly in the store
+			Set("foo", "qux")
+			require.Equal(t, "", Source("foo"))
+		},
+	)
+
+	t.Run(
+		"declared keys",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l1 = &OwnerLoader{
+				name: "l1",
+				keys: []string{"db"},
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"db.host", "localhost"}},
+				},
+			}
+			var l2 = &OwnerLoader{
+				name: "l2",
+				DummyLoader: DummyLoader{
+					DataToLoad: [][2]string{{"db.host", "remote"}, {"dbx", "foo"}},
+				},
+			}
+			RegisterLoader(l1)
+			RegisterLoaderWatcher(NewLoaderWatcher(l2, NopWatcher{}))
+
+			require.Nil(t, Load())
+			require.Equal(t, "l1", Source("db.host"))
+			require.Equal(t, "l1", Source("db.port"))
+			require.Equal(t, "l2", Source("dbx"))
+		},
+	)
+}
+
+func TestProvenance(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l = &OwnerLoader{
+		name: "l1",
+		DummyLoader: DummyLoader{
+			DataToLoad: [][2]string{{"foo", "bar"}},
+		},
+	}
+	RegisterLoader(l)
+
+	var before = time.Now()
+	require.Nil(t, Load())
+
+	var loader, at, ok = Provenance("foo")
+	require.True(t, ok)
+	require.Equal(t, "l1", loader)
+	require.False(t, at.Before(before))
+
+	_, _, ok = Provenance("nope")
+	require.False(t, ok)
+
+	// set directly in the store
+	before = time.Now()
+	Set("foo", "baz")
+	loader, at, ok = Provenance("foo")
+	require.True(t, ok)
+	require.Equal(t, "", loader)
+	require.False(t, at.Before(before))
+}
+
+func TestSourceFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var source, loader string
+	var ok bool
+	RegisterHook(func(s Store) error {
+		source = s.Source("foo")
+		loader, _, ok = s.Provenance("foo")
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.Equal(t, "a", source)
+	require.Equal(t, "a", loader)
+	require.True(t, ok)
+}
@@ -0,0 +1,81 @@
+package konfig
+
+import (
+	"fmt"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+var (
+	// ErrStaleMsg is the message of the error returned by Health when no loader of the store loaded successfully within MaxStaleness
+	ErrStaleMsg = "Err config is stale, no successful load since %s"
+	// ErrLoaderStaleMsg is the message of the error returned by Health when a loader did not load successfully within its MaxAge
+	ErrLoaderStaleMsg = "Err loader '%s' is stale, no successful load since %s"
+)
+
+// MaxAgeLoader is an optional interface a Loader can implement to declare for how long its values are valid.
+// If the loader does not load successfully within MaxAge, the store is stale. A zero MaxAge disables the check.
+// For example, the vault loader returns the TTL of its token when it renews itself.
+type MaxAgeLoader interface {
+	// MaxAge returns the maximum duration between two successful loads of the loader
+	MaxAge() time.Duration
+}
+
+// Stale tells wether the config of the global store is stale.
+// See Store.Stale.
+func Stale() bool {
+	return instance().Stale()
+}
+
+// Stale tells wether the config of the store is stale, that is if no loader loaded successfully within MaxStaleness,
+// or if a loader implementing MaxAgeLoader did not load successfully within its MaxAge.
+// Durations are counted from the creation of the store until the first successful load. Disabled loaders are not checked.
+// Health also returns an error when the store is stale.
+func (c *store) Stale() bool {
+	return c.staleness() != nil
+}
+
+// staleness returns an error for each staleness check failing, nil if the store is not stale
+func (c *store) staleness() error {
+	var now = time.Now()
+	var multiErr *multierror.Error
+	var last = c.created
+
+	for _, wl := range c.loaders() {
+		wl.statusMut.Lock()
+		var success = wl.lastSuccess
+		wl.statusMut.Unlock()
+
+		if success.After(last) {
+			last = success
+		}
+
+		var maxAge = loaderMaxAge(wl.Loader)
+		if maxAge <= 0 || !wl.isEnabled() {
+			continue
+		}
+		if success.IsZero() {
+			success = c.created
+		}
+		if now.Sub(success) > maxAge {
+			multiErr = multierror.Append(multiErr, fmt.Errorf(ErrLoaderStaleMsg, wl.Name(), success.Format(time.RFC3339)))
+		}
+	}
+
+	if c.cfg.MaxStaleness > 0 && now.Sub(last) > c.cfg.MaxStaleness {
+		multiErr = multierror.Append(multiErr, fmt.Errorf(ErrStaleMsg, last.Format(time.RFC3339)))
+	}
+
+	return multiErr.ErrorOrNil()
+}
+
+func loaderMaxAge(l Loader) time.Duration {
+	switch lt := l.(type) {
+	case *loaderWatcher:
+		return loaderMaxAge(lt.Loader)
+	case MaxAgeLoader:
+		return lt.MaxAge()
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MaxAgeMapLoader struct {
+	MapLoader
+	maxAge time.Duration
+}
+
+func (m *MaxAgeMapLoader) MaxAge() time.Duration {
+	return m.maxAge
+}
+
+func TestStale(t *testing.T) {
+	t.Run(
+		"max staleness",
+		func(t *testing.T) {
+			reset()
+			Init(&Config{MaxStaleness: 50 * time.Millisecond})
+
+			var l = &DummyLoader{DataToLoad: [][2]string{{"foo", "bar"}}}
+			RegisterLoader(l)
+			require.Nil(t, Load())
+			require.False(t, Stale())
+			require.Nil(t, Health())
+
+			time.Sleep(60 * time.Millisecond)
+			require.True(t, Stale())
+			require.NotNil(t, Health())
+			require.Contains(t, Health().Error(), "Err config is stale")
+			require.True(t, Stats().Stale)
+
+			// a failed reload does not refresh the config
+			l.err = true
+			require.NotNil(t, Reload())
+			require.True(t, Stale())
+
+			l.err = false
+
+			require.Nil(t, Reload())
+			require.False(t, Stale())
+			require.False(t, Stats().Loaders[0].LastSuccess.IsZero())
+		},
+	)
+
+	t.Run(
+		"loader max age",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &MaxAgeMapLoader{
+				MapLoader: MapLoader{name: "vault", values: Values{"foo": "bar"}},
+				maxAge:    50 * time.Millisecond,
+			}
+			RegisterLoader(&MapLoader{name: "file", values: Values{"bar": "foo"}})
+			RegisterLoader(l)
+			require.Nil(t, Load())
+			require.False(t, Stale())
+
+			time.Sleep(60 * time.Millisecond)
+			require.Nil(t, ReloadLoader("file"))
+			require.True(t, Stale())
+			require.Contains(t, Health().Error(), "Err loader 'vault' is stale")
+
+			require.Nil(t, ReloadLoader("vault"))
+			require.False(t, Stale())
+		},
+	)
+
+	t.Run(
+		"staleness is not checked by default",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			RegisterLoader(&MapLoader{name: "file", values: Values{"foo": "bar"}})
+
+			require.False(t, Stale())
+		},
+	)
+}
@@ -0,0 +1,84 @@
+package konfig
+
+import "time"
+
+// StoreStats is the runtime state of a store and its loaders
+type StoreStats struct {
+	// Keys is the number of keys in the store
+	Keys int
+	// Loaders is the state of the loaders of the store, in registration order
+	Loaders []LoaderStats
+	// Health is the error returned by Store.Health
+	Health error
+	// Stale tells wether the store is stale, see Store.Stale
+	Stale bool
+}
+
+// LoaderStats is the runtime state of a loader and its watcher
+type LoaderStats struct {
+	// Name is the name of the loader
+	Name string
+	// Enabled tells wether the loader is enabled
+	Enabled bool
+	// Keys is the number of keys owned by the loader, that is the number of keys it loaded in the last successful load
+	Keys int
+	// LastLoad is the time the last load started, it is zero if the loader never loaded
+	LastLoad time.Time
+	// LastDuration is the duration of the last load, retries included
+	LastDuration time.Duration
+	// LastError is the error of the last load, it is nil if the last load succeeded
+	LastError error
+	// LastSuccess is the time the last successful load ended, it is zero if the loader never loaded successfully
+	LastSuccess time.Time
+	// Degraded tells wether the loader failed during the initial load and did not load successfully since
+	Degraded bool
+	// WatcherFailed tells wether the watcher of the loader failed permanently
+	WatcherFailed bool
+	// WatcherError is the last panic of the watcher of the loader
+	WatcherError error
+}
+
+// Stats returns the runtime state of the global store.
+// See Store.Stats.
+func Stats() StoreStats {
+	return instance().Stats()
+}
+
+// Stats returns the runtime state of the store: the state of each loader and the health of their watchers.
+// It is meant for introspection, for example to expose the state of the store on a debug endpoint.
+// It does not lock the store, so it neither waits for running hooks nor deadlocks when called from a hook.
+func (c *store) Stats() StoreStats {
+	var m = c.m.Load().(s)
+
+	c.stateMut.RLock()
+	var st = StoreStats{
+		Keys:    len(m),
+		Loaders: make([]LoaderStats, len(c.WatcherLoaders)),
+	}
+	var wls = c.WatcherLoaders
+	for i, wl := range wls {
+		st.Loaders[i].Keys = len(wl.values)
+	}
+	c.stateMut.RUnlock()
+
+	for i, wl := range wls {
+		var ls = &st.Loaders[i]
+		ls.Name = wl.Name()
+		ls.Enabled = wl.isEnabled()
+
+		wl.statusMut.Lock()
+		ls.LastLoad = wl.lastLoad
+		ls.LastDuration = wl.lastLoadDuration
+		ls.LastError = wl.lastLoadErr
+		ls.LastSuccess = wl.lastSuccess
+		ls.Degraded = wl.degraded
+		ls.WatcherFailed = wl.failed
+		ls.WatcherError = wl.lastErr
+		wl.statusMut.Unlock()
+	}
+
+	st.Health = c.Health()
+	st.Stale = c.Stale()
+
+	return st
+}
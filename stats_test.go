@@ -0,0 +1,77 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	reset()
+	Init(DefaultConfig())
+
+	var l1 = &OwnerLoader{
+		name: "l1",
+		DummyLoader: DummyLoader{
+			DataToLoad: [][2]string{{"foo", "bar"}, {"bar", "foo"}},
+		},
+	}
+	var l2 = &OwnerLoader{
+		name: "l2",
+		DummyLoader: DummyLoader{
+			DataToLoad: [][2]string{{"baz", "qux"}},
+		},
+	}
+	RegisterLoader(l1)
+	RegisterLoader(l2)
+
+	var st = Stats()
+	require.Equal(t, 0, st.Keys)
+	require.Len(t, st.Loaders, 2)
+	require.True(t, st.Loaders[0].LastLoad.IsZero())
+
+	var before = time.Now()
+	require.Nil(t, Load())
+
+	st = Stats()
+	require.Equal(t, 3, st.Keys)
+	require.Nil(t, st.Health)
+
+	require.Equal(t, "l1", st.Loaders[0].Name)
+	require.True(t, st.Loaders[0].Enabled)
+	require.Equal(t, 2, st.Loaders[0].Keys)
+	require.False(t, st.Loaders[0].LastLoad.Before(before))
+	require.Nil(t, st.Loaders[0].LastError)
+	require.False(t, st.Loaders[0].WatcherFailed)
+
+	require.Equal(t, "l2", st.Loaders[1].Name)
+	require.Equal(t, 1, st.Loaders[1].Keys)
+
+	// failed reload
+	l2.err = true
+	require.NotNil(t, ReloadLoader("l2"))
+
+	st = Stats()
+	require.NotNil(t, st.Loaders[1].LastError)
+	require.Equal(t, 1, st.Loaders[1].Keys)
+}
+
+func TestStatsFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var st StoreStats
+	RegisterHook(func(s Store) error {
+		st = s.Stats()
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.Equal(t, 1, st.Keys)
+	require.Len(t, st.Loaders, 1)
+	require.Equal(t, 1, st.Loaders[0].Keys)
+	require.Nil(t, st.Health)
+}
@@ -0,0 +1,198 @@
+package konfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const templateDelim = "{{"
+
+var (
+	// ErrTemplateCycleMsg is the error message returned when templates reference each other in a cycle
+	ErrTemplateCycleMsg = "Err cyclic template reference: %s"
+	// ErrTemplateMsg is the error message returned when a template value cannot be parsed or rendered
+	ErrTemplateMsg = "Err template for key '%s': %v"
+)
+
+// isTemplate tells wether v is a template value
+func isTemplate(v interface{}) bool {
+	var str, ok = v.(string)
+	return ok && strings.Contains(str, templateDelim)
+}
+
+// setTemplates returns a copy of the templates tpls updated with the values x loaded over the values ox
+func setTemplates(tpls map[string]string, ox Values, x Values) map[string]string {
+	var ntpls = make(map[string]string, len(tpls))
+	for k, v := range tpls {
+		if _, ok := ox[k]; !ok {
+			ntpls[k] = v
+		}
+	}
+	for k, v := range x {
+		if isTemplate(v) {
+			ntpls[k] = v.(string)
+		}
+	}
+	return ntpls
+}
+
+// templateRenderer renders the template values of a store
+type templateRenderer struct {
+	m     s
+	tpls  map[string]*template.Template
+	deps  map[string][]string
+	state map[string]bool
+	r     Values
+}
+
+// renderTemplates renders the templates tpls using the values in m as context and sets the results in m.
+// Templates referencing other template values are rendered after them. It returns the rendered values.
+func renderTemplates(m s, tpls map[string]string) (Values, error) {
+	var tr = &templateRenderer{
+		m:     m,
+		tpls:  make(map[string]*template.Template, len(tpls)),
+		deps:  make(map[string][]string, len(tpls)),
+		state: make(map[string]bool, len(tpls)),
+		r:     make(Values, len(tpls)),
+	}
+
+	for k, v := range tpls {
+		var t, err = template.New(k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf(ErrTemplateMsg, k, err)
+		}
+		tr.tpls[k] = t
+		tr.deps[k] = templateFields(t.Tree.Root, nil)
+	}
+
+	for k := range tr.tpls {
+		if err := tr.render(k, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return tr.r, nil
+}
+
+func (tr *templateRenderer) render(k string, stack []string) error {
+	var rendering, ok = tr.state[k]
+	if ok && !rendering {
+		return nil
+	}
+
+	stack = append(stack, k)
+	if rendering {
+		return fmt.Errorf(ErrTemplateCycleMsg, strings.Join(stack, " -> "))
+	}
+	tr.state[k] = true
+
+	// render the templates this one depends on first
+	for _, dep := range tr.deps[k] {
+		for kk := range tr.tpls {
+			if kk == dep || strings.HasPrefix(kk, dep+KeySep) {
+				if err := tr.render(kk, stack); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := tr.tpls[k].Execute(&b, templateContext(tr.m)); err != nil {
+		return fmt.Errorf(ErrTemplateMsg, k, err)
+	}
+
+	tr.m[k] = b.String()
+	tr.r[k] = b.String()
+	tr.state[k] = false
+
+	return nil
+}
+
+// templateContext returns the context to execute templates with.
+// It contains all the keys of m, keys containing KeySep are also accessible as nested maps
+// (e.g. "db.host" is accessible with {{.db.host}}) unless they conflict with another key.
+func templateContext(m s) map[string]interface{} {
+	var ctx = make(map[string]interface{}, len(m))
+	for k, v := range m {
+		ctx[k] = v
+	}
+
+	for k, v := range m {
+		var parts = strings.Split(k, KeySep)
+		if len(parts) < 2 {
+			continue
+		}
+
+		var cur = ctx
+		for i, p := range parts {
+			if i == len(parts)-1 {
+				if _, ok := cur[p]; !ok {
+					cur[p] = v
+				}
+				break
+			}
+
+			var next, ok = cur[p].(map[string]interface{})
+			if !ok {
+				if _, exists := cur[p]; exists {
+					// conflicts with a leaf value
+					break
+				}
+				next = make(map[string]interface{})
+				cur[p] = next
+			}
+			cur = next
+		}
+	}
+
+	return ctx
+}
+
+// templateFields returns the keys referenced by the field nodes in the template tree
+func templateFields(node parse.Node, fields []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return fields
+		}
+		for _, nn := range n.Nodes {
+			fields = templateFields(nn, fields)
+		}
+	case *parse.ActionNode:
+		fields = templateFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return fields
+		}
+		for _, cmd := range n.Cmds {
+			fields = templateFields(cmd, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			fields = templateFields(arg, fields)
+		}
+	case *parse.ChainNode:
+		fields = templateFields(n.Node, fields)
+	case *parse.FieldNode:
+		fields = append(fields, strings.Join(n.Ident, KeySep))
+	case *parse.IfNode:
+		fields = templateBranchFields(&n.BranchNode, fields)
+	case *parse.RangeNode:
+		fields = templateBranchFields(&n.BranchNode, fields)
+	case *parse.WithNode:
+		fields = templateBranchFields(&n.BranchNode, fields)
+	case *parse.TemplateNode:
+		fields = templateFields(n.Pipe, fields)
+	}
+	return fields
+}
+
+func templateBranchFields(n *parse.BranchNode, fields []string) []string {
+	fields = templateFields(n.Pipe, fields)
+	fields = templateFields(n.List, fields)
+	return templateFields(n.ElseList, fields)
+}
@@ -0,0 +1,157 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func templateConfig() *Config {
+	var cfg = DefaultConfig()
+	cfg.Templates = true
+	return cfg
+}
+
+func TestTemplates(t *testing.T) {
+	t.Run(
+		"render templates",
+		func(t *testing.T) {
+			reset()
+			Init(templateConfig())
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"host", "localhost"},
+						{"port", "8080"},
+						{"db.host", "db.local"},
+						{"url", "https://{{.host}}:{{.port}}"},
+						{"db.url", "postgres://{{.db.host}}"},
+						{"api", "{{.url}}/api"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "https://localhost:8080", MustString("url"))
+			require.Equal(t, "postgres://db.local", MustString("db.url"))
+			require.Equal(t, "https://localhost:8080/api", MustString("api"))
+		},
+	)
+
+	t.Run(
+		"disabled by default",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"host", "localhost"},
+						{"url", "https://{{.host}}"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "https://{{.host}}", MustString("url"))
+		},
+	)
+
+	t.Run(
+		"re-rendered on reload",
+		func(t *testing.T) {
+			reset()
+			Init(templateConfig())
+
+			var l = &DummyLoader{
+				DataToLoad: [][2]string{
+					{"host", "localhost"},
+				},
+			}
+			RegisterLoader(l)
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"url", "https://{{.host}}"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "https://localhost", MustString("url"))
+
+			l.DataToLoad[0][1] = "example.com"
+			require.Nil(t, Load())
+			require.Equal(t, "https://example.com", MustString("url"))
+		},
+	)
+
+	t.Run(
+		"cyclic references",
+		func(t *testing.T) {
+			reset()
+			Init(templateConfig())
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"a", "{{.b}}"},
+						{"b", "{{.a}}"},
+					},
+				},
+			)
+
+			var err = Load()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "Err cyclic template reference")
+			require.Nil(t, Get("a"))
+		},
+	)
+
+	t.Run(
+		"missing key",
+		func(t *testing.T) {
+			reset()
+			Init(templateConfig())
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"url", "https://{{.host}}"},
+					},
+				},
+			)
+
+			var err = Load()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "Err template for key 'url'")
+		},
+	)
+
+	t.Run(
+		"bound value",
+		func(t *testing.T) {
+			type Config struct {
+				URL string `konfig:"url"`
+			}
+
+			reset()
+			Init(templateConfig())
+			Bind(Config{})
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"host", "localhost"},
+						{"url", "https://{{.host}}"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, "https://localhost", Value().(Config).URL)
+		},
+	)
+}
@@ -0,0 +1,64 @@
+package konfig
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPEMMsg is the error message returned when a value does not contain the expected PEM data
+	ErrPEMMsg = "Err value of key '%s' does not contain PEM %s data"
+	// ErrTLSCertificateMsg is the error message returned when a certificate and its key cannot be parsed into a key pair
+	ErrTLSCertificateMsg = "Err certificate '%s' and key '%s' are not a valid key pair: %v"
+)
+
+// TLSCertificate parses the PEM encoded certificate and private key at the keys certKey and keyKey of the global store.
+// See Store.TLSCertificate.
+func TLSCertificate(certKey, keyKey string) (tls.Certificate, error) {
+	return instance().TLSCertificate(certKey, keyKey)
+}
+
+// TLSCertificate parses the PEM encoded certificate and private key at the keys certKey and keyKey into a tls.Certificate.
+// Values can be strings or []byte. The error names the key whose value is missing or malformed.
+func (c *store) TLSCertificate(certKey, keyKey string) (tls.Certificate, error) {
+	var certPEM, err = c.pemValue(certKey, "CERTIFICATE")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err := c.pemValue(keyKey, "PRIVATE KEY")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf(ErrTLSCertificateMsg, certKey, keyKey, err)
+	}
+
+	return cert, nil
+}
+
+// pemValue returns the value at the key k if it contains a PEM block whose type ends with typ
+func (c *store) pemValue(k string, typ string) ([]byte, error) {
+	var m = c.m.Load().(s)
+	var v, ok = m[k]
+	if !ok {
+		return nil, fmt.Errorf(ErrConfigNotFoundMsg, k)
+	}
+
+	var b = toBytes(v)
+	for rest := b; len(rest) > 0; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if strings.HasSuffix(block.Type, typ) {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf(ErrPEMMsg, k, typ)
+}
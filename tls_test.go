@@ -0,0 +1,120 @@
+package konfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKeyPair(t *testing.T) ([]byte, []byte) {
+	var key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	var tpl = &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "konfig"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestTLSCertificate(t *testing.T) {
+	var certPEM, keyPEM = testKeyPair(t)
+	var otherCertPEM, _ = testKeyPair(t)
+
+	var testCases = []struct {
+		name   string
+		values Values
+		err    string
+	}{
+		{
+			name: "strings",
+			values: Values{
+				"tls.cert": string(certPEM),
+				"tls.key":  string(keyPEM),
+			},
+		},
+		{
+			name: "bytes",
+			values: Values{
+				"tls.cert": certPEM,
+				"tls.key":  keyPEM,
+			},
+		},
+		{
+			name: "missing cert",
+			values: Values{
+				"tls.key": keyPEM,
+			},
+			err: "'tls.cert' not found",
+		},
+		{
+			name: "malformed cert",
+			values: Values{
+				"tls.cert": "foo",
+				"tls.key":  keyPEM,
+			},
+			err: "'tls.cert' does not contain PEM CERTIFICATE data",
+		},
+		{
+			name: "key in cert",
+			values: Values{
+				"tls.cert": keyPEM,
+				"tls.key":  keyPEM,
+			},
+			err: "'tls.cert' does not contain PEM CERTIFICATE data",
+		},
+		{
+			name: "malformed key",
+			values: Values{
+				"tls.cert": certPEM,
+				"tls.key":  certPEM,
+			},
+			err: "'tls.key' does not contain PEM PRIVATE KEY data",
+		},
+		{
+			name: "mismatched pair",
+			values: Values{
+				"tls.cert": otherCertPEM,
+				"tls.key":  keyPEM,
+			},
+			err: "certificate 'tls.cert' and key 'tls.key' are not a valid key pair",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			for k, v := range testCase.values {
+				Set(k, v)
+			}
+
+			var cert, err = TLSCertificate("tls.cert", "tls.key")
+			if testCase.err != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), testCase.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, cert.Certificate, 1)
+			require.NotNil(t, cert.PrivateKey)
+		})
+	}
+}
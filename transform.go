@@ -0,0 +1,80 @@
+package konfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ErrTransformMsg is the error message returned when a transform of a loader fails
+var ErrTransformMsg = "Err transform %d of loader '%s' failed: %v"
+
+// Transform is a function transforming the values produced by a loader.
+// It must not modify the values it receives, it must return new values instead.
+type Transform func(Values) (Values, error)
+
+// Transforms adds transforms to the loader. They run in order after each successful load of the loader,
+// before its values are added to the store, each transform receiving the values returned by the previous one.
+// If a transform fails, the load fails and the values of the loader in the store are left unchanged.
+func (cl *ConfigLoader) Transforms(transforms ...Transform) *ConfigLoader {
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	cl.loaderWatcher.transforms = append(cl.loaderWatcher.transforms, transforms...)
+
+	return cl
+}
+
+// transform runs the transforms of the loader on the values v
+func (lw *loaderWatcher) transform(v Values) (Values, error) {
+	for i, t := range lw.transforms {
+		var tv, err = t(v)
+		if err != nil {
+			return nil, fmt.Errorf(ErrTransformMsg, i, lw.Name(), err)
+		}
+		if tv == nil {
+			tv = Values{}
+		}
+		v = tv
+	}
+	return v, nil
+}
+
+// PruneMode is the definition of the empty values removed by Prune
+type PruneMode int
+
+const (
+	// PruneNil removes nil values
+	PruneNil PruneMode = iota
+	// PruneEmpty removes nil values, empty strings, and empty slices and maps
+	PruneEmpty
+)
+
+// Prune returns a transform removing the keys whose values are empty according to the mode,
+// so that blank optional values (e.g. a YAML key without a value) do not override the values of the loaders registered before.
+func Prune(mode PruneMode) Transform {
+	return func(v Values) (Values, error) {
+		var r = make(Values, len(v))
+		for k, vv := range v {
+			if !isEmpty(vv, mode) {
+				r[k] = vv
+			}
+		}
+		return r, nil
+	}
+}
+
+func isEmpty(v interface{}, mode PruneMode) bool {
+	if v == nil {
+		return true
+	}
+	if mode == PruneNil {
+		return false
+	}
+
+	var rv = reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package konfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransforms(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+
+	var lowerKeys = func(v Values) (Values, error) {
+		var r = make(Values, len(v))
+		for k, vv := range v {
+			r[strings.ToLower(k)] = vv
+		}
+		return r, nil
+	}
+	var dropNil = func(v Values) (Values, error) {
+		var r = make(Values, len(v))
+		for k, vv := range v {
+			if vv != nil {
+				r[k] = vv
+			}
+		}
+		return r, nil
+	}
+	var fail bool
+	var failing = func(v Values) (Values, error) {
+		if fail {
+			return nil, errors.New("transform failed")
+		}
+		return v, nil
+	}
+
+	var l = &MapLoader{
+		name: "l",
+		values: Values{
+			"FOO": "bar",
+			"Nil": nil,
+		},
+	}
+	c.RegisterLoader(l).Transforms(lowerKeys, dropNil).Transforms(failing)
+
+	require.Nil(t, c.Load())
+	require.Equal(t, Values{"foo": "bar"}, c.Snapshot())
+
+	// a failing transform aborts the load of the loader
+	fail = true
+	l.values = Values{"FOO": "baz"}
+	var err = c.Load()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "transform 2 of loader 'l' failed")
+	require.Equal(t, Values{"foo": "bar"}, c.Snapshot())
+}
+
+func TestPrune(t *testing.T) {
+	var v = Values{
+		"nil":      nil,
+		"str":      "",
+		"slice":    []string{},
+		"map":      map[string]interface{}{},
+		"zero":     0,
+		"false":    false,
+		"value":    "foo",
+		"nonempty": []string{"foo"},
+	}
+
+	var testCases = []struct {
+		name     string
+		mode     PruneMode
+		expected Values
+	}{
+		{
+			name: "nil",
+			mode: PruneNil,
+			expected: Values{
+				"str":      "",
+				"slice":    []string{},
+				"map":      map[string]interface{}{},
+				"zero":     0,
+				"false":    false,
+				"value":    "foo",
+				"nonempty": []string{"foo"},
+			},
+		},
+		{
+			name: "empty",
+			mode: PruneEmpty,
+			expected: Values{
+				"zero":     0,
+				"false":    false,
+				"value":    "foo",
+				"nonempty": []string{"foo"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var r, err = Prune(testCase.mode)(v)
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, r)
+		})
+	}
+}
+
+func TestPruneLayering(t *testing.T) {
+	reset()
+	var c = New(DefaultConfig())
+
+	c.RegisterLoader(&MapLoader{
+		name:   "defaults",
+		values: Values{"db.host": "localhost"},
+	})
+	c.RegisterLoader(&MapLoader{
+		name:   "yaml",
+		values: Values{"db.host": ""},
+	}).Transforms(Prune(PruneEmpty))
+
+	require.Nil(t, c.Load())
+	require.Equal(t, "localhost", c.String("db.host"))
+}
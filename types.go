@@ -0,0 +1,127 @@
+package konfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrUnsupportedType is the error thrown when registering a type for which no conversion exists
+	ErrUnsupportedType = errors.New("Unsupported type")
+	// ErrTypeMsg is the error message returned when a value cannot be converted to the type registered for its key
+	ErrTypeMsg = "Err converting key '%s' to %s: %v"
+)
+
+// typedKey is a key whose values are converted to a registered type
+type typedKey struct {
+	t    reflect.Type
+	conv func(interface{}) (interface{}, error)
+}
+
+// RegisterType registers the type of a key on the global store.
+// See Store.RegisterType.
+func RegisterType(k string, sample interface{}) Store {
+	return instance().RegisterType(k, sample)
+}
+
+// RegisterType declares the type of the values of key k with a sample value of that type (e.g. 0 for an int, []string{} for a slice of strings).
+// Values of k are converted to the type at load time and the load fails if a value cannot be converted,
+// getters of the matching type are then infallible for registered keys.
+// Supported types are bool, string, int, int32, int64, uint, uint32, uint64, float32, float64, time.Duration, time.Time,
+// []string, []int, []float64, []interface{}, map[string]string and map[string]interface{}. It panics with ErrUnsupportedType for other types.
+func (c *store) RegisterType(k string, sample interface{}) Store {
+	var conv = c.typeConverter(sample)
+	if conv == nil {
+		panic(ErrUnsupportedType)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.types == nil {
+		c.types = make(map[string]typedKey)
+	}
+	c.types[k] = typedKey{t: reflect.TypeOf(sample), conv: conv}
+
+	return c
+}
+
+// setTypes converts the values of the registered keys in m.
+// It returns the converted values.
+func (c *store) setTypes(m s) (Values, error) {
+	if len(c.types) == 0 {
+		return nil, nil
+	}
+
+	var x = make(Values, len(c.types))
+	for k, tk := range c.types {
+		var v, ok = m[k]
+		if !ok || reflect.TypeOf(v) == tk.t {
+			continue
+		}
+
+		// explicit nulls are converted to the zero value of the type
+		if v == nil {
+			var zv = reflect.Zero(tk.t).Interface()
+			m[k] = zv
+			x[k] = zv
+			continue
+		}
+
+		var cv, err = tk.conv(v)
+		if err != nil {
+			return nil, fmt.Errorf(ErrTypeMsg, k, tk.t, err)
+		}
+		m[k] = cv
+		x[k] = cv
+	}
+
+	return x, nil
+}
+
+func (c *store) typeConverter(sample interface{}) func(interface{}) (interface{}, error) {
+	var sep = c.cfg.ListSeparator
+	switch sample.(type) {
+	case bool:
+		return func(v interface{}) (interface{}, error) { return c.bools.parse(v) }
+	case string:
+		return func(v interface{}) (interface{}, error) { return cast.ToStringE(v) }
+	case int:
+		return func(v interface{}) (interface{}, error) { return cast.ToIntE(v) }
+	case int32:
+		return func(v interface{}) (interface{}, error) { return cast.ToInt32E(v) }
+	case int64:
+		return func(v interface{}) (interface{}, error) { return cast.ToInt64E(v) }
+	case uint:
+		return func(v interface{}) (interface{}, error) { return cast.ToUintE(v) }
+	case uint32:
+		return func(v interface{}) (interface{}, error) { return cast.ToUint32E(v) }
+	case uint64:
+		return func(v interface{}) (interface{}, error) { return cast.ToUint64E(v) }
+	case float32:
+		return func(v interface{}) (interface{}, error) { return cast.ToFloat32E(v) }
+	case float64:
+		return func(v interface{}) (interface{}, error) { return cast.ToFloat64E(v) }
+	case time.Duration:
+		return func(v interface{}) (interface{}, error) { return cast.ToDurationE(v) }
+	case time.Time:
+		return func(v interface{}) (interface{}, error) { return cast.ToTimeE(v) }
+	case []string:
+		return func(v interface{}) (interface{}, error) { return toStringSlice(v, sep) }
+	case []int:
+		return func(v interface{}) (interface{}, error) { return toIntSlice(v, sep) }
+	case []float64:
+		return func(v interface{}) (interface{}, error) { return toFloatSlice(v, sep) }
+	case []interface{}:
+		return func(v interface{}) (interface{}, error) { return cast.ToSliceE(v) }
+	case map[string]string:
+		return func(v interface{}) (interface{}, error) { return cast.ToStringMapStringE(v) }
+	case map[string]interface{}:
+		return func(v interface{}) (interface{}, error) { return cast.ToStringMapE(v) }
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterType(t *testing.T) {
+	t.Run(
+		"values converted at load",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterType("db.port", 0)
+			RegisterType("timeout", time.Duration(0))
+			RegisterType("features", []string{})
+			RegisterType("debug", false)
+
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"db.port", "5432"},
+						{"timeout", "5s"},
+						{"features", "a b"},
+						{"debug", "true"},
+						{"name", "konfig"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, 5432, Get("db.port"))
+			require.Equal(t, 5*time.Second, Get("timeout"))
+			require.Equal(t, []string{"a", "b"}, Get("features"))
+			require.Equal(t, true, Get("debug"))
+			require.Equal(t, "konfig", Get("name"))
+		},
+	)
+
+	t.Run(
+		"conversion error",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterType("db.port", 0)
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"db.port", "not a port"},
+					},
+				},
+			)
+
+			var err = Load()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "Err converting key 'db.port' to int")
+			require.False(t, Exists("db.port"))
+		},
+	)
+
+	t.Run(
+		"bound value",
+		func(t *testing.T) {
+			type Config struct {
+				Port int `konfig:"port"`
+			}
+
+			reset()
+			Init(DefaultConfig())
+			Bind(Config{})
+
+			RegisterType("port", 0)
+			RegisterLoader(
+				&DummyLoader{
+					DataToLoad: [][2]string{
+						{"port", "8080"},
+					},
+				},
+			)
+
+			require.Nil(t, Load())
+			require.Equal(t, 8080, Value().(Config).Port)
+		},
+	)
+
+	t.Run(
+		"unsupported type panics",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			require.PanicsWithValue(t, ErrUnsupportedType, func() {
+				RegisterType("foo", struct{}{})
+			})
+		},
+	)
+}
@@ -0,0 +1,77 @@
+package konfig
+
+// UnregisterLoader removes the loader with the given name from the global store.
+// See Store.UnregisterLoader.
+func UnregisterLoader(name string) error {
+	return instance().UnregisterLoader(name)
+}
+
+// UnregisterLoader removes the loader with the given name from the store and closes its watcher,
+// if no loader with the given name is registered in the store it returns ErrLoaderNotFound.
+// It waits for the in-flight load of the loader to end, the loader is not loaded anymore once it returns, even by a load cycle in progress.
+// The values of the loader are removed from the store and its keys are restored from the loaders registered before it,
+// unless KeepUnregisteredValues is set in the config of the store.
+// The returned error is the error of closing the watcher, the loader is unregistered even if it is not nil.
+// As it writes the store, it must not be called from hooks.
+func (c *store) UnregisterLoader(name string) error {
+	var wl *loaderWatcher
+	for _, owl := range c.loaders() {
+		if owl.Name() == name {
+			wl = owl
+			break
+		}
+	}
+	if wl == nil {
+		return ErrLoaderNotFound
+	}
+
+	// we wait for the current load of the loader
+	wl.loadMut.Lock()
+	defer wl.loadMut.Unlock()
+
+	if wl.unregistered {
+		return ErrLoaderNotFound
+	}
+	wl.unregistered = true
+
+	// values are removed while the loader is still registered so that the keys are restored from the previous loaders
+	if !c.cfg.KeepUnregisteredValues {
+		wl.changedKeys = nil
+		c.unloadLoader(wl)
+		c.sendEvent(wl, nil)
+	}
+
+	c.mut.Lock()
+	c.stateMut.Lock()
+	// we copy the slices so that load cycles in progress keep iterating over the previous ones
+	var wls = make([]*loaderWatcher, 0, len(c.WatcherLoaders))
+	for _, owl := range c.WatcherLoaders {
+		if owl != wl {
+			wls = append(wls, owl)
+		}
+	}
+	c.WatcherLoaders = wls
+
+	var watcher, hasWatcher = wl.Watcher.(LoaderWatcher)
+	if hasWatcher {
+		var closers = make(Closers, 0, len(c.WatcherClosers))
+		for _, closer := range c.WatcherClosers {
+			if closer != watcher {
+				closers = append(closers, closer)
+			}
+		}
+		c.WatcherClosers = closers
+	}
+	c.stateMut.Unlock()
+	c.mut.Unlock()
+
+	if c.cfg.Metrics {
+		c.deleteMetrics(wl)
+	}
+
+	// closing the watcher stops the watch goroutine of the loader
+	if hasWatcher {
+		return watcher.Close()
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package konfig
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type BlockingLoader struct {
+	MapLoader
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *BlockingLoader) Load(v Values) error {
+	close(b.started)
+	<-b.release
+	return b.MapLoader.Load(v)
+}
+
+func TestUnregisterLoader(t *testing.T) {
+	t.Run(
+		"removes the values of the loader",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "a", "bar": 1}})
+			RegisterLoader(&MapLoader{name: "b", values: Values{"foo": "b", "baz": 2}})
+
+			require.Nil(t, Load())
+			require.Equal(t, "b", Get("foo"))
+
+			require.Nil(t, UnregisterLoader("b"))
+			require.Equal(t, "a", Get("foo"))
+			require.Equal(t, 1, Get("bar"))
+			require.False(t, Exists("baz"))
+			require.Len(t, instance().WatcherLoaders, 1)
+			require.Equal(t, []LayerValue{{Loader: "a", Value: "a", Effective: true}}, Explain("foo"))
+
+			require.Equal(t, ErrLoaderNotFound, UnregisterLoader("b"))
+			require.Equal(t, ErrLoaderNotFound, ReloadLoader("b"))
+			require.Nil(t, Reload())
+			require.False(t, Exists("baz"))
+		},
+	)
+
+	t.Run(
+		"keeps the values of the loader",
+		func(t *testing.T) {
+			reset()
+			Init(&Config{KeepUnregisteredValues: true})
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "a"}})
+			RegisterLoader(&MapLoader{name: "b", values: Values{"foo": "b", "baz": 2}})
+
+			require.Nil(t, Load())
+			require.Nil(t, UnregisterLoader("b"))
+			require.Equal(t, "b", Get("foo"))
+			require.Equal(t, 2, Get("baz"))
+		},
+	)
+
+	t.Run(
+		"closes the watcher",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reset()
+			Init(DefaultConfig())
+
+			var done = make(chan struct{})
+			var mockW = NewMockWatcher(ctrl)
+			mockW.EXPECT().Start().Return(nil)
+			mockW.EXPECT().Done().AnyTimes().Return(done)
+			mockW.EXPECT().Watch().AnyTimes().Return(nil)
+			mockW.EXPECT().Err().AnyTimes().Return(nil)
+			mockW.EXPECT().Close().DoAndReturn(func() error {
+				close(done)
+				return nil
+			})
+
+			var mockL = NewMockLoader(ctrl)
+			mockL.EXPECT().Name().AnyTimes().Return("debug")
+			mockL.EXPECT().Load(gomock.Any()).Return(nil)
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "a"}})
+			RegisterLoaderWatcher(NewLoaderWatcher(mockL, mockW))
+
+			require.Nil(t, LoadWatch())
+			require.Nil(t, UnregisterLoader("debug"))
+			require.Len(t, instance().WatcherLoaders, 1)
+			require.Len(t, instance().WatcherClosers, 0)
+		},
+	)
+
+	t.Run(
+		"waits for the in-flight load",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			var l = &BlockingLoader{
+				MapLoader: MapLoader{name: "b", values: Values{"foo": "b"}},
+				started:   make(chan struct{}),
+				release:   make(chan struct{}),
+			}
+			RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "a"}})
+			RegisterLoader(l)
+
+			var loaded = make(chan error)
+			go func() {
+				loaded <- Load()
+			}()
+			<-l.started
+
+			var unregistered = make(chan error)
+			go func() {
+				unregistered <- UnregisterLoader("b")
+			}()
+
+			select {
+			case <-unregistered:
+				t.Fatal("unregistered during the load of the loader")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(l.release)
+			require.Nil(t, <-loaded)
+			require.Nil(t, <-unregistered)
+			require.Equal(t, "a", Get("foo"))
+		},
+	)
+	t.Run(
+		"concurrently with loads and health checks",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+
+			RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "a"}})
+			require.Nil(t, Load())
+
+			var wg, started sync.WaitGroup
+			var done = make(chan struct{})
+			for _, f := range []func(){
+				func() { _ = Load() },
+				func() { _ = Reload() },
+				func() { _ = Health() },
+				func() { _ = Degraded() },
+				func() { _ = Stale() },
+			} {
+				wg.Add(1)
+				started.Add(1)
+				go func(f func()) {
+					defer wg.Done()
+					f()
+					started.Done()
+					for {
+						select {
+						case <-done:
+							return
+						default:
+							f()
+						}
+					}
+				}(f)
+			}
+			started.Wait()
+
+			for i := 0; i < 100; i++ {
+				var name = fmt.Sprintf("b%d", i)
+				RegisterLoader(&MapLoader{name: name, values: Values{"foo": name}})
+				require.Nil(t, UnregisterLoader(name))
+			}
+			close(done)
+			wg.Wait()
+
+			require.Equal(t, "a", Get("foo"))
+			require.Len(t, instance().WatcherLoaders, 1)
+		},
+	)
+}
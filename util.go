@@ -2,6 +2,7 @@ package konfig
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/spf13/cast"
@@ -19,6 +20,19 @@ func (c *store) Exists(k string) bool {
 	return ok
 }
 
+// Snapshot returns a copy of all the values currently set in the global store
+func Snapshot() Values {
+	return instance().Snapshot()
+}
+func (c *store) Snapshot() Values {
+	var m = c.m.Load().(s)
+	var v = make(Values, len(m))
+	for kk, vv := range m {
+		v[kk] = vv
+	}
+	return v
+}
+
 // Get will return the value in config with given key k
 // If not value is found, Get it returns nil
 func Get(k string) interface{} {
@@ -31,6 +45,12 @@ func MustGet(k string) interface{} {
 	return instance().MustGet(k)
 }
 
+// Lookup returns the value in config with given key k and wether it is set in the global store.
+// See Store.Lookup.
+func Lookup(k string) (interface{}, bool) {
+	return instance().Lookup(k)
+}
+
 // Set will set the key value to the sync.Map
 func Set(k string, v interface{}) {
 	instance().Set(k, v)
@@ -41,20 +61,73 @@ func (c *store) Set(k string, v interface{}) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
+	c.set(k, v)
+}
+
+// CompareAndSet sets the key k to the value nv in the global store if its current value equals ov.
+// See Store.CompareAndSet.
+func CompareAndSet(k string, ov, nv interface{}) bool {
+	return instance().CompareAndSet(k, ov, nv)
+}
+
+// CompareAndSet atomically sets the key k to the value nv if its current value equals ov, compared with reflect.DeepEqual,
+// and returns wether the value was set. If ov is nil, the value is set only if the key is not set.
+// The comparison and the write happen under the store lock, so they are atomic with regards to Set, other calls to CompareAndSet and loads.
+// A loader loading the key later overwrites it like it overwrites a value set with Set,
+// a CompareAndSet following such a load fails unless ov equals the loaded value.
+// As it writes the store, it must not be called from hooks or WatchField callbacks, they run with the store locked:
+// to write back state derived from a reload, call it from a goroutine started by the hook.
+func (c *store) CompareAndSet(k string, ov, nv interface{}) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
 	var m = c.m.Load().(s)
+	var cv, ok = m[k]
+	if ov == nil {
+		if ok {
+			return false
+		}
+	} else if !ok || !reflect.DeepEqual(cv, ov) {
+		return false
+	}
+
+	c.set(k, nv)
+	return true
+}
 
-	var nm = make(s)
+// set sets the value v to the key k and its aliases, it must be called with the store mutex locked
+func (c *store) set(k string, v interface{}) {
+	var m = c.m.Load().(s)
+
+	var nm = make(s, len(m)+1)
 	for kk, vv := range m {
 		nm[kk] = vv
 	}
-	nm[k] = v
 
-	// if there is a value bound we set it there also
+	for _, ak := range c.aliasKeys(k) {
+		nm[ak] = v
+
+		// the key is not owned by its last loader anymore
+		c.stateMut.Lock()
+		c.sources[ak] = provenance{at: time.Now()}
+		if ov, ok := m[ak]; !ok || !reflect.DeepEqual(ov, v) {
+			c.changed[ak] = time.Now()
+		}
+		c.stateMut.Unlock()
+		delete(c.templates, ak)
+		delete(c.refs, ak)
+
+		// if there is a value bound we set it there also
+		if c.v != nil {
+			c.v.set(ak, v)
+		}
+	}
 	if c.v != nil {
-		c.v.set(k, v)
+		c.v.publish()
 	}
 
 	c.m.Store(nm)
+	c.notifyUpdated()
 }
 
 // Get gets a value from config
@@ -66,6 +139,14 @@ func (c *store) Get(k string) interface{} {
 	return nil
 }
 
+// Lookup gets a value from config and tells wether the key is set,
+// a key set to an explicit null, such as `key: null` in YAML, returns nil and true.
+func (c *store) Lookup(k string) (interface{}, bool) {
+	var m = c.m.Load().(s)
+	var v, ok = m[k]
+	return v, ok
+}
+
 // MustGet gets a value from config and panics if the value does not exist
 func (c *store) MustGet(k string) interface{} {
 	var m = c.m.Load().(s)
@@ -138,17 +219,19 @@ func MustBool(k string) bool {
 }
 
 func (c *store) MustBool(k string) bool {
-	return cast.ToBool(c.MustGet(k))
+	var b, _ = c.bools.parse(c.MustGet(k))
+	return b
 }
 
-// Bool gets the config k and converts it to a bool.
-// It returns the zero value if it doesn't find the config.
+// Bool gets the config k and converts it to a bool, strings are parsed with the BoolTokens of the config.
+// It returns the zero value if it doesn't find the config or if the value is not a boolean.
 func Bool(k string) bool {
 	return instance().Bool(k)
 }
 
 func (c *store) Bool(k string) bool {
-	return cast.ToBool(c.Get(k))
+	var b, _ = c.bools.parse(c.Get(k))
+	return b
 }
 
 // MustDuration gets the config k and tries to convert it to a duration
@@ -197,16 +280,19 @@ func MustStringSlice(k string) []string {
 	return instance().MustStringSlice(k)
 }
 func (c *store) MustStringSlice(k string) []string {
-	return cast.ToStringSlice(c.MustGet(k))
+	var ss, _ = toStringSlice(c.MustGet(k), c.cfg.ListSeparator)
+	return ss
 }
 
 // StringSlice gets the config k and converts it to a []string.
+// Strings are split on the ListSeparator of the config if it is set, on white spaces otherwise.
 // It returns the zero value if it doesn't find the config.
 func StringSlice(k string) []string {
 	return instance().StringSlice(k)
 }
 func (c *store) StringSlice(k string) []string {
-	return cast.ToStringSlice(c.Get(k))
+	var ss, _ = toStringSlice(c.Get(k), c.cfg.ListSeparator)
+	return ss
 }
 
 // MustIntSlice gets the config k and tries to convert it to a []int
@@ -215,16 +301,18 @@ func MustIntSlice(k string) []int {
 	return instance().MustIntSlice(k)
 }
 func (c *store) MustIntSlice(k string) []int {
-	return cast.ToIntSlice(c.MustGet(k))
+	var i, _ = toIntSlice(c.MustGet(k), c.cfg.ListSeparator)
+	return i
 }
 
 // IntSlice gets the config k and converts it to a []int.
-// it returns the zero value if it doesn't find the config.
+// Strings are split on the ListSeparator of the config, or on commas if it is not set, it returns the zero value if it doesn't find the config or if an element cannot be converted.
 func IntSlice(k string) []int {
 	return instance().IntSlice(k)
 }
 func (c *store) IntSlice(k string) []int {
-	return cast.ToIntSlice(c.Get(k))
+	var i, _ = toIntSlice(c.Get(k), c.cfg.ListSeparator)
+	return i
 }
 
 // MustStringMap gets the config k and tries to convert it to a map[string]interface{}
@@ -262,3 +350,34 @@ func StringMapString(k string) map[string]string {
 func (c *store) StringMapString(k string) map[string]string {
 	return cast.ToStringMapString(c.Get(k))
 }
+
+// MustBytes gets the config k and tries to convert it to a []byte
+// it panics if it fails.
+func MustBytes(k string) []byte {
+	return instance().MustBytes(k)
+}
+func (c *store) MustBytes(k string) []byte {
+	return toBytes(c.MustGet(k))
+}
+
+// Bytes gets the config k and converts it to a []byte.
+// []byte values are returned as is, other values are converted to strings first.
+// It returns the zero value if it doesn't find the config.
+func Bytes(k string) []byte {
+	return instance().Bytes(k)
+}
+func (c *store) Bytes(k string) []byte {
+	return toBytes(c.Get(k))
+}
+
+func toBytes(v interface{}) []byte {
+	switch vt := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return vt
+	case string:
+		return []byte(vt)
+	}
+	return []byte(cast.ToString(v))
+}
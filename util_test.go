@@ -1,6 +1,7 @@
 package konfig
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -199,6 +200,33 @@ func TestUtils(t *testing.T) {
 				require.Panics(t, func() { MustStringSlice("foo") })
 			},
 		},
+		{
+			name: "BytesSuccess",
+			test: func(t *testing.T) {
+				Set("foo", []byte{0xff, 0x00, 0xfe})
+				var b = Bytes("foo")
+				require.Equal(t, []byte{0xff, 0x00, 0xfe}, b)
+
+				Set("foo", "bar")
+				b = Bytes("foo")
+				require.Equal(t, []byte("bar"), b)
+			},
+		},
+		{
+			name: "MustBytesSuccess",
+			test: func(t *testing.T) {
+				Set("foo", []byte{0xff})
+				var b []byte
+				require.NotPanics(t, func() { b = MustBytes("foo") })
+				require.Equal(t, []byte{0xff}, b)
+			},
+		},
+		{
+			name: "MustBytesPanics",
+			test: func(t *testing.T) {
+				require.Panics(t, func() { MustBytes("foo") })
+			},
+		},
 		{
 			name: "IntSliceSuccess",
 			test: func(t *testing.T) {
@@ -276,6 +304,27 @@ func TestUtils(t *testing.T) {
 				require.False(t, Exists("foo"))
 			},
 		},
+		{
+			name: "CompareAndSet",
+			test: func(t *testing.T) {
+				require.False(t, CompareAndSet("foo", 1, 2))
+				require.False(t, Exists("foo"))
+
+				require.True(t, CompareAndSet("foo", nil, 1))
+				require.Equal(t, 1, MustGet("foo"))
+				require.False(t, CompareAndSet("foo", nil, 2))
+
+				require.False(t, CompareAndSet("foo", 2, 3))
+				require.Equal(t, 1, MustGet("foo"))
+
+				require.True(t, CompareAndSet("foo", 1, 2))
+				require.Equal(t, 2, MustGet("foo"))
+
+				Set("list", []string{"a"})
+				require.True(t, CompareAndSet("list", []string{"a"}, []string{"a", "b"}))
+				require.Equal(t, []string{"a", "b"}, MustGet("list"))
+			},
+		},
 	}
 	for _, testCase := range testCases {
 
@@ -285,3 +334,139 @@ func TestUtils(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareAndSetConcurrent(t *testing.T) {
+	reset()
+	Set("counter", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for {
+					var v = MustInt("counter")
+					if CompareAndSet("counter", v, v+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 1000, MustInt("counter"))
+}
+
+func TestExplicitNull(t *testing.T) {
+	type Config struct {
+		Name    string        `konfig:"name"`
+		Timeout time.Duration `konfig:"timeout"`
+		Any     interface{}   `konfig:"any"`
+		Hosts   []string      `konfig:"hosts"`
+	}
+
+	reset()
+	Init(DefaultConfig())
+	Bind(Config{Name: "default"})
+	RegisterType("timeout", time.Duration(0))
+
+	var l = &MapLoader{name: "yaml", values: Values{
+		"name":    nil,
+		"timeout": nil,
+		"any":     nil,
+		"hosts":   nil,
+		"ports":   nil,
+	}}
+	RegisterLoader(l)
+	require.Nil(t, Load())
+
+	var v, ok = Lookup("name")
+	require.True(t, ok)
+	require.Nil(t, v)
+	_, ok = Lookup("nope")
+	require.False(t, ok)
+	require.True(t, Exists("name"))
+	require.Equal(t, time.Duration(0), Get("timeout"))
+	require.Equal(t, Config{}, Value())
+
+	var getters = []struct {
+		name string
+		get  func(k string) interface{}
+		zero interface{}
+	}{
+		{"String", func(k string) interface{} { return String(k) }, ""},
+		{"Int", func(k string) interface{} { return Int(k) }, 0},
+		{"Float", func(k string) interface{} { return Float(k) }, float64(0)},
+		{"Bool", func(k string) interface{} { return Bool(k) }, false},
+		{"Duration", func(k string) interface{} { return Duration(k) }, time.Duration(0)},
+		{"Time", func(k string) interface{} { return Time(k) }, time.Time{}},
+		{"StringSlice", func(k string) interface{} { return StringSlice(k) }, []string(nil)},
+		{"IntSlice", func(k string) interface{} { return IntSlice(k) }, []int(nil)},
+		{"FloatSlice", func(k string) interface{} { return FloatSlice(k) }, []float64(nil)},
+		{"StringMap", func(k string) interface{} { return StringMap(k) }, map[string]interface{}{}},
+		{"StringMapString", func(k string) interface{} { return StringMapString(k) }, map[string]string{}},
+		{"Bytes", func(k string) interface{} { return Bytes(k) }, []byte(nil)},
+		{"ByteSize", func(k string) interface{} { return ByteSize(k) }, int64(0)},
+	}
+	var mustGetters = []struct {
+		name string
+		get  func(k string) interface{}
+	}{
+		{"MustGet", func(k string) interface{} { return MustGet(k) }},
+		{"MustString", func(k string) interface{} { return MustString(k) }},
+		{"MustInt", func(k string) interface{} { return MustInt(k) }},
+		{"MustFloat", func(k string) interface{} { return MustFloat(k) }},
+		{"MustBool", func(k string) interface{} { return MustBool(k) }},
+		{"MustDuration", func(k string) interface{} { return MustDuration(k) }},
+		{"MustTime", func(k string) interface{} { return MustTime(k) }},
+		{"MustStringSlice", func(k string) interface{} { return MustStringSlice(k) }},
+		{"MustIntSlice", func(k string) interface{} { return MustIntSlice(k) }},
+		{"MustFloatSlice", func(k string) interface{} { return MustFloatSlice(k) }},
+		{"MustStringMap", func(k string) interface{} { return MustStringMap(k) }},
+		{"MustStringMapString", func(k string) interface{} { return MustStringMapString(k) }},
+		{"MustBytes", func(k string) interface{} { return MustBytes(k) }},
+		{"MustByteSize", func(k string) interface{} { return MustByteSize(k) }},
+	}
+	var requireGetters = []struct {
+		name string
+		get  func(k string) (interface{}, error)
+	}{
+		{"Require", func(k string) (interface{}, error) { return Require(k) }},
+		{"RequireString", func(k string) (interface{}, error) { return RequireString(k) }},
+		{"RequireInt", func(k string) (interface{}, error) { return RequireInt(k) }},
+		{"RequireFloat", func(k string) (interface{}, error) { return RequireFloat(k) }},
+		{"RequireBool", func(k string) (interface{}, error) { return RequireBool(k) }},
+		{"RequireDuration", func(k string) (interface{}, error) { return RequireDuration(k) }},
+		{"RequireByteSize", func(k string) (interface{}, error) { return RequireByteSize(k) }},
+		{"RequireIntSlice", func(k string) (interface{}, error) { return RequireIntSlice(k) }},
+		{"RequireFloatSlice", func(k string) (interface{}, error) { return RequireFloatSlice(k) }},
+	}
+
+	for _, getter := range getters {
+		t.Run(getter.name, func(t *testing.T) {
+			require.Equal(t, getter.zero, getter.get("ports"), "explicit null")
+			require.Equal(t, getter.zero, getter.get("nope"), "absent key")
+		})
+	}
+	for _, getter := range mustGetters {
+		t.Run(getter.name, func(t *testing.T) {
+			require.NotPanics(t, func() { getter.get("ports") }, "explicit null")
+			require.Panics(t, func() { getter.get("nope") }, "absent key")
+		})
+	}
+	for _, getter := range requireGetters {
+		t.Run(getter.name, func(t *testing.T) {
+			var _, err = getter.get("ports")
+			require.Nil(t, err, "explicit null")
+			_, err = getter.get("nope")
+			require.NotNil(t, err, "absent key")
+		})
+	}
+
+	// keys removed after being null are reset in the bound value
+	l.values = Values{"name": "app"}
+	require.Nil(t, Load())
+	require.Equal(t, Config{Name: "app"}, Value())
+}
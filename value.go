@@ -26,11 +26,15 @@ var (
 )
 
 type value struct {
-	s     *store
-	v     *atomic.Value
+	s *store
+	v *atomic.Value
+	// snap is the bound value published after each load or Set, once all its updates are applied
+	snap  *atomic.Value
 	vt    reflect.Type
 	mut   *sync.Mutex
 	isMap bool
+	// fieldWatchers are called when their field changes between two snapshots, they are guarded by the store mutex
+	fieldWatchers []fieldWatcher
 }
 
 // Value returns the value bound to the root config store
@@ -48,6 +52,23 @@ func (c *store) Value() interface{} {
 	return c.v.v.Load()
 }
 
+// BoundSnapshot returns a consistent snapshot of the value bound to the root config store
+func BoundSnapshot() interface{} {
+	return instance().BoundSnapshot()
+}
+
+// BoundSnapshot returns a snapshot of the value bound to the config store, e.g. konfig.BoundSnapshot().(MyConfig).
+// Unlike Value, which is updated for each step of a load (values, templates, typed keys, derived keys),
+// the snapshot is swapped atomically once all the updates of a load, or of a Set, are applied,
+// so readers always see a consistent version of the bound value.
+// A snapshot is never modified after it is published: a bound struct is copied on each update
+// and fields which are pointers to structs are replaced by updated copies instead of being modified in place.
+// Maps and slices of the snapshot are shared with the store values and with the next snapshots, they must not be modified.
+// A bound map[string]interface{} is copied on each update the same way.
+func (c *store) BoundSnapshot() interface{} {
+	return c.v.snap.Load()
+}
+
 // Bind binds a value (either a map[string]interface{} or a struct) to the config store. When config values are set on the config store, they are also set on the bound value.
 func (c *store) Bind(v interface{}) {
 	var t = reflect.TypeOf(v)
@@ -77,9 +98,22 @@ func (c *store) Bind(v interface{}) {
 
 	val.v = &atomicValue
 
+	var snap atomic.Value
+	snap.Store(n.Interface())
+	val.snap = &snap
+
 	c.v = val
 }
 
+// publish publishes the bound value as the latest snapshot and notifies the watchers of the fields which changed
+func (val *value) publish() {
+	var ov, nv = val.snap.Load(), val.v.Load()
+	val.snap.Store(nv)
+	if len(val.fieldWatchers) > 0 {
+		val.notifyFields(ov, nv)
+	}
+}
+
 func (val *value) set(k string, v interface{}) {
 	val.mut.Lock()
 	defer val.mut.Unlock()
@@ -113,7 +147,8 @@ func (val *value) set(k string, v interface{}) {
 	val.v.Store(nVal.Elem().Interface())
 }
 
-func (val *value) setValues(ox Values, x Values) {
+// setValues sets the values xs in order in a copy of the value, removing the keys of ox which are not in xs anymore
+func (val *value) setValues(ox Values, xs ...Values) {
 	val.mut.Lock()
 	defer val.mut.Unlock()
 
@@ -123,14 +158,16 @@ func (val *value) setValues(ox Values, x Values) {
 	// store things in a map
 	if val.isMap {
 		var mapV = configValue.(map[string]interface{})
-		var nMap = make(map[string]interface{})
+		var nMap = make(map[string]interface{}, len(mapV))
 
 		for kk, vv := range mapV {
 			nMap[kk] = vv
 		}
 
-		for kk, vv := range x {
-			nMap[kk] = vv
+		for _, x := range xs {
+			for kk, vv := range x {
+				nMap[kk] = vv
+			}
 		}
 
 		val.v.Store(nMap)
@@ -144,18 +181,16 @@ func (val *value) setValues(ox Values, x Values) {
 	copier.Copy(nVal.Interface(), configValue)
 
 	// reset to zero value keys not present anymore
-	for kk, vv := range ox {
-		if _, ok := x[kk]; !ok {
-			val.setStruct(
-				kk,
-				reflect.Zero(reflect.TypeOf(vv)).Interface(),
-				nVal.Interface(),
-			)
+	for kk := range ox {
+		if !inValues(kk, xs) {
+			val.setStruct(kk, nil, nVal.Interface())
 		}
 	}
 
-	for kk, vv := range x {
-		val.setStruct(kk, vv, nVal.Interface())
+	for _, x := range xs {
+		for kk, vv := range x {
+			val.setStruct(kk, vv, nVal.Interface())
+		}
 	}
 	val.v.Store(nVal.Elem().Interface())
 }
@@ -178,7 +213,12 @@ func (val *value) setStruct(k string, v interface{}, targetValue interface{}) bo
 		if tag == k || strings.EqualFold(fieldName, k) {
 			var field = valValue.FieldByName(fieldType.Name)
 			if field.CanSet() {
-				field.Set(reflect.ValueOf(castValue(field.Interface(), v)))
+				// nil values, such as explicit nulls, set the zero value of the field
+				var cv = reflect.ValueOf(castValue(field.Interface(), v, val.s))
+				if !cv.IsValid() {
+					cv = reflect.Zero(field.Type())
+				}
+				field.Set(cv)
 			}
 			set = true
 			continue
@@ -249,12 +289,14 @@ func (val *value) setStruct(k string, v interface{}, targetValue interface{}) bo
 	return set
 }
 
-func castValue(f interface{}, v interface{}) interface{} {
+func castValue(f interface{}, v interface{}, c *store) interface{} {
+	var sep = c.cfg.ListSeparator
 	switch f.(type) {
 	case string:
 		return cast.ToString(v)
 	case bool:
-		return cast.ToBool(v)
+		var b, _ = c.bools.parse(v)
+		return b
 	case int:
 		return cast.ToInt(v)
 	case int64:
@@ -272,9 +314,14 @@ func castValue(f interface{}, v interface{}) interface{} {
 	case uint8:
 		return cast.ToUint8(v)
 	case []string:
-		return cast.ToStringSlice(v)
+		var ss, _ = toStringSlice(v, sep)
+		return ss
 	case []int:
-		return cast.ToIntSlice(v)
+		var i, _ = toIntSlice(v, sep)
+		return i
+	case []float64:
+		var f, _ = toFloatSlice(v, sep)
+		return f
 	case time.Time:
 		return cast.ToTime(v)
 	case time.Duration:
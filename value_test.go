@@ -284,9 +284,96 @@ func TestCastValue(t *testing.T) {
 		t.Run(
 			fmt.Sprintf("%T", testCase.x),
 			func(t *testing.T) {
-				var v = castValue(testCase.x, testCase.y)
+				var v = castValue(testCase.x, testCase.y, newStore(DefaultConfig()))
 				require.Equal(t, testCase.expectedV, v)
 			},
 		)
 	}
 }
+
+func TestBoundSnapshot(t *testing.T) {
+	type DBConfig struct {
+		Host string `konfig:"host"`
+	}
+	type Config struct {
+		Port    int       `konfig:"port"`
+		Address string    `konfig:"address"`
+		DB      *DBConfig `konfig:"db"`
+	}
+
+	reset()
+	Init(DefaultConfig())
+	Bind(Config{})
+	require.Equal(t, Config{}, BoundSnapshot())
+
+	RegisterDerived("address", func(v Values) interface{} {
+		return fmt.Sprintf("localhost:%v", v["port"])
+	})
+
+	var l = &MapLoader{name: "l1", values: Values{"port": 8080, "db.host": "db1"}}
+	RegisterLoader(l)
+	require.Nil(t, Load())
+
+	var snap = BoundSnapshot().(Config)
+	require.Equal(t, 8080, snap.Port)
+	require.Equal(t, "localhost:8080", snap.Address)
+	require.Equal(t, "db1", snap.DB.Host)
+
+	// published snapshots are never modified, nested structs included
+	l.values = Values{"port": 9090, "db.host": "db2"}
+	require.Nil(t, Load())
+	require.Equal(t, 8080, snap.Port)
+	require.Equal(t, "db1", snap.DB.Host)
+
+	var next = BoundSnapshot().(Config)
+	require.Equal(t, 9090, next.Port)
+	require.Equal(t, "localhost:9090", next.Address)
+	require.Equal(t, "db2", next.DB.Host)
+
+	Set("db.host", "db3")
+	require.Equal(t, "db2", next.DB.Host)
+	require.Equal(t, "db3", BoundSnapshot().(Config).DB.Host)
+}
+
+func TestBoundSnapshotConsistent(t *testing.T) {
+	type Config struct {
+		Port    int    `konfig:"port"`
+		Address string `konfig:"address"`
+	}
+
+	reset()
+	Init(DefaultConfig())
+	Bind(Config{})
+	RegisterDerived("address", func(v Values) interface{} {
+		return fmt.Sprintf("localhost:%v", v["port"])
+	})
+
+	var l = &MapLoader{name: "l1", values: Values{"port": 0}}
+	RegisterLoader(l)
+	require.Nil(t, Load())
+
+	var done = make(chan struct{})
+	var errs = make(chan error, 1)
+	go func() {
+		defer close(errs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			var snap = BoundSnapshot().(Config)
+			if snap.Address != fmt.Sprintf("localhost:%d", snap.Port) {
+				errs <- fmt.Errorf("torn snapshot %+v", snap)
+				return
+			}
+		}
+	}()
+
+	for i := 1; i < 200; i++ {
+		l.values = Values{"port": i}
+		require.Nil(t, Load())
+	}
+	close(done)
+	require.Nil(t, <-errs)
+}
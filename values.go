@@ -8,29 +8,231 @@ func (x Values) Set(k string, v interface{}) {
 	x[k] = v
 }
 
-func (x Values) load(ox Values, c *store) {
+func (x Values) load(ox Values, c *store) error {
+	return x.loadLoader(nil, ox, c)
+}
+
+// loadLoader loads the values x of the loader wl in the store over the values ox it previously loaded.
+// Keys loaded by loaders registered after wl keep their values as those loaders have precedence,
+// keys not loaded anymore by wl are restored from the last loader registered before wl loading them.
+// It also sets x as the values of wl. If wl is nil, x is loaded over ox.
+func (x Values) loadLoader(wl *loaderWatcher, ox Values, c *store) error {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
 	var m = c.m.Load().(s)
 
+	// we set the new keys of deprecated keys and the aliased keys
+	c.setAliases(x)
+	c.setAliasGroups(x)
+
+	// we keep the precedence of the other loaders
+	var lx = x
+	var restored map[string]*loaderWatcher
+	if wl != nil {
+		ox, x, restored = c.shadow(wl, ox, x)
+	}
+
 	// we copy the previous store
-	// but we omit what was on the previous values
-	var nm = make(s)
+	// but we omit what was on the previous values,
+	// the new store is pre-sized so that it does not grow while adding the new values
+	var size = len(m) - len(ox) + len(x)
+	if size < len(x) {
+		size = len(x)
+	}
+	var nm = make(s, size)
 	for kk, vv := range m {
 		if _, ok := ox[kk]; !ok {
 			nm[kk] = vv
 		}
 	}
 	// we add the new values
-	for kk, vv := range x {
-		nm[kk] = vv
+	var bx = x
+	if c.cfg.Merge == nil {
+		MergeReplace(Values(nm), x)
+	} else {
+		// the values of the loader are merged over the values of the previous loaders
+		if wl != nil {
+			for kk := range x {
+				if _, ok := ox[kk]; ok {
+					if vv, ok := c.previousValue(wl, kk); ok {
+						nm[kk] = vv
+					}
+				}
+			}
+		}
+		c.cfg.Merge(Values(nm), x)
+
+		bx = make(Values, len(x))
+		for kk := range x {
+			bx[kk] = nm[kk]
+		}
 	}
 
+	// we resolve the references
+	var rx Values
+	var refs map[string]string
+	if c.cfg.References {
+		refs = setReferences(c.refs, ox, x)
+
+		var err error
+		if rx, err = resolveReferences(nm, refs); err != nil {
+			return err
+		}
+	}
+
+	// we render the templates
+	var tx Values
+	var tpls map[string]string
+	if c.cfg.Templates {
+		tpls = setTemplates(c.templates, ox, x)
+
+		var err error
+		if tx, err = renderTemplates(nm, tpls); err != nil {
+			return err
+		}
+	}
+
+	// we convert the values of typed keys
+	var cx, err = c.setTypes(nm)
+	if err != nil {
+		return err
+	}
+
+	// we compute the derived keys
+	var dx = c.setDerived(nm)
+
 	// if there is a value bound we set it there also
 	if c.v != nil {
-		c.v.setValues(ox, x)
+		// the value is copied once for all the values
+		c.v.setValues(ox, bx, rx, tx, cx, dx)
+		c.v.publish()
 	}
 
+	if c.cfg.References {
+		c.refs = refs
+	}
+	if c.cfg.Templates {
+		c.templates = tpls
+	}
+	c.stateMut.Lock()
+	var changed = c.setChanged(m, nm, ox, x, rx, tx, cx, dx)
 	c.m.Store(nm)
+
+	if wl != nil {
+		wl.changedKeys = changed
+		c.setSources(wl, wl.values, x, restored)
+		wl.values = lx
+	}
+	c.stateMut.Unlock()
+	c.notifyUpdated()
+
+	return nil
+}
+
+// shadow returns the values to remove from the store and the values to write in the store
+// when the loader wl loads x over ox, keeping the precedence of the other loaders.
+// It also returns the loaders from which keys not loaded anymore by wl are restored.
+// ox and x are returned as is if none of their keys is loaded by the loaders registered after wl, so that large configs are not copied on each load.
+func (c *store) shadow(wl *loaderWatcher, ox Values, x Values) (Values, Values, map[string]*loaderWatcher) {
+	var wls = c.loaders()
+	var i = -1
+	for j, owl := range wls {
+		if owl == wl {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return ox, x, nil
+	}
+
+	var before, after = wls[:i], wls[i+1:]
+
+	var rox = ox
+	if shadowed(after, ox) {
+		rox = make(Values, len(ox))
+		for k, v := range ox {
+			if !loadedBy(after, k) {
+				rox[k] = v
+			}
+		}
+	}
+
+	// x is copied before being written as it is kept as the values of wl
+	var wx, copied = x, false
+	if shadowed(after, x) {
+		wx, copied = make(Values, len(x)), true
+		for k, v := range x {
+			if !loadedBy(after, k) {
+				wx[k] = v
+			}
+		}
+	}
+
+	var restored map[string]*loaderWatcher
+	for k := range rox {
+		if _, ok := x[k]; ok {
+			continue
+		}
+		for j := len(before) - 1; j >= 0; j-- {
+			if v, ok := before[j].values[k]; ok {
+				if restored == nil {
+					restored = make(map[string]*loaderWatcher)
+				}
+				if !copied {
+					wx, copied = copyValues(x), true
+				}
+				wx[k] = v
+				restored[k] = before[j]
+				break
+			}
+		}
+	}
+
+	return rox, wx, restored
+}
+
+func loadedBy(wls []*loaderWatcher, k string) bool {
+	for _, wl := range wls {
+		if _, ok := wl.values[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shadowed tells wether one of the keys of x is loaded by one of the loaders wls
+func shadowed(wls []*loaderWatcher, x Values) bool {
+	for _, wl := range wls {
+		// we range over the smallest values
+		var a, b = wl.values, x
+		if len(a) > len(b) {
+			a, b = b, a
+		}
+		for k := range a {
+			if _, ok := b[k]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func copyValues(x Values) Values {
+	var cx = make(Values, len(x))
+	for k, v := range x {
+		cx[k] = v
+	}
+	return cx
+}
+
+// inValues tells wether the key k is in one of the values xs
+func inValues(k string, xs []Values) bool {
+	for _, x := range xs {
+		if _, ok := x[k]; ok {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,87 @@
+package konfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func largeValues(n int) Values {
+	var v = make(Values, n)
+	for i := 0; i < n; i++ {
+		v["service"+strconv.Itoa(i%100)+".key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+	return v
+}
+
+// largeConfigStore returns a store loaded with a loader of 10k keys between a loader of defaults and a loader of overrides,
+// the loader of 10k keys and its loader watcher
+func largeConfigStore(tb testing.TB, overrides Values) (*store, *MapLoader, *loaderWatcher) {
+	var c = newStore(DefaultConfig())
+	var l = &MapLoader{name: "file", values: largeValues(10000)}
+	c.RegisterLoader(&MapLoader{name: "defaults", values: largeValues(1000)})
+	c.RegisterLoader(l)
+	c.RegisterLoader(&MapLoader{name: "env", values: overrides})
+	if err := c.Load(); err != nil {
+		tb.Fatal(err)
+	}
+	return c, l, c.WatcherLoaders[1]
+}
+
+// TestLoadLargeConfigAllocs checks that reloading a loader whose keys are not overridden does not copy its values,
+// the load allocates the values loaded and the new store only, so less than three copies of the store.
+func TestLoadLargeConfigAllocs(t *testing.T) {
+	var c, l, wl = largeConfigStore(t, Values{"env": "prod"})
+
+	var m = c.m.Load().(s)
+	var copyAllocs = testing.AllocsPerRun(10, func() {
+		var nm = make(s, len(m))
+		for k, v := range m {
+			nm[k] = v
+		}
+	})
+
+	var i int
+	var allocs = testing.AllocsPerRun(10, func() {
+		i++
+		l.values["service0.key0"] = i
+		if err := c.loaderLoad(context.Background(), wl); err != nil {
+			t.Fatal(err)
+		}
+	})
+	require.True(t, allocs < 3*copyAllocs, "%v allocations per load, %v per copy of the store", allocs, copyAllocs)
+}
+
+// BenchmarkLoadLargeConfig reloads a loader of 10k keys changing one key, between a loader of defaults and a loader of overrides
+func BenchmarkLoadLargeConfig(b *testing.B) {
+	var benchCases = []struct {
+		name      string
+		overrides Values
+	}{
+		{
+			name:      "no overridden keys",
+			overrides: Values{"env": "prod"},
+		},
+		{
+			name:      "overridden keys",
+			overrides: Values{"service1.key1": "env"},
+		},
+	}
+
+	for _, benchCase := range benchCases {
+		b.Run(benchCase.name, func(b *testing.B) {
+			var c, l, wl = largeConfigStore(b, benchCase.overrides)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.values["service0.key0"] = i
+				if err := c.loaderLoad(context.Background(), wl); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
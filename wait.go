@@ -0,0 +1,69 @@
+package konfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrWaitForKeysMsg is the error message returned when the context of WaitForKeys is done before all the keys are present
+	ErrWaitForKeysMsg = "Err keys missing after waiting: %s: %v"
+)
+
+// WaitForKeys blocks until all the keys are present in the global store or ctx is done.
+// See Store.WaitForKeys.
+func WaitForKeys(ctx context.Context, keys ...string) ([]string, error) {
+	return instance().WaitForKeys(ctx, keys...)
+}
+
+// WaitForKeys blocks until all the keys are present in the store, set by any loader or by Set, or until ctx is done.
+// A key is present if it is set in the store or if it prefixes a key set in the store followed by KeySep, like for ExpectKeys.
+// It returns immediately if the keys are already present. If ctx is done first, it returns the keys still missing, sorted in the order of keys,
+// and an error built with ErrWaitForKeysMsg wrapping the error of ctx.
+// It lets a subsystem started along with LoadWatch or Load running in its own goroutine wait for the keys it depends on (e.g. "db.dsn" from vault).
+func (c *store) WaitForKeys(ctx context.Context, keys ...string) ([]string, error) {
+	for {
+		c.mut.Lock()
+		var missing = missingKeys(c.m.Load().(s), keys)
+		if len(missing) == 0 {
+			c.mut.Unlock()
+			return nil, nil
+		}
+		if c.updated == nil {
+			c.updated = make(chan struct{})
+		}
+		var updated = c.updated
+		c.mut.Unlock()
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			// the keys may have been set while ctx was done
+			if missing = missingKeys(c.m.Load().(s), keys); len(missing) == 0 {
+				return nil, nil
+			}
+			return missing, fmt.Errorf(ErrWaitForKeysMsg, "'"+strings.Join(missing, "', '")+"'", ctx.Err())
+		}
+	}
+}
+
+// notifyUpdated wakes up the callers of WaitForKeys after the values of the store were updated,
+// it must be called with the store mutex locked
+func (c *store) notifyUpdated() {
+	if c.updated != nil {
+		close(c.updated)
+		c.updated = nil
+	}
+}
+
+// missingKeys returns the keys which are not present in m
+func missingKeys(m s, keys []string) []string {
+	var missing []string
+	for _, k := range keys {
+		if !keyPresent(m, k) {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
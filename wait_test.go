@@ -0,0 +1,96 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForKeys(t *testing.T) {
+	t.Run(
+		"keys already present",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			Set("db.dsn", "dsn")
+			Set("foo", "bar")
+
+			var missing, err = WaitForKeys(context.Background(), "db", "foo")
+			require.Nil(t, err)
+			require.Nil(t, missing)
+		},
+	)
+
+	t.Run(
+		"keys set by a loader and Set",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			var l = &MapLoader{name: "vault", values: Values{"db.dsn": "dsn"}}
+			RegisterLoader(l)
+
+			var done = make(chan error)
+			go func() {
+				var _, err = WaitForKeys(context.Background(), "db.dsn", "foo")
+				done <- err
+			}()
+
+			require.Nil(t, Load())
+			select {
+			case <-done:
+				t.Fatal("WaitForKeys returned before all the keys were set")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			Set("foo", "bar")
+			select {
+			case err := <-done:
+				require.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("WaitForKeys did not return")
+			}
+		},
+	)
+
+	t.Run(
+		"context done",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			Set("foo", "bar")
+
+			var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			var missing, err = WaitForKeys(ctx, "db.dsn", "foo", "api.key")
+			require.Equal(t, []string{"db.dsn", "api.key"}, missing)
+			require.Equal(t, "Err keys missing after waiting: 'db.dsn', 'api.key': context deadline exceeded", err.Error())
+		},
+	)
+
+	t.Run(
+		"group",
+		func(t *testing.T) {
+			reset()
+			Init(DefaultConfig())
+			var g = Group("g")
+
+			var done = make(chan error)
+			go func() {
+				var _, err = g.WaitForKeys(context.Background(), "foo")
+				done <- err
+			}()
+
+			Set("foo", "bar")
+			g.Set("foo", "bar")
+			select {
+			case err := <-done:
+				require.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("WaitForKeys did not return")
+			}
+		},
+	)
+}
@@ -1,5 +1,33 @@
 package konfig
 
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+const (
+	defaultWatcherRestartDelay = 1 * time.Second
+	maxWatcherRestartDelay     = 1 * time.Minute
+)
+
+// ErrWatcherFailedMsg is the message of the error returned by Health for a watcher which failed permanently
+var ErrWatcherFailedMsg = "Err watcher of loader '%s' failed: %v"
+
+// WatcherPanicError is the error recorded when a watcher goroutine panics
+type WatcherPanicError struct {
+	// Value is the value the watcher panicked with
+	Value interface{}
+	// Stack is the stack trace of the goroutine when the watcher panicked
+	Stack []byte
+}
+
+func (e *WatcherPanicError) Error() string {
+	return fmt.Sprintf("Err watcher panicked: %v", e.Value)
+}
+
 // Watcher is the interface implementing a config watcher.
 // Config watcher trigger loaders. A file watcher or a simple
 // Timer can be valid watchers.
@@ -40,6 +68,11 @@ func (NopWatcher) Start() error {
 	return nil
 }
 
+// Err implements watcher interface and always returns a nil error
+func (NopWatcher) Err() error {
+	return nil
+}
+
 // Watch starts the watchers on loaders
 func Watch() error {
 	return instance().Watch()
@@ -55,11 +88,83 @@ func (c *store) Watch() error {
 		}
 	}
 
-	for _, wl := range c.WatcherLoaders {
+	for _, wl := range c.loaders() {
+		// disabled loaders are not watched
+		if !wl.isEnabled() {
+			continue
+		}
 		if err := wl.Start(); err != nil {
 			return err
 		}
-		go c.watchLoader(wl)
+		go c.watchLoader(wl, 0)
 	}
 	return nil
 }
+
+// watcherPanic records the panic of the watcher goroutine and restarts it with backoff.
+// Once restarts are exhausted the watcher is marked as failed.
+func (c *store) watcherPanic(wl *loaderWatcher, restarts int, r interface{}) {
+	var err = &WatcherPanicError{
+		Value: r,
+		Stack: debug.Stack(),
+	}
+	c.cfg.Logger.Get().Error(err.Error())
+
+	wl.statusMut.Lock()
+	wl.lastErr = err
+	wl.failed = restarts >= c.cfg.WatcherMaxRestarts
+	var failed = wl.failed
+	wl.statusMut.Unlock()
+
+	c.reportError(err)
+
+	if failed {
+		if wl.StopOnFailure() {
+			c.stop()
+		}
+		return
+	}
+
+	time.Sleep(c.watcherRestartDelay(restarts))
+	go c.watchLoader(wl, restarts+1)
+}
+
+// watcherRestartDelay returns the delay before restarting a watcher which was restarted restarts times,
+// it uses the Backoff of the config if set
+func (c *store) watcherRestartDelay(restarts int) time.Duration {
+	if c.cfg.Backoff != nil {
+		return c.cfg.Backoff.NextDelay(restarts + 1)
+	}
+
+	var d = c.cfg.WatcherRestartDelay
+	if d == 0 {
+		d = defaultWatcherRestartDelay
+	}
+	return ExponentialBackoff{Initial: d, Max: maxWatcherRestartDelay}.NextDelay(restarts + 1)
+}
+
+// Health returns a non nil error if a watcher of the global store failed permanently.
+// See Store.Health.
+func Health() error {
+	return instance().Health()
+}
+
+// Health returns a non nil error if a watcher of the store failed permanently,
+// that is if it panicked more than WatcherMaxRestarts times, or if the store is stale (see Stale).
+// The error is a multierror.Error with an error per failed watcher and per failed staleness check.
+func (c *store) Health() error {
+	var multiErr *multierror.Error
+	for _, wl := range c.loaders() {
+		wl.statusMut.Lock()
+		var failed, err = wl.failed, wl.lastErr
+		wl.statusMut.Unlock()
+
+		if failed {
+			multiErr = multierror.Append(multiErr, fmt.Errorf(ErrWatcherFailedMsg, wl.Name(), err))
+		}
+	}
+	if err := c.staleness(); err != nil {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	return multiErr.ErrorOrNil()
+}
@@ -0,0 +1,146 @@
+// Package kwcron provides a konfig.Watcher sending events according to a cron schedule.
+package kwcron
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/nui/nlogger"
+	"github.com/robfig/cron"
+)
+
+var (
+	_ konfig.Watcher = (*CronWatcher)(nil)
+	// ErrNoSpec is the error thrown when trying to create a CronWatcher without a Spec or a Schedule
+	ErrNoSpec = errors.New("No cron spec or schedule provided")
+	// ErrAlreadyClosed is the error returned when trying to close an already closed CronWatcher
+	ErrAlreadyClosed = errors.New("CronWatcher already closed")
+	// ErrNeverFires is the error of a CronWatcher whose schedule has no next activation time (e.g. "0 0 30 2 *")
+	ErrNeverFires = errors.New("Cron schedule never fires")
+)
+
+// Config is the config of a CronWatcher
+type Config struct {
+	// Spec is a standard cron expression (e.g. "0 * * * *" for the top of every hour)
+	// or a descriptor (e.g. "@hourly", "@every 1h30m").
+	Spec string
+	// Schedule is the schedule of the watcher, if set Spec is ignored
+	Schedule cron.Schedule
+	// Location is the time zone in which the schedule is evaluated, default is time.Local
+	Location *time.Location
+	// Debug sets the debug mode
+	Debug bool
+	// Logger is the logger used to log debug messages
+	Logger nlogger.Provider
+}
+
+// CronWatcher is a konfig.Watcher sending events according to a cron schedule
+type CronWatcher struct {
+	cfg       *Config
+	err       error
+	watchChan chan struct{}
+	done      chan struct{}
+}
+
+// New creates a new CronWatcher from the given config.
+// It panics if the Spec cannot be parsed.
+func New(cfg *Config) *CronWatcher {
+	if cfg.Schedule == nil {
+		if cfg.Spec == "" {
+			panic(ErrNoSpec)
+		}
+		var schedule, err = cron.ParseStandard(cfg.Spec)
+		if err != nil {
+			panic(err)
+		}
+		cfg.Schedule = schedule
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+
+	return &CronWatcher{
+		cfg:       cfg,
+		done:      make(chan struct{}),
+		watchChan: make(chan struct{}),
+	}
+}
+
+// Done indicates wether the watcher is done or not
+func (cw *CronWatcher) Done() <-chan struct{} {
+	return cw.done
+}
+
+// Start starts the cron watcher
+func (cw *CronWatcher) Start() error {
+	go cw.watch()
+	return nil
+}
+
+// Watch returns the channel to which events are written
+func (cw *CronWatcher) Watch() <-chan struct{} {
+	return cw.watchChan
+}
+
+// Err returns the cron watcher error
+func (cw *CronWatcher) Err() error {
+	return cw.err
+}
+
+// Close closes the CronWatcher
+func (cw *CronWatcher) Close() error {
+	select {
+	case <-cw.done:
+		return ErrAlreadyClosed
+	default:
+		close(cw.done)
+	}
+	return nil
+}
+
+func (cw *CronWatcher) watch() {
+	for {
+		var now = time.Now().In(cw.cfg.Location)
+		var next = cw.cfg.Schedule.Next(now)
+
+		// the schedule never fires, we stop the watcher instead of firing continuously
+		if next.IsZero() {
+			cw.cfg.Logger.Get().Error(ErrNeverFires.Error())
+			cw.err = ErrNeverFires
+			cw.Close()
+			return
+		}
+
+		if cw.cfg.Debug {
+			cw.cfg.Logger.Get().Debug(
+				fmt.Sprintf("Next cron tick at %s", next),
+			)
+		}
+
+		var timer = time.NewTimer(next.Sub(now))
+		select {
+		case <-cw.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+			if cw.cfg.Debug {
+				cw.cfg.Logger.Get().Debug("Sending watch event")
+			}
+			select {
+			case cw.watchChan <- struct{}{}:
+			case <-cw.done:
+				return
+			}
+		}
+	}
+}
+
+func defaultLogger() nlogger.Provider {
+	return nlogger.NewProvider(nlogger.New(os.Stdout, "CRONWATCHER | "))
+}
@@ -0,0 +1,99 @@
+package kwcron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+func TestCronWatcher(t *testing.T) {
+	t.Run(
+		"panics no spec",
+		func(t *testing.T) {
+			require.Panics(t, func() {
+				New(&Config{})
+			})
+		},
+	)
+
+	t.Run(
+		"panics invalid spec",
+		func(t *testing.T) {
+			require.Panics(t, func() {
+				New(&Config{Spec: "not a cron spec"})
+			})
+		},
+	)
+
+	t.Run(
+		"parses spec",
+		func(t *testing.T) {
+			var w = New(&Config{Spec: "0 * * * *"})
+			var now = time.Date(2019, 1, 10, 10, 30, 0, 0, time.UTC)
+			require.Equal(
+				t,
+				time.Date(2019, 1, 10, 11, 0, 0, 0, time.UTC),
+				w.cfg.Schedule.Next(now),
+			)
+			require.Equal(t, time.Local, w.cfg.Location)
+		},
+	)
+
+	t.Run(
+		"sends events on schedule",
+		func(t *testing.T) {
+			var w = New(&Config{
+				Schedule: everySchedule(50 * time.Millisecond),
+				Debug:    true,
+			})
+			require.Nil(t, w.Start())
+
+			var timer = time.NewTimer(275 * time.Millisecond)
+			var watched int
+		main:
+			for {
+				select {
+				case <-timer.C:
+					require.Nil(t, w.Close())
+					break main
+				case <-w.Watch():
+					watched++
+				}
+			}
+
+			require.True(t, watched >= 3 && watched <= 5)
+			require.Equal(t, ErrAlreadyClosed, w.Close())
+			require.Nil(t, w.Err())
+
+			select {
+			case <-w.Done():
+			default:
+				t.Error("watcher should be done")
+			}
+		},
+	)
+
+	t.Run(
+		"stops if the schedule never fires",
+		func(t *testing.T) {
+			var w = New(&Config{Spec: "0 0 30 2 *"})
+			require.Nil(t, w.Start())
+
+			select {
+			case <-w.Done():
+			case <-w.Watch():
+				t.Fatal("watcher should not send events")
+			case <-time.After(time.Second):
+				t.Fatal("watcher should be done")
+			}
+			require.Equal(t, ErrNeverFires, w.Err())
+		},
+	)
+}
@@ -0,0 +1,82 @@
+package kwpoll
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const defaultAdaptiveFactor = 2
+
+var (
+	// ErrAdaptiveMinMax is the error thrown when creating an AdaptiveRater with an invalid Min or Max
+	ErrAdaptiveMinMax = errors.New("Adaptive rater Min must be positive and lower than or equal to Max")
+)
+
+// ChangeRater is a Rater notified after each poll of a diff PollWatcher wether the values changed.
+// It lets the rater adapt the duration until the next tick.
+type ChangeRater interface {
+	Rater
+	Changed(changed bool)
+}
+
+// AdaptiveConfig is the config of an AdaptiveRater
+type AdaptiveConfig struct {
+	// Min is the shortest interval, it is the initial interval and the interval after a change
+	Min time.Duration
+	// Max is the longest interval
+	Max time.Duration
+	// Factor is the factor the interval is multiplied by after a poll without change. Default is 2.
+	Factor float64
+}
+
+// AdaptiveRater is a ChangeRater which lengthens its interval by Factor, up to Max, after each poll without change
+// and resets it to Min after a change. Passed as the Rater of a diff PollWatcher, it adapts the poll rate to the volatility of the source.
+type AdaptiveRater struct {
+	cfg      *AdaptiveConfig
+	mut      sync.Mutex
+	interval time.Duration
+}
+
+// NewAdaptiveRater returns a new AdaptiveRater from the given config
+func NewAdaptiveRater(cfg *AdaptiveConfig) *AdaptiveRater {
+	if cfg.Min <= 0 || cfg.Max < cfg.Min {
+		panic(ErrAdaptiveMinMax)
+	}
+	if cfg.Factor <= 1 {
+		cfg.Factor = defaultAdaptiveFactor
+	}
+	return &AdaptiveRater{
+		cfg:      cfg,
+		interval: cfg.Min,
+	}
+}
+
+// Time implements Rater, it returns the current interval
+func (a *AdaptiveRater) Time() time.Duration {
+	return a.Interval()
+}
+
+// Interval returns the current interval
+func (a *AdaptiveRater) Interval() time.Duration {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return a.interval
+}
+
+// Changed implements ChangeRater, it resets the interval to Min if changed is true, else it lengthens it
+func (a *AdaptiveRater) Changed(changed bool) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if changed {
+		a.interval = a.cfg.Min
+		return
+	}
+
+	var next = time.Duration(float64(a.interval) * a.cfg.Factor)
+	if next > a.cfg.Max {
+		next = a.cfg.Max
+	}
+	a.interval = next
+}
@@ -0,0 +1,91 @@
+package kwpoll
+
+import (
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/lalamove/konfig"
+	"github.com/lalamove/konfig/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveRater(t *testing.T) {
+	t.Run(
+		"invalid min max",
+		func(t *testing.T) {
+			require.Panics(t, func() {
+				NewAdaptiveRater(&AdaptiveConfig{Max: time.Second})
+			})
+			require.Panics(t, func() {
+				NewAdaptiveRater(&AdaptiveConfig{Min: time.Second, Max: time.Millisecond})
+			})
+		},
+	)
+
+	t.Run(
+		"lengthens without change and resets on change",
+		func(t *testing.T) {
+			var a = NewAdaptiveRater(&AdaptiveConfig{
+				Min: time.Second,
+				Max: 5 * time.Second,
+			})
+			require.Equal(t, time.Second, a.Time())
+
+			a.Changed(false)
+			require.Equal(t, 2*time.Second, a.Interval())
+			a.Changed(false)
+			require.Equal(t, 4*time.Second, a.Interval())
+			a.Changed(false)
+			require.Equal(t, 5*time.Second, a.Interval())
+			a.Changed(false)
+			require.Equal(t, 5*time.Second, a.Interval())
+
+			a.Changed(true)
+			require.Equal(t, time.Second, a.Interval())
+		},
+	)
+
+	t.Run(
+		"custom factor",
+		func(t *testing.T) {
+			var a = NewAdaptiveRater(&AdaptiveConfig{
+				Min:    time.Second,
+				Max:    time.Minute,
+				Factor: 1.5,
+			})
+			a.Changed(false)
+			require.Equal(t, 1500*time.Millisecond, a.Interval())
+		},
+	)
+
+	t.Run(
+		"notified by diff poll watcher",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var l = mocks.NewMockLoader(ctrl)
+			l.EXPECT().Load(konfig.Values{}).AnyTimes().Do(func(v konfig.Values) {
+				v.Set("foo", "bar")
+			}).Return(nil)
+
+			var a = NewAdaptiveRater(&AdaptiveConfig{
+				Min: 10 * time.Millisecond,
+				Max: 40 * time.Millisecond,
+			})
+			var w = New(&Config{
+				Rater:     a,
+				Diff:      true,
+				Loader:    l,
+				InitValue: konfig.Values{"foo": "bar"},
+			})
+			require.Nil(t, w.Start())
+
+			time.Sleep(150 * time.Millisecond)
+			w.Close()
+
+			require.Equal(t, 40*time.Millisecond, a.Interval())
+		},
+	)
+}
@@ -0,0 +1,68 @@
+package kwpoll
+
+import (
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/spf13/cast"
+)
+
+const (
+	// DefaultIntervalKey is the default key a KeyRater reads its interval from
+	DefaultIntervalKey = "konfig.refresh_interval"
+	defaultMinInterval = time.Second
+)
+
+// KeyConfig is the config of a KeyRater
+type KeyConfig struct {
+	// Store is the store the interval is read from, default is the global store
+	Store konfig.Store
+	// Key is the key of the interval in the store, default is DefaultIntervalKey.
+	// The value is a duration string (e.g. "30s") or any value spf13/cast can convert to a time.Duration.
+	Key string
+	// Default is the interval used when the key is not set or its value is not a valid duration, default is 5 seconds
+	Default time.Duration
+	// Min is the shortest interval, shorter intervals read from the store are raised to it, default is 1 second
+	Min time.Duration
+}
+
+// KeyRater is a Rater reading its interval from a key of a konfig.Store.
+// The key is read before each tick, so reloading the store with a new interval applies it from the next tick,
+// which makes the poll rate itself hot-reloadable.
+type KeyRater struct {
+	cfg *KeyConfig
+}
+
+// NewKeyRater returns a new KeyRater from the given config
+func NewKeyRater(cfg *KeyConfig) *KeyRater {
+	if cfg.Store == nil {
+		cfg.Store = konfig.Instance()
+	}
+	if cfg.Key == "" {
+		cfg.Key = DefaultIntervalKey
+	}
+	if cfg.Default == 0 {
+		cfg.Default = defaultDuration
+	}
+	if cfg.Min == 0 {
+		cfg.Min = defaultMinInterval
+	}
+	return &KeyRater{
+		cfg: cfg,
+	}
+}
+
+// Time implements Rater, it returns the interval set in the store, or the default interval,
+// and never less than the minimum interval
+func (k *KeyRater) Time() time.Duration {
+	var d = k.cfg.Default
+	if v := k.cfg.Store.Get(k.cfg.Key); v != nil {
+		if dd, err := cast.ToDurationE(v); err == nil && dd > 0 {
+			d = dd
+		}
+	}
+	if d < k.cfg.Min {
+		d = k.cfg.Min
+	}
+	return d
+}
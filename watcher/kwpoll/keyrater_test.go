@@ -0,0 +1,81 @@
+package kwpoll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRater(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		value    interface{}
+		cfg      *KeyConfig
+		expected time.Duration
+	}{
+		{
+			name:     "key not set",
+			cfg:      &KeyConfig{},
+			expected: defaultDuration,
+		},
+		{
+			name:     "duration string",
+			value:    "30s",
+			cfg:      &KeyConfig{},
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "duration",
+			value:    2 * time.Minute,
+			cfg:      &KeyConfig{},
+			expected: 2 * time.Minute,
+		},
+		{
+			name:     "invalid value",
+			value:    "often",
+			cfg:      &KeyConfig{Default: time.Minute},
+			expected: time.Minute,
+		},
+		{
+			name:     "below minimum",
+			value:    "1ms",
+			cfg:      &KeyConfig{},
+			expected: defaultMinInterval,
+		},
+		{
+			name:     "custom minimum",
+			value:    "1ms",
+			cfg:      &KeyConfig{Min: 10 * time.Millisecond},
+			expected: 10 * time.Millisecond,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var s = konfig.New(konfig.DefaultConfig())
+			if testCase.value != nil {
+				s.Set(DefaultIntervalKey, testCase.value)
+			}
+			testCase.cfg.Store = s
+
+			require.Equal(t, testCase.expected, NewKeyRater(testCase.cfg).Time())
+		})
+	}
+}
+
+func TestKeyRaterReload(t *testing.T) {
+	var s = konfig.New(konfig.DefaultConfig())
+	var r = NewKeyRater(&KeyConfig{
+		Store: s,
+		Key:   "poll.interval",
+	})
+	require.Equal(t, defaultDuration, r.Time())
+
+	s.Set("poll.interval", "10s")
+	require.Equal(t, 10*time.Second, r.Time())
+
+	s.Set("poll.interval", "1m")
+	require.Equal(t, time.Minute, r.Time())
+}
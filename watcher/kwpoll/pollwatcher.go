@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -39,7 +40,8 @@ func (t Time) Time() time.Duration {
 
 // Config is the config of a PollWatcher
 type Config struct {
-	// Rater is the rater the PollWatcher calls to get the duration until the next tick
+	// Rater is the rater the PollWatcher calls to get the duration until the next tick.
+	// If Diff is set and the rater is a ChangeRater, it is notified after each poll wether the values changed.
 	Rater Rater
 	// Debug sets the debug mode
 	Debug bool
@@ -148,7 +150,11 @@ func (t *PollWatcher) watch() {
 					t.Close()
 					return
 				}
-				if !t.valuesEqual(v) {
+				var changed = !t.valuesEqual(v)
+				if cr, ok := t.cfg.Rater.(ChangeRater); ok {
+					cr.Changed(changed)
+				}
+				if changed {
 					if t.cfg.Debug {
 						t.cfg.Logger.Get().Debug(
 							"Value is different: " + spew.Sdump(t.pv, v) + "\n",
@@ -190,7 +196,7 @@ func (t *PollWatcher) valuesEqual(v konfig.Values) bool {
 
 	for k, x := range v {
 		if y, ok := t.pv[k]; ok {
-			if y != x {
+			if !reflect.DeepEqual(y, x) {
 				return false
 			}
 			continue
@@ -0,0 +1,186 @@
+package konfig
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherRestart(t *testing.T) {
+	t.Run(
+		"panicking watcher is restarted then marked as failed",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = New(&Config{
+				NoExitOnError:       true,
+				WatcherMaxRestarts:  2,
+				WatcherRestartDelay: time.Millisecond,
+			})
+
+			var panics int32
+			var mockW = NewMockWatcher(ctrl)
+			mockW.EXPECT().Done().AnyTimes().Return(nil)
+			mockW.EXPECT().Watch().Times(3).DoAndReturn(func() <-chan struct{} {
+				atomic.AddInt32(&panics, 1)
+				panic("boom")
+			})
+
+			var mockL = NewMockLoader(ctrl)
+			mockL.EXPECT().StopOnFailure().Return(false)
+			mockL.EXPECT().Name().Return("l")
+
+			var wl = &loaderWatcher{
+				Watcher: mockW,
+				Loader:  mockL,
+			}
+			c.(*store).WatcherLoaders = []*loaderWatcher{wl}
+
+			go c.(*store).watchLoader(wl, 0)
+
+			for i := 0; i < 3; i++ {
+				select {
+				case err := <-c.Errors():
+					var perr, ok = err.(*WatcherPanicError)
+					require.True(t, ok)
+					require.Equal(t, "boom", perr.Value)
+					require.NotEmpty(t, perr.Stack)
+				case <-time.After(time.Second):
+					t.Fatal("expected watcher panic error")
+				}
+			}
+
+			require.Equal(t, int32(3), atomic.LoadInt32(&panics))
+
+			var err = c.Health()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "watcher of loader 'l' failed")
+		},
+	)
+
+	t.Run(
+		"healthy store",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			require.Nil(t, c.Health())
+		},
+	)
+}
+
+func TestWatcherScopedReload(t *testing.T) {
+	var ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var c = New(&Config{NoExitOnError: true})
+
+	var events = make(chan struct{})
+	var reloaded = make(chan struct{})
+
+	var mockW1 = NewMockWatcher(ctrl)
+	mockW1.EXPECT().Start().Return(nil)
+	mockW1.EXPECT().Done().AnyTimes().Return(nil)
+	mockW1.EXPECT().Watch().AnyTimes().Return(events)
+
+	var mockL1 = NewMockLoader(ctrl)
+	mockL1.EXPECT().Name().AnyTimes().Return("file")
+	gomock.InOrder(
+		mockL1.EXPECT().Load(gomock.Any()).DoAndReturn(func(v Values) error {
+			v.Set("foo", "bar")
+			return nil
+		}),
+		mockL1.EXPECT().Load(gomock.Any()).DoAndReturn(func(v Values) error {
+			v.Set("foo", "baz")
+			close(reloaded)
+			return nil
+		}),
+	)
+
+	var mockW2 = NewMockWatcher(ctrl)
+	mockW2.EXPECT().Start().Return(nil)
+	mockW2.EXPECT().Done().AnyTimes().Return(nil)
+	mockW2.EXPECT().Watch().AnyTimes().Return(nil)
+
+	// the vault loader is loaded only once, the event of the file watcher does not reload it
+	var mockL2 = NewMockLoader(ctrl)
+	mockL2.EXPECT().Name().AnyTimes().Return("vault")
+	mockL2.EXPECT().Load(gomock.Any()).Times(1).DoAndReturn(func(v Values) error {
+		v.Set("secret", "s3cr3t")
+		return nil
+	})
+
+	c.RegisterLoaderWatcher(NewLoaderWatcher(mockL1, mockW1))
+	c.RegisterLoaderWatcher(NewLoaderWatcher(mockL2, mockW2))
+
+	require.Nil(t, c.LoadWatch())
+
+	events <- struct{}{}
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("expected file loader to be reloaded")
+	}
+
+	// wait for the reloaded values to be set
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, "baz", c.Get("foo"))
+	require.Equal(t, "s3cr3t", c.Get("secret"))
+}
+
+func TestWatcherRestartDelay(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		delay    time.Duration
+		restarts int
+		expected time.Duration
+	}{
+		{
+			name:     "default delay",
+			expected: time.Second,
+		},
+		{
+			name:     "doubles on each restart",
+			delay:    10 * time.Millisecond,
+			restarts: 3,
+			expected: 80 * time.Millisecond,
+		},
+		{
+			name:     "capped to one minute",
+			delay:    time.Second,
+			restarts: 20,
+			expected: time.Minute,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var c = New(&Config{WatcherRestartDelay: testCase.delay}).(*store)
+			require.Equal(t, testCase.expected, c.watcherRestartDelay(testCase.restarts))
+		})
+	}
+}
+
+func TestHealthFromHook(t *testing.T) {
+	reset()
+	Init(&Config{HookTimeout: time.Second})
+
+	RegisterLoader(&MapLoader{name: "a", values: Values{"foo": "bar"}})
+
+	var ran bool
+	var health error
+	var stale bool
+	RegisterHook(func(s Store) error {
+		ran = true
+		health = s.Health()
+		stale = s.Stale()
+		return nil
+	})
+
+	require.Nil(t, Load())
+	require.True(t, ran)
+	require.Nil(t, health)
+	require.False(t, stale)
+}